@@ -41,6 +41,11 @@ import (
 // @in header
 // @name Authorization
 // @description Type "Bearer" followed by a space and JWT token.
+
+// @securityDefinitions.apikey ApiKeyAuth
+// @in header
+// @name X-API-Key
+// @description API key of an API client, on routes open to clients.
 func main() {
 	if err := godotenv.Load(); err != nil {
 		log.Println(err.Error())
@@ -105,7 +110,22 @@ func main() {
 
 	repositories := database.NewRepositories(postgresPool, redisClient)
 
-	services := application.NewServices(repositories, cfg, redisClient, metricsService)
+	services, err := application.NewServices(repositories, cfg, redisClient, metricsService)
+	if err != nil {
+		log.Fatal(ctx, "Failed to initialize services", logger.F("error", err))
+	}
+
+	shutdownManager.AddHandler(shutdown.NewBackgroundWorkerShutdownHandler("jwt_keyring", func(ctx context.Context) error {
+		return services.Keyring.Close()
+	}))
+
+	shutdownManager.AddHandler(shutdown.NewBackgroundWorkerShutdownHandler("tenants", func(ctx context.Context) error {
+		return services.TenantService.Close()
+	}))
+
+	shutdownManager.AddHandler(shutdown.NewBackgroundWorkerShutdownHandler("roles", func(ctx context.Context) error {
+		return services.RoleService.Close()
+	}))
 
 	ctx = context.WithValue(ctx, "metrics", metricsService)
 
@@ -126,6 +146,9 @@ func main() {
 	middleware := middleware.NewMiddleware(cfg, log, redisClient)
 
 	middleware.SetAuthService(services.AuthService)
+	middleware.SetAPIClientService(services.APIClientService)
+	middleware.SetTenantService(services.TenantService)
+	middleware.SetRoleService(services.RoleService)
 	middleware.SetMetricsService(metricsService)
 
 	log.Info(ctx, "Creating Fiber router")
@@ -24,45 +24,76 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
-        "/api/v1/auth/send-otp": {
-            "post": {
-                "description": "Send a one-time password (OTP) to the provided phone number for authentication",
-                "consumes": [
+        "/.well-known/jwks.json": {
+            "get": {
+                "description": "Public keys verifying the access tokens, identified by the kid header of each token. Includes the signing key, its announced successor and retired keys whose tokens may still be valid. Empty when tokens are signed with HS256.",
+                "produces": [
                     "application/json"
                 ],
+                "tags": [
+                    "System"
+                ],
+                "summary": "JSON Web Key Set",
+                "responses": {
+                    "200": {
+                        "description": "Public keys",
+                        "schema": {
+                            "$ref": "#/definitions/keyring.JWKSet"
+                        }
+                    }
+                }
+            }
+        },
+        "/.well-known/openid-configuration": {
+            "get": {
+                "description": "OpenID Connect discovery document listing the endpoints, scopes, claims and algorithms of the provider",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Authentication"
+                    "OpenID Connect"
                 ],
-                "summary": "Send OTP",
-                "parameters": [
-                    {
-                        "description": "Send OTP request with phone number",
-                        "name": "request",
-                        "in": "body",
-                        "required": true,
+                "summary": "OpenID Provider configuration",
+                "responses": {
+                    "200": {
+                        "description": "Provider configuration",
                         "schema": {
-                            "$ref": "#/definitions/dto.SendOTPRequest"
+                            "$ref": "#/definitions/services.ProviderMetadata"
                         }
                     }
+                }
+            }
+        },
+        "/api/v1/admin/api-clients": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "List every registered API client, including deactivated ones. Users with the clients:read permission only.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Admin"
                 ],
+                "summary": "List API clients",
                 "responses": {
                     "200": {
-                        "description": "OTP sent successfully",
+                        "description": "Registered clients",
                         "schema": {
-                            "$ref": "#/definitions/dto.SendOTPResponse"
+                            "$ref": "#/definitions/dto.APIClientsResponse"
                         }
                     },
-                    "400": {
-                        "description": "Invalid phone number format",
+                    "401": {
+                        "description": "Unauthorized - invalid or missing JWT token",
                         "schema": {
                             "$ref": "#/definitions/dto.ErrorResponse"
                         }
                     },
-                    "429": {
-                        "description": "Too many OTP requests",
+                    "403": {
+                        "description": "The clients:read permission required",
                         "schema": {
                             "$ref": "#/definitions/dto.ErrorResponse"
                         }
@@ -74,11 +105,14 @@ const docTemplate = `{
                         }
                     }
                 }
-            }
-        },
-        "/api/v1/auth/verify-otp": {
+            },
             "post": {
-                "description": "Verify the one-time password (OTP) sent to the user's phone number and return JWT authentication tokens",
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Register a backend service that calls the API on its own behalf. The client secret is returned only once; send it as the X-API-Key header, or exchange it for an access token with the client credentials grant. Users with the clients:write permission only.",
                 "consumes": [
                     "application/json"
                 ],
@@ -86,47 +120,47 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Authentication"
+                    "Admin"
                 ],
-                "summary": "Verify OTP",
+                "summary": "Register API client",
                 "parameters": [
                     {
-                        "description": "Verify OTP request with phone number and OTP code",
+                        "description": "Client to register",
                         "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/dto.VerifyOTPRequest"
+                            "$ref": "#/definitions/dto.RegisterAPIClientRequest"
                         }
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "Authentication successful - returns access token, refresh token, and user info",
+                    "201": {
+                        "description": "Client registered",
                         "schema": {
-                            "$ref": "#/definitions/dto.AuthResponse"
+                            "$ref": "#/definitions/dto.APIClientResponse"
                         }
                     },
                     "400": {
-                        "description": "Invalid request format or missing required fields",
+                        "description": "Invalid name, scope or rate limit",
                         "schema": {
                             "$ref": "#/definitions/dto.ErrorResponse"
                         }
                     },
                     "401": {
-                        "description": "Invalid OTP or expired OTP",
+                        "description": "Unauthorized - invalid or missing JWT token",
                         "schema": {
                             "$ref": "#/definitions/dto.ErrorResponse"
                         }
                     },
-                    "429": {
-                        "description": "Too many verification attempts",
+                    "403": {
+                        "description": "The clients:write permission required",
                         "schema": {
                             "$ref": "#/definitions/dto.ErrorResponse"
                         }
                     },
                     "500": {
-                        "description": "Internal server error during token generation",
+                        "description": "Internal server error",
                         "schema": {
                             "$ref": "#/definitions/dto.ErrorResponse"
                         }
@@ -134,29 +168,78 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/v1/users/profile": {
-            "get": {
+        "/api/v1/admin/api-clients/{client_id}": {
+            "delete": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Get the profile information of the currently authenticated user",
-                "consumes": [
-                    "application/json"
+                "description": "Stop a client from calling the API. Its API key and the access tokens it holds stop working at once. Users with the clients:write permission only.",
+                "tags": [
+                    "Admin"
+                ],
+                "summary": "Deactivate API client",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Client ID",
+                        "name": "client_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "Client deactivated"
+                    },
+                    "401": {
+                        "description": "Unauthorized - invalid or missing JWT token",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "The clients:write permission required",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Client not found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/admin/oauth/clients": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
                 ],
+                "description": "List every registered OpenID Connect client, including deactivated ones. Users of the default tenant with the clients:read permission only.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Users"
+                    "Admin"
                 ],
-                "summary": "Get User Profile",
+                "summary": "List OAuth clients",
                 "responses": {
                     "200": {
-                        "description": "User profile retrieved successfully",
+                        "description": "Registered clients",
                         "schema": {
-                            "$ref": "#/definitions/dto.UserResponse"
+                            "$ref": "#/definitions/dto.OAuthClientsResponse"
                         }
                     },
                     "401": {
@@ -165,6 +248,12 @@ const docTemplate = `{
                             "$ref": "#/definitions/dto.ErrorResponse"
                         }
                     },
+                    "403": {
+                        "description": "The clients:read permission within the default tenant required",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
                     "500": {
                         "description": "Internal server error",
                         "schema": {
@@ -173,13 +262,13 @@ const docTemplate = `{
                     }
                 }
             },
-            "put": {
+            "post": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Update the profile information of the currently authenticated user",
+                "description": "Register an application that logs users in through the OpenID Connect provider. The client secret is returned only once; public clients get none and must use PKCE, which every client has to anyway. Users of the default tenant with the clients:write permission only.",
                 "consumes": [
                     "application/json"
                 ],
@@ -187,29 +276,29 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Users"
+                    "Admin"
                 ],
-                "summary": "Update User Profile",
+                "summary": "Register OAuth client",
                 "parameters": [
                     {
-                        "description": "Profile update data",
+                        "description": "Client to register",
                         "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/dto.UpdateProfileRequest"
+                            "$ref": "#/definitions/dto.RegisterOAuthClientRequest"
                         }
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "Profile updated successfully",
+                    "201": {
+                        "description": "Client registered",
                         "schema": {
-                            "$ref": "#/definitions/dto.UserResponse"
+                            "$ref": "#/definitions/dto.OAuthClientResponse"
                         }
                     },
                     "400": {
-                        "description": "Invalid request data",
+                        "description": "Invalid name, redirect URI or scope",
                         "schema": {
                             "$ref": "#/definitions/dto.ErrorResponse"
                         }
@@ -220,6 +309,12 @@ const docTemplate = `{
                             "$ref": "#/definitions/dto.ErrorResponse"
                         }
                     },
+                    "403": {
+                        "description": "The clients:write permission within the default tenant required",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
                     "500": {
                         "description": "Internal server error",
                         "schema": {
@@ -229,59 +324,88 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/v1/users/search": {
-            "get": {
+        "/api/v1/admin/oauth/clients/{client_id}": {
+            "delete": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Get users with optional search and pagination in a single endpoint",
-                "consumes": [
-                    "application/json"
-                ],
-                "produces": [
-                    "application/json"
-                ],
+                "description": "Stop a client from logging users in and from refreshing its tokens. Access tokens already issued stay valid until they expire. Users of the default tenant with the clients:write permission only.",
                 "tags": [
-                    "Users"
+                    "Admin"
                 ],
-                "summary": "Get Users Unified",
+                "summary": "Deactivate OAuth client",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Search query (optional)",
-                        "name": "query",
-                        "in": "query"
+                        "description": "Client ID",
+                        "name": "client_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "Client deactivated"
                     },
-                    {
-                        "type": "integer",
-                        "description": "Pagination offset (default: 0)",
-                        "name": "offset",
-                        "in": "query"
+                    "401": {
+                        "description": "Unauthorized - invalid or missing JWT token",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "The clients:write permission within the default tenant required",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Client not found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
                     },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/admin/permissions": {
+            "get": {
+                "security": [
                     {
-                        "type": "integer",
-                        "description": "Pagination limit (default: 10, max: 100)",
-                        "name": "limit",
-                        "in": "query"
+                        "BearerAuth": []
                     }
                 ],
+                "description": "List every permission roles can grant. Users with the roles:read permission only.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Admin"
+                ],
+                "summary": "List permissions",
                 "responses": {
                     "200": {
-                        "description": "Users retrieved successfully",
+                        "description": "Permissions",
                         "schema": {
-                            "$ref": "#/definitions/dto.UnifiedUsersResponse"
+                            "$ref": "#/definitions/dto.PermissionsResponse"
                         }
                     },
-                    "400": {
-                        "description": "Invalid parameters",
+                    "401": {
+                        "description": "Unauthorized - invalid or missing JWT token",
                         "schema": {
                             "$ref": "#/definitions/dto.ErrorResponse"
                         }
                     },
-                    "401": {
-                        "description": "Unauthorized - invalid or missing JWT token",
+                    "403": {
+                        "description": "The roles:read permission required",
                         "schema": {
                             "$ref": "#/definitions/dto.ErrorResponse"
                         }
@@ -294,106 +418,4813 @@ const docTemplate = `{
                     }
                 }
             }
-        }
-    },
-    "definitions": {
-        "dto.AuthResponse": {
-            "description": "Successful authentication response with token and user info",
-            "type": "object",
-            "properties": {
-                "token": {
-                    "description": "@Description JWT token for API authentication\n@Example eyJhbGciOiJIUzI1NiIsInR5cCI6IkpXVCJ9...",
-                    "type": "string",
-                    "example": "eyJhbGciOiJIUzI1NiIsInR5cCI6IkpXVCJ9..."
-                },
-                "user": {
-                    "description": "@Description User information",
-                    "allOf": [
-                        {
-                            "$ref": "#/definitions/dto.AuthUserResponse"
+        },
+        "/api/v1/admin/roles": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "List every role with the permissions it grants. Roles are shared by all tenants. Users with the roles:read permission only.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Admin"
+                ],
+                "summary": "List roles",
+                "responses": {
+                    "200": {
+                        "description": "Roles",
+                        "schema": {
+                            "$ref": "#/definitions/dto.RolesResponse"
                         }
-                    ]
+                    },
+                    "401": {
+                        "description": "Unauthorized - invalid or missing JWT token",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "The roles:read permission required",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
                 }
             }
         },
-        "dto.AuthUserResponse": {
-            "description": "User profile information for authentication",
-            "type": "object",
-            "properties": {
-                "id": {
-                    "description": "@Description Unique user identifier\n@Example 123",
-                    "type": "integer",
-                    "example": 123
-                },
-                "name": {
-                    "description": "@Description User's full name\n@Example John Doe",
-                    "type": "string",
-                    "example": "John Doe"
+        "/api/v1/admin/roles/{name}": {
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Create a role, or replace the description and permissions of an existing one. Changes reach every instance within RBAC_RELOAD_INTERVAL. The admin role cannot be changed. Users of the default tenant with the roles:write permission only.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Admin"
+                ],
+                "summary": "Create or update role",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Role name",
+                        "name": "name",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Role description and permissions",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.RoleRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Role saved",
+                        "schema": {
+                            "$ref": "#/definitions/dto.RoleResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid role name or unknown permission",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized - invalid or missing JWT token",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "The roles:write permission within the default tenant required",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/admin/tenants": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "List every tenant, including deactivated ones. Users of the default tenant with the tenants:read permission only.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Admin"
+                ],
+                "summary": "List tenants",
+                "responses": {
+                    "200": {
+                        "description": "Tenants",
+                        "schema": {
+                            "$ref": "#/definitions/dto.TenantsResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized - invalid or missing JWT token",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "The tenants:read permission within the default tenant required",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Create a tenant with its own users, OTP settings, message templates and rate limits. Requests are resolved to it by the X-Tenant header or one of its hosts. Users of the default tenant with the tenants:write permission only.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Admin"
+                ],
+                "summary": "Create tenant",
+                "parameters": [
+                    {
+                        "description": "Tenant to create",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.TenantRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Tenant created",
+                        "schema": {
+                            "$ref": "#/definitions/dto.TenantResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid slug, name, host or setting",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized - invalid or missing JWT token",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "The tenants:write permission within the default tenant required",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Slug already taken",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/admin/tenants/{id}": {
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Replace the name, hosts, setting overrides and status of a tenant. Changes reach every instance within TENANT_RELOAD_INTERVAL. Users of the default tenant with the tenants:write permission only.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Admin"
+                ],
+                "summary": "Update tenant",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Tenant ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Tenant settings",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.TenantRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Tenant updated",
+                        "schema": {
+                            "$ref": "#/definitions/dto.TenantResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid name, host or setting",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized - invalid or missing JWT token",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "The tenants:write permission within the default tenant required",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Tenant not found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/admin/users": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    },
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "List the users of the tenant, newest first, optionally filtered. Users with the users:read permission, or API clients with the users:read or users:admin scope.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Admin"
+                ],
+                "summary": "List users",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Part of the phone number or name",
+                        "name": "query",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Role",
+                        "name": "role",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "active",
+                            "suspended",
+                            "banned",
+                            "pending_deletion",
+                            "deactivated"
+                        ],
+                        "type": "string",
+                        "description": "Status; suspensions that have lifted count as active",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Created at or after, RFC 3339",
+                        "name": "created_after",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Created before, RFC 3339",
+                        "name": "created_before",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Pagination offset (default: 0)",
+                        "name": "offset",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Pagination limit (default: 10, max: 100)",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Users retrieved successfully",
+                        "schema": {
+                            "$ref": "#/definitions/dto.UsersListResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid filter",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized - invalid or missing JWT token",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "The users:read permission or scope required",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "Client rate limit exceeded",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/admin/users/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    },
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Get a user of the tenant. Users with the users:read permission, or API clients with the users:read or users:admin scope.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Admin"
+                ],
+                "summary": "Get user",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "User retrieved successfully",
+                        "schema": {
+                            "$ref": "#/definitions/dto.UserResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid user ID",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized - invalid or missing JWT token",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "The users:read permission or scope required",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "User not found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/admin/users/{id}/activate": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    },
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Activate a suspended, banned, deactivated or pending deletion user, recording it in the status history. Users with the users:write permission, or API clients with the users:admin scope.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Admin"
+                ],
+                "summary": "Activate user",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "User activated",
+                        "schema": {
+                            "$ref": "#/definitions/dto.UserResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid user ID",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized - invalid or missing JWT token",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "The users:write permission or the users:admin scope required",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "User not found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/admin/users/{id}/deactivate": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    },
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Deactivate a user: their tokens are revoked and they cannot log in or request OTPs until activated again. Users cannot deactivate themselves. Users with the users:write permission, or API clients with the users:admin scope.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Admin"
+                ],
+                "summary": "Deactivate user",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "User deactivated",
+                        "schema": {
+                            "$ref": "#/definitions/dto.UserResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid user ID, or the admin's own account",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized - invalid or missing JWT token",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "The users:write permission or the users:admin scope required",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "User not found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/admin/users/{id}/revoke-tokens": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    },
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Revoke every access and refresh token issued to the user so far, logging them out on all devices. Users with the users:write permission, or API clients with the users:admin scope.",
+                "tags": [
+                    "Admin"
+                ],
+                "summary": "Revoke all tokens of a user",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "Tokens revoked"
+                    },
+                    "400": {
+                        "description": "Invalid user ID",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized - invalid or missing JWT token",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "The users:write permission or the users:admin scope required",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "User not found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "Client rate limit exceeded",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/admin/users/{id}/role": {
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    },
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Give a user another role, e.g. promote them to admin or demote them to a regular user; it applies to their current tokens at once. Users cannot change their own role. Users with the roles:write permission, or API clients with the users:admin scope.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Admin"
+                ],
+                "summary": "Change user role",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "New role",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.ChangeRoleRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Role changed",
+                        "schema": {
+                            "$ref": "#/definitions/dto.UserResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid user ID or role, or the admin's own account",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized - invalid or missing JWT token",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "The roles:write permission or the users:admin scope required",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "User not found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/admin/users/{id}/status": {
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    },
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Suspend a user until a given time, ban them, schedule their account for deletion, deactivate or activate them, with an optional reason. Users who are not active have their tokens revoked and cannot log in or request OTPs; suspensions lift by themselves once their time passes. Every change is recorded in the status history. Users cannot change their own status. Users with the users:write permission, or API clients with the users:admin scope.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Admin"
+                ],
+                "summary": "Set user status",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "New status",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.SetUserStatusRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Status changed",
+                        "schema": {
+                            "$ref": "#/definitions/dto.UserResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid user ID, status, reason or until, or the admin's own account",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized - invalid or missing JWT token",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "The users:write permission or the users:admin scope required",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "User not found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "Client rate limit exceeded",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/admin/users/{id}/status-history": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    },
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Get the audit trail of a user's status changes, newest first, with who made each change and why. Users with the logs:read permission, or API clients with the users:read or users:admin scope.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Admin"
+                ],
+                "summary": "Get user status history",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Status history",
+                        "schema": {
+                            "$ref": "#/definitions/dto.UserStatusHistoryResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid user ID",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized - invalid or missing JWT token",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "The logs:read permission or the users:read scope required",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "User not found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/auth/logout": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Revoke the access token of this request and the refresh tokens of its session. Other sessions stay logged in.",
+                "tags": [
+                    "Authentication"
+                ],
+                "summary": "Logout",
+                "responses": {
+                    "204": {
+                        "description": "Logged out"
+                    },
+                    "401": {
+                        "description": "Unauthorized - invalid or missing JWT token",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/auth/logout-all": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Revoke every access and refresh token issued to the authenticated user so far, on all devices",
+                "tags": [
+                    "Authentication"
+                ],
+                "summary": "Logout from all sessions",
+                "responses": {
+                    "204": {
+                        "description": "Logged out of all sessions"
+                    },
+                    "401": {
+                        "description": "Unauthorized - invalid or missing JWT token",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/auth/otp/{verification_id}": {
+            "get": {
+                "description": "Get the status of an OTP verification challenge, including whether its code can still be verified",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Authentication"
+                ],
+                "summary": "Get OTP status",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Verification ID returned by send-otp",
+                        "name": "verification_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OTP challenge status",
+                        "schema": {
+                            "$ref": "#/definitions/dto.OTPStatusResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Unknown or no longer retained verification ID",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/auth/refresh": {
+            "post": {
+                "description": "Exchange a refresh token for a new access token and a new refresh token. Refresh tokens are single-use: presenting one that was already exchanged is treated as theft and revokes every token descending from the same login.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Authentication"
+                ],
+                "summary": "Refresh token",
+                "parameters": [
+                    {
+                        "description": "Refresh token",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.RefreshTokenRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Tokens refreshed",
+                        "schema": {
+                            "$ref": "#/definitions/dto.RefreshTokenResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Missing refresh token",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Invalid, expired, reused or revoked refresh token",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/auth/resend-otp": {
+            "post": {
+                "description": "Resend the code of an OTP challenge. Within the reuse window the same code is sent again, afterwards a new code replaces it.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Authentication"
+                ],
+                "summary": "Resend OTP",
+                "parameters": [
+                    {
+                        "description": "Resend OTP request with verification ID",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.ResendOTPRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OTP resent successfully",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ResendOTPResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request or challenge already completed",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "The account of the phone number is suspended, banned or deactivated",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Unknown or no longer retained verification ID",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "Resent too recently, resend limit reached or phone number locked out",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "502": {
+                        "description": "OTP delivery provider failed",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/auth/send-otp": {
+            "post": {
+                "description": "Send a one-time password (OTP) to the provided phone number for authentication. The code goes out over the preferred channel, or the first configured one, and falls back to the next channel when delivery fails or is not confirmed in time.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Authentication"
+                ],
+                "summary": "Send OTP",
+                "parameters": [
+                    {
+                        "description": "Send OTP request with phone number",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.SendOTPRequest"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "example": "fa-IR,fa;q=0.9,en;q=0.8",
+                        "description": "Preferred message languages",
+                        "name": "Accept-Language",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OTP sent successfully",
+                        "schema": {
+                            "$ref": "#/definitions/dto.SendOTPResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid phone number format or unavailable channel",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "The account of the phone number is suspended, banned or deactivated",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "Too many OTP requests or phone number locked out",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "502": {
+                        "description": "OTP delivery provider failed",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/auth/step-up": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Send a one-time password to the authenticated user's phone number for stepping up. Routes guarding sensitive actions answer step_up_required when the user last authenticated too long ago; verifying this code at /api/v1/auth/step-up/verify issues an elevated token for them.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Authentication"
+                ],
+                "summary": "Send step-up OTP",
+                "parameters": [
+                    {
+                        "description": "Delivery preferences",
+                        "name": "request",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/dto.StepUpRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OTP sent successfully",
+                        "schema": {
+                            "$ref": "#/definitions/dto.SendOTPResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid channel",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized - invalid or missing JWT token",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "Phone number locked out",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "502": {
+                        "description": "OTP delivery provider failed",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/auth/step-up/verify": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Verify a step-up code sent to the authenticated user and issue a short-lived access token for the current session with a fresh auth_time. No new session is started and no refresh token is issued.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Authentication"
+                ],
+                "summary": "Verify step-up OTP",
+                "parameters": [
+                    {
+                        "description": "Verification ID and code",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.VerifyStepUpRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Elevated token issued",
+                        "schema": {
+                            "$ref": "#/definitions/dto.StepUpResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Invalid or expired OTP",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "Phone number locked out",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/auth/verify-otp": {
+            "post": {
+                "description": "Verify the one-time password (OTP) sent to the user's phone number and return JWT authentication tokens. Users with an authenticator app enrolled get an MFA token instead, to present to verify-totp. Users who lost their phone can present a recovery code instead of the OTP; the account then has to confirm a new phone number before it can use the API.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Authentication"
+                ],
+                "summary": "Verify OTP",
+                "parameters": [
+                    {
+                        "description": "Verify OTP request with verification ID and OTP code",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.VerifyOTPRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Authentication successful - returns access token, refresh token, and user info",
+                        "schema": {
+                            "$ref": "#/definitions/dto.AuthResponse"
+                        }
+                    },
+                    "202": {
+                        "description": "Second factor required",
+                        "schema": {
+                            "$ref": "#/definitions/dto.SecondFactorRequiredResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request format or missing required fields",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Invalid OTP or expired OTP",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "The account is suspended, banned or deactivated",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "Too many failed attempts - phone number is temporarily locked out",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error during token generation",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/auth/verify-totp": {
+            "post": {
+                "description": "Complete a login that required a second factor with the current code from the user's authenticator app. Each code is accepted once.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Authentication"
+                ],
+                "summary": "Verify second factor",
+                "parameters": [
+                    {
+                        "description": "MFA token from verify-otp and authenticator code",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.VerifyTOTPRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Authentication successful",
+                        "schema": {
+                            "$ref": "#/definitions/dto.AuthResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request format or missing required fields",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Invalid code, or expired or burned MFA token",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "The account is suspended, banned or deactivated",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/otp/login": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Send a login OTP to a phone number like /api/v1/auth/send-otp does, for backend services driving the login of their users. Not subject to the per-IP auth limit; the per-phone OTP limit and the client's own rate limit apply. API clients with the otp:send scope, or users with the otp:send permission.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Authentication"
+                ],
+                "summary": "Send OTP on behalf of a user",
+                "parameters": [
+                    {
+                        "description": "Send OTP request with phone number",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.SendOTPRequest"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "example": "fa-IR,fa;q=0.9,en;q=0.8",
+                        "description": "Preferred message languages",
+                        "name": "Accept-Language",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OTP sent successfully",
+                        "schema": {
+                            "$ref": "#/definitions/dto.SendOTPResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid phone number format or unavailable channel",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Invalid API key or token",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "The otp:send scope or permission required, or the account of the phone number is suspended, banned or deactivated",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "Too many OTP requests, client rate limit exceeded or phone number locked out",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "502": {
+                        "description": "OTP delivery provider failed",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/otp/send": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Send a one-time password confirming a sensitive action (phone change, account deletion) to the authenticated user's phone number. Codes are scoped to their purpose and cannot be used for anything else.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Authentication"
+                ],
+                "summary": "Send confirmation OTP",
+                "parameters": [
+                    {
+                        "description": "Action to confirm",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.SendConfirmationOTPRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OTP sent successfully",
+                        "schema": {
+                            "$ref": "#/definitions/dto.SendOTPResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid purpose or channel",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized - invalid or missing JWT token",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "Phone number locked out",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "502": {
+                        "description": "OTP delivery provider failed",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/otp/transaction": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Send a one-time password signing a specific operation (dynamic linking). The code is bound to the canonical hash of the payload and the message shows a summary of the operation; verifying requires the same payload.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Authentication"
+                ],
+                "summary": "Send transaction OTP",
+                "parameters": [
+                    {
+                        "description": "Operation to sign",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.SendTransactionOTPRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OTP sent successfully",
+                        "schema": {
+                            "$ref": "#/definitions/dto.SendTransactionOTPResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid payload or channel",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized - invalid or missing JWT token",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "Phone number locked out",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "502": {
+                        "description": "OTP delivery provider failed",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/otp/transaction/verify": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Verify a transaction code. The payload must be the one the code was sent for; any difference fails verification and counts as a wrong attempt.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Authentication"
+                ],
+                "summary": "Verify transaction OTP",
+                "parameters": [
+                    {
+                        "description": "Verification ID, payload and code",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.VerifyTransactionOTPRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Transaction confirmed",
+                        "schema": {
+                            "$ref": "#/definitions/dto.VerifyTransactionOTPResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request or payload",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Invalid or expired OTP, or a different payload",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "Phone number locked out",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/otp/verify": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Verify a confirmation code sent to the authenticated user. The purpose must match the one the code was sent for.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Authentication"
+                ],
+                "summary": "Verify confirmation OTP",
+                "parameters": [
+                    {
+                        "description": "Verification ID, purpose and code",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.VerifyConfirmationOTPRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OTP verified successfully",
+                        "schema": {
+                            "$ref": "#/definitions/dto.VerifyConfirmationOTPResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Invalid or expired OTP, or wrong purpose",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "Phone number locked out",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/users/mfa/factors": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "List the second factors enrolled by the authenticated user, confirmed or not",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "MFA"
+                ],
+                "summary": "List second factors",
+                "responses": {
+                    "200": {
+                        "description": "Enrolled factors",
+                        "schema": {
+                            "$ref": "#/definitions/dto.FactorsResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized - invalid or missing JWT token",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/users/mfa/factors/{id}": {
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Remove a second factor. Once no confirmed factor is left, logins no longer ask for one. Users who last authenticated more than JWT_STEP_UP_MAX_AGE ago must step up first.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "MFA"
+                ],
+                "summary": "Remove second factor",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Factor ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "Factor removed"
+                    },
+                    "400": {
+                        "description": "Invalid factor ID",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized - invalid or missing JWT token, or step_up_required",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Factor not found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/users/mfa/recovery-codes": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get the number of unused recovery codes of the authenticated user",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "MFA"
+                ],
+                "summary": "Get recovery code status",
+                "responses": {
+                    "200": {
+                        "description": "Recovery code status",
+                        "schema": {
+                            "$ref": "#/definitions/dto.RecoveryCodesStatusResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized - invalid or missing JWT token",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Generate a new set of single-use recovery codes, which log in without the SMS code if the phone is lost. The codes are shown only once; generating a new set invalidates the previous one. Users who last authenticated more than JWT_STEP_UP_MAX_AGE ago must step up first.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "MFA"
+                ],
+                "summary": "Generate recovery codes",
+                "responses": {
+                    "201": {
+                        "description": "Recovery codes generated",
+                        "schema": {
+                            "$ref": "#/definitions/dto.RecoveryCodesResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized - invalid or missing JWT token, or step_up_required",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/users/mfa/totp": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Create an unconfirmed authenticator app factor and return its secret and otpauth:// URI. The secret is shown only once; the factor is used at login once confirmed with a first code.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "MFA"
+                ],
+                "summary": "Start TOTP enrollment",
+                "parameters": [
+                    {
+                        "description": "Factor label",
+                        "name": "request",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/dto.StartTOTPEnrollmentRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Enrollment started",
+                        "schema": {
+                            "$ref": "#/definitions/dto.TOTPEnrollmentResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid name or too many factors",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized - invalid or missing JWT token",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/users/mfa/totp/{id}/confirm": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Confirm an authenticator app enrollment with the first code it shows. From then on, logins require a code from the app.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "MFA"
+                ],
+                "summary": "Confirm TOTP enrollment",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Factor ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Authenticator code",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.ConfirmTOTPEnrollmentRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Factor confirmed",
+                        "schema": {
+                            "$ref": "#/definitions/dto.FactorResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request, factor already confirmed or enrollment expired",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Invalid code",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Factor not found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/users/phone/change": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Send a code to the new phone number and, unless the tenant turned OTP_PHONE_CHANGE_VERIFY_OLD_NUMBER off, one to the current number. The number must not belong to another user. Requires a recent authentication.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Users"
+                ],
+                "summary": "Start phone number change",
+                "parameters": [
+                    {
+                        "description": "New phone number",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.StartPhoneChangeRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OTPs sent successfully",
+                        "schema": {
+                            "$ref": "#/definitions/dto.StartPhoneChangeResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid phone number or channel, or the current number",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized - invalid or missing JWT token, or step_up_required",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Phone number belongs to another user",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "Phone number locked out",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "502": {
+                        "description": "OTP delivery provider failed",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/users/phone/change/verify": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Change the phone number with the codes sent by the start request. Every session is logged out, a phone_number_changed event lets the old number be notified, and a new token carrying the number is returned.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Users"
+                ],
+                "summary": "Change phone number",
+                "parameters": [
+                    {
+                        "description": "Verification IDs and codes",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.ChangePhoneNumberRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Phone number changed",
+                        "schema": {
+                            "$ref": "#/definitions/dto.AuthResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request or the code of the current number missing",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Invalid or expired OTP, or step_up_required",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Phone number belongs to another user",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "Phone number locked out",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/users/phone/confirm/send": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "After a recovery code login the account is blocked until the user confirms a phone number they control. This sends a code to that number, which may be the old one or a new one not used by another account.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Users"
+                ],
+                "summary": "Send phone confirmation OTP",
+                "parameters": [
+                    {
+                        "description": "Phone number to confirm",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.SendPhoneConfirmationRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OTP sent successfully",
+                        "schema": {
+                            "$ref": "#/definitions/dto.SendOTPResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid phone number or channel, or no confirmation pending",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized - invalid or missing JWT token",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Phone number belongs to another user",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "Phone number locked out",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "502": {
+                        "description": "OTP delivery provider failed",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/users/phone/confirm/verify": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Confirm the phone number with the code sent to it. The number becomes the user's phone number, the account is unblocked and a new token carrying the number is returned.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Users"
+                ],
+                "summary": "Confirm phone number",
+                "parameters": [
+                    {
+                        "description": "Verification ID and code",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.ConfirmPhoneNumberRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Phone number confirmed",
+                        "schema": {
+                            "$ref": "#/definitions/dto.AuthResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request or no confirmation pending",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Invalid or expired OTP",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Phone number belongs to another user",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "Phone number locked out",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/users/profile": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get the profile information of the currently authenticated user",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Users"
+                ],
+                "summary": "Get User Profile",
+                "responses": {
+                    "200": {
+                        "description": "User profile retrieved successfully",
+                        "schema": {
+                            "$ref": "#/definitions/dto.UserResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized - invalid or missing JWT token",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Update the profile information of the currently authenticated user. The phone number is changed with /api/v1/users/phone/change instead.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Users"
+                ],
+                "summary": "Update User Profile",
+                "parameters": [
+                    {
+                        "description": "Profile update data",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.UpdateProfileRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Profile updated successfully",
+                        "schema": {
+                            "$ref": "#/definitions/dto.UserResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request data",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized - invalid or missing JWT token",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/users/search": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    },
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Get users with optional search and pagination in a single endpoint. Open to API clients with the users:read or users:admin scope.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Users"
+                ],
+                "summary": "Get Users Unified",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Search query (optional)",
+                        "name": "query",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Pagination offset (default: 0)",
+                        "name": "offset",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Pagination limit (default: 10, max: 100)",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Users retrieved successfully",
+                        "schema": {
+                            "$ref": "#/definitions/dto.UnifiedUsersResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid parameters",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized - invalid or missing JWT token or API key",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "The users:read scope required",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "Rate limit exceeded",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/users/sessions": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "List the active sessions of the authenticated user, one per logged in device, newest first. The session of the request is marked as current.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Users"
+                ],
+                "summary": "List sessions",
+                "responses": {
+                    "200": {
+                        "description": "Active sessions",
+                        "schema": {
+                            "$ref": "#/definitions/dto.SessionsResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized - invalid or missing JWT token",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/users/sessions/{id}": {
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Log out one of the authenticated user's sessions, for example a lost device. Its access and refresh tokens stop working at once.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Users"
+                ],
+                "summary": "Revoke session",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Session ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "Session revoked"
+                    },
+                    "401": {
+                        "description": "Unauthorized - invalid or missing JWT token",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Session not found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/webhooks/delivery/{provider}": {
+            "post": {
+                "description": "Receive delivery receipts (DLRs) for OTP messages. The request must carry the provider's signature; receipts update the delivery status of the matching OTP challenge.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Webhooks"
+                ],
+                "summary": "Delivery receipt webhook",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "example": "http",
+                        "description": "Delivery provider name",
+                        "name": "provider",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Receipts accepted",
+                        "schema": {
+                            "$ref": "#/definitions/dto.DeliveryReceiptResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Malformed receipt",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Invalid signature",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Provider not configured or does not send receipts",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/oauth/authorize": {
+            "get": {
+                "description": "Start an OpenID Connect authorization code flow. Shows a login page where the user enters their phone number, the SMS code and, if enrolled, their TOTP code, then redirects back to the client with a code. PKCE with S256 is required.",
+                "produces": [
+                    "text/html"
+                ],
+                "tags": [
+                    "OpenID Connect"
+                ],
+                "summary": "Authorization endpoint",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Must be code",
+                        "name": "response_type",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Client ID",
+                        "name": "client_id",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Registered redirect URI",
+                        "name": "redirect_uri",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "example": "openid phone",
+                        "description": "Space-separated scopes, must include openid",
+                        "name": "scope",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Opaque value returned to the client",
+                        "name": "state",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Value copied into the ID token",
+                        "name": "nonce",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "PKCE code challenge",
+                        "name": "code_challenge",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Must be S256",
+                        "name": "code_challenge_method",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Login page",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "302": {
+                        "description": "Redirect to the client with an error"
+                    },
+                    "400": {
+                        "description": "Unknown client or redirect URI",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Login page form: send the SMS code to the phone number entered for a pending authorization request",
+                "consumes": [
+                    "application/x-www-form-urlencoded"
+                ],
+                "produces": [
+                    "text/html"
+                ],
+                "tags": [
+                    "OpenID Connect"
+                ],
+                "summary": "Send login code",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Authorization request ID",
+                        "name": "request_id",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Phone number in E.164 format",
+                        "name": "phone_number",
+                        "in": "formData",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Code entry page",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid phone number or expired request",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/oauth/authorize/totp": {
+            "post": {
+                "description": "Login page form: verify the TOTP code of a pending authorization request and redirect back to the client with an authorization code",
+                "consumes": [
+                    "application/x-www-form-urlencoded"
+                ],
+                "produces": [
+                    "text/html"
+                ],
+                "tags": [
+                    "OpenID Connect"
+                ],
+                "summary": "Verify login second factor",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Authorization request ID",
+                        "name": "request_id",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "TOTP code",
+                        "name": "code",
+                        "in": "formData",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "303": {
+                        "description": "Redirect to the client"
+                    },
+                    "400": {
+                        "description": "Invalid code or expired request",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/oauth/authorize/verify": {
+            "post": {
+                "description": "Login page form: verify the SMS code of a pending authorization request. Redirects back to the client with an authorization code, or asks for the TOTP code of users with a second factor.",
+                "consumes": [
+                    "application/x-www-form-urlencoded"
+                ],
+                "produces": [
+                    "text/html"
+                ],
+                "tags": [
+                    "OpenID Connect"
+                ],
+                "summary": "Verify login code",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Authorization request ID",
+                        "name": "request_id",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "SMS code",
+                        "name": "otp",
+                        "in": "formData",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "TOTP entry page",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "303": {
+                        "description": "Redirect to the client"
+                    },
+                    "400": {
+                        "description": "Invalid code or expired request",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/oauth/introspect": {
+            "post": {
+                "description": "Report whether an access or refresh token is active (RFC 7662), for resource servers authenticating as confidential clients. Tokens that are invalid, expired, revoked or belong to a deactivated user are reported as {\"active\": false} and nothing else. Any token can be introspected, whether issued to a client or by the API login.",
+                "consumes": [
+                    "application/x-www-form-urlencoded"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "OpenID Connect"
+                ],
+                "summary": "Token introspection endpoint",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Access or refresh token",
+                        "name": "token",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "access_token or refresh_token; not needed, since the token format tells them apart",
+                        "name": "token_type_hint",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Client ID, unless sent with HTTP Basic",
+                        "name": "client_id",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Client secret, unless sent with HTTP Basic",
+                        "name": "client_secret",
+                        "in": "formData"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Token state",
+                        "schema": {
+                            "$ref": "#/definitions/dto.IntrospectionResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Missing token or public client",
+                        "schema": {
+                            "$ref": "#/definitions/dto.OAuthErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Client authentication failed",
+                        "schema": {
+                            "$ref": "#/definitions/dto.OAuthErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.OAuthErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/oauth/revoke": {
+            "post": {
+                "description": "Revoke an access or refresh token issued to the client (RFC 7009), ending its session: the matching access and refresh tokens stop working too. Invalid, expired and already revoked tokens are ignored.",
+                "consumes": [
+                    "application/x-www-form-urlencoded"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "OpenID Connect"
+                ],
+                "summary": "Token revocation endpoint",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Access or refresh token",
+                        "name": "token",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "access_token or refresh_token; not needed, since the token format tells them apart",
+                        "name": "token_type_hint",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Client ID, unless sent with HTTP Basic",
+                        "name": "client_id",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Client secret, unless sent with HTTP Basic",
+                        "name": "client_secret",
+                        "in": "formData"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Token revoked or invalid"
+                    },
+                    "400": {
+                        "description": "Missing token, or token of another client",
+                        "schema": {
+                            "$ref": "#/definitions/dto.OAuthErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Client authentication failed",
+                        "schema": {
+                            "$ref": "#/definitions/dto.OAuthErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.OAuthErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/oauth/token": {
+            "post": {
+                "description": "Exchange an authorization code together with its PKCE code verifier, or a refresh token, for tokens. Confidential clients authenticate with HTTP Basic or client_id and client_secret form parameters; public clients send only client_id. API clients get an access token, without a refresh token, with the client_credentials grant.",
+                "consumes": [
+                    "application/x-www-form-urlencoded"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "OpenID Connect"
+                ],
+                "summary": "Token endpoint",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "authorization_code, refresh_token or client_credentials",
+                        "name": "grant_type",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Authorization code",
+                        "name": "code",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Redirect URI of the authorization request",
+                        "name": "redirect_uri",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "PKCE code verifier",
+                        "name": "code_verifier",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Refresh token",
+                        "name": "refresh_token",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Space-separated API client scopes for client_credentials; all scopes of the client by default",
+                        "name": "scope",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Client ID, unless sent with HTTP Basic",
+                        "name": "client_id",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Client secret, unless sent with HTTP Basic",
+                        "name": "client_secret",
+                        "in": "formData"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Tokens",
+                        "schema": {
+                            "$ref": "#/definitions/dto.OAuthTokenResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request or grant",
+                        "schema": {
+                            "$ref": "#/definitions/dto.OAuthErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Client authentication failed",
+                        "schema": {
+                            "$ref": "#/definitions/dto.OAuthErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.OAuthErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/oauth/userinfo": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Claims about the user the access token was issued for, as granted by its scope: sub always, phone_number and phone_number_verified with phone, name with profile. The token must have been issued to a client with the openid scope.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "OpenID Connect"
+                ],
+                "summary": "UserInfo endpoint",
+                "responses": {
+                    "200": {
+                        "description": "User claims",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "401": {
+                        "description": "Invalid or expired access token",
+                        "schema": {
+                            "$ref": "#/definitions/dto.OAuthErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Access token lacks the openid scope",
+                        "schema": {
+                            "$ref": "#/definitions/dto.OAuthErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Claims about the user the access token was issued for, as granted by its scope: sub always, phone_number and phone_number_verified with phone, name with profile. The token must have been issued to a client with the openid scope.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "OpenID Connect"
+                ],
+                "summary": "UserInfo endpoint",
+                "responses": {
+                    "200": {
+                        "description": "User claims",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "401": {
+                        "description": "Invalid or expired access token",
+                        "schema": {
+                            "$ref": "#/definitions/dto.OAuthErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Access token lacks the openid scope",
+                        "schema": {
+                            "$ref": "#/definitions/dto.OAuthErrorResponse"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "dto.APIClientResponse": {
+            "description": "API client",
+            "type": "object",
+            "properties": {
+                "client_id": {
+                    "description": "@Description Client ID\n@Example 8d1e4f2a-7c3b-4a9e-b5d6-0f1a2b3c4d5e",
+                    "type": "string",
+                    "example": "8d1e4f2a-7c3b-4a9e-b5d6-0f1a2b3c4d5e"
+                },
+                "client_secret": {
+                    "description": "@Description Client secret, used as the API key and for the client credentials grant; only returned at registration\n@Example Zx3k9Qm1vT7bR2nW8yL4pC6hJ0dF5sA9gE1uK3oI7qM",
+                    "type": "string",
+                    "example": "Zx3k9Qm1vT7bR2nW8yL4pC6hJ0dF5sA9gE1uK3oI7qM"
+                },
+                "created_at": {
+                    "description": "@Description Registration timestamp\n@Example 2024-01-01T00:00:00Z",
+                    "type": "string",
+                    "example": "2024-01-01T00:00:00Z"
+                },
+                "is_active": {
+                    "description": "@Description Whether the client may call the API\n@Example true",
+                    "type": "boolean",
+                    "example": true
+                },
+                "name": {
+                    "description": "@Description Client name\n@Example Billing Service",
+                    "type": "string",
+                    "example": "Billing Service"
+                },
+                "rate_limit": {
+                    "description": "@Description Requests allowed per rate limit window; omitted for the default limit\n@Example 1000",
+                    "type": "integer",
+                    "example": 1000
+                },
+                "scopes": {
+                    "description": "@Description Scopes granted to the client\n@Example [\"otp:send\",\"users:read\"]",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "otp:send",
+                        "users:read"
+                    ]
+                }
+            }
+        },
+        "dto.APIClientsResponse": {
+            "description": "Registered API clients",
+            "type": "object",
+            "properties": {
+                "clients": {
+                    "description": "@Description Clients",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.APIClientResponse"
+                    }
+                }
+            }
+        },
+        "dto.AuthResponse": {
+            "description": "Successful authentication response with token and user info",
+            "type": "object",
+            "properties": {
+                "expires_in": {
+                    "description": "@Description Access token lifetime in seconds\n@Example 900",
+                    "type": "integer",
+                    "example": 900
+                },
+                "refresh_expires_in": {
+                    "description": "@Description Refresh token lifetime in seconds\n@Example 2592000",
+                    "type": "integer",
+                    "example": 2592000
+                },
+                "refresh_token": {
+                    "description": "@Description Opaque refresh token; exchange it at /api/v1/auth/refresh for a new pair\n@Example 8Jd3kQ0vX1mZr7YtP2cW9nB4sLhA6eGuF5iKoTqRjVw",
+                    "type": "string",
+                    "example": "8Jd3kQ0vX1mZr7YtP2cW9nB4sLhA6eGuF5iKoTqRjVw"
+                },
+                "token": {
+                    "description": "@Description JWT access token for API authentication\n@Example eyJhbGciOiJIUzI1NiIsInR5cCI6IkpXVCJ9...",
+                    "type": "string",
+                    "example": "eyJhbGciOiJIUzI1NiIsInR5cCI6IkpXVCJ9..."
+                },
+                "user": {
+                    "description": "@Description User information",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/dto.AuthUserResponse"
+                        }
+                    ]
+                }
+            }
+        },
+        "dto.AuthUserResponse": {
+            "description": "User profile information for authentication",
+            "type": "object",
+            "properties": {
+                "id": {
+                    "description": "@Description Unique user identifier\n@Example 123",
+                    "type": "integer",
+                    "example": 123
+                },
+                "name": {
+                    "description": "@Description User's full name\n@Example John Doe",
+                    "type": "string",
+                    "example": "John Doe"
+                },
+                "phone_confirmation_required": {
+                    "description": "@Description Whether the user must confirm a new phone number before using the API, after a recovery code login\n@Example false",
+                    "type": "boolean",
+                    "example": false
+                },
+                "phone_number": {
+                    "description": "@Description User's phone number\n@Example +1234567890",
+                    "type": "string",
+                    "example": "+1234567890"
+                },
+                "role": {
+                    "description": "@Description Name of the user's role, which grants their permissions\n@Example user",
+                    "type": "string",
+                    "example": "user"
+                }
+            }
+        },
+        "dto.ChangePhoneNumberRequest": {
+            "description": "Request to change the phone number with the codes sent to the new and current numbers",
+            "type": "object",
+            "required": [
+                "otp",
+                "verification_id"
+            ],
+            "properties": {
+                "old_otp": {
+                    "description": "@Description One-time password sent to the current number, when one was sent\n@Example 654321",
+                    "type": "string",
+                    "example": "654321"
+                },
+                "old_verification_id": {
+                    "description": "@Description Verification ID of the code sent to the current number, when one was sent\n@Example 9a4c2e6b-1f3d-4b8a-8e2c-7d5f1a3b9c64",
+                    "type": "string",
+                    "example": "9a4c2e6b-1f3d-4b8a-8e2c-7d5f1a3b9c64"
+                },
+                "otp": {
+                    "description": "@Description One-time password sent to the new number\n@Example 123456\n@Required",
+                    "type": "string",
+                    "example": "123456"
+                },
+                "verification_id": {
+                    "description": "@Description Verification ID of the code sent to the new number\n@Example 3f2b8c1e-7d4a-4e8b-9c1f-2a6d5e4b3c21\n@Required",
+                    "type": "string",
+                    "example": "3f2b8c1e-7d4a-4e8b-9c1f-2a6d5e4b3c21"
+                }
+            }
+        },
+        "dto.ChangeRoleRequest": {
+            "description": "Role to give the user",
+            "type": "object",
+            "required": [
+                "role"
+            ],
+            "properties": {
+                "role": {
+                    "description": "@Description Name of the new role, e.g. user, admin, support or auditor\n@Example admin\n@Required",
+                    "type": "string",
+                    "example": "admin"
+                }
+            }
+        },
+        "dto.ConfirmPhoneNumberRequest": {
+            "description": "Request to confirm a new phone number with the code sent to it",
+            "type": "object",
+            "required": [
+                "otp",
+                "verification_id"
+            ],
+            "properties": {
+                "otp": {
+                    "description": "@Description One-time password sent to the new number\n@Example 123456\n@Required",
+                    "type": "string",
+                    "example": "123456"
+                },
+                "verification_id": {
+                    "description": "@Description Verification ID returned by the send request\n@Example 3f2b8c1e-7d4a-4e8b-9c1f-2a6d5e4b3c21\n@Required",
+                    "type": "string",
+                    "example": "3f2b8c1e-7d4a-4e8b-9c1f-2a6d5e4b3c21"
+                }
+            }
+        },
+        "dto.ConfirmTOTPEnrollmentRequest": {
+            "description": "Request to confirm an enrollment with the first code from the authenticator app",
+            "type": "object",
+            "required": [
+                "code"
+            ],
+            "properties": {
+                "code": {
+                    "description": "@Description Current code from the authenticator app\n@Example 287082\n@Required",
+                    "type": "string",
+                    "example": "287082"
+                }
+            }
+        },
+        "dto.DeliveryEventResponse": {
+            "description": "Delivery status transition reported by a provider",
+            "type": "object",
+            "properties": {
+                "at": {
+                    "description": "@Description When the status was reached\n@Example 2024-01-15T10:30:04Z",
+                    "type": "string",
+                    "example": "2024-01-15T10:30:04Z"
+                },
+                "channel": {
+                    "description": "@Description Channel the message was sent over\n@Example sms",
+                    "type": "string",
+                    "example": "sms"
+                },
+                "error_code": {
+                    "description": "@Description Provider error code or delivery error class\n@Example timeout",
+                    "type": "string",
+                    "example": "timeout"
+                },
+                "message_id": {
+                    "description": "@Description Provider's message ID\n@Example msg_01HF3Z6",
+                    "type": "string",
+                    "example": "msg_01HF3Z6"
+                },
+                "provider": {
+                    "description": "@Description Delivery provider\n@Example http",
+                    "type": "string",
+                    "example": "http"
+                },
+                "status": {
+                    "description": "@Description Delivery status\n@Example delivered",
+                    "type": "string",
+                    "enum": [
+                        "queued",
+                        "sent",
+                        "delivered",
+                        "failed",
+                        "expired"
+                    ],
+                    "example": "delivered"
+                }
+            }
+        },
+        "dto.DeliveryReceiptResponse": {
+            "description": "Outcome of a delivery receipt request",
+            "type": "object",
+            "properties": {
+                "ignored": {
+                    "description": "@Description Receipts for unknown messages, duplicates and out-of-order receipts\n@Example 0",
+                    "type": "integer",
+                    "example": 0
+                },
+                "processed": {
+                    "description": "@Description Receipts applied to a message\n@Example 1",
+                    "type": "integer",
+                    "example": 1
+                }
+            }
+        },
+        "dto.ErrorResponse": {
+            "description": "Standard error response format",
+            "type": "object",
+            "properties": {
+                "error": {
+                    "description": "@Description Error type or category\n@Example validation_error",
+                    "type": "string",
+                    "example": "validation_error"
+                },
+                "message": {
+                    "description": "@Description Detailed error message\n@Example Phone number format is invalid",
+                    "type": "string",
+                    "example": "Phone number format is invalid"
+                }
+            }
+        },
+        "dto.FactorResponse": {
+            "description": "Second factor enrolled by the user",
+            "type": "object",
+            "properties": {
+                "confirmed": {
+                    "description": "@Description Whether enrollment was confirmed; only confirmed factors are used at login\n@Example true",
+                    "type": "boolean",
+                    "example": true
+                },
+                "created_at": {
+                    "description": "@Description Enrollment timestamp\n@Example 2024-01-01T00:00:00Z",
+                    "type": "string",
+                    "example": "2024-01-01T00:00:00Z"
+                },
+                "id": {
+                    "description": "@Description Factor ID\n@Example 1",
+                    "type": "integer",
+                    "example": 1
+                },
+                "last_used_at": {
+                    "description": "@Description When the last code was accepted\n@Example 2024-01-15T10:30:00Z",
+                    "type": "string",
+                    "example": "2024-01-15T10:30:00Z"
+                },
+                "name": {
+                    "description": "@Description Factor label\n@Example Work phone",
+                    "type": "string",
+                    "example": "Work phone"
+                },
+                "type": {
+                    "description": "@Description Factor type\n@Example totp",
+                    "type": "string",
+                    "enum": [
+                        "totp"
+                    ],
+                    "example": "totp"
+                }
+            }
+        },
+        "dto.FactorsResponse": {
+            "description": "Second factors enrolled by the user",
+            "type": "object",
+            "properties": {
+                "factors": {
+                    "description": "@Description Enrolled factors",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.FactorResponse"
+                    }
+                }
+            }
+        },
+        "dto.IntrospectionResponse": {
+            "description": "State of a token; only active is set for inactive tokens",
+            "type": "object",
+            "properties": {
+                "acr": {
+                    "description": "@Description Authentication context class: 1 for one factor, 2 for multi-factor\n@Example 1",
+                    "type": "string",
+                    "example": "1"
+                },
+                "active": {
+                    "description": "@Description Whether the token is valid, unexpired, not revoked and its user active\n@Example true",
+                    "type": "boolean",
+                    "example": true
+                },
+                "amr": {
+                    "description": "@Description Authentication methods: otp, totp, recovery, mfa\n@Example [\"otp\"]",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "otp"
+                    ]
+                },
+                "auth_time": {
+                    "description": "@Description When the user last authenticated, as Unix time\n@Example 1705313690",
+                    "type": "integer",
+                    "example": 1705313690
+                },
+                "client_id": {
+                    "description": "@Description Client the token was issued to; empty for API logins\n@Example 5f0c8a52-6f4e-4c3b-9d7a-1e2b3c4d5e6f",
+                    "type": "string",
+                    "example": "5f0c8a52-6f4e-4c3b-9d7a-1e2b3c4d5e6f"
+                },
+                "exp": {
+                    "description": "@Description Expiry as Unix time\n@Example 1705314600",
+                    "type": "integer",
+                    "example": 1705314600
+                },
+                "iat": {
+                    "description": "@Description Issue time as Unix time\n@Example 1705313700",
+                    "type": "integer",
+                    "example": 1705313700
+                },
+                "jti": {
+                    "description": "@Description Token ID, for access tokens\n@Example 0f8e7d6c-5b4a-4392-8170-6f5e4d3c2b1a",
+                    "type": "string",
+                    "example": "0f8e7d6c-5b4a-4392-8170-6f5e4d3c2b1a"
+                },
+                "phone_number": {
+                    "description": "@Description Current phone number of the user\n@Example +1234567890",
+                    "type": "string",
+                    "example": "+1234567890"
+                },
+                "role": {
+                    "description": "@Description Current role of the user\n@Example user",
+                    "type": "string",
+                    "example": "user"
+                },
+                "scope": {
+                    "description": "@Description Granted scope; empty for API logins\n@Example openid phone",
+                    "type": "string",
+                    "example": "openid phone"
+                },
+                "sid": {
+                    "description": "@Description Session ID\n@Example 9b2f4c1e-3a7d-4e8b-8c1f-2a6d5e4b3c21",
+                    "type": "string",
+                    "example": "9b2f4c1e-3a7d-4e8b-8c1f-2a6d5e4b3c21"
+                },
+                "sub": {
+                    "description": "@Description User ID, or the client ID for tokens of API clients\n@Example 1",
+                    "type": "string",
+                    "example": "1"
+                },
+                "tenant_id": {
+                    "description": "@Description Tenant of the user or API client\n@Example 1",
+                    "type": "integer",
+                    "example": 1
+                },
+                "token_type": {
+                    "description": "@Description access_token or refresh_token\n@Example access_token",
+                    "type": "string",
+                    "example": "access_token"
+                }
+            }
+        },
+        "dto.OAuthClientResponse": {
+            "description": "OpenID Connect client",
+            "type": "object",
+            "properties": {
+                "client_id": {
+                    "description": "@Description Client ID\n@Example 5f0c8a52-6f4e-4c3b-9d7a-1e2b3c4d5e6f",
+                    "type": "string",
+                    "example": "5f0c8a52-6f4e-4c3b-9d7a-1e2b3c4d5e6f"
+                },
+                "client_secret": {
+                    "description": "@Description Client secret, only returned at registration\n@Example Zx3k9Qm1vT7bR2nW8yL4pC6hJ0dF5sA9gE1uK3oI7qM",
+                    "type": "string",
+                    "example": "Zx3k9Qm1vT7bR2nW8yL4pC6hJ0dF5sA9gE1uK3oI7qM"
+                },
+                "created_at": {
+                    "description": "@Description Registration timestamp\n@Example 2024-01-01T00:00:00Z",
+                    "type": "string",
+                    "example": "2024-01-01T00:00:00Z"
+                },
+                "is_active": {
+                    "description": "@Description Whether the client may log users in\n@Example true",
+                    "type": "boolean",
+                    "example": true
+                },
+                "name": {
+                    "description": "@Description Client name\n@Example Example App",
+                    "type": "string",
+                    "example": "Example App"
+                },
+                "public": {
+                    "description": "@Description Whether the client has no secret\n@Example false",
+                    "type": "boolean",
+                    "example": false
+                },
+                "redirect_uris": {
+                    "description": "@Description Registered redirect URIs\n@Example [\"https://app.example.com/callback\"]",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "https://app.example.com/callback"
+                    ]
+                },
+                "scopes": {
+                    "description": "@Description Scopes the client may request\n@Example [\"openid\",\"phone\",\"profile\"]",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "openid",
+                        "phone",
+                        "profile"
+                    ]
+                }
+            }
+        },
+        "dto.OAuthClientsResponse": {
+            "description": "Registered OpenID Connect clients",
+            "type": "object",
+            "properties": {
+                "clients": {
+                    "description": "@Description Clients",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.OAuthClientResponse"
+                    }
+                }
+            }
+        },
+        "dto.OAuthErrorResponse": {
+            "description": "OAuth 2.0 error",
+            "type": "object",
+            "properties": {
+                "error": {
+                    "description": "@Description Error code\n@Example invalid_grant",
+                    "type": "string",
+                    "example": "invalid_grant"
+                },
+                "error_description": {
+                    "description": "@Description Human-readable description\n@Example invalid or expired authorization code",
+                    "type": "string",
+                    "example": "invalid or expired authorization code"
+                }
+            }
+        },
+        "dto.OAuthTokenResponse": {
+            "description": "Tokens issued to an OpenID Connect client or an API client",
+            "type": "object",
+            "properties": {
+                "access_token": {
+                    "description": "@Description Access token\n@Example eyJhbGciOiJSUzI1NiIsImtpZCI6Ii4uLiJ9...",
+                    "type": "string",
+                    "example": "eyJhbGciOiJSUzI1NiIsImtpZCI6Ii4uLiJ9..."
+                },
+                "expires_in": {
+                    "description": "@Description Access token lifetime in seconds\n@Example 900",
+                    "type": "integer",
+                    "example": 900
+                },
+                "id_token": {
+                    "description": "@Description ID token, only for an authorization code\n@Example eyJhbGciOiJSUzI1NiIsImtpZCI6Ii4uLiJ9...",
+                    "type": "string",
+                    "example": "eyJhbGciOiJSUzI1NiIsImtpZCI6Ii4uLiJ9..."
+                },
+                "refresh_token": {
+                    "description": "@Description Refresh token, rotated on every use\n@Example 3q2-7wEjRWeJq83vASNFZ4mrze8BI0VniavN7wEjRWc",
+                    "type": "string",
+                    "example": "3q2-7wEjRWeJq83vASNFZ4mrze8BI0VniavN7wEjRWc"
+                },
+                "scope": {
+                    "description": "@Description Granted scope\n@Example openid phone",
+                    "type": "string",
+                    "example": "openid phone"
+                },
+                "token_type": {
+                    "description": "@Description Token type\n@Example Bearer",
+                    "type": "string",
+                    "example": "Bearer"
+                }
+            }
+        },
+        "dto.OTPStatusResponse": {
+            "description": "Status of an OTP verification challenge",
+            "type": "object",
+            "properties": {
+                "attempts": {
+                    "description": "@Description Number of wrong codes submitted so far\n@Example 1",
+                    "type": "integer",
+                    "example": 1
+                },
+                "attempts_remaining": {
+                    "description": "@Description Number of wrong codes that may still be submitted, counting those sent to other challenges of the number\n@Example 4",
+                    "type": "integer",
+                    "example": 4
+                },
+                "channel": {
+                    "description": "@Description Channel the code was sent over\n@Example sms",
+                    "type": "string",
+                    "example": "sms"
+                },
+                "channels": {
+                    "description": "@Description Channels tried in order when delivery fails or is not confirmed\n@Example [\"sms\",\"voice\"]",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "sms",
+                        "voice"
+                    ]
+                },
+                "created_at": {
+                    "description": "@Description When the challenge was created\n@Example 2024-01-15T10:30:00Z",
+                    "type": "string",
+                    "example": "2024-01-15T10:30:00Z"
+                },
+                "deliveries": {
+                    "description": "@Description Delivery status transitions of every message sent for the challenge, oldest first",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.DeliveryEventResponse"
+                    }
+                },
+                "delivery_status": {
+                    "description": "@Description Delivery state of the last message on the current channel\n@Example sent",
+                    "type": "string",
+                    "enum": [
+                        "queued",
+                        "sent",
+                        "delivered",
+                        "failed",
+                        "expired"
+                    ],
+                    "example": "sent"
+                },
+                "expires_in": {
+                    "description": "@Description Seconds until the code expires (0 once it is no longer valid)\n@Example 95",
+                    "type": "integer",
+                    "example": 95
+                },
+                "locale": {
+                    "description": "@Description Locale of the message\n@Example en",
+                    "type": "string",
+                    "example": "en"
+                },
+                "phone_number": {
+                    "description": "@Description Masked phone number the code was sent to\n@Example +1******7890",
+                    "type": "string",
+                    "example": "+1******7890"
+                },
+                "purpose": {
+                    "description": "@Description What the verified challenge may be used for\n@Example login",
+                    "type": "string",
+                    "example": "login"
+                },
+                "resend_in": {
+                    "description": "@Description Seconds until the code may be resent\n@Example 0",
+                    "type": "integer",
+                    "example": 0
+                },
+                "resends_remaining": {
+                    "description": "@Description Number of resends left for this challenge\n@Example 3",
+                    "type": "integer",
+                    "example": 3
+                },
+                "status": {
+                    "description": "@Description Challenge status\n@Example pending",
+                    "type": "string",
+                    "enum": [
+                        "pending",
+                        "verified",
+                        "failed",
+                        "expired"
+                    ],
+                    "example": "pending"
+                },
+                "valid": {
+                    "description": "@Description Whether the code can still be verified\n@Example true",
+                    "type": "boolean",
+                    "example": true
+                },
+                "verification_id": {
+                    "description": "@Description Verification ID of the challenge\n@Example 3f2b8c1e-7d4a-4e8b-9c1f-2a6d5e4b3c21",
+                    "type": "string",
+                    "example": "3f2b8c1e-7d4a-4e8b-9c1f-2a6d5e4b3c21"
+                }
+            }
+        },
+        "dto.PermissionResponse": {
+            "description": "Permission",
+            "type": "object",
+            "properties": {
+                "description": {
+                    "description": "@Description What the permission allows\n@Example List, view and search users",
+                    "type": "string",
+                    "example": "List, view and search users"
+                },
+                "name": {
+                    "description": "@Description Permission name\n@Example users:read",
+                    "type": "string",
+                    "example": "users:read"
+                }
+            }
+        },
+        "dto.PermissionsResponse": {
+            "description": "Permissions",
+            "type": "object",
+            "properties": {
+                "permissions": {
+                    "description": "@Description Permissions",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.PermissionResponse"
+                    }
+                }
+            }
+        },
+        "dto.RecoveryCodesResponse": {
+            "description": "New recovery codes; they are never shown again and the previous set no longer works",
+            "type": "object",
+            "properties": {
+                "codes": {
+                    "description": "@Description Single-use recovery codes\n@Example [\"7k3mq-x9d2p\",\"c4hv8-2nrbt\"]",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "7k3mq-x9d2p",
+                        "c4hv8-2nrbt"
+                    ]
+                },
+                "remaining": {
+                    "description": "@Description Number of codes in the set\n@Example 10",
+                    "type": "integer",
+                    "example": 10
+                }
+            }
+        },
+        "dto.RecoveryCodesStatusResponse": {
+            "description": "Number of unused recovery codes",
+            "type": "object",
+            "properties": {
+                "remaining": {
+                    "description": "@Description Unused recovery codes left\n@Example 9",
+                    "type": "integer",
+                    "example": 9
+                }
+            }
+        },
+        "dto.RefreshTokenRequest": {
+            "description": "Request to exchange a refresh token for a new token pair",
+            "type": "object",
+            "required": [
+                "refresh_token"
+            ],
+            "properties": {
+                "refresh_token": {
+                    "description": "@Description Refresh token from the last login or refresh\n@Example 8Jd3kQ0vX1mZr7YtP2cW9nB4sLhA6eGuF5iKoTqRjVw\n@Required",
+                    "type": "string",
+                    "example": "8Jd3kQ0vX1mZr7YtP2cW9nB4sLhA6eGuF5iKoTqRjVw"
+                }
+            }
+        },
+        "dto.RefreshTokenResponse": {
+            "description": "Response when access token is refreshed successfully",
+            "type": "object",
+            "properties": {
+                "access_token": {
+                    "description": "@Description New JWT access token\n@Example eyJhbGciOiJIUzI1NiIsInR5cCI6IkpXVCJ9...",
+                    "type": "string",
+                    "example": "eyJhbGciOiJIUzI1NiIsInR5cCI6IkpXVCJ9..."
+                },
+                "expires_in": {
+                    "description": "@Description Token expiration time in seconds\n@Example 900",
+                    "type": "integer",
+                    "example": 900
+                },
+                "message": {
+                    "description": "@Description Success message\n@Example Token refreshed successfully",
+                    "type": "string",
+                    "example": "Token refreshed successfully"
+                },
+                "refresh_expires_in": {
+                    "description": "@Description Refresh token expiration time in seconds\n@Example 2592000",
+                    "type": "integer",
+                    "example": 2592000
+                },
+                "refresh_token": {
+                    "description": "@Description New refresh token; the one presented no longer works\n@Example Qm5vT1xZ8aW3rE0pK7dJ2hF6cN9bL4sGyUiVoXtRjAe",
+                    "type": "string",
+                    "example": "Qm5vT1xZ8aW3rE0pK7dJ2hF6cN9bL4sGyUiVoXtRjAe"
+                }
+            }
+        },
+        "dto.RegisterAPIClientRequest": {
+            "description": "API client to register",
+            "type": "object",
+            "properties": {
+                "name": {
+                    "description": "@Description Client name\n@Example Billing Service",
+                    "type": "string",
+                    "example": "Billing Service"
+                },
+                "rate_limit": {
+                    "description": "@Description Requests allowed per rate limit window; the default limit when omitted\n@Example 1000",
+                    "type": "integer",
+                    "example": 1000
+                },
+                "scopes": {
+                    "description": "@Description Scopes granted to the client: otp:send, users:read, users:admin\n@Example [\"otp:send\",\"users:read\"]",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "otp:send",
+                        "users:read"
+                    ]
+                }
+            }
+        },
+        "dto.RegisterOAuthClientRequest": {
+            "description": "OpenID Connect client to register",
+            "type": "object",
+            "properties": {
+                "name": {
+                    "description": "@Description Client name, shown on the login page\n@Example Example App",
+                    "type": "string",
+                    "example": "Example App"
+                },
+                "public": {
+                    "description": "@Description Whether the client cannot keep a secret, like a single-page or mobile app\n@Example false",
+                    "type": "boolean",
+                    "example": false
+                },
+                "redirect_uris": {
+                    "description": "@Description Redirect URIs; authorization requests must use one of them exactly\n@Example [\"https://app.example.com/callback\"]",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "https://app.example.com/callback"
+                    ]
+                },
+                "scopes": {
+                    "description": "@Description Scopes the client may request; all supported scopes by default\n@Example [\"openid\",\"phone\"]",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "openid",
+                        "phone"
+                    ]
+                }
+            }
+        },
+        "dto.ResendOTPRequest": {
+            "description": "Request to resend the code of an existing OTP challenge",
+            "type": "object",
+            "required": [
+                "verification_id"
+            ],
+            "properties": {
+                "verification_id": {
+                    "description": "@Description Verification ID returned by send-otp\n@Example 3f2b8c1e-7d4a-4e8b-9c1f-2a6d5e4b3c21\n@Required",
+                    "type": "string",
+                    "example": "3f2b8c1e-7d4a-4e8b-9c1f-2a6d5e4b3c21"
+                }
+            }
+        },
+        "dto.ResendOTPResponse": {
+            "description": "Response when OTP is resent successfully",
+            "type": "object",
+            "properties": {
+                "expires_in": {
+                    "description": "@Description Seconds until the code expires\n@Example 120",
+                    "type": "integer",
+                    "example": 120
+                },
+                "message": {
+                    "description": "@Description Success message\n@Example OTP resent successfully",
+                    "type": "string",
+                    "example": "OTP resent successfully"
+                },
+                "resend_in": {
+                    "description": "@Description Seconds until the next resend is allowed\n@Example 30",
+                    "type": "integer",
+                    "example": 30
+                },
+                "resends_remaining": {
+                    "description": "@Description Number of resends left for this challenge\n@Example 2",
+                    "type": "integer",
+                    "example": 2
+                },
+                "timestamp": {
+                    "description": "@Description Timestamp when OTP was resent\n@Example 2024-01-15T10:30:00Z",
+                    "type": "string",
+                    "example": "2024-01-15T10:30:00Z"
+                },
+                "verification_id": {
+                    "description": "@Description Verification ID of the challenge\n@Example 3f2b8c1e-7d4a-4e8b-9c1f-2a6d5e4b3c21",
+                    "type": "string",
+                    "example": "3f2b8c1e-7d4a-4e8b-9c1f-2a6d5e4b3c21"
+                }
+            }
+        },
+        "dto.RoleRequest": {
+            "description": "Role to create or update",
+            "type": "object",
+            "properties": {
+                "description": {
+                    "description": "@Description Role description\n@Example Support staff viewing users",
+                    "type": "string",
+                    "example": "Support staff viewing users"
+                },
+                "permissions": {
+                    "description": "@Description Permissions granted by the role, replacing those it had\n@Example [\"users:read\"]",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "users:read"
+                    ]
+                }
+            }
+        },
+        "dto.RoleResponse": {
+            "description": "Role",
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "description": "@Description Creation timestamp\n@Example 2024-01-01T00:00:00Z",
+                    "type": "string",
+                    "example": "2024-01-01T00:00:00Z"
+                },
+                "description": {
+                    "description": "@Description Role description\n@Example Support staff viewing users",
+                    "type": "string",
+                    "example": "Support staff viewing users"
+                },
+                "id": {
+                    "description": "@Description Role ID\n@Example 3",
+                    "type": "integer",
+                    "example": 3
+                },
+                "name": {
+                    "description": "@Description Role name, as held by users\n@Example support",
+                    "type": "string",
+                    "example": "support"
+                },
+                "permissions": {
+                    "description": "@Description Permissions granted by the role\n@Example [\"users:read\"]",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "users:read"
+                    ]
+                },
+                "updated_at": {
+                    "description": "@Description Last update timestamp\n@Example 2024-01-01T00:00:00Z",
+                    "type": "string",
+                    "example": "2024-01-01T00:00:00Z"
+                }
+            }
+        },
+        "dto.RolesResponse": {
+            "description": "Roles",
+            "type": "object",
+            "properties": {
+                "roles": {
+                    "description": "@Description Roles",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.RoleResponse"
+                    }
+                }
+            }
+        },
+        "dto.SecondFactorRequiredResponse": {
+            "description": "Returned by verify-otp instead of a token when the user has a second factor enrolled",
+            "type": "object",
+            "properties": {
+                "expires_in": {
+                    "description": "@Description Seconds until the MFA token expires\n@Example 300",
+                    "type": "integer",
+                    "example": 300
+                },
+                "factors": {
+                    "description": "@Description Factor types the user can complete the login with\n@Example [\"totp\"]",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "totp"
+                    ]
+                },
+                "mfa_token": {
+                    "description": "@Description Token to present to verify-totp together with the code\n@Example 5f0c1e9a7b3d4c2e8f6a1b0d9c7e5f3a2b1c0d9e8f7a6b5c4d3e2f1a0b9c8d7e",
+                    "type": "string",
+                    "example": "5f0c1e9a7b3d4c2e8f6a1b0d9c7e5f3a2b1c0d9e8f7a6b5c4d3e2f1a0b9c8d7e"
+                },
+                "second_factor_required": {
+                    "description": "@Description Always true\n@Example true",
+                    "type": "boolean",
+                    "example": true
+                }
+            }
+        },
+        "dto.SendConfirmationOTPRequest": {
+            "description": "Request to send a confirmation code to the authenticated user's phone number",
+            "type": "object",
+            "required": [
+                "purpose"
+            ],
+            "properties": {
+                "channel": {
+                    "description": "@Description Preferred delivery channel\n@Example sms",
+                    "type": "string",
+                    "enum": [
+                        "sms",
+                        "voice",
+                        "whatsapp",
+                        "email"
+                    ],
+                    "example": "sms"
+                },
+                "locale": {
+                    "description": "@Description Message locale; defaults to the Accept-Language header\n@Example en",
+                    "type": "string",
+                    "example": "en"
+                },
+                "purpose": {
+                    "description": "@Description Action the code confirms\n@Example account_deletion\n@Required",
+                    "type": "string",
+                    "enum": [
+                        "phone_change",
+                        "account_deletion"
+                    ],
+                    "example": "account_deletion"
+                }
+            }
+        },
+        "dto.SendOTPRequest": {
+            "description": "Request to send OTP to a phone number",
+            "type": "object",
+            "required": [
+                "phone_number"
+            ],
+            "properties": {
+                "channel": {
+                    "description": "@Description Preferred delivery channel; defaults to the first channel configured for the number\n@Example sms",
+                    "type": "string",
+                    "enum": [
+                        "sms",
+                        "voice",
+                        "whatsapp",
+                        "email"
+                    ],
+                    "example": "sms"
+                },
+                "email": {
+                    "description": "@Description Email address, required for the email channel\n@Example user@example.com",
+                    "type": "string",
+                    "example": "user@example.com"
+                },
+                "locale": {
+                    "description": "@Description Message locale; defaults to the Accept-Language header, then the default locale\n@Example fa",
+                    "type": "string",
+                    "example": "fa"
+                },
+                "phone_number": {
+                    "description": "@Description Phone number in international format (e.g., +1234567890)\n@Example +1234567890\n@Required",
+                    "type": "string",
+                    "example": "+1234567890"
+                }
+            }
+        },
+        "dto.SendOTPResponse": {
+            "description": "Response when OTP is sent successfully",
+            "type": "object",
+            "properties": {
+                "channel": {
+                    "description": "@Description Channel the code was sent over\n@Example sms",
+                    "type": "string",
+                    "example": "sms"
+                },
+                "expires_in": {
+                    "description": "@Description Seconds until the code expires\n@Example 120",
+                    "type": "integer",
+                    "example": 120
+                },
+                "locale": {
+                    "description": "@Description Locale the message was written in\n@Example en",
+                    "type": "string",
+                    "example": "en"
+                },
+                "message": {
+                    "description": "@Description Success message\n@Example OTP sent successfully",
+                    "type": "string",
+                    "example": "OTP sent successfully"
+                },
+                "phone_number": {
+                    "description": "@Description Phone number where OTP was sent\n@Example +1234567890",
+                    "type": "string",
+                    "example": "+1234567890"
+                },
+                "timestamp": {
+                    "description": "@Description Timestamp when OTP was sent\n@Example 2024-01-15T10:30:00Z",
+                    "type": "string",
+                    "example": "2024-01-15T10:30:00Z"
+                },
+                "verification_id": {
+                    "description": "@Description Verification ID to present to verify-otp together with the code\n@Example 3f2b8c1e-7d4a-4e8b-9c1f-2a6d5e4b3c21",
+                    "type": "string",
+                    "example": "3f2b8c1e-7d4a-4e8b-9c1f-2a6d5e4b3c21"
+                }
+            }
+        },
+        "dto.SendPhoneConfirmationRequest": {
+            "description": "Request to send a code to the phone number to confirm after a recovery code login",
+            "type": "object",
+            "required": [
+                "phone_number"
+            ],
+            "properties": {
+                "channel": {
+                    "description": "@Description Preferred delivery channel\n@Example sms",
+                    "type": "string",
+                    "enum": [
+                        "sms",
+                        "voice",
+                        "whatsapp",
+                        "email"
+                    ],
+                    "example": "sms"
+                },
+                "locale": {
+                    "description": "@Description Message locale; defaults to the Accept-Language header\n@Example en",
+                    "type": "string",
+                    "example": "en"
                 },
                 "phone_number": {
-                    "description": "@Description User's phone number\n@Example +1234567890",
+                    "description": "@Description Phone number to confirm in international format\n@Example +1234567890\n@Required",
                     "type": "string",
                     "example": "+1234567890"
-                },
-                "role": {
-                    "description": "@Description User's role in the system\n@Example user",
+                }
+            }
+        },
+        "dto.SendTransactionOTPRequest": {
+            "description": "Request to send a code signing a transaction to the authenticated user's phone number",
+            "type": "object",
+            "required": [
+                "transaction"
+            ],
+            "properties": {
+                "channel": {
+                    "description": "@Description Preferred delivery channel\n@Example sms",
                     "type": "string",
                     "enum": [
-                        "user",
-                        "admin"
+                        "sms",
+                        "voice",
+                        "whatsapp",
+                        "email"
                     ],
-                    "example": "user"
+                    "example": "sms"
+                },
+                "locale": {
+                    "description": "@Description Message locale; defaults to the Accept-Language header\n@Example en",
+                    "type": "string",
+                    "example": "en"
+                },
+                "transaction": {
+                    "description": "@Description Operation to sign\n@Required",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/dto.TransactionPayload"
+                        }
+                    ]
                 }
             }
         },
-        "dto.ErrorResponse": {
-            "description": "Standard error response format",
+        "dto.SendTransactionOTPResponse": {
+            "description": "Response when a transaction code is sent",
             "type": "object",
             "properties": {
-                "error": {
-                    "description": "@Description Error type or category\n@Example validation_error",
+                "channel": {
+                    "description": "@Description Channel the code was sent over\n@Example sms",
                     "type": "string",
-                    "example": "validation_error"
+                    "example": "sms"
+                },
+                "expires_in": {
+                    "description": "@Description Seconds until the code expires\n@Example 300",
+                    "type": "integer",
+                    "example": 300
+                },
+                "locale": {
+                    "description": "@Description Locale the message was written in\n@Example en",
+                    "type": "string",
+                    "example": "en"
                 },
                 "message": {
-                    "description": "@Description Detailed error message\n@Example Phone number format is invalid",
+                    "description": "@Description Success message\n@Example OTP sent successfully",
                     "type": "string",
-                    "example": "Phone number format is invalid"
+                    "example": "OTP sent successfully"
+                },
+                "payload_hash": {
+                    "description": "@Description SHA-256 of the canonical payload the code is bound to\n@Example 9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08",
+                    "type": "string",
+                    "example": "9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08"
+                },
+                "phone_number": {
+                    "description": "@Description Phone number where OTP was sent\n@Example +1234567890",
+                    "type": "string",
+                    "example": "+1234567890"
+                },
+                "summary": {
+                    "description": "@Description Summary of the operation shown in the message\n@Example transfer 100.5 EUR to DE89370400440532013000 (ref INV-2024-001)",
+                    "type": "string",
+                    "example": "transfer 100.5 EUR to DE89370400440532013000 (ref INV-2024-001)"
+                },
+                "timestamp": {
+                    "description": "@Description Timestamp when OTP was sent\n@Example 2024-01-15T10:30:00Z",
+                    "type": "string",
+                    "example": "2024-01-15T10:30:00Z"
+                },
+                "verification_id": {
+                    "description": "@Description Verification ID to present with the code and the same payload\n@Example 3f2b8c1e-7d4a-4e8b-9c1f-2a6d5e4b3c21",
+                    "type": "string",
+                    "example": "3f2b8c1e-7d4a-4e8b-9c1f-2a6d5e4b3c21"
                 }
             }
         },
-        "dto.SendOTPRequest": {
-            "description": "Request to send OTP to a phone number",
+        "dto.SessionResponse": {
+            "description": "Login of the user on one device",
+            "type": "object",
+            "properties": {
+                "client_id": {
+                    "description": "@Description OpenID Connect client the user logged in to, if any\n@Example 5f0c8a52-6f4e-4c3b-9d7a-1e2b3c4d5e6f",
+                    "type": "string",
+                    "example": "5f0c8a52-6f4e-4c3b-9d7a-1e2b3c4d5e6f"
+                },
+                "created_at": {
+                    "description": "@Description Login timestamp\n@Example 2024-01-01T00:00:00Z",
+                    "type": "string",
+                    "example": "2024-01-01T00:00:00Z"
+                },
+                "current": {
+                    "description": "@Description Whether this is the session of the request\n@Example true",
+                    "type": "boolean",
+                    "example": true
+                },
+                "device_name": {
+                    "description": "@Description Device name given at login\n@Example Pixel 8",
+                    "type": "string",
+                    "example": "Pixel 8"
+                },
+                "expires_at": {
+                    "description": "@Description When the session ends unless it refreshes its tokens\n@Example 2024-02-14T10:30:00Z",
+                    "type": "string",
+                    "example": "2024-02-14T10:30:00Z"
+                },
+                "id": {
+                    "description": "@Description Session ID\n@Example 9b2f4c1e-3a7d-4e8b-8c1f-2a6d5e4b3c21",
+                    "type": "string",
+                    "example": "9b2f4c1e-3a7d-4e8b-8c1f-2a6d5e4b3c21"
+                },
+                "ip_address": {
+                    "description": "@Description Client IP of the login request\n@Example 203.0.113.7",
+                    "type": "string",
+                    "example": "203.0.113.7"
+                },
+                "last_used_at": {
+                    "description": "@Description When the session last refreshed its tokens\n@Example 2024-01-15T10:30:00Z",
+                    "type": "string",
+                    "example": "2024-01-15T10:30:00Z"
+                },
+                "user_agent": {
+                    "description": "@Description User-Agent of the login request\n@Example Mozilla/5.0 (Linux; Android 14; Pixel 8)",
+                    "type": "string",
+                    "example": "Mozilla/5.0 (Linux; Android 14; Pixel 8)"
+                }
+            }
+        },
+        "dto.SessionsResponse": {
+            "description": "Active sessions of the user",
+            "type": "object",
+            "properties": {
+                "sessions": {
+                    "description": "@Description Sessions, newest first",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.SessionResponse"
+                    }
+                }
+            }
+        },
+        "dto.SetUserStatusRequest": {
+            "description": "Status to give the user",
+            "type": "object",
+            "required": [
+                "status"
+            ],
+            "properties": {
+                "reason": {
+                    "description": "@Description Why the status is set, up to 500 characters\n@Example Repeated spam reports",
+                    "type": "string",
+                    "example": "Repeated spam reports"
+                },
+                "status": {
+                    "description": "@Description New status\n@Example suspended\n@Required",
+                    "type": "string",
+                    "enum": [
+                        "active",
+                        "suspended",
+                        "banned",
+                        "pending_deletion",
+                        "deactivated"
+                    ],
+                    "example": "suspended"
+                },
+                "until": {
+                    "description": "@Description When the suspension lifts; required for, and only allowed with, the suspended status\n@Example 2024-02-01T00:00:00Z",
+                    "type": "string",
+                    "example": "2024-02-01T00:00:00Z"
+                }
+            }
+        },
+        "dto.StartPhoneChangeRequest": {
+            "description": "Request to send codes confirming a change of the user's phone number",
             "type": "object",
             "required": [
                 "phone_number"
             ],
             "properties": {
+                "channel": {
+                    "description": "@Description Preferred delivery channel for the new number\n@Example sms",
+                    "type": "string",
+                    "enum": [
+                        "sms",
+                        "voice",
+                        "whatsapp",
+                        "email"
+                    ],
+                    "example": "sms"
+                },
+                "locale": {
+                    "description": "@Description Message locale; defaults to the Accept-Language header\n@Example en",
+                    "type": "string",
+                    "example": "en"
+                },
                 "phone_number": {
-                    "description": "@Description Phone number in international format (e.g., +1234567890)\n@Example +1234567890\n@Required",
+                    "description": "@Description New phone number in international format\n@Example +1987654321\n@Required",
                     "type": "string",
-                    "example": "+1234567890"
+                    "example": "+1987654321"
+                }
+            }
+        },
+        "dto.StartPhoneChangeResponse": {
+            "description": "Verification IDs of the codes sent to the new and, when required, the current phone number",
+            "type": "object",
+            "properties": {
+                "channel": {
+                    "description": "@Description Channel the code was sent to the new number over\n@Example sms",
+                    "type": "string",
+                    "example": "sms"
+                },
+                "expires_in": {
+                    "description": "@Description Seconds until the code sent to the new number expires\n@Example 120",
+                    "type": "integer",
+                    "example": 120
+                },
+                "message": {
+                    "description": "@Description Success message\n@Example OTP sent successfully",
+                    "type": "string",
+                    "example": "OTP sent successfully"
+                },
+                "old_expires_in": {
+                    "description": "@Description Seconds until the code sent to the current number expires\n@Example 120",
+                    "type": "integer",
+                    "example": 120
+                },
+                "old_verification_id": {
+                    "description": "@Description Verification ID of the code sent to the current number; omitted when the tenant does not require it\n@Example 9a4c2e6b-1f3d-4b8a-8e2c-7d5f1a3b9c64",
+                    "type": "string",
+                    "example": "9a4c2e6b-1f3d-4b8a-8e2c-7d5f1a3b9c64"
+                },
+                "phone_number": {
+                    "description": "@Description New phone number, masked\n@Example +1******4321",
+                    "type": "string",
+                    "example": "+1******4321"
+                },
+                "timestamp": {
+                    "description": "@Description Timestamp when the codes were sent\n@Example 2024-01-15T10:30:00Z",
+                    "type": "string",
+                    "example": "2024-01-15T10:30:00Z"
+                },
+                "verification_id": {
+                    "description": "@Description Verification ID of the code sent to the new number\n@Example 3f2b8c1e-7d4a-4e8b-9c1f-2a6d5e4b3c21",
+                    "type": "string",
+                    "example": "3f2b8c1e-7d4a-4e8b-9c1f-2a6d5e4b3c21"
+                }
+            }
+        },
+        "dto.StartTOTPEnrollmentRequest": {
+            "description": "Request to start enrolling an authenticator app",
+            "type": "object",
+            "properties": {
+                "name": {
+                    "description": "@Description Label for the factor, e.g. the device name\n@Example Work phone",
+                    "type": "string",
+                    "example": "Work phone"
+                }
+            }
+        },
+        "dto.StepUpRequest": {
+            "description": "Request to send a code for authenticating again",
+            "type": "object",
+            "properties": {
+                "channel": {
+                    "description": "@Description Preferred delivery channel\n@Example sms",
+                    "type": "string",
+                    "enum": [
+                        "sms",
+                        "voice",
+                        "whatsapp",
+                        "email"
+                    ],
+                    "example": "sms"
+                },
+                "locale": {
+                    "description": "@Description Message locale; defaults to the Accept-Language header\n@Example en",
+                    "type": "string",
+                    "example": "en"
+                }
+            }
+        },
+        "dto.StepUpResponse": {
+            "description": "Elevated access token for the current session",
+            "type": "object",
+            "properties": {
+                "auth_time": {
+                    "description": "@Description Authentication time as Unix time, carried in the auth_time claim\n@Example 1705313700",
+                    "type": "integer",
+                    "example": 1705313700
+                },
+                "expires_in": {
+                    "description": "@Description Elevated token lifetime in seconds\n@Example 300",
+                    "type": "integer",
+                    "example": 300
+                },
+                "message": {
+                    "description": "@Description Success message\n@Example Authentication confirmed",
+                    "type": "string",
+                    "example": "Authentication confirmed"
+                },
+                "token": {
+                    "description": "@Description Elevated JWT access token; use it for sensitive actions until it expires, then go on with the session's regular tokens\n@Example eyJhbGciOiJIUzI1NiIsInR5cCI6IkpXVCJ9...",
+                    "type": "string",
+                    "example": "eyJhbGciOiJIUzI1NiIsInR5cCI6IkpXVCJ9..."
+                }
+            }
+        },
+        "dto.TOTPEnrollmentResponse": {
+            "description": "Secret and key URI for the authenticator app; the secret is never shown again",
+            "type": "object",
+            "properties": {
+                "expires_in": {
+                    "description": "@Description Seconds left to confirm the enrollment\n@Example 600",
+                    "type": "integer",
+                    "example": 600
+                },
+                "factor_id": {
+                    "description": "@Description ID of the new, unconfirmed factor\n@Example 1",
+                    "type": "integer",
+                    "example": 1
+                },
+                "name": {
+                    "description": "@Description Factor label\n@Example Work phone",
+                    "type": "string",
+                    "example": "Work phone"
+                },
+                "otpauth_uri": {
+                    "description": "@Description otpauth:// key URI, usually shown as a QR code\n@Example otpauth://totp/OTP%20Server:+1234567890?algorithm=SHA1\u0026digits=6\u0026issuer=OTP+Server\u0026period=30\u0026secret=JBSWY3DPEHPK3PXPJBSWY3DPEHPK3PXP",
+                    "type": "string",
+                    "example": "otpauth://totp/OTP%20Server:+1234567890?algorithm=SHA1\u0026digits=6\u0026issuer=OTP+Server\u0026period=30\u0026secret=JBSWY3DPEHPK3PXPJBSWY3DPEHPK3PXP"
+                },
+                "secret": {
+                    "description": "@Description Base32 secret for manual entry\n@Example JBSWY3DPEHPK3PXPJBSWY3DPEHPK3PXP",
+                    "type": "string",
+                    "example": "JBSWY3DPEHPK3PXPJBSWY3DPEHPK3PXP"
+                }
+            }
+        },
+        "dto.TenantRequest": {
+            "description": "Tenant to create or update",
+            "type": "object",
+            "properties": {
+                "hosts": {
+                    "description": "@Description Request hosts resolved to the tenant\n@Example [\"login.acme.com\"]",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "login.acme.com"
+                    ]
+                },
+                "is_active": {
+                    "description": "@Description Whether the tenant serves requests; defaults to true on creation\n@Example true",
+                    "type": "boolean",
+                    "example": true
+                },
+                "name": {
+                    "description": "@Description Tenant name\n@Example Acme",
+                    "type": "string",
+                    "example": "Acme"
+                },
+                "settings": {
+                    "description": "@Description Overrides of the OTP_*, RATE_LIMIT_* and message template settings, keyed by environment variable name",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "slug": {
+                    "description": "@Description Tenant identifier sent in the X-Tenant header: lowercase letters, digits and dashes; cannot be changed\n@Example acme",
+                    "type": "string",
+                    "example": "acme"
+                }
+            }
+        },
+        "dto.TenantResponse": {
+            "description": "Tenant",
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "description": "@Description Creation timestamp\n@Example 2024-01-01T00:00:00Z",
+                    "type": "string",
+                    "example": "2024-01-01T00:00:00Z"
+                },
+                "hosts": {
+                    "description": "@Description Request hosts resolved to the tenant\n@Example [\"login.acme.com\"]",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "login.acme.com"
+                    ]
+                },
+                "id": {
+                    "description": "@Description Tenant ID\n@Example 2",
+                    "type": "integer",
+                    "example": 2
+                },
+                "is_active": {
+                    "description": "@Description Whether the tenant serves requests\n@Example true",
+                    "type": "boolean",
+                    "example": true
+                },
+                "name": {
+                    "description": "@Description Tenant name\n@Example Acme",
+                    "type": "string",
+                    "example": "Acme"
+                },
+                "settings": {
+                    "description": "@Description Overrides of the server settings, keyed by environment variable name",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "slug": {
+                    "description": "@Description Tenant identifier sent in the X-Tenant header\n@Example acme",
+                    "type": "string",
+                    "example": "acme"
+                },
+                "updated_at": {
+                    "description": "@Description Last update timestamp\n@Example 2024-01-01T00:00:00Z",
+                    "type": "string",
+                    "example": "2024-01-01T00:00:00Z"
+                }
+            }
+        },
+        "dto.TenantsResponse": {
+            "description": "Tenants",
+            "type": "object",
+            "properties": {
+                "tenants": {
+                    "description": "@Description Tenants",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.TenantResponse"
+                    }
                 }
             }
         },
-        "dto.SendOTPResponse": {
-            "description": "Response when OTP is sent successfully",
+        "dto.TransactionPayload": {
+            "description": "Operation a transaction code signs; the same payload must be presented to verify the code",
             "type": "object",
+            "required": [
+                "amount",
+                "currency",
+                "operation",
+                "recipient"
+            ],
             "properties": {
-                "message": {
-                    "description": "@Description Success message\n@Example OTP sent successfully",
+                "amount": {
+                    "description": "@Description Amount as a decimal string\n@Example 100.50\n@Required",
                     "type": "string",
-                    "example": "OTP sent successfully"
+                    "example": "100.50"
                 },
-                "phone_number": {
-                    "description": "@Description Phone number where OTP was sent\n@Example +1234567890",
+                "currency": {
+                    "description": "@Description ISO 4217 currency code\n@Example EUR\n@Required",
                     "type": "string",
-                    "example": "+1234567890"
+                    "example": "EUR"
                 },
-                "timestamp": {
-                    "description": "@Description Timestamp when OTP was sent\n@Example 2024-01-15T10:30:00Z",
+                "operation": {
+                    "description": "@Description Operation type\n@Example transfer\n@Required",
                     "type": "string",
-                    "example": "2024-01-15T10:30:00Z"
+                    "example": "transfer"
+                },
+                "recipient": {
+                    "description": "@Description Payee, e.g. an IBAN or account name\n@Example DE89370400440532013000\n@Required",
+                    "type": "string",
+                    "example": "DE89370400440532013000"
+                },
+                "reference": {
+                    "description": "@Description Optional payment reference\n@Example INV-2024-001",
+                    "type": "string",
+                    "example": "INV-2024-001"
                 }
             }
         },
@@ -454,7 +5285,7 @@ const docTemplate = `{
                     "example": 123
                 },
                 "is_active": {
-                    "description": "@Description Whether the user account is active\n@Example true",
+                    "description": "@Description Whether the user can log in, i.e. the status is active\n@Example true",
                     "type": "boolean",
                     "example": true
                 },
@@ -473,6 +5304,28 @@ const docTemplate = `{
                     "type": "string",
                     "example": "user"
                 },
+                "status": {
+                    "description": "@Description Account status; a suspension whose time has passed shows as active\n@Example active",
+                    "type": "string",
+                    "enum": [
+                        "active",
+                        "suspended",
+                        "banned",
+                        "pending_deletion",
+                        "deactivated"
+                    ],
+                    "example": "active"
+                },
+                "status_reason": {
+                    "description": "@Description Why the status was set; admin API only\n@Example Chargeback fraud",
+                    "type": "string",
+                    "example": "Chargeback fraud"
+                },
+                "status_until": {
+                    "description": "@Description When the suspension lifts; admin API only\n@Example 2024-02-01T00:00:00Z",
+                    "type": "string",
+                    "example": "2024-02-01T00:00:00Z"
+                },
                 "updated_at": {
                     "description": "@Description Last profile update timestamp\n@Example 2024-01-01T00:00:00Z",
                     "type": "string",
@@ -480,34 +5333,413 @@ const docTemplate = `{
                 }
             }
         },
+        "dto.UserStatusChangeResponse": {
+            "description": "Status change",
+            "type": "object",
+            "properties": {
+                "actor": {
+                    "description": "@Description Who changed the status: user:\u003cid\u003e or api_client:\u003cclient_id\u003e\n@Example user:1",
+                    "type": "string",
+                    "example": "user:1"
+                },
+                "created_at": {
+                    "description": "@Description When the status changed\n@Example 2024-01-15T10:30:00Z",
+                    "type": "string",
+                    "example": "2024-01-15T10:30:00Z"
+                },
+                "previous_status": {
+                    "description": "@Description Status before the change\n@Example active",
+                    "type": "string",
+                    "example": "active"
+                },
+                "reason": {
+                    "description": "@Description Why the status was set\n@Example Repeated spam reports",
+                    "type": "string",
+                    "example": "Repeated spam reports"
+                },
+                "status": {
+                    "description": "@Description Status after the change\n@Example suspended",
+                    "type": "string",
+                    "example": "suspended"
+                },
+                "until": {
+                    "description": "@Description When the suspension lifts\n@Example 2024-02-01T00:00:00Z",
+                    "type": "string",
+                    "example": "2024-02-01T00:00:00Z"
+                }
+            }
+        },
+        "dto.UserStatusHistoryResponse": {
+            "description": "Status changes, newest first",
+            "type": "object",
+            "properties": {
+                "changes": {
+                    "description": "@Description Status changes, newest first",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.UserStatusChangeResponse"
+                    }
+                }
+            }
+        },
+        "dto.UsersListResponse": {
+            "description": "Response containing a list of users with pagination info",
+            "type": "object",
+            "properties": {
+                "limit": {
+                    "description": "@Description Current limit\n@Example 10",
+                    "type": "integer",
+                    "example": 10
+                },
+                "offset": {
+                    "description": "@Description Current offset\n@Example 0",
+                    "type": "integer",
+                    "example": 0
+                },
+                "total": {
+                    "description": "@Description Total number of users\n@Example 100",
+                    "type": "integer",
+                    "example": 100
+                },
+                "users": {
+                    "description": "@Description List of users",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.UserResponse"
+                    }
+                }
+            }
+        },
+        "dto.VerifyConfirmationOTPRequest": {
+            "description": "Request to verify a confirmation code for a sensitive action",
+            "type": "object",
+            "required": [
+                "otp",
+                "purpose",
+                "verification_id"
+            ],
+            "properties": {
+                "otp": {
+                    "description": "@Description One-time password\n@Example 123456\n@Required",
+                    "type": "string",
+                    "example": "123456"
+                },
+                "purpose": {
+                    "description": "@Description Action the code confirms; must match the purpose it was sent for\n@Example account_deletion\n@Required",
+                    "type": "string",
+                    "enum": [
+                        "phone_change",
+                        "account_deletion"
+                    ],
+                    "example": "account_deletion"
+                },
+                "verification_id": {
+                    "description": "@Description Verification ID returned by the send request\n@Example 3f2b8c1e-7d4a-4e8b-9c1f-2a6d5e4b3c21\n@Required",
+                    "type": "string",
+                    "example": "3f2b8c1e-7d4a-4e8b-9c1f-2a6d5e4b3c21"
+                }
+            }
+        },
+        "dto.VerifyConfirmationOTPResponse": {
+            "description": "Confirmation of a verified action code",
+            "type": "object",
+            "properties": {
+                "message": {
+                    "description": "@Description Success message\n@Example OTP verified successfully",
+                    "type": "string",
+                    "example": "OTP verified successfully"
+                },
+                "purpose": {
+                    "description": "@Description Action the code confirmed\n@Example account_deletion",
+                    "type": "string",
+                    "example": "account_deletion"
+                },
+                "verification_id": {
+                    "description": "@Description Verification ID of the verified challenge\n@Example 3f2b8c1e-7d4a-4e8b-9c1f-2a6d5e4b3c21",
+                    "type": "string",
+                    "example": "3f2b8c1e-7d4a-4e8b-9c1f-2a6d5e4b3c21"
+                }
+            }
+        },
         "dto.VerifyOTPRequest": {
             "description": "Request to verify OTP and authenticate user",
             "type": "object",
             "required": [
                 "name",
-                "otp",
-                "phone_number"
+                "verification_id"
             ],
             "properties": {
+                "device_name": {
+                    "description": "@Description Name of the device logging in, shown in the session list\n@Example Pixel 8",
+                    "type": "string",
+                    "example": "Pixel 8"
+                },
                 "name": {
                     "description": "@Description User's name (required for new user registration)\n@Example John Doe\n@Required",
                     "type": "string",
                     "example": "John Doe"
                 },
                 "otp": {
-                    "description": "@Description One-time password (6 digits)\n@Example 123456\n@Required",
+                    "description": "@Description One-time password (6 digits); required unless a recovery code is given\n@Example 123456",
                     "type": "string",
                     "example": "123456"
                 },
-                "phone_number": {
-                    "description": "@Description Phone number in international format\n@Example +1234567890\n@Required",
+                "recovery_code": {
+                    "description": "@Description Recovery code to log in with instead of the OTP, for users who lost their phone\n@Example 7k3mq-x9d2p",
                     "type": "string",
-                    "example": "+1234567890"
+                    "example": "7k3mq-x9d2p"
+                },
+                "verification_id": {
+                    "description": "@Description Verification ID returned by send-otp\n@Example 3f2b8c1e-7d4a-4e8b-9c1f-2a6d5e4b3c21\n@Required",
+                    "type": "string",
+                    "example": "3f2b8c1e-7d4a-4e8b-9c1f-2a6d5e4b3c21"
+                }
+            }
+        },
+        "dto.VerifyStepUpRequest": {
+            "description": "Request to verify a step-up code",
+            "type": "object",
+            "required": [
+                "otp",
+                "verification_id"
+            ],
+            "properties": {
+                "otp": {
+                    "description": "@Description One-time password\n@Example 123456\n@Required",
+                    "type": "string",
+                    "example": "123456"
+                },
+                "verification_id": {
+                    "description": "@Description Verification ID returned by the send request\n@Example 3f2b8c1e-7d4a-4e8b-9c1f-2a6d5e4b3c21\n@Required",
+                    "type": "string",
+                    "example": "3f2b8c1e-7d4a-4e8b-9c1f-2a6d5e4b3c21"
+                }
+            }
+        },
+        "dto.VerifyTOTPRequest": {
+            "description": "Request to complete a login with an authenticator app code",
+            "type": "object",
+            "required": [
+                "code",
+                "mfa_token"
+            ],
+            "properties": {
+                "code": {
+                    "description": "@Description Current code from the authenticator app\n@Example 287082\n@Required",
+                    "type": "string",
+                    "example": "287082"
+                },
+                "device_name": {
+                    "description": "@Description Name of the device logging in, shown in the session list\n@Example Pixel 8",
+                    "type": "string",
+                    "example": "Pixel 8"
+                },
+                "mfa_token": {
+                    "description": "@Description MFA token returned by verify-otp\n@Example 5f0c1e9a7b3d4c2e8f6a1b0d9c7e5f3a2b1c0d9e8f7a6b5c4d3e2f1a0b9c8d7e\n@Required",
+                    "type": "string",
+                    "example": "5f0c1e9a7b3d4c2e8f6a1b0d9c7e5f3a2b1c0d9e8f7a6b5c4d3e2f1a0b9c8d7e"
+                }
+            }
+        },
+        "dto.VerifyTransactionOTPRequest": {
+            "description": "Request to verify a transaction code against the payload it signs",
+            "type": "object",
+            "required": [
+                "otp",
+                "transaction",
+                "verification_id"
+            ],
+            "properties": {
+                "otp": {
+                    "description": "@Description One-time password\n@Example 123456\n@Required",
+                    "type": "string",
+                    "example": "123456"
+                },
+                "transaction": {
+                    "description": "@Description Operation being executed; must match the signed payload\n@Required",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/dto.TransactionPayload"
+                        }
+                    ]
+                },
+                "verification_id": {
+                    "description": "@Description Verification ID returned by the send request\n@Example 3f2b8c1e-7d4a-4e8b-9c1f-2a6d5e4b3c21\n@Required",
+                    "type": "string",
+                    "example": "3f2b8c1e-7d4a-4e8b-9c1f-2a6d5e4b3c21"
+                }
+            }
+        },
+        "dto.VerifyTransactionOTPResponse": {
+            "description": "Confirmation that the transaction was signed",
+            "type": "object",
+            "properties": {
+                "message": {
+                    "description": "@Description Success message\n@Example Transaction confirmed",
+                    "type": "string",
+                    "example": "Transaction confirmed"
+                },
+                "operation": {
+                    "description": "@Description Operation that was signed\n@Example transfer",
+                    "type": "string",
+                    "example": "transfer"
+                },
+                "payload_hash": {
+                    "description": "@Description SHA-256 of the canonical payload that was signed\n@Example 9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08",
+                    "type": "string",
+                    "example": "9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08"
+                },
+                "verification_id": {
+                    "description": "@Description Verification ID of the verified challenge\n@Example 3f2b8c1e-7d4a-4e8b-9c1f-2a6d5e4b3c21",
+                    "type": "string",
+                    "example": "3f2b8c1e-7d4a-4e8b-9c1f-2a6d5e4b3c21"
+                }
+            }
+        },
+        "keyring.JSONWebKey": {
+            "type": "object",
+            "properties": {
+                "alg": {
+                    "type": "string"
+                },
+                "crv": {
+                    "type": "string"
+                },
+                "e": {
+                    "type": "string"
+                },
+                "kid": {
+                    "type": "string"
+                },
+                "kty": {
+                    "type": "string"
+                },
+                "n": {
+                    "type": "string"
+                },
+                "use": {
+                    "type": "string"
+                },
+                "x": {
+                    "type": "string"
+                },
+                "y": {
+                    "type": "string"
+                }
+            }
+        },
+        "keyring.JWKSet": {
+            "type": "object",
+            "properties": {
+                "keys": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/keyring.JSONWebKey"
+                    }
+                }
+            }
+        },
+        "services.ProviderMetadata": {
+            "type": "object",
+            "properties": {
+                "acr_values_supported": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "authorization_endpoint": {
+                    "type": "string"
+                },
+                "claims_supported": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "code_challenge_methods_supported": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "grant_types_supported": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "id_token_signing_alg_values_supported": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "introspection_endpoint": {
+                    "type": "string"
+                },
+                "introspection_endpoint_auth_methods_supported": {
+                    "description": "Introspection is only open to confidential clients",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "issuer": {
+                    "type": "string"
+                },
+                "jwks_uri": {
+                    "type": "string"
+                },
+                "response_types_supported": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "revocation_endpoint": {
+                    "type": "string"
+                },
+                "revocation_endpoint_auth_methods_supported": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "scopes_supported": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "subject_types_supported": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "token_endpoint": {
+                    "type": "string"
+                },
+                "token_endpoint_auth_methods_supported": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "userinfo_endpoint": {
+                    "type": "string"
                 }
             }
         }
     },
     "securityDefinitions": {
+        "ApiKeyAuth": {
+            "description": "API key of an API client, on routes open to clients.",
+            "type": "apiKey",
+            "name": "X-API-Key",
+            "in": "header"
+        },
         "BearerAuth": {
             "description": "Type \"Bearer\" followed by a space and JWT token.",
             "type": "apiKey",
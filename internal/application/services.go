@@ -2,61 +2,262 @@ package application
 
 import (
 	"context"
+	"fmt"
+	"time"
+
 	"otp-server/internal/infrastructure/config"
 	log "otp-server/internal/infrastructure/logger"
 
 	"otp-server/internal/application/services"
 	"otp-server/internal/domain/entities"
+	"otp-server/internal/domain/repositories"
 	"otp-server/internal/infrastructure/cache"
 	"otp-server/internal/infrastructure/database"
+	"otp-server/internal/infrastructure/delivery"
 	"otp-server/internal/infrastructure/events"
+	"otp-server/internal/infrastructure/keyring"
 	"otp-server/internal/infrastructure/metrics"
 	"otp-server/internal/infrastructure/redis"
 )
 
 // Service interfaces
 type AuthServiceInterface interface {
-	SendOTP(ctx context.Context, phoneNumber string) error
-	VerifyOTPAndAuthenticate(ctx context.Context, phoneNumber, otpCode, name string) (*entities.User, string, error)
+	SendOTP(ctx context.Context, req *entities.OTPRequest) (*entities.OTPChallenge, error)
+	GetOTPStatus(ctx context.Context, verificationID string) (*services.OTPStatus, error)
+	ResendOTP(ctx context.Context, verificationID string) (*services.OTPStatus, error)
+	VerifyOTPAndAuthenticate(ctx context.Context, verificationID, otpCode, name string, device *entities.DeviceInfo) (*services.AuthResult, error)
+	VerifySecondFactor(ctx context.Context, mfaToken, code string, device *entities.DeviceInfo) (*services.AuthResult, error)
+	RefreshTokens(ctx context.Context, refreshToken string) (*services.AuthResult, error)
+	Logout(ctx context.Context, user *entities.User, tokenString string) error
+	LogoutAll(ctx context.Context, user *entities.User) error
+	VerifyRecoveryCodeAndAuthenticate(ctx context.Context, verificationID, recoveryCode string, device *entities.DeviceInfo) (*services.AuthResult, error)
+	SendPhoneConfirmationOTP(ctx context.Context, user *entities.User, req *entities.OTPRequest) (*entities.OTPChallenge, error)
+	ConfirmPhoneNumber(ctx context.Context, user *entities.User, verificationID, otpCode string, device *entities.DeviceInfo) (*services.AuthResult, error)
+	StartPhoneChange(ctx context.Context, user *entities.User, req *entities.OTPRequest) (*services.PhoneChange, error)
+	ChangePhoneNumber(ctx context.Context, user *entities.User, verificationID, otpCode, oldVerificationID, oldOTPCode string, device *entities.DeviceInfo) (*services.AuthResult, error)
+	SendConfirmationOTP(ctx context.Context, user *entities.User, req *entities.OTPRequest) (*entities.OTPChallenge, error)
+	VerifyConfirmationOTP(ctx context.Context, user *entities.User, verificationID string, purpose entities.OTPPurpose, otpCode string) (*entities.OTPChallenge, error)
+	SendTransactionOTP(ctx context.Context, user *entities.User, req *entities.OTPRequest) (*entities.OTPChallenge, error)
+	VerifyTransactionOTP(ctx context.Context, user *entities.User, verificationID string, payload *entities.TransactionPayload, otpCode string) (*entities.OTPChallenge, error)
 	GetUserFromToken(tokenString string) (*entities.User, error)
+	SendStepUpOTP(ctx context.Context, user *entities.User, req *entities.OTPRequest) (*entities.OTPChallenge, error)
+	StepUp(ctx context.Context, user *entities.User, tokenString, verificationID, otpCode string) (*services.StepUpResult, error)
+	AuthTime(tokenString string) time.Time
+}
+
+type MFAServiceInterface interface {
+	StartTOTPEnrollment(ctx context.Context, user *entities.User, name string) (*services.TOTPEnrollment, error)
+	ConfirmTOTPEnrollment(ctx context.Context, user *entities.User, factorID int, code string) (*entities.UserFactor, error)
+	ListFactors(ctx context.Context, user *entities.User) ([]*entities.UserFactor, error)
+	RemoveFactor(ctx context.Context, user *entities.User, factorID int) error
+}
+
+type RecoveryServiceInterface interface {
+	GenerateCodes(ctx context.Context, user *entities.User) ([]string, error)
+	RemainingCodes(ctx context.Context, user *entities.User) (int, error)
+}
+
+type SessionServiceInterface interface {
+	ListSessions(ctx context.Context, user *entities.User) ([]*entities.Session, error)
+	RevokeSession(ctx context.Context, user *entities.User, sessionID string) error
+	CurrentSessionID(tokenString string) string
+}
+
+type OIDCServiceInterface interface {
+	Metadata() *services.ProviderMetadata
+	ResolveClient(ctx context.Context, clientID, redirectURI string) (*entities.OAuthClient, error)
+	StartAuthorization(ctx context.Context, client *entities.OAuthClient, redirectURI string, params *services.AuthorizationParams) (*services.AuthorizationRequest, error)
+	GetAuthorization(ctx context.Context, requestID string) (*services.AuthorizationRequest, error)
+	SendLoginOTP(ctx context.Context, requestID string, req *entities.OTPRequest) (*services.AuthorizationRequest, *entities.OTPChallenge, error)
+	VerifyLoginOTP(ctx context.Context, requestID, otpCode string, device *entities.DeviceInfo) (*services.AuthorizationResult, error)
+	VerifyLoginSecondFactor(ctx context.Context, requestID, code string, device *entities.DeviceInfo) (*services.AuthorizationResult, error)
+	Exchange(ctx context.Context, req *services.TokenRequest) (*services.TokenResponse, error)
+	UserInfo(ctx context.Context, accessToken string) (map[string]interface{}, error)
+	Introspect(ctx context.Context, clientID, clientSecret, token string) (*services.Introspection, error)
+	Revoke(ctx context.Context, clientID, clientSecret, token string) error
+	RegisterClient(ctx context.Context, name string, redirectURIs, scopes []string, public bool) (*entities.OAuthClient, string, error)
+	ListClients(ctx context.Context) ([]*entities.OAuthClient, error)
+	DeactivateClient(ctx context.Context, clientID string) error
+}
+
+type APIClientServiceInterface interface {
+	AuthenticateKey(ctx context.Context, apiKey string) (*entities.APIClient, error)
+	IsClientToken(tokenString string) bool
+	GetClientFromToken(ctx context.Context, tokenString string) (*entities.APIClient, string, error)
+	RegisterClient(ctx context.Context, name string, scopes []string, rateLimit int) (*entities.APIClient, string, error)
+	ListClients(ctx context.Context) ([]*entities.APIClient, error)
+	DeactivateClient(ctx context.Context, clientID string) error
+}
+
+type RoleServiceInterface interface {
+	GetRole(name entities.UserRole) (*entities.Role, error)
+	ListRoles(ctx context.Context) ([]*entities.Role, error)
+	ListPermissions(ctx context.Context) ([]*entities.Permission, error)
+	SaveRole(ctx context.Context, role *entities.Role) error
+	Close() error
+}
+
+type TenantServiceInterface interface {
+	Resolve(host, slug string) (*entities.Tenant, error)
+	GetTenant(tenantID int) (*entities.Tenant, error)
+	Config(tenantID int) *config.TenantConfig
+	CreateTenant(ctx context.Context, tenant *entities.Tenant) error
+	ListTenants(ctx context.Context) ([]*entities.Tenant, error)
+	UpdateTenant(ctx context.Context, tenant *entities.Tenant) error
+	Close() error
+}
+
+type KeyringInterface interface {
+	JWKS() *keyring.JWKSet
+	CacheMaxAge() time.Duration
+}
+
+type DeliveryReceiptServiceInterface interface {
+	HandleReceipts(ctx context.Context, provider string, header func(string) string, body []byte) (*services.ReceiptResult, error)
 }
 
 type UserServiceInterface interface {
 	GetUserByID(ctx context.Context, userID int) (*entities.User, error)
 	GetUsers(ctx context.Context, query string, offset, limit int) ([]*entities.User, int, error)
 	UpdateUserProfile(ctx context.Context, userID int, name string) (*entities.User, error)
+	ListUsers(ctx context.Context, filter *repositories.UserFilter, offset, limit int) ([]*entities.User, int, error)
+	GetUser(ctx context.Context, userID int) (*entities.User, error)
+	ActivateUser(ctx context.Context, actor string, userID int) (*entities.User, error)
+	DeactivateUser(ctx context.Context, actor string, userID int) (*entities.User, error)
+	SetStatus(ctx context.Context, actor string, userID int, status entities.UserStatus, reason string, until *time.Time) (*entities.User, error)
+	GetStatusHistory(ctx context.Context, userID int) ([]*entities.UserStatusChange, error)
+	ChangeRole(ctx context.Context, actor string, userID int, role entities.UserRole) (*entities.User, error)
+	ForceLogout(ctx context.Context, actor string, userID int) error
 }
 
 // Services holds all application services
 type Services struct {
 	AuthService      AuthServiceInterface
 	UserService      UserServiceInterface
+	MFAService       MFAServiceInterface
+	RecoveryService  RecoveryServiceInterface
+	SessionService   SessionServiceInterface
+	OIDCService      OIDCServiceInterface
+	APIClientService APIClientServiceInterface
+	TenantService    TenantServiceInterface
+	RoleService      RoleServiceInterface
+	ReceiptService   DeliveryReceiptServiceInterface
 	EventService     *events.EventService
 	UserCacheService *cache.UserCacheService
+	Keyring          *keyring.Keyring
 }
 
 // NewServices creates a new services container
-func NewServices(repos *database.Repositories, config *config.Config, redisClient *redis.Client, metricsService *metrics.MetricsService) *Services {
+func NewServices(repos *database.Repositories, config *config.Config, redisClient *redis.Client, metricsService *metrics.MetricsService) (*Services, error) {
 	logger := log.New(config.Log)
 
 	eventService := events.NewEventService(redisClient, &config.Events, logger)
 
 	otpService := redis.NewOTPService(redisClient, &config.OTP, logger, metricsService)
 
-	otpService.SetEventHandler(func(ctx context.Context, phoneNumber, otpCode string) error {
-		return eventService.PublishOTPGenerated(ctx, phoneNumber, otpCode)
+	otpService.SetEventHandler(func(ctx context.Context, phoneNumber, purpose string) error {
+		return eventService.PublishOTPGenerated(ctx, phoneNumber, purpose)
+	})
+
+	otpService.SetVerifyHandler(func(ctx context.Context, challenge *entities.OTPChallenge) error {
+		if err := eventService.PublishOTPVerified(ctx, challenge.PhoneNumber, challenge.ID, string(challenge.Purpose)); err != nil {
+			return err
+		}
+		if challenge.PayloadHash == "" {
+			return nil
+		}
+		// Audit record of the exact operation the user signed
+		return eventService.PublishOTPTransactionVerified(ctx, challenge.PhoneNumber, challenge.ID, challenge.Operation, challenge.Summary, challenge.PayloadHash)
+	})
+
+	// Codes are handed over in-process and never travel over the event bus
+	deliveryService, err := delivery.NewService(&config.Delivery, redisClient, logger, metricsService)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize OTP delivery: %w", err)
+	}
+	otpService.SetDeliverer(deliveryService)
+
+	tenantService := services.NewTenantService(repos.TenantRepository, &config.Tenants, logger)
+
+	// Tenants may override the message templates and OTP settings
+	tenantService.SetTemplateHandler(deliveryService.SetTenantTemplates)
+
+	if err := tenantService.Start(context.Background()); err != nil {
+		return nil, fmt.Errorf("failed to load tenants: %w", err)
+	}
+
+	otpService.SetTenantConfig(tenantService.OTPConfig)
+
+	otpService.SetLockoutHandler(func(ctx context.Context, phoneNumber string, lockoutDuration time.Duration, lockoutCount int) error {
+		return eventService.PublishOTPLocked(ctx, phoneNumber, lockoutDuration, lockoutCount)
+	})
+
+	otpService.SetFallbackHandler(func(ctx context.Context, phoneNumber, challengeID, fromChannel, toChannel, reason string) error {
+		return eventService.PublishOTPChannelFallback(ctx, phoneNumber, challengeID, fromChannel, toChannel, reason)
 	})
 
 	userCacheService := cache.NewUserCacheService(redisClient, logger, metricsService)
 
 	repos.SetUserCacheRepository(userCacheService)
 
+	mfaService := services.NewMFAService(repos.UserFactorRepository, redisClient, &config.MFA, logger)
+
+	recoveryService := services.NewRecoveryService(repos.UserRepository, repos.RecoveryCodeRepository, userCacheService, &config.MFA, logger)
+
+	recoveryService.SetUsedHandler(func(ctx context.Context, user *entities.User, remaining int) error {
+		return eventService.PublishRecoveryCodeUsed(ctx, user.ID, user.PhoneNumber, remaining)
+	})
+
+	jwtKeyring, err := keyring.New(&config.JWT, logger)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize JWT keys: %w", err)
+	}
+
+	// ID tokens may outlive access tokens, so retired keys must too
+	jwtKeyring.Retain(config.OIDC.IDTokenExpiry)
+
+	if jwtKeyring.Algorithm() == keyring.AlgorithmHS256 {
+		logger.Warn(context.Background(), "OpenID Connect logins are disabled: ID tokens need an asymmetric JWT_ALGORITHM")
+	}
+
+	tokenService := services.NewTokenService(repos.UserRepository, repos.RefreshTokenRepository, repos.SessionRepository, redisClient, jwtKeyring, &config.JWT, logger)
+
+	roleService := services.NewRoleService(repos.RoleRepository, &config.RBAC, logger)
+
+	if err := roleService.Start(context.Background()); err != nil {
+		return nil, fmt.Errorf("failed to load roles: %w", err)
+	}
+
+	userService := services.NewUserService(repos.UserRepository, tokenService, roleService, logger, redisClient, userCacheService, metricsService)
+
+	userService.SetAdminActionHandler(func(ctx context.Context, user *entities.User, action, actor string) error {
+		return eventService.PublishUserAdminAction(ctx, user.ID, user.TenantID, user.PhoneNumber, action, actor)
+	})
+
+	// The old number is told about the change by whoever consumes the event
+	userService.SetPhoneNumberChangedHandler(func(ctx context.Context, user *entities.User, oldPhoneNumber string) error {
+		return eventService.PublishPhoneNumberChanged(ctx, user.ID, user.TenantID, oldPhoneNumber, user.PhoneNumber)
+	})
+
+	authService := services.NewAuthService(repos.UserRepository, otpService, mfaService, recoveryService, tokenService, userService, logger, metricsService)
+
+	apiClientService := services.NewAPIClientService(repos.APIClientRepository, tokenService, logger)
+
 	return &Services{
-		AuthService:      services.NewAuthService(repos.UserRepository, otpService, logger, config.JWT.Secret, metricsService),
-		UserService:      services.NewUserService(repos.UserRepository, logger, redisClient, userCacheService, metricsService),
+		AuthService:      authService,
+		UserService:      userService,
+		MFAService:       mfaService,
+		RecoveryService:  recoveryService,
+		SessionService:   services.NewSessionService(tokenService, logger),
+		OIDCService:      services.NewOIDCService(repos.OAuthClientRepository, repos.UserRepository, authService, tokenService, apiClientService, redisClient, jwtKeyring, &config.OIDC, logger),
+		APIClientService: apiClientService,
+		TenantService:    tenantService,
+		RoleService:      roleService,
+		ReceiptService:   services.NewDeliveryReceiptService(deliveryService, otpService, logger),
 		EventService:     eventService,
 		UserCacheService: userCacheService,
-	}
+		Keyring:          jwtKeyring,
+	}, nil
 }
 
 // GetEventService returns the event service
@@ -0,0 +1,233 @@
+package services
+
+import (
+	"context"
+	"crypto/subtle"
+	"fmt"
+	"strings"
+
+	"otp-server/internal/domain/entities"
+	"otp-server/internal/domain/errors"
+	"otp-server/internal/domain/repositories"
+	"otp-server/internal/infrastructure/logger"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+)
+
+// GrantTypeClientCredentials is the grant API clients get access tokens with
+const GrantTypeClientCredentials = "client_credentials"
+
+// APIClientService authenticates backend services calling the API on their
+// own behalf. A client sends its secret as an API key, or exchanges it for
+// an access token with the client credentials grant.
+type APIClientService struct {
+	clientRepo   repositories.APIClientRepository
+	tokenService *TokenService
+	logger       logger.Logger
+}
+
+// NewAPIClientService creates a new API client service
+func NewAPIClientService(clientRepo repositories.APIClientRepository, tokenService *TokenService, logger logger.Logger) *APIClientService {
+	return &APIClientService{
+		clientRepo:   clientRepo,
+		tokenService: tokenService,
+		logger:       logger,
+	}
+}
+
+// AuthenticateKey returns the active client an API key belongs to
+func (s *APIClientService) AuthenticateKey(ctx context.Context, apiKey string) (*entities.APIClient, error) {
+	if apiKey == "" {
+		return nil, fmt.Errorf("invalid API key")
+	}
+
+	client, err := s.clientRepo.GetBySecretHash(ctx, hashClientSecret(apiKey))
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return nil, fmt.Errorf("invalid API key")
+		}
+		return nil, err
+	}
+	if !client.IsActive {
+		return nil, fmt.Errorf("invalid API key")
+	}
+
+	return client, nil
+}
+
+// IsClientToken checks if an access token was issued to an API client,
+// without verifying it, so the token must be verified afterwards
+func (s *APIClientService) IsClientToken(tokenString string) bool {
+	claims := jwt.MapClaims{}
+	if _, _, err := jwt.NewParser().ParseUnverified(tokenString, claims); err != nil {
+		return false
+	}
+
+	clientID, _ := claims["api_client_id"].(string)
+	return clientID != ""
+}
+
+// GetClientFromToken verifies an access token issued to an API client and
+// returns the client with the scope granted to the token. Tokens of a
+// deactivated client stop working at once.
+func (s *APIClientService) GetClientFromToken(ctx context.Context, tokenString string) (*entities.APIClient, string, error) {
+	claims, err := s.tokenService.ParseAccessToken(ctx, tokenString)
+	if err != nil {
+		return nil, "", err
+	}
+	if claims.APIClientID == "" {
+		return nil, "", fmt.Errorf("token was not issued to an API client")
+	}
+
+	client, err := s.clientRepo.GetByClientID(ctx, claims.APIClientID)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return nil, "", fmt.Errorf("client not found")
+		}
+		return nil, "", err
+	}
+	if !client.IsActive {
+		return nil, "", fmt.Errorf("client is deactivated")
+	}
+
+	return client, claims.Scope, nil
+}
+
+// ActiveClient returns the client with the client ID. Deactivated clients
+// are reported as not found.
+func (s *APIClientService) ActiveClient(ctx context.Context, clientID string) (*entities.APIClient, error) {
+	client, err := s.clientRepo.GetByClientID(ctx, clientID)
+	if err != nil {
+		return nil, err
+	}
+	if !client.IsActive {
+		return nil, errors.NewNotFound("API client")
+	}
+
+	return client, nil
+}
+
+// IssueToken runs the client credentials grant, issuing an access token for
+// the requested scope, or for every scope of the client when none is
+// requested
+func (s *APIClientService) IssueToken(ctx context.Context, clientID, clientSecret, scope string) (*TokenResponse, error) {
+	client, err := s.authenticate(ctx, clientID, clientSecret)
+	if err != nil {
+		return nil, err
+	}
+
+	requested := strings.Fields(scope)
+	if len(requested) == 0 {
+		requested = client.Scopes
+	}
+	for _, r := range requested {
+		if !entities.HasScope(strings.Join(entities.APIClientScopes, " "), r) {
+			return nil, errors.NewOAuthError("invalid_scope", "unknown scope "+r)
+		}
+	}
+	if !client.HasScopes(requested...) {
+		return nil, errors.NewOAuthError("invalid_scope", "the client may not request the scope")
+	}
+	scope = strings.Join(requested, " ")
+
+	accessToken, err := s.tokenService.IssueClientToken(client, scope)
+	if err != nil {
+		return nil, err
+	}
+
+	s.logger.Info(ctx, "API client token issued", logger.F("client_id", client.ClientID), logger.F("scope", scope))
+
+	return &TokenResponse{
+		AccessToken: accessToken,
+		ExpiresIn:   s.tokenService.config.Expiry,
+		Scope:       scope,
+	}, nil
+}
+
+// RegisterClient registers a new client of the context's tenant and returns
+// it with its secret, which is not stored and cannot be shown again. A rate
+// limit of zero leaves the client on the default limit.
+func (s *APIClientService) RegisterClient(ctx context.Context, name string, scopes []string, rateLimit int) (*entities.APIClient, string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, "", errors.NewInvalidInput("name", name)
+	}
+
+	if len(scopes) == 0 {
+		return nil, "", errors.NewInvalidInput("scopes", scopes)
+	}
+	for _, scope := range scopes {
+		if !entities.HasScope(strings.Join(entities.APIClientScopes, " "), scope) {
+			return nil, "", errors.NewInvalidInput("scopes", scope)
+		}
+	}
+
+	if rateLimit < 0 {
+		return nil, "", errors.NewInvalidInput("rate_limit", rateLimit)
+	}
+
+	secret, err := randomToken()
+	if err != nil {
+		return nil, "", err
+	}
+
+	client := &entities.APIClient{
+		TenantID:   entities.TenantIDFromContext(ctx),
+		ClientID:   uuid.NewString(),
+		SecretHash: hashClientSecret(secret),
+		Name:       name,
+		Scopes:     scopes,
+		RateLimit:  rateLimit,
+		IsActive:   true,
+	}
+
+	if err := s.clientRepo.Create(ctx, client); err != nil {
+		return nil, "", err
+	}
+
+	s.logger.Info(ctx, "API client registered", logger.F("client_id", client.ClientID), logger.F("scopes", scopes))
+
+	return client, secret, nil
+}
+
+// ListClients returns every client registered with the context's tenant
+func (s *APIClientService) ListClients(ctx context.Context) ([]*entities.APIClient, error) {
+	return s.clientRepo.List(ctx, entities.TenantIDFromContext(ctx))
+}
+
+// DeactivateClient stops a client from calling the API. Its API key and
+// the access tokens it holds stop working at once.
+func (s *APIClientService) DeactivateClient(ctx context.Context, clientID string) error {
+	if err := s.clientRepo.Deactivate(ctx, entities.TenantIDFromContext(ctx), clientID); err != nil {
+		return err
+	}
+
+	s.logger.Info(ctx, "API client deactivated", logger.F("client_id", clientID))
+	return nil
+}
+
+// authenticate authenticates a client by its ID and secret for the client
+// credentials grant
+func (s *APIClientService) authenticate(ctx context.Context, clientID, clientSecret string) (*entities.APIClient, error) {
+	if clientID == "" || clientSecret == "" {
+		return nil, errors.NewOAuthError("invalid_client", "client authentication failed")
+	}
+
+	client, err := s.clientRepo.GetByClientID(ctx, clientID)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return nil, errors.NewOAuthError("invalid_client", "client authentication failed")
+		}
+		return nil, err
+	}
+	if !client.IsActive {
+		return nil, errors.NewOAuthError("invalid_client", "client authentication failed")
+	}
+
+	if subtle.ConstantTimeCompare([]byte(hashClientSecret(clientSecret)), []byte(client.SecretHash)) != 1 {
+		return nil, errors.NewOAuthError("invalid_client", "client authentication failed")
+	}
+
+	return client, nil
+}
@@ -6,123 +6,605 @@ import (
 	"time"
 
 	"otp-server/internal/domain/entities"
+	"otp-server/internal/domain/errors"
 	"otp-server/internal/domain/repositories"
 	"otp-server/internal/infrastructure/logger"
 	"otp-server/internal/infrastructure/metrics"
 	"otp-server/internal/infrastructure/redis"
-
-	"github.com/golang-jwt/jwt/v5"
 )
 
 // AuthService handles authentication operations
 type AuthService struct {
-	userRepo   repositories.UserRepository
-	otpService *redis.OTPService
-	logger     logger.Logger
-	jwtSecret  string
-	metrics    *metrics.MetricsService
+	userRepo     repositories.UserRepository
+	otpService   *redis.OTPService
+	mfaService   *MFAService
+	recovery     *RecoveryService
+	tokenService *TokenService
+	userService  *UserService
+	logger       logger.Logger
+	metrics      *metrics.MetricsService
 }
 
 // NewAuthService creates a new auth service
-func NewAuthService(userRepo repositories.UserRepository, otpService *redis.OTPService, logger logger.Logger, jwtSecret string, metricsService *metrics.MetricsService) *AuthService {
+func NewAuthService(userRepo repositories.UserRepository, otpService *redis.OTPService, mfaService *MFAService, recoveryService *RecoveryService, tokenService *TokenService, userService *UserService, logger logger.Logger, metricsService *metrics.MetricsService) *AuthService {
 	return &AuthService{
-		userRepo:   userRepo,
-		otpService: otpService,
-		logger:     logger,
-		jwtSecret:  jwtSecret,
-		metrics:    metricsService,
+		userRepo:     userRepo,
+		otpService:   otpService,
+		mfaService:   mfaService,
+		recovery:     recoveryService,
+		tokenService: tokenService,
+		userService:  userService,
+		logger:       logger,
+		metrics:      metricsService,
 	}
 }
 
-func (s *AuthService) SendOTP(ctx context.Context, phoneNumber string) error {
-	if !s.isValidPhoneNumber(phoneNumber) {
-		return fmt.Errorf("invalid phone number format")
+// OTPStatus describes the current state of an OTP challenge
+type OTPStatus struct {
+	Challenge         *entities.OTPChallenge
+	Valid             bool
+	ExpiresIn         time.Duration
+	AttemptsRemaining int
+	ResendIn          time.Duration
+	ResendsRemaining  int
+	Deliveries        []*entities.DeliveryEvent
+}
+
+// AuthResult is the outcome of a login. Users with a second factor get an
+// MFA token to present together with their TOTP code instead of tokens.
+type AuthResult struct {
+	User                 *entities.User
+	Token                string
+	ExpiresIn            time.Duration
+	RefreshToken         string
+	RefreshExpiresIn     time.Duration
+	SecondFactorRequired bool
+	MFAToken             string
+	MFAExpiresIn         time.Duration
+}
+
+// PhoneChange is a started phone number change: the challenge of the new
+// number, and of the current number when it must confirm the change too
+type PhoneChange struct {
+	New *entities.OTPChallenge
+	Old *entities.OTPChallenge
+}
+
+// StepUpResult is an elevated access token issued by stepping up
+type StepUpResult struct {
+	Token     string
+	ExpiresIn time.Duration
+	AuthTime  time.Time
+}
+
+// singleFactorMethods and multiFactorMethods are the authentication methods
+// of logins with the SMS code alone and together with a TOTP code
+var (
+	singleFactorMethods = []string{entities.AuthMethodOTP}
+	multiFactorMethods  = []string{entities.AuthMethodOTP, entities.AuthMethodTOTP, entities.AuthMethodMFA}
+)
+
+// SendOTP sends a login code. Numbers of users who may not log in get no
+// SMS, so banned users cannot run up the delivery bill.
+func (s *AuthService) SendOTP(ctx context.Context, req *entities.OTPRequest) (*entities.OTPChallenge, error) {
+	if !s.isValidPhoneNumber(req.PhoneNumber) {
+		return nil, fmt.Errorf("invalid phone number format")
 	}
 
-	_, err := s.otpService.GenerateOTP(ctx, phoneNumber)
+	if user, err := s.userRepo.GetByPhoneNumber(ctx, entities.TenantIDFromContext(ctx), req.PhoneNumber); err == nil {
+		if err := checkUserStatus(user); err != nil {
+			return nil, err
+		}
+	}
+
+	challenge, err := s.otpService.GenerateOTP(ctx, req)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	return nil
+	return challenge, nil
+}
+
+// SendConfirmationOTP sends a code confirming a sensitive action (any purpose
+// but login, transaction and step-up) to the authenticated user's own phone
+// number
+func (s *AuthService) SendConfirmationOTP(ctx context.Context, user *entities.User, req *entities.OTPRequest) (*entities.OTPChallenge, error) {
+	if req.Purpose == entities.OTPPurposeLogin || req.Purpose == entities.OTPPurposeTransaction || req.Purpose == entities.OTPPurposeStepUp || !req.Purpose.IsValid() {
+		return nil, errors.NewInvalidInput("purpose", req.Purpose)
+	}
+
+	req.PhoneNumber = user.PhoneNumber
+	return s.otpService.GenerateOTP(ctx, req)
+}
+
+// VerifyConfirmationOTP verifies a confirmation code sent to the
+// authenticated user for the given purpose
+func (s *AuthService) VerifyConfirmationOTP(ctx context.Context, user *entities.User, verificationID string, purpose entities.OTPPurpose, otpCode string) (*entities.OTPChallenge, error) {
+	challenge, err := s.otpService.GetChallenge(ctx, verificationID)
+	if err != nil || challenge.PhoneNumber != user.PhoneNumber {
+		return nil, errors.ErrOTPInvalid
+	}
+
+	return s.otpService.ValidateOTP(ctx, verificationID, purpose, otpCode)
+}
+
+// SendTransactionOTP sends a code signing the transaction payload to the
+// authenticated user's own phone number
+func (s *AuthService) SendTransactionOTP(ctx context.Context, user *entities.User, req *entities.OTPRequest) (*entities.OTPChallenge, error) {
+	req.PhoneNumber = user.PhoneNumber
+	req.Purpose = entities.OTPPurposeTransaction
+	return s.otpService.GenerateOTP(ctx, req)
+}
+
+// VerifyTransactionOTP verifies a transaction code sent to the authenticated
+// user against the payload it was issued for
+func (s *AuthService) VerifyTransactionOTP(ctx context.Context, user *entities.User, verificationID string, payload *entities.TransactionPayload, otpCode string) (*entities.OTPChallenge, error) {
+	challenge, err := s.otpService.GetChallenge(ctx, verificationID)
+	if err != nil || challenge.PhoneNumber != user.PhoneNumber {
+		return nil, errors.ErrOTPInvalid
+	}
+
+	return s.otpService.ValidateTransactionOTP(ctx, verificationID, payload, otpCode)
+}
+
+// SendStepUpOTP sends a code to the authenticated user's own phone number
+// for stepping up
+func (s *AuthService) SendStepUpOTP(ctx context.Context, user *entities.User, req *entities.OTPRequest) (*entities.OTPChallenge, error) {
+	req.PhoneNumber = user.PhoneNumber
+	req.Purpose = entities.OTPPurposeStepUp
+	return s.otpService.GenerateOTP(ctx, req)
+}
+
+// StepUp verifies a step-up code sent to the authenticated user and issues
+// an elevated access token for the session of tokenString, proving the user
+// authenticated just now. The session and its refresh tokens stay as they
+// are.
+func (s *AuthService) StepUp(ctx context.Context, user *entities.User, tokenString, verificationID, otpCode string) (*StepUpResult, error) {
+	claims, err := s.tokenService.ParseAccessToken(ctx, tokenString)
+	if err != nil {
+		return nil, err
+	}
+
+	challenge, err := s.otpService.GetChallenge(ctx, verificationID)
+	if err != nil || challenge.PhoneNumber != user.PhoneNumber || challenge.TenantID != user.TenantID {
+		return nil, errors.ErrOTPInvalid
+	}
+
+	if _, err := s.otpService.ValidateOTP(ctx, verificationID, entities.OTPPurposeStepUp, otpCode); err != nil {
+		return nil, err
+	}
+
+	token, expiresIn, err := s.tokenService.IssueElevated(user, claims, singleFactorMethods)
+	if err != nil {
+		return nil, err
+	}
+
+	s.logger.Info(ctx, "User stepped up", logger.F("user_id", user.ID), logger.F("session_id", claims.SessionID))
+
+	return &StepUpResult{
+		Token:     token,
+		ExpiresIn: expiresIn,
+		AuthTime:  time.Now(),
+	}, nil
+}
+
+// AuthTime returns when the user of an access token last authenticated. It
+// does not verify the token, so it must only be used on tokens verified
+// before.
+func (s *AuthService) AuthTime(tokenString string) time.Time {
+	return s.tokenService.AuthTimeFromToken(tokenString)
+}
+
+// GetOTPStatus returns the state of the OTP challenge with the given verification ID
+func (s *AuthService) GetOTPStatus(ctx context.Context, verificationID string) (*OTPStatus, error) {
+	challenge, err := s.otpService.GetChallenge(ctx, verificationID)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.otpStatus(ctx, challenge), nil
 }
 
-func (s *AuthService) VerifyOTPAndAuthenticate(ctx context.Context, phoneNumber, otpCode, name string) (*entities.User, string, error) {
-	if err := s.otpService.ValidateOTP(ctx, phoneNumber, otpCode); err != nil {
-		return nil, "", err
+// ResendOTP sends the code of an existing OTP challenge again, unless the
+// user of the phone number may no longer log in
+func (s *AuthService) ResendOTP(ctx context.Context, verificationID string) (*OTPStatus, error) {
+	challenge, err := s.otpService.GetChallenge(ctx, verificationID)
+	if err != nil {
+		return nil, err
+	}
+	if user, err := s.userRepo.GetByPhoneNumber(ctx, challenge.TenantID, challenge.PhoneNumber); err == nil {
+		if err := checkUserStatus(user); err != nil {
+			return nil, err
+		}
 	}
 
-	user, err := s.userRepo.GetByPhoneNumber(ctx, phoneNumber)
+	challenge, err = s.otpService.ResendOTP(ctx, verificationID)
 	if err != nil {
-		user = entities.NewUser(phoneNumber, name)
+		return nil, err
+	}
+
+	return s.otpStatus(ctx, challenge), nil
+}
+
+func (s *AuthService) otpStatus(ctx context.Context, challenge *entities.OTPChallenge) *OTPStatus {
+	status := &OTPStatus{
+		Challenge:         challenge,
+		Valid:             challenge.IsPending() && s.otpService.IsOTPValid(ctx, challenge),
+		AttemptsRemaining: s.otpService.AttemptsRemaining(ctx, challenge),
+	}
+
+	if status.Valid {
+		if ttl, err := s.otpService.GetOTPTTL(ctx, challenge); err == nil && ttl > 0 {
+			status.ExpiresIn = ttl
+		}
+	}
+
+	status.ResendIn, status.ResendsRemaining = s.otpService.ResendAvailability(ctx, challenge)
+
+	deliveries, err := s.otpService.DeliveryHistory(ctx, challenge)
+	if err != nil {
+		s.logger.Error(ctx, "Failed to load OTP delivery history", logger.F("error", err), logger.F("challenge_id", challenge.ID))
+	}
+	status.Deliveries = deliveries
+
+	return status
+}
+
+func (s *AuthService) VerifyOTPAndAuthenticate(ctx context.Context, verificationID, otpCode, name string, device *entities.DeviceInfo) (*AuthResult, error) {
+	result, err := s.VerifyOTPLogin(ctx, verificationID, otpCode, name)
+	if err != nil || result.SecondFactorRequired {
+		return result, err
+	}
+
+	return s.authenticate(ctx, result.User, device, singleFactorMethods)
+}
+
+// VerifyOTPLogin checks the code of a login challenge and returns the user,
+// created on first login, without issuing tokens. For users with a second
+// factor, the result asks for it instead.
+func (s *AuthService) VerifyOTPLogin(ctx context.Context, verificationID, otpCode, name string) (*AuthResult, error) {
+	challenge, err := s.otpService.ValidateOTP(ctx, verificationID, entities.OTPPurposeLogin, otpCode)
+	if err != nil {
+		return nil, err
+	}
+	phoneNumber := challenge.PhoneNumber
+
+	user, err := s.userRepo.GetByPhoneNumber(ctx, challenge.TenantID, phoneNumber)
+	if err != nil {
+		user = entities.NewUser(challenge.TenantID, phoneNumber, name)
 		if err := s.userRepo.Create(ctx, user); err != nil {
-			return nil, "", fmt.Errorf("failed to create user")
+			return nil, fmt.Errorf("failed to create user")
 		}
 
 		if s.metrics != nil {
-			s.metrics.RecordUserRegistration(user.ID, phoneNumber)
+			s.metrics.RecordUserRegistration(user.TenantID, user.ID, phoneNumber)
 		}
 	} else {
+		if err := checkUserStatus(user); err != nil {
+			return nil, err
+		}
+
 		user.UpdateLastSeen()
 		if err := s.userRepo.Update(ctx, user); err != nil {
 		}
 
 		if s.metrics != nil {
-			s.metrics.RecordUserLogin(user.ID, phoneNumber)
+			s.metrics.RecordUserLogin(user.TenantID, user.ID, phoneNumber)
 		}
 	}
 
-	token, err := s.generateJWT(user)
+	hasSecondFactor, err := s.mfaService.HasSecondFactor(ctx, user.ID)
 	if err != nil {
-		return nil, "", fmt.Errorf("failed to generate authentication token")
+		return nil, err
+	}
+	if hasSecondFactor {
+		mfaToken, err := s.mfaService.StartChallenge(ctx, user.ID)
+		if err != nil {
+			return nil, err
+		}
+
+		return &AuthResult{
+			User:                 user,
+			SecondFactorRequired: true,
+			MFAToken:             mfaToken,
+			MFAExpiresIn:         s.mfaService.ChallengeTTL(),
+		}, nil
 	}
 
-	return user, token, nil
+	return &AuthResult{User: user}, nil
 }
 
-func (s *AuthService) GetUserFromToken(tokenString string) (*entities.User, error) {
-	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+// VerifyRecoveryCodeAndAuthenticate completes a login challenge with a
+// recovery code instead of the SMS code, for users who lost their phone. The
+// code stands in for every factor, so no second factor is asked for, but the
+// user must confirm a new phone number before using the account.
+func (s *AuthService) VerifyRecoveryCodeAndAuthenticate(ctx context.Context, verificationID, recoveryCode string, device *entities.DeviceInfo) (*AuthResult, error) {
+	challenge, err := s.otpService.GetChallenge(ctx, verificationID)
+	if err != nil {
+		return nil, errors.ErrOTPInvalid
+	}
+
+	// Recovery codes are single-use, so one must not be spent on an account
+	// that would be turned away anyway
+	if owner, err := s.userRepo.GetByPhoneNumber(ctx, challenge.TenantID, challenge.PhoneNumber); err == nil {
+		if err := checkUserStatus(owner); err != nil {
+			return nil, err
+		}
+	}
+
+	var user *entities.User
+	_, err = s.otpService.ValidateAlternative(ctx, verificationID, entities.OTPPurposeLogin, func(challenge *entities.OTPChallenge) bool {
+		redeemed, err := s.recovery.Redeem(ctx, challenge.PhoneNumber, recoveryCode)
+		if err != nil {
+			if !errors.IsNotFound(err) && !errors.IsOTPInvalid(err) {
+				s.logger.Error(ctx, "Failed to redeem recovery code", logger.F("error", err), logger.F("challenge_id", challenge.ID))
+			}
+			return false
 		}
-		return []byte(s.jwtSecret), nil
+		user = redeemed
+		return true
 	})
+	if err != nil {
+		return nil, err
+	}
+
+	if s.metrics != nil {
+		s.metrics.RecordUserLogin(user.TenantID, user.ID, user.PhoneNumber)
+	}
 
+	return s.authenticate(ctx, user, device, []string{entities.AuthMethodRecovery})
+}
+
+// SendPhoneConfirmationOTP sends a code to the phone number a user who
+// logged in with a recovery code wants to confirm. The challenge is bound to
+// the user, since the number is not theirs yet.
+func (s *AuthService) SendPhoneConfirmationOTP(ctx context.Context, user *entities.User, req *entities.OTPRequest) (*entities.OTPChallenge, error) {
+	if !user.PhoneConfirmationRequired {
+		return nil, errors.NewInvalidInput("phone_number", "no phone confirmation pending")
+	}
+	if !s.isValidPhoneNumber(req.PhoneNumber) {
+		return nil, errors.NewInvalidInput("phone_number", req.PhoneNumber)
+	}
+
+	if existing, err := s.userRepo.GetByPhoneNumber(ctx, user.TenantID, req.PhoneNumber); err == nil && existing.ID != user.ID {
+		return nil, errors.NewAlreadyExists("user")
+	}
+
+	req.Purpose = entities.OTPPurposePhoneChange
+	req.UserID = user.ID
+	return s.otpService.GenerateOTP(ctx, req)
+}
+
+// ConfirmPhoneNumber verifies the code sent by SendPhoneConfirmationOTP,
+// makes the number the user's phone number and returns a new token, since
+// tokens carry the phone number
+func (s *AuthService) ConfirmPhoneNumber(ctx context.Context, user *entities.User, verificationID, otpCode string, device *entities.DeviceInfo) (*AuthResult, error) {
+	// Other users change their number with ChangePhoneNumber, which may
+	// need the current number to agree
+	if !user.PhoneConfirmationRequired {
+		return nil, errors.NewInvalidInput("phone_number", "no phone confirmation pending")
+	}
+
+	challenge, err := s.otpService.GetChallenge(ctx, verificationID)
+	if err != nil || challenge.UserID != user.ID {
+		return nil, errors.ErrOTPInvalid
+	}
+
+	challenge, err = s.otpService.ValidateOTP(ctx, verificationID, entities.OTPPurposePhoneChange, otpCode)
 	if err != nil {
-		return nil, fmt.Errorf("invalid token")
+		return nil, err
 	}
 
-	if claims, ok := token.Claims.(jwt.MapClaims); ok && token.Valid {
-		userID := int(claims["user_id"].(float64))
-		phoneNumber := claims["phone_number"].(string)
+	if err := s.recovery.ConfirmPhoneNumber(ctx, user, challenge.PhoneNumber); err != nil {
+		return nil, err
+	}
+
+	return s.authenticate(ctx, user, device, singleFactorMethods)
+}
+
+// StartPhoneChange sends a code to the number the user wants to change to
+// and, unless the tenant turned it off, one to their current number. Both
+// challenges are bound to the user.
+func (s *AuthService) StartPhoneChange(ctx context.Context, user *entities.User, req *entities.OTPRequest) (*PhoneChange, error) {
+	if !s.isValidPhoneNumber(req.PhoneNumber) {
+		return nil, errors.NewInvalidInput("phone_number", req.PhoneNumber)
+	}
+	if req.PhoneNumber == user.PhoneNumber {
+		return nil, errors.NewInvalidInput("phone_number", "already the user's phone number")
+	}
 
-		user, err := s.userRepo.GetByID(context.Background(), userID)
+	if existing, err := s.userRepo.GetByPhoneNumber(ctx, user.TenantID, req.PhoneNumber); err == nil && existing.ID != user.ID {
+		return nil, errors.NewAlreadyExists("user")
+	}
+
+	req.Purpose = entities.OTPPurposePhoneChange
+	req.UserID = user.ID
+	challenge, err := s.otpService.GenerateOTP(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	change := &PhoneChange{New: challenge}
+
+	if s.otpService.PhoneChangeVerifiesOldNumber(user.TenantID) {
+		change.Old, err = s.otpService.GenerateOTP(ctx, &entities.OTPRequest{
+			PhoneNumber: user.PhoneNumber,
+			Purpose:     entities.OTPPurposePhoneChange,
+			Locales:     req.Locales,
+			UserID:      user.ID,
+		})
 		if err != nil {
-			return nil, fmt.Errorf("user not found")
+			return nil, err
 		}
+	}
 
-		if user.PhoneNumber != phoneNumber {
-			return nil, fmt.Errorf("token mismatch")
+	return change, nil
+}
+
+// ChangePhoneNumber verifies the codes sent by StartPhoneChange and makes
+// the new number the user's phone number. Every session is logged out, so a
+// new token carrying the number is returned.
+func (s *AuthService) ChangePhoneNumber(ctx context.Context, user *entities.User, verificationID, otpCode, oldVerificationID, oldOTPCode string, device *entities.DeviceInfo) (*AuthResult, error) {
+	challenge, err := s.otpService.GetChallenge(ctx, verificationID)
+	if err != nil || challenge.UserID != user.ID || challenge.PhoneNumber == user.PhoneNumber {
+		return nil, errors.ErrOTPInvalid
+	}
+
+	verifyOld := s.otpService.PhoneChangeVerifiesOldNumber(user.TenantID)
+	if verifyOld {
+		if oldVerificationID == "" || oldOTPCode == "" {
+			return nil, errors.NewInvalidInput("old_otp", "the current phone number must confirm the change")
+		}
+
+		old, err := s.otpService.GetChallenge(ctx, oldVerificationID)
+		if err != nil || old.UserID != user.ID || old.PhoneNumber != user.PhoneNumber {
+			return nil, errors.ErrOTPInvalid
+		}
+
+		// Neither code is spent until both are right, so a typo in one does
+		// not make the user start over
+		if err := s.otpService.CheckOTP(ctx, oldVerificationID, entities.OTPPurposePhoneChange, oldOTPCode); err != nil {
+			return nil, err
+		}
+	}
+
+	challenge, err = s.otpService.ValidateOTP(ctx, verificationID, entities.OTPPurposePhoneChange, otpCode)
+	if err != nil {
+		return nil, err
+	}
+
+	if verifyOld {
+		if _, err := s.otpService.ValidateOTP(ctx, oldVerificationID, entities.OTPPurposePhoneChange, oldOTPCode); err != nil {
+			return nil, err
 		}
+	}
+
+	if err := s.userService.ChangePhoneNumber(ctx, user, challenge.PhoneNumber); err != nil {
+		return nil, err
+	}
+
+	return s.authenticate(ctx, user, device, singleFactorMethods)
+}
 
-		return user, nil
+// VerifySecondFactor completes a login that required a second factor
+func (s *AuthService) VerifySecondFactor(ctx context.Context, mfaToken, code string, device *entities.DeviceInfo) (*AuthResult, error) {
+	user, err := s.VerifySecondFactorLogin(ctx, mfaToken, code)
+	if err != nil {
+		return nil, err
 	}
 
-	return nil, fmt.Errorf("invalid token")
+	return s.authenticate(ctx, user, device, multiFactorMethods)
 }
 
-func (s *AuthService) generateJWT(user *entities.User) (string, error) {
-	claims := jwt.MapClaims{
-		"user_id":      user.ID,
-		"phone_number": user.PhoneNumber,
-		"name":         user.Name,
-		"role":         user.Role,
-		"exp":          time.Now().Add(24 * time.Hour).Unix(),
-		"iat":          time.Now().Unix(),
+// VerifySecondFactorLogin checks the second factor of a login and returns
+// the user, without issuing tokens
+func (s *AuthService) VerifySecondFactorLogin(ctx context.Context, mfaToken, code string) (*entities.User, error) {
+	userID, err := s.mfaService.CompleteChallenge(ctx, mfaToken, code)
+	if err != nil {
+		return nil, err
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString([]byte(s.jwtSecret))
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkUserStatus(user); err != nil {
+		return nil, err
+	}
+
+	return user, nil
+}
+
+// RefreshTokens exchanges a refresh token for a new access token and a new
+// refresh token; the presented one stops working
+func (s *AuthService) RefreshTokens(ctx context.Context, refreshToken string) (*AuthResult, error) {
+	user, pair, err := s.tokenService.Refresh(ctx, refreshToken, "")
+	if err != nil {
+		return nil, err
+	}
+
+	return authResult(user, pair), nil
+}
+
+// authenticate issues tokens to a user who may log in
+func (s *AuthService) authenticate(ctx context.Context, user *entities.User, device *entities.DeviceInfo, methods []string) (*AuthResult, error) {
+	if err := checkUserStatus(user); err != nil {
+		return nil, err
+	}
+
+	pair, err := s.tokenService.Issue(ctx, user, device, methods)
+	if err != nil {
+		return nil, err
+	}
+
+	return authResult(user, pair), nil
+}
+
+func authResult(user *entities.User, pair *TokenPair) *AuthResult {
+	return &AuthResult{
+		User:             user,
+		Token:            pair.AccessToken,
+		ExpiresIn:        pair.ExpiresIn,
+		RefreshToken:     pair.RefreshToken,
+		RefreshExpiresIn: pair.RefreshExpiresIn,
+	}
+}
+
+func (s *AuthService) GetUserFromToken(tokenString string) (*entities.User, error) {
+	ctx := context.Background()
+
+	claims, err := s.tokenService.ParseAccessToken(ctx, tokenString)
+	if err != nil {
+		return nil, err
+	}
+	if claims.APIClientID != "" {
+		return nil, fmt.Errorf("token was issued to an API client")
+	}
+	// Tokens of OpenID Connect clients only grant userinfo, not the API
+	if claims.ClientID != "" {
+		return nil, fmt.Errorf("token was issued to an OpenID Connect client")
+	}
+
+	user, err := s.userRepo.GetByID(ctx, claims.UserID)
+	if err != nil {
+		return nil, fmt.Errorf("user not found")
+	}
+
+	if user.PhoneNumber != claims.PhoneNumber || user.TenantID != claims.TenantID {
+		return nil, fmt.Errorf("token mismatch")
+	}
+
+	// Suspended, banned and deactivated users are shut out at once, not
+	// when their tokens expire
+	if err := checkUserStatus(user); err != nil {
+		return nil, err
+	}
+
+	return user, nil
+}
+
+// Logout revokes the access token and the refresh tokens of its session
+func (s *AuthService) Logout(ctx context.Context, user *entities.User, tokenString string) error {
+	claims, err := s.tokenService.ParseAccessToken(ctx, tokenString)
+	if err != nil {
+		return err
+	}
+
+	if err := s.tokenService.RevokeAccessToken(ctx, claims); err != nil {
+		return err
+	}
+
+	s.logger.Info(ctx, "User logged out", logger.F("user_id", user.ID), logger.F("session_id", claims.SessionID))
+	return nil
+}
+
+// LogoutAll revokes every token of the user, ending all sessions
+func (s *AuthService) LogoutAll(ctx context.Context, user *entities.User) error {
+	if err := s.tokenService.RevokeAll(ctx, user.ID); err != nil {
+		return err
+	}
+
+	s.logger.Info(ctx, "User logged out of all sessions", logger.F("user_id", user.ID))
+	return nil
 }
 
 func (s *AuthService) isValidPhoneNumber(phoneNumber string) bool {
@@ -0,0 +1,73 @@
+package services
+
+import (
+	"context"
+	stderrors "errors"
+
+	"otp-server/internal/domain/errors"
+	"otp-server/internal/infrastructure/delivery"
+	"otp-server/internal/infrastructure/logger"
+	"otp-server/internal/infrastructure/redis"
+)
+
+// DeliveryReceiptService ingests the delivery receipts (DLRs) providers post
+// back for OTP messages
+type DeliveryReceiptService struct {
+	deliveryService *delivery.Service
+	otpService      *redis.OTPService
+	logger          logger.Logger
+}
+
+// ReceiptResult summarizes one receipt request
+type ReceiptResult struct {
+	Processed int
+	Ignored   int
+}
+
+// NewDeliveryReceiptService creates a new delivery receipt service
+func NewDeliveryReceiptService(deliveryService *delivery.Service, otpService *redis.OTPService, logger logger.Logger) *DeliveryReceiptService {
+	return &DeliveryReceiptService{
+		deliveryService: deliveryService,
+		otpService:      otpService,
+		logger:          logger,
+	}
+}
+
+// HandleReceipts verifies the request posted by the named provider and applies
+// every receipt it carries. Receipts for unknown messages, and duplicate or
+// out-of-order ones, are ignored rather than rejected so the provider does not
+// keep retrying them.
+func (s *DeliveryReceiptService) HandleReceipts(ctx context.Context, provider string, header func(string) string, body []byte) (*ReceiptResult, error) {
+	receipts, err := s.deliveryService.ParseReceipts(provider, header, body)
+	if err != nil {
+		switch {
+		case stderrors.Is(err, delivery.ErrReceiptsUnsupported):
+			return nil, errors.NewNotFound("delivery provider")
+		case stderrors.Is(err, delivery.ErrInvalidSignature):
+			return nil, errors.ErrUnauthorized.WithError(err)
+		case stderrors.Is(err, delivery.ErrMalformedReceipt):
+			return nil, errors.ErrInvalidInput.WithDetails(err.Error())
+		}
+		return nil, err
+	}
+
+	result := &ReceiptResult{}
+	for _, receipt := range receipts {
+		applied, err := s.otpService.RecordDeliveryReceipt(ctx, receipt)
+		if err != nil && !errors.IsNotFound(err) {
+			return nil, err
+		}
+
+		if !applied {
+			s.logger.Debug(ctx, "Ignoring delivery receipt",
+				logger.F("provider", provider),
+				logger.F("message_id", receipt.MessageID),
+				logger.F("status", receipt.Status))
+			result.Ignored++
+			continue
+		}
+		result.Processed++
+	}
+
+	return result, nil
+}
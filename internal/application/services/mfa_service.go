@@ -0,0 +1,307 @@
+package services
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"otp-server/internal/domain/entities"
+	"otp-server/internal/domain/errors"
+	"otp-server/internal/domain/repositories"
+	"otp-server/internal/infrastructure/config"
+	"otp-server/internal/infrastructure/logger"
+	"otp-server/internal/infrastructure/redis"
+	"otp-server/internal/infrastructure/totp"
+)
+
+const (
+	maxFactorsPerUser = 5
+	maxFactorName     = 64
+	defaultFactorName = "Authenticator"
+)
+
+// MFAService manages second authentication factors: TOTP enrollment and
+// the second step of a login for users who have one
+type MFAService struct {
+	factorRepo  repositories.UserFactorRepository
+	redisClient *redis.Client
+	config      *config.MFAConfig
+	generator   *totp.Generator
+	logger      logger.Logger
+}
+
+// NewMFAService creates a new MFA service
+func NewMFAService(factorRepo repositories.UserFactorRepository, redisClient *redis.Client, cfg *config.MFAConfig, logger logger.Logger) *MFAService {
+	return &MFAService{
+		factorRepo:  factorRepo,
+		redisClient: redisClient,
+		config:      cfg,
+		generator:   totp.NewGenerator(cfg.Period, cfg.Digits, cfg.Skew),
+		logger:      logger,
+	}
+}
+
+// TOTPEnrollment is a started enrollment. The secret is only ever returned
+// here; afterwards it is only stored encrypted.
+type TOTPEnrollment struct {
+	Factor    *entities.UserFactor
+	Secret    string
+	URI       string
+	ExpiresAt time.Time
+}
+
+// StartTOTPEnrollment creates an unconfirmed TOTP factor and returns its
+// secret and otpauth:// URI for the authenticator app
+func (s *MFAService) StartTOTPEnrollment(ctx context.Context, user *entities.User, name string) (*TOTPEnrollment, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		name = defaultFactorName
+	}
+	if len(name) > maxFactorName {
+		return nil, errors.NewInvalidInput("name", fmt.Sprintf("at most %d characters", maxFactorName))
+	}
+
+	factors, err := s.factorRepo.ListByUser(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+	if len(factors) >= maxFactorsPerUser {
+		return nil, errors.NewInvalidInput("factors", fmt.Sprintf("at most %d factors per user", maxFactorsPerUser))
+	}
+
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		return nil, err
+	}
+
+	sealed, err := s.sealSecret(user.ID, secret)
+	if err != nil {
+		return nil, err
+	}
+
+	factor := entities.NewTOTPFactor(user.ID, name, sealed)
+	if err := s.factorRepo.Create(ctx, factor); err != nil {
+		return nil, err
+	}
+
+	s.logger.Info(ctx, "TOTP enrollment started", logger.F("user_id", user.ID), logger.F("factor_id", factor.ID))
+
+	return &TOTPEnrollment{
+		Factor:    factor,
+		Secret:    totp.EncodeSecret(secret),
+		URI:       s.generator.URI(s.config.Issuer, user.PhoneNumber, secret),
+		ExpiresAt: factor.CreatedAt.Add(s.config.EnrollmentTTL),
+	}, nil
+}
+
+// ConfirmTOTPEnrollment completes an enrollment with the first code from the
+// authenticator app. Enrollments not confirmed within EnrollmentTTL are
+// discarded.
+func (s *MFAService) ConfirmTOTPEnrollment(ctx context.Context, user *entities.User, factorID int, code string) (*entities.UserFactor, error) {
+	factor, err := s.factorRepo.GetByID(ctx, user.ID, factorID)
+	if err != nil {
+		return nil, err
+	}
+
+	if factor.IsConfirmed() {
+		return nil, errors.NewInvalidInput("factor", "already confirmed")
+	}
+
+	if time.Since(factor.CreatedAt) > s.config.EnrollmentTTL {
+		if err := s.factorRepo.Delete(ctx, user.ID, factor.ID); err != nil {
+			s.logger.Error(ctx, "Failed to discard expired TOTP enrollment", logger.F("error", err), logger.F("factor_id", factor.ID))
+		}
+		return nil, errors.NewInvalidInput("factor", "enrollment expired, start again")
+	}
+
+	if !s.useCode(ctx, factor, code) {
+		return nil, errors.ErrSecondFactorInvalid
+	}
+
+	now := time.Now()
+	factor.ConfirmedAt = &now
+
+	s.logger.Info(ctx, "TOTP enrollment confirmed", logger.F("user_id", user.ID), logger.F("factor_id", factor.ID))
+
+	return factor, nil
+}
+
+// ListFactors returns the user's factors, confirmed or not
+func (s *MFAService) ListFactors(ctx context.Context, user *entities.User) ([]*entities.UserFactor, error) {
+	return s.factorRepo.ListByUser(ctx, user.ID)
+}
+
+// RemoveFactor deletes one of the user's factors
+func (s *MFAService) RemoveFactor(ctx context.Context, user *entities.User, factorID int) error {
+	if err := s.factorRepo.Delete(ctx, user.ID, factorID); err != nil {
+		return err
+	}
+
+	s.logger.Info(ctx, "Second factor removed", logger.F("user_id", user.ID), logger.F("factor_id", factorID))
+	return nil
+}
+
+// HasSecondFactor checks if logging in requires a second factor
+func (s *MFAService) HasSecondFactor(ctx context.Context, userID int) (bool, error) {
+	return s.factorRepo.HasConfirmed(ctx, userID)
+}
+
+// VerifyTOTP checks the code against the user's confirmed TOTP factors
+func (s *MFAService) VerifyTOTP(ctx context.Context, userID int, code string) error {
+	factors, err := s.factorRepo.ListByUser(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	for _, factor := range factors {
+		if factor.Type == entities.FactorTypeTOTP && factor.IsConfirmed() && s.useCode(ctx, factor, code) {
+			return nil
+		}
+	}
+
+	return errors.ErrSecondFactorInvalid
+}
+
+// StartChallenge records that the user passed the first factor and returns
+// the token to present together with the second factor. Only a hash of the
+// token is stored.
+func (s *MFAService) StartChallenge(ctx context.Context, userID int) (string, error) {
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return "", err
+	}
+	token := hex.EncodeToString(raw)
+
+	key := s.challengeKey(token)
+	if err := s.redisClient.HSet(ctx, key, "user_id", userID, "attempts", 0); err != nil {
+		return "", err
+	}
+	if err := s.redisClient.Expire(ctx, key, s.config.ChallengeTTL); err != nil {
+		return "", err
+	}
+
+	return token, nil
+}
+
+// CompleteChallenge checks the second factor code for the challenge and
+// returns the user who passed both factors. The challenge is burned after
+// MaxAttempts wrong codes.
+func (s *MFAService) CompleteChallenge(ctx context.Context, token, code string) (int, error) {
+	key := s.challengeKey(token)
+
+	fields, err := s.redisClient.HGetAll(ctx, key)
+	if err != nil {
+		return 0, err
+	}
+	userID, err := strconv.Atoi(fields["user_id"])
+	if err != nil {
+		return 0, errors.ErrSecondFactorInvalid
+	}
+
+	if err := s.VerifyTOTP(ctx, userID, code); err != nil {
+		if !errors.IsSecondFactorInvalid(err) {
+			return 0, err
+		}
+
+		attempts, incrErr := s.redisClient.HIncrBy(ctx, key, "attempts", 1)
+		if incrErr == nil && int(attempts) >= s.config.MaxAttempts {
+			s.redisClient.Del(ctx, key)
+			s.logger.Warn(ctx, "Second factor challenge burned after too many attempts", logger.F("user_id", userID))
+		}
+		return 0, err
+	}
+
+	// Only the request that actually removes the challenge wins a concurrent race
+	deleted, err := s.redisClient.DelCount(ctx, key)
+	if err != nil {
+		return 0, err
+	}
+	if deleted == 0 {
+		return 0, errors.ErrSecondFactorInvalid
+	}
+
+	return userID, nil
+}
+
+// ChallengeTTL returns how long a second factor challenge stays open
+func (s *MFAService) ChallengeTTL() time.Duration {
+	return s.config.ChallengeTTL
+}
+
+// useCode checks the code against the factor and spends its time step, so
+// the same code cannot be used twice
+func (s *MFAService) useCode(ctx context.Context, factor *entities.UserFactor, code string) bool {
+	secret, err := s.openSecret(factor.UserID, factor.SecretEncrypted)
+	if err != nil {
+		s.logger.Error(ctx, "Failed to decrypt TOTP secret", logger.F("error", err), logger.F("factor_id", factor.ID))
+		return false
+	}
+
+	step, ok := s.generator.Match(secret, code, time.Now())
+	if !ok || step <= factor.LastUsedStep {
+		return false
+	}
+
+	used, err := s.factorRepo.UseStep(ctx, factor.ID, step)
+	if err != nil {
+		s.logger.Error(ctx, "Failed to record TOTP step", logger.F("error", err), logger.F("factor_id", factor.ID))
+		return false
+	}
+	if !used {
+		s.logger.Warn(ctx, "TOTP code replayed", logger.F("user_id", factor.UserID), logger.F("factor_id", factor.ID))
+	}
+
+	return used
+}
+
+// sealSecret encrypts the secret with AES-256-GCM. The user ID is bound as
+// associated data, so a secret copied to another user's row does not open.
+func (s *MFAService) sealSecret(userID int, secret []byte) ([]byte, error) {
+	gcm, err := s.secretCipher()
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+
+	return gcm.Seal(nonce, nonce, secret, []byte(strconv.Itoa(userID))), nil
+}
+
+// openSecret decrypts a secret sealed by sealSecret
+func (s *MFAService) openSecret(userID int, sealed []byte) ([]byte, error) {
+	gcm, err := s.secretCipher()
+	if err != nil {
+		return nil, err
+	}
+
+	if len(sealed) < gcm.NonceSize() {
+		return nil, fmt.Errorf("malformed sealed secret")
+	}
+
+	nonce, ciphertext := sealed[:gcm.NonceSize()], sealed[gcm.NonceSize():]
+	return gcm.Open(nil, nonce, ciphertext, []byte(strconv.Itoa(userID)))
+}
+
+func (s *MFAService) secretCipher() (cipher.AEAD, error) {
+	key := sha256.Sum256([]byte("mfa-totp:" + s.config.EncryptionKey))
+	block, err := aes.NewCipher(key[:])
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+func (s *MFAService) challengeKey(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return "mfa:challenge:" + hex.EncodeToString(sum[:])
+}
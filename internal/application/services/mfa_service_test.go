@@ -0,0 +1,129 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"otp-server/internal/domain/entities"
+	"otp-server/internal/domain/repositories"
+	"otp-server/internal/infrastructure/config"
+	"otp-server/internal/infrastructure/logger"
+	"otp-server/internal/infrastructure/totp"
+)
+
+// stepFactorRepo keeps the last accepted step of factors in memory, like the
+// conditional update of the database repository
+type stepFactorRepo struct {
+	repositories.UserFactorRepository
+	steps map[int]int64
+}
+
+func (r *stepFactorRepo) UseStep(ctx context.Context, id int, step int64) (bool, error) {
+	if last, ok := r.steps[id]; ok && step <= last {
+		return false, nil
+	}
+	r.steps[id] = step
+	return true, nil
+}
+
+func newTestMFAService(key string) *MFAService {
+	cfg := &config.MFAConfig{
+		EncryptionKey: key,
+		Period:        30 * time.Second,
+		Digits:        6,
+		Skew:          1,
+	}
+	return NewMFAService(&stepFactorRepo{steps: map[int]int64{}}, nil, cfg, logger.New(config.LogConfig{Level: "disabled"}))
+}
+
+func TestSealSecretRoundTrip(t *testing.T) {
+	s := newTestMFAService("0123456789abcdef0123456789abcdef")
+	secret := []byte("12345678901234567890")
+
+	sealed, err := s.sealSecret(42, secret)
+	if err != nil {
+		t.Fatalf("sealSecret: %v", err)
+	}
+	if bytes.Contains(sealed, secret) {
+		t.Fatal("sealed secret contains the plaintext")
+	}
+
+	again, err := s.sealSecret(42, secret)
+	if err != nil {
+		t.Fatalf("sealSecret: %v", err)
+	}
+	if bytes.Equal(sealed, again) {
+		t.Error("sealing twice produced the same ciphertext")
+	}
+
+	opened, err := s.openSecret(42, sealed)
+	if err != nil {
+		t.Fatalf("openSecret: %v", err)
+	}
+	if !bytes.Equal(opened, secret) {
+		t.Errorf("openSecret = %q, want %q", opened, secret)
+	}
+}
+
+func TestOpenSecretRejects(t *testing.T) {
+	s := newTestMFAService("0123456789abcdef0123456789abcdef")
+	sealed, err := s.sealSecret(42, []byte("12345678901234567890"))
+	if err != nil {
+		t.Fatalf("sealSecret: %v", err)
+	}
+
+	tampered := append([]byte(nil), sealed...)
+	tampered[len(tampered)-1] ^= 0x01
+
+	tests := []struct {
+		name    string
+		service *MFAService
+		userID  int
+		sealed  []byte
+	}{
+		{"other user", s, 43, sealed},
+		{"other key", newTestMFAService("fedcba9876543210fedcba9876543210"), 42, sealed},
+		{"tampered", s, 42, tampered},
+		{"truncated", s, 42, sealed[:4]},
+		{"empty", s, 42, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := tt.service.openSecret(tt.userID, tt.sealed); err == nil {
+				t.Error("openSecret succeeded, want an error")
+			}
+		})
+	}
+}
+
+func TestUseCodeRejectsReplay(t *testing.T) {
+	s := newTestMFAService("0123456789abcdef0123456789abcdef")
+	secret := []byte("12345678901234567890")
+	sealed, err := s.sealSecret(42, secret)
+	if err != nil {
+		t.Fatalf("sealSecret: %v", err)
+	}
+
+	factor := entities.NewTOTPFactor(42, "phone", sealed)
+	factor.ID = 7
+
+	g := totp.NewGenerator(30*time.Second, 6, 1)
+	now := time.Now()
+	code := g.Code(secret, g.Step(now))
+
+	if !s.useCode(context.Background(), factor, code) {
+		t.Fatal("first use of the code was rejected")
+	}
+	if s.useCode(context.Background(), factor, code) {
+		t.Error("replayed code was accepted")
+	}
+
+	// A step before the last accepted one is refused without the repository
+	factor.LastUsedStep = g.Step(now)
+	if s.useCode(context.Background(), factor, g.Code(secret, g.Step(now)-1)) {
+		t.Error("code of an earlier step was accepted")
+	}
+}
@@ -0,0 +1,1056 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"otp-server/internal/domain/entities"
+	"otp-server/internal/domain/errors"
+	"otp-server/internal/domain/repositories"
+	"otp-server/internal/infrastructure/config"
+	"otp-server/internal/infrastructure/keyring"
+	"otp-server/internal/infrastructure/logger"
+	"otp-server/internal/infrastructure/redis"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+)
+
+// OAuth 2.0 grant types supported by the token endpoint
+const (
+	GrantTypeAuthorizationCode = "authorization_code"
+	GrantTypeRefreshToken      = "refresh_token"
+)
+
+// Token types reported by introspection
+const (
+	TokenTypeAccessToken  = "access_token"
+	TokenTypeRefreshToken = "refresh_token"
+)
+
+// supportedScopes are the scopes clients can be registered for
+var supportedScopes = []string{entities.ScopeOpenID, entities.ScopePhone, entities.ScopeProfile}
+
+// OIDCService is the OpenID Connect provider. It runs the authorization
+// code flow with PKCE for registered clients, logging users in with the
+// same OTP and second factor as the API, and issues ID tokens.
+type OIDCService struct {
+	clientRepo       repositories.OAuthClientRepository
+	userRepo         repositories.UserRepository
+	authService      *AuthService
+	tokenService     *TokenService
+	apiClientService *APIClientService
+	redisClient      *redis.Client
+	keyring          *keyring.Keyring
+	config           *config.OIDCConfig
+	logger           logger.Logger
+}
+
+// NewOIDCService creates a new OpenID Connect service
+func NewOIDCService(clientRepo repositories.OAuthClientRepository, userRepo repositories.UserRepository, authService *AuthService, tokenService *TokenService, apiClientService *APIClientService, redisClient *redis.Client, keyring *keyring.Keyring, cfg *config.OIDCConfig, logger logger.Logger) *OIDCService {
+	return &OIDCService{
+		clientRepo:       clientRepo,
+		userRepo:         userRepo,
+		authService:      authService,
+		tokenService:     tokenService,
+		apiClientService: apiClientService,
+		redisClient:      redisClient,
+		keyring:          keyring,
+		config:           cfg,
+		logger:           logger,
+	}
+}
+
+// ProviderMetadata is the OpenID Provider configuration served for discovery
+type ProviderMetadata struct {
+	Issuer                            string   `json:"issuer"`
+	AuthorizationEndpoint             string   `json:"authorization_endpoint"`
+	TokenEndpoint                     string   `json:"token_endpoint"`
+	UserInfoEndpoint                  string   `json:"userinfo_endpoint"`
+	IntrospectionEndpoint             string   `json:"introspection_endpoint"`
+	RevocationEndpoint                string   `json:"revocation_endpoint"`
+	JWKSURI                           string   `json:"jwks_uri"`
+	ResponseTypesSupported            []string `json:"response_types_supported"`
+	SubjectTypesSupported             []string `json:"subject_types_supported"`
+	IDTokenSigningAlgValuesSupported  []string `json:"id_token_signing_alg_values_supported"`
+	ScopesSupported                   []string `json:"scopes_supported"`
+	TokenEndpointAuthMethodsSupported []string `json:"token_endpoint_auth_methods_supported"`
+	// Introspection is only open to confidential clients
+	IntrospectionEndpointAuthMethodsSupported []string `json:"introspection_endpoint_auth_methods_supported"`
+	RevocationEndpointAuthMethodsSupported    []string `json:"revocation_endpoint_auth_methods_supported"`
+	CodeChallengeMethodsSupported             []string `json:"code_challenge_methods_supported"`
+	GrantTypesSupported                       []string `json:"grant_types_supported"`
+	ClaimsSupported                           []string `json:"claims_supported"`
+	ACRValuesSupported                        []string `json:"acr_values_supported"`
+}
+
+// AuthorizationParams are the parameters of an authorization request
+// besides the client and its redirect URI
+type AuthorizationParams struct {
+	ResponseType        string
+	Scope               string
+	State               string
+	Nonce               string
+	CodeChallenge       string
+	CodeChallengeMethod string
+}
+
+// AuthorizationRequest is an authorization request waiting for the user to
+// log in. It is kept server-side and the login page only carries its ID,
+// so nobody can complete a login someone else started.
+type AuthorizationRequest struct {
+	ID            string    `json:"-"`
+	ClientID      string    `json:"client_id"`
+	ClientName    string    `json:"client_name"`
+	RedirectURI   string    `json:"redirect_uri"`
+	Scope         string    `json:"scope"`
+	State         string    `json:"state"`
+	Nonce         string    `json:"nonce"`
+	CodeChallenge string    `json:"code_challenge"`
+	ExpiresAt     time.Time `json:"expires_at"`
+	// VerificationID is the login challenge sent to PhoneNumber
+	VerificationID string `json:"verification_id,omitempty"`
+	PhoneNumber    string `json:"phone_number,omitempty"`
+	// MFAToken is set once the code was verified for a user with a second
+	// factor
+	MFAToken string `json:"mfa_token,omitempty"`
+}
+
+// AuthorizationResult is the outcome of a login step of an authorization
+// request: either the user has to enter a second factor, or they are sent
+// back to the client
+type AuthorizationResult struct {
+	Request              *AuthorizationRequest
+	SecondFactorRequired bool
+	// RedirectURL carries the authorization code, or an error if the user
+	// may not log in to the client
+	RedirectURL string
+}
+
+// authorizationCode is what an authorization code stands for until the
+// client exchanges it
+type authorizationCode struct {
+	UserID        int    `json:"user_id"`
+	ClientID      string `json:"client_id"`
+	RedirectURI   string `json:"redirect_uri"`
+	Scope         string `json:"scope"`
+	Nonce         string `json:"nonce"`
+	CodeChallenge string `json:"code_challenge"`
+	AuthTime      int64  `json:"auth_time"`
+	// AuthMethods are the authentication methods of the login
+	AuthMethods []string `json:"amr"`
+	UserAgent   string   `json:"user_agent"`
+	IPAddress   string   `json:"ip_address"`
+}
+
+// TokenRequest is a request to the token endpoint
+type TokenRequest struct {
+	GrantType    string
+	ClientID     string
+	ClientSecret string
+	Code         string
+	RedirectURI  string
+	CodeVerifier string
+	RefreshToken string
+	// Scope is requested by API clients with the client credentials grant
+	Scope string
+}
+
+// Introspection is the state of a token and of the user it was issued for
+// (RFC 7662). Tokens that are invalid, expired or revoked, or whose user was
+// deactivated, are only reported as not active.
+type Introspection struct {
+	Active      bool      `json:"active"`
+	TokenType   string    `json:"token_type,omitempty"`
+	UserID      int       `json:"user_id,omitempty"`
+	TenantID    int       `json:"tenant_id,omitempty"`
+	PhoneNumber string    `json:"phone_number,omitempty"`
+	Role        string    `json:"role,omitempty"`
+	ClientID    string    `json:"client_id,omitempty"`
+	Scope       string    `json:"scope,omitempty"`
+	TokenID     string    `json:"jti,omitempty"`
+	SessionID   string    `json:"sid,omitempty"`
+	AuthTime    time.Time `json:"auth_time"`
+	ACR         string    `json:"acr,omitempty"`
+	AuthMethods []string  `json:"amr,omitempty"`
+	IssuedAt    time.Time `json:"iat"`
+	ExpiresAt   time.Time `json:"exp"`
+}
+
+// TokenResponse are the tokens issued by the token endpoint. The ID token
+// is only issued for an authorization code.
+type TokenResponse struct {
+	AccessToken  string
+	ExpiresIn    time.Duration
+	RefreshToken string
+	IDToken      string
+	Scope        string
+}
+
+// Metadata returns the provider configuration
+func (s *OIDCService) Metadata() *ProviderMetadata {
+	return &ProviderMetadata{
+		Issuer:                            s.config.Issuer,
+		AuthorizationEndpoint:             s.config.Issuer + "/oauth/authorize",
+		TokenEndpoint:                     s.config.Issuer + "/oauth/token",
+		UserInfoEndpoint:                  s.config.Issuer + "/oauth/userinfo",
+		IntrospectionEndpoint:             s.config.Issuer + "/oauth/introspect",
+		RevocationEndpoint:                s.config.Issuer + "/oauth/revoke",
+		JWKSURI:                           s.config.Issuer + "/.well-known/jwks.json",
+		ResponseTypesSupported:            []string{"code"},
+		SubjectTypesSupported:             []string{"public"},
+		IDTokenSigningAlgValuesSupported:  []string{s.keyring.Algorithm()},
+		ScopesSupported:                   supportedScopes,
+		TokenEndpointAuthMethodsSupported: []string{"client_secret_basic", "client_secret_post", "none"},
+		IntrospectionEndpointAuthMethodsSupported: []string{"client_secret_basic", "client_secret_post"},
+		RevocationEndpointAuthMethodsSupported:    []string{"client_secret_basic", "client_secret_post", "none"},
+		CodeChallengeMethodsSupported:             []string{"S256"},
+		GrantTypesSupported:                       []string{GrantTypeAuthorizationCode, GrantTypeRefreshToken, GrantTypeClientCredentials},
+		ClaimsSupported:                           []string{"iss", "sub", "aud", "exp", "iat", "auth_time", "acr", "amr", "nonce", "sid", "name", "phone_number", "phone_number_verified"},
+		ACRValuesSupported:                        []string{entities.ACRSingleFactor, entities.ACRMultiFactor},
+	}
+}
+
+// ResolveClient returns the active client with the redirect URI registered.
+// Until both are known, errors must be shown to the user rather than sent
+// to the redirect URI.
+func (s *OIDCService) ResolveClient(ctx context.Context, clientID, redirectURI string) (*entities.OAuthClient, error) {
+	if clientID == "" || redirectURI == "" {
+		return nil, errors.NewOAuthError("invalid_request", "client_id and redirect_uri are required")
+	}
+
+	client, err := s.clientRepo.GetByClientID(ctx, clientID)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return nil, errors.NewOAuthError("invalid_client", "unknown client")
+		}
+		return nil, err
+	}
+	if !client.IsActive {
+		return nil, errors.NewOAuthError("invalid_client", "unknown client")
+	}
+
+	if !client.HasRedirectURI(redirectURI) {
+		return nil, errors.NewOAuthError("invalid_request", "redirect_uri is not registered for the client")
+	}
+
+	return client, nil
+}
+
+// StartAuthorization validates an authorization request of the client and
+// stores it until the user logged in. PKCE is required of every client.
+func (s *OIDCService) StartAuthorization(ctx context.Context, client *entities.OAuthClient, redirectURI string, params *AuthorizationParams) (*AuthorizationRequest, error) {
+	if params.ResponseType != "code" {
+		return nil, errors.NewOAuthError("unsupported_response_type", "response_type must be code")
+	}
+	if !entities.HasScope(params.Scope, entities.ScopeOpenID) {
+		return nil, errors.NewOAuthError("invalid_scope", "scope must include openid")
+	}
+	if !client.AllowsScope(params.Scope) {
+		return nil, errors.NewOAuthError("invalid_scope", "scope is not allowed for the client")
+	}
+	if params.CodeChallenge == "" {
+		return nil, errors.NewOAuthError("invalid_request", "code_challenge is required")
+	}
+	if params.CodeChallengeMethod != "S256" {
+		return nil, errors.NewOAuthError("invalid_request", "code_challenge_method must be S256")
+	}
+	// A base64url encoded SHA-256 hash
+	if len(params.CodeChallenge) != 43 {
+		return nil, errors.NewOAuthError("invalid_request", "code_challenge is malformed")
+	}
+
+	id, err := randomToken()
+	if err != nil {
+		return nil, err
+	}
+
+	request := &AuthorizationRequest{
+		ID:            id,
+		ClientID:      client.ClientID,
+		ClientName:    client.Name,
+		RedirectURI:   redirectURI,
+		Scope:         strings.Join(strings.Fields(params.Scope), " "),
+		State:         params.State,
+		Nonce:         params.Nonce,
+		CodeChallenge: params.CodeChallenge,
+		ExpiresAt:     time.Now().Add(s.config.AuthorizationTTL),
+	}
+	if err := s.saveAuthorization(ctx, request); err != nil {
+		return nil, err
+	}
+
+	return request, nil
+}
+
+// GetAuthorization returns a pending authorization request
+func (s *OIDCService) GetAuthorization(ctx context.Context, requestID string) (*AuthorizationRequest, error) {
+	data, err := s.redisClient.Get(ctx, s.authorizationKey(requestID))
+	if err != nil {
+		if redis.IsNil(err) {
+			return nil, errors.NewNotFound("authorization request")
+		}
+		return nil, err
+	}
+
+	var request AuthorizationRequest
+	if err := json.Unmarshal([]byte(data), &request); err != nil {
+		return nil, err
+	}
+	request.ID = requestID
+
+	return &request, nil
+}
+
+// SendLoginOTP sends the login code for a pending authorization request
+func (s *OIDCService) SendLoginOTP(ctx context.Context, requestID string, req *entities.OTPRequest) (*AuthorizationRequest, *entities.OTPChallenge, error) {
+	request, err := s.GetAuthorization(ctx, requestID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	req.Purpose = entities.OTPPurposeLogin
+	challenge, err := s.authService.SendOTP(ctx, req)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	request.VerificationID = challenge.ID
+	request.PhoneNumber = challenge.PhoneNumber
+	request.MFAToken = ""
+	if err := s.saveAuthorization(ctx, request); err != nil {
+		return nil, nil, err
+	}
+
+	return request, challenge, nil
+}
+
+// VerifyLoginOTP checks the login code of a pending authorization request.
+// Unless the user has a second factor, this completes the authorization.
+func (s *OIDCService) VerifyLoginOTP(ctx context.Context, requestID, otpCode string, device *entities.DeviceInfo) (*AuthorizationResult, error) {
+	request, err := s.GetAuthorization(ctx, requestID)
+	if err != nil {
+		return nil, err
+	}
+	if request.VerificationID == "" {
+		return nil, errors.ErrOTPInvalid
+	}
+
+	result, err := s.authService.VerifyOTPLogin(ctx, request.VerificationID, otpCode, "")
+	if err != nil {
+		return nil, err
+	}
+
+	if result.SecondFactorRequired {
+		request.MFAToken = result.MFAToken
+		if err := s.saveAuthorization(ctx, request); err != nil {
+			return nil, err
+		}
+		return &AuthorizationResult{Request: request, SecondFactorRequired: true}, nil
+	}
+
+	return s.completeAuthorization(ctx, request, result.User, device, singleFactorMethods)
+}
+
+// VerifyLoginSecondFactor checks the second factor of a pending
+// authorization request and completes it
+func (s *OIDCService) VerifyLoginSecondFactor(ctx context.Context, requestID, code string, device *entities.DeviceInfo) (*AuthorizationResult, error) {
+	request, err := s.GetAuthorization(ctx, requestID)
+	if err != nil {
+		return nil, err
+	}
+	if request.MFAToken == "" {
+		return nil, errors.ErrSecondFactorInvalid
+	}
+
+	user, err := s.authService.VerifySecondFactorLogin(ctx, request.MFAToken, code)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.completeAuthorization(ctx, request, user, device, multiFactorMethods)
+}
+
+// Exchange serves a token request of a client
+func (s *OIDCService) Exchange(ctx context.Context, req *TokenRequest) (*TokenResponse, error) {
+	// API clients are not OpenID Connect clients and have a grant of their own
+	if req.GrantType == GrantTypeClientCredentials {
+		return s.apiClientService.IssueToken(ctx, req.ClientID, req.ClientSecret, req.Scope)
+	}
+
+	client, err := s.AuthenticateClient(ctx, req.ClientID, req.ClientSecret)
+	if err != nil {
+		return nil, err
+	}
+
+	switch req.GrantType {
+	case GrantTypeAuthorizationCode:
+		return s.exchangeCode(ctx, client, req)
+	case GrantTypeRefreshToken:
+		return s.refresh(ctx, client, req)
+	}
+
+	return nil, errors.NewOAuthError("unsupported_grant_type", "grant_type must be authorization_code, refresh_token or client_credentials")
+}
+
+// AuthenticateClient authenticates a client by its ID and secret. Public
+// clients authenticate by their ID alone.
+func (s *OIDCService) AuthenticateClient(ctx context.Context, clientID, clientSecret string) (*entities.OAuthClient, error) {
+	if clientID == "" {
+		return nil, errors.NewOAuthError("invalid_client", "client authentication failed")
+	}
+
+	client, err := s.clientRepo.GetByClientID(ctx, clientID)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return nil, errors.NewOAuthError("invalid_client", "client authentication failed")
+		}
+		return nil, err
+	}
+	if !client.IsActive {
+		return nil, errors.NewOAuthError("invalid_client", "client authentication failed")
+	}
+
+	if client.IsPublic() {
+		if clientSecret != "" {
+			return nil, errors.NewOAuthError("invalid_client", "client authentication failed")
+		}
+		return client, nil
+	}
+
+	if subtle.ConstantTimeCompare([]byte(hashClientSecret(clientSecret)), []byte(client.SecretHash)) != 1 {
+		return nil, errors.NewOAuthError("invalid_client", "client authentication failed")
+	}
+
+	return client, nil
+}
+
+// Introspect reports whether a token is active and what it grants, to
+// resource servers authenticating as confidential clients. Any access or
+// refresh token can be introspected, whether it was issued to a client or
+// by the API login; the two are told apart by their format.
+func (s *OIDCService) Introspect(ctx context.Context, clientID, clientSecret, token string) (*Introspection, error) {
+	client, err := s.AuthenticateClient(ctx, clientID, clientSecret)
+	if err != nil {
+		return nil, err
+	}
+	if client.IsPublic() {
+		return nil, errors.NewOAuthError("unauthorized_client", "public clients cannot introspect tokens")
+	}
+	if token == "" {
+		return nil, errors.NewOAuthError("invalid_request", "token is required")
+	}
+
+	if isAccessToken(token) {
+		return s.introspectAccessToken(ctx, token)
+	}
+	return s.introspectRefreshToken(ctx, token)
+}
+
+// Revoke revokes a token the client holds together with its session, so
+// the matching access or refresh tokens stop working too (RFC 7009). Tokens
+// that are invalid, expired or already revoked are ignored.
+func (s *OIDCService) Revoke(ctx context.Context, clientID, clientSecret, token string) error {
+	client, err := s.AuthenticateClient(ctx, clientID, clientSecret)
+	if err != nil {
+		return err
+	}
+	if token == "" {
+		return errors.NewOAuthError("invalid_request", "token is required")
+	}
+
+	if isAccessToken(token) {
+		claims, err := s.tokenService.ParseAccessToken(ctx, token)
+		if err != nil {
+			return nil
+		}
+		if claims.ClientID != client.ClientID {
+			return errors.NewOAuthError("unauthorized_client", "the token was not issued to the client")
+		}
+
+		if err := s.tokenService.RevokeAccessToken(ctx, claims); err != nil {
+			return err
+		}
+
+		s.logger.Info(ctx, "Access token revoked by client", logger.F("client_id", client.ClientID), logger.F("session_id", claims.SessionID))
+		return nil
+	}
+
+	stored, session, err := s.tokenService.LookupRefreshToken(ctx, token)
+	if err != nil {
+		if errors.IsRefreshTokenInvalid(err) {
+			return nil
+		}
+		return err
+	}
+	if session.ClientID != client.ClientID {
+		return errors.NewOAuthError("unauthorized_client", "the token was not issued to the client")
+	}
+
+	if err := s.tokenService.RevokeSession(ctx, stored.UserID, session.ID); err != nil && !errors.IsNotFound(err) {
+		return err
+	}
+
+	s.logger.Info(ctx, "Refresh token revoked by client", logger.F("client_id", client.ClientID), logger.F("session_id", session.ID))
+	return nil
+}
+
+// UserInfo returns the claims about the user an access token was issued
+// for, limited to the scope granted to the client
+func (s *OIDCService) UserInfo(ctx context.Context, accessToken string) (map[string]interface{}, error) {
+	claims, err := s.tokenService.ParseAccessToken(ctx, accessToken)
+	if err != nil {
+		return nil, errors.NewOAuthError("invalid_token", "invalid or expired access token")
+	}
+	if !entities.HasScope(claims.Scope, entities.ScopeOpenID) {
+		return nil, errors.NewOAuthError("insufficient_scope", "the access token was not granted the openid scope")
+	}
+
+	user, err := s.userRepo.GetByID(ctx, claims.UserID)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return nil, errors.NewOAuthError("invalid_token", "invalid or expired access token")
+		}
+		return nil, err
+	}
+	if !user.IsActive() {
+		return nil, errors.NewOAuthError("invalid_token", "invalid or expired access token")
+	}
+
+	info := map[string]interface{}{"sub": strconv.Itoa(user.ID)}
+	for name, value := range userClaims(user, claims.Scope) {
+		info[name] = value
+	}
+
+	return info, nil
+}
+
+// RegisterClient registers a new client and returns it with its secret,
+// which is not stored and cannot be shown again. Public clients get no
+// secret. Confidential clients without redirect URIs cannot log users in,
+// which suits resource servers that only introspect tokens.
+func (s *OIDCService) RegisterClient(ctx context.Context, name string, redirectURIs, scopes []string, public bool) (*entities.OAuthClient, string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, "", errors.NewInvalidInput("name", name)
+	}
+
+	if len(redirectURIs) == 0 {
+		if public {
+			return nil, "", errors.NewInvalidInput("redirect_uris", redirectURIs)
+		}
+		redirectURIs = []string{}
+	}
+	for _, uri := range redirectURIs {
+		parsed, err := url.Parse(uri)
+		if err != nil || parsed.Scheme == "" || parsed.Host == "" || parsed.Fragment != "" {
+			return nil, "", errors.NewInvalidInput("redirect_uris", uri)
+		}
+	}
+
+	if len(scopes) == 0 {
+		scopes = supportedScopes
+	}
+	for _, scope := range scopes {
+		if !entities.HasScope(strings.Join(supportedScopes, " "), scope) {
+			return nil, "", errors.NewInvalidInput("scopes", scope)
+		}
+	}
+	if !entities.HasScope(strings.Join(scopes, " "), entities.ScopeOpenID) {
+		return nil, "", errors.NewInvalidInput("scopes", "openid is required")
+	}
+
+	client := &entities.OAuthClient{
+		ClientID:     uuid.NewString(),
+		Name:         name,
+		RedirectURIs: redirectURIs,
+		Scopes:       scopes,
+		IsActive:     true,
+	}
+
+	var secret string
+	if !public {
+		var err error
+		if secret, err = randomToken(); err != nil {
+			return nil, "", err
+		}
+		client.SecretHash = hashClientSecret(secret)
+	}
+
+	if err := s.clientRepo.Create(ctx, client); err != nil {
+		return nil, "", err
+	}
+
+	s.logger.Info(ctx, "OAuth client registered", logger.F("client_id", client.ClientID), logger.F("public", public))
+
+	return client, secret, nil
+}
+
+// ListClients returns every registered client
+func (s *OIDCService) ListClients(ctx context.Context) ([]*entities.OAuthClient, error) {
+	return s.clientRepo.List(ctx)
+}
+
+// DeactivateClient stops a client from logging users in and from
+// refreshing the tokens it holds
+func (s *OIDCService) DeactivateClient(ctx context.Context, clientID string) error {
+	if err := s.clientRepo.Deactivate(ctx, clientID); err != nil {
+		return err
+	}
+
+	s.logger.Info(ctx, "OAuth client deactivated", logger.F("client_id", clientID))
+	return nil
+}
+
+// AuthorizationErrorURL returns the redirect URI with an error response of
+// an authorization request
+func AuthorizationErrorURL(redirectURI, state string, err *errors.OAuthError) string {
+	params := url.Values{"error": {err.Code}, "error_description": {err.Description}}
+	if state != "" {
+		params.Set("state", state)
+	}
+	return authorizationRedirect(redirectURI, params)
+}
+
+// introspectAccessToken reports on an access token. Active results are
+// cached briefly, sparing the signature check and the user lookup on hot
+// tokens, but revocation is checked on every call.
+func (s *OIDCService) introspectAccessToken(ctx context.Context, token string) (*Introspection, error) {
+	key := s.introspectionKey(token)
+
+	if cached := s.cachedIntrospection(ctx, key); cached != nil {
+		claims := &AccessClaims{
+			UserID:    cached.UserID,
+			ID:        cached.TokenID,
+			SessionID: cached.SessionID,
+			IssuedAt:  cached.IssuedAt,
+		}
+		if err := s.tokenService.checkRevoked(ctx, claims); err != nil {
+			return &Introspection{}, nil
+		}
+		return cached, nil
+	}
+
+	claims, err := s.tokenService.ParseAccessToken(ctx, token)
+	if err != nil {
+		return &Introspection{}, nil
+	}
+
+	if claims.APIClientID != "" {
+		return s.introspectClientToken(ctx, claims)
+	}
+
+	user, err := s.userRepo.GetByID(ctx, claims.UserID)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return &Introspection{}, nil
+		}
+		return nil, err
+	}
+	if !user.IsActive() || user.PhoneNumber != claims.PhoneNumber {
+		return &Introspection{}, nil
+	}
+
+	result := &Introspection{
+		Active:      true,
+		TokenType:   TokenTypeAccessToken,
+		UserID:      user.ID,
+		TenantID:    user.TenantID,
+		PhoneNumber: user.PhoneNumber,
+		Role:        string(user.Role),
+		ClientID:    claims.ClientID,
+		Scope:       claims.Scope,
+		TokenID:     claims.ID,
+		SessionID:   claims.SessionID,
+		AuthTime:    claims.AuthTime,
+		ACR:         claims.ACR,
+		AuthMethods: claims.AuthMethods,
+		IssuedAt:    claims.IssuedAt,
+		ExpiresAt:   claims.ExpiresAt,
+	}
+	s.cacheIntrospection(ctx, key, result)
+
+	return result, nil
+}
+
+// introspectClientToken reports on an access token issued to an API client.
+// These are not cached, so deactivating the client takes effect at once.
+func (s *OIDCService) introspectClientToken(ctx context.Context, claims *AccessClaims) (*Introspection, error) {
+	client, err := s.apiClientService.ActiveClient(ctx, claims.APIClientID)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return &Introspection{}, nil
+		}
+		return nil, err
+	}
+
+	return &Introspection{
+		Active:    true,
+		TokenType: TokenTypeAccessToken,
+		TenantID:  client.TenantID,
+		ClientID:  client.ClientID,
+		Scope:     claims.Scope,
+		TokenID:   claims.ID,
+		IssuedAt:  claims.IssuedAt,
+		ExpiresAt: claims.ExpiresAt,
+	}, nil
+}
+
+// introspectRefreshToken reports on a refresh token. Refresh tokens are
+// only presented when they are exchanged, so they are not cached.
+func (s *OIDCService) introspectRefreshToken(ctx context.Context, token string) (*Introspection, error) {
+	stored, session, err := s.tokenService.LookupRefreshToken(ctx, token)
+	if err != nil {
+		if errors.IsRefreshTokenInvalid(err) {
+			return &Introspection{}, nil
+		}
+		return nil, err
+	}
+
+	user, err := s.userRepo.GetByID(ctx, stored.UserID)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return &Introspection{}, nil
+		}
+		return nil, err
+	}
+	if !user.IsActive() {
+		return &Introspection{}, nil
+	}
+
+	return &Introspection{
+		Active:      true,
+		TokenType:   TokenTypeRefreshToken,
+		UserID:      user.ID,
+		PhoneNumber: user.PhoneNumber,
+		Role:        string(user.Role),
+		ClientID:    session.ClientID,
+		Scope:       session.Scope,
+		SessionID:   session.ID,
+		AuthTime:    session.AuthTime,
+		ACR:         entities.AuthContextClass(session.AuthMethods),
+		AuthMethods: session.AuthMethods,
+		IssuedAt:    stored.CreatedAt,
+		ExpiresAt:   stored.ExpiresAt,
+	}, nil
+}
+
+func (s *OIDCService) cachedIntrospection(ctx context.Context, key string) *Introspection {
+	if s.config.IntrospectionCacheTTL <= 0 {
+		return nil
+	}
+
+	data, err := s.redisClient.Get(ctx, key)
+	if err != nil {
+		if !redis.IsNil(err) {
+			s.logger.Error(ctx, "Failed to read cached introspection", logger.F("error", err))
+		}
+		return nil
+	}
+
+	var result Introspection
+	if err := json.Unmarshal([]byte(data), &result); err != nil {
+		return nil
+	}
+	return &result
+}
+
+func (s *OIDCService) cacheIntrospection(ctx context.Context, key string, result *Introspection) {
+	ttl := s.config.IntrospectionCacheTTL
+	if remaining := time.Until(result.ExpiresAt); remaining < ttl {
+		ttl = remaining
+	}
+	if ttl <= 0 {
+		return
+	}
+
+	data, err := json.Marshal(result)
+	if err != nil {
+		return
+	}
+	if err := s.redisClient.Set(ctx, key, data, ttl); err != nil {
+		s.logger.Error(ctx, "Failed to cache introspection", logger.F("error", err))
+	}
+}
+
+// completeAuthorization ends a pending authorization request once the user
+// logged in with the methods, issuing an authorization code unless the user
+// may not log in
+func (s *OIDCService) completeAuthorization(ctx context.Context, request *AuthorizationRequest, user *entities.User, device *entities.DeviceInfo, methods []string) (*AuthorizationResult, error) {
+	if err := s.redisClient.Del(ctx, s.authorizationKey(request.ID)); err != nil {
+		return nil, err
+	}
+
+	if !user.IsActive() {
+		return &AuthorizationResult{
+			Request:     request,
+			RedirectURL: AuthorizationErrorURL(request.RedirectURI, request.State, errors.NewOAuthError("access_denied", "the account is not active")),
+		}, nil
+	}
+	if user.PhoneConfirmationRequired {
+		return &AuthorizationResult{
+			Request:     request,
+			RedirectURL: AuthorizationErrorURL(request.RedirectURI, request.State, errors.NewOAuthError("access_denied", "the phone number must be confirmed first")),
+		}, nil
+	}
+
+	code, err := randomToken()
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := json.Marshal(authorizationCode{
+		UserID:        user.ID,
+		ClientID:      request.ClientID,
+		RedirectURI:   request.RedirectURI,
+		Scope:         request.Scope,
+		Nonce:         request.Nonce,
+		CodeChallenge: request.CodeChallenge,
+		AuthTime:      time.Now().Unix(),
+		AuthMethods:   methods,
+		UserAgent:     device.UserAgent,
+		IPAddress:     device.IPAddress,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := s.redisClient.Set(ctx, s.codeKey(code), data, s.config.CodeTTL); err != nil {
+		return nil, err
+	}
+
+	s.logger.Info(ctx, "Authorization code issued", logger.F("user_id", user.ID), logger.F("client_id", request.ClientID))
+
+	params := url.Values{"code": {code}}
+	if request.State != "" {
+		params.Set("state", request.State)
+	}
+
+	return &AuthorizationResult{
+		Request:     request,
+		RedirectURL: authorizationRedirect(request.RedirectURI, params),
+	}, nil
+}
+
+// exchangeCode exchanges an authorization code for tokens. The code is
+// consumed by the first attempt, whether it succeeds or not.
+func (s *OIDCService) exchangeCode(ctx context.Context, client *entities.OAuthClient, req *TokenRequest) (*TokenResponse, error) {
+	if req.Code == "" {
+		return nil, errors.NewOAuthError("invalid_request", "code is required")
+	}
+
+	data, err := s.redisClient.GetDel(ctx, s.codeKey(req.Code))
+	if err != nil {
+		if redis.IsNil(err) {
+			return nil, errors.NewOAuthError("invalid_grant", "invalid or expired authorization code")
+		}
+		return nil, err
+	}
+
+	var code authorizationCode
+	if err := json.Unmarshal([]byte(data), &code); err != nil {
+		return nil, err
+	}
+
+	if code.ClientID != client.ClientID {
+		return nil, errors.NewOAuthError("invalid_grant", "invalid or expired authorization code")
+	}
+	if code.RedirectURI != req.RedirectURI {
+		return nil, errors.NewOAuthError("invalid_grant", "redirect_uri does not match the authorization request")
+	}
+	if !verifyCodeChallenge(req.CodeVerifier, code.CodeChallenge) {
+		return nil, errors.NewOAuthError("invalid_grant", "code_verifier does not match the code challenge")
+	}
+
+	user, err := s.userRepo.GetByID(ctx, code.UserID)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return nil, errors.NewOAuthError("invalid_grant", "the user no longer exists")
+		}
+		return nil, err
+	}
+	if !user.IsActive() {
+		return nil, errors.NewOAuthError("invalid_grant", "the account is not active")
+	}
+
+	device := &entities.DeviceInfo{
+		Name:      client.Name,
+		UserAgent: code.UserAgent,
+		IPAddress: code.IPAddress,
+	}
+	authTime := time.Unix(code.AuthTime, 0)
+	pair, err := s.tokenService.IssueForClient(ctx, user, device, authTime, code.AuthMethods, client.ClientID, code.Scope)
+	if err != nil {
+		return nil, err
+	}
+
+	idToken, err := s.idToken(user, client.ClientID, code.Scope, code.Nonce, code.AuthTime, code.AuthMethods, pair.SessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &TokenResponse{
+		AccessToken:  pair.AccessToken,
+		ExpiresIn:    pair.ExpiresIn,
+		RefreshToken: pair.RefreshToken,
+		IDToken:      idToken,
+		Scope:        pair.Scope,
+	}, nil
+}
+
+func (s *OIDCService) refresh(ctx context.Context, client *entities.OAuthClient, req *TokenRequest) (*TokenResponse, error) {
+	if req.RefreshToken == "" {
+		return nil, errors.NewOAuthError("invalid_request", "refresh_token is required")
+	}
+
+	_, pair, err := s.tokenService.Refresh(ctx, req.RefreshToken, client.ClientID)
+	if err != nil {
+		if errors.IsRefreshTokenInvalid(err) {
+			return nil, errors.NewOAuthError("invalid_grant", "invalid, expired or revoked refresh token")
+		}
+		return nil, err
+	}
+
+	return &TokenResponse{
+		AccessToken:  pair.AccessToken,
+		ExpiresIn:    pair.ExpiresIn,
+		RefreshToken: pair.RefreshToken,
+		Scope:        pair.Scope,
+	}, nil
+}
+
+// idToken signs the ID token of a login to the client. ID tokens are signed
+// with the same keys as access tokens, so clients verify them through the
+// JWKS; an HS256 token could only be checked with the server's own secret.
+func (s *OIDCService) idToken(user *entities.User, clientID, scope, nonce string, authTime int64, methods []string, sessionID string) (string, error) {
+	if s.keyring.Algorithm() == keyring.AlgorithmHS256 {
+		return "", fmt.Errorf("ID tokens cannot be signed with %s", keyring.AlgorithmHS256)
+	}
+
+	now := time.Now()
+	claims := jwt.MapClaims{
+		"iss":       s.config.Issuer,
+		"sub":       strconv.Itoa(user.ID),
+		"aud":       clientID,
+		"exp":       now.Add(s.config.IDTokenExpiry).Unix(),
+		"iat":       now.Unix(),
+		"auth_time": authTime,
+		"acr":       entities.AuthContextClass(methods),
+		"sid":       sessionID,
+	}
+	if len(methods) > 0 {
+		claims["amr"] = methods
+	}
+	if nonce != "" {
+		claims["nonce"] = nonce
+	}
+	for name, value := range userClaims(user, scope) {
+		claims[name] = value
+	}
+
+	return s.keyring.Sign(claims)
+}
+
+func (s *OIDCService) saveAuthorization(ctx context.Context, request *AuthorizationRequest) error {
+	ttl := time.Until(request.ExpiresAt)
+	if ttl <= 0 {
+		return errors.NewNotFound("authorization request")
+	}
+
+	data, err := json.Marshal(request)
+	if err != nil {
+		return err
+	}
+
+	return s.redisClient.Set(ctx, s.authorizationKey(request.ID), data, ttl)
+}
+
+func (s *OIDCService) authorizationKey(requestID string) string {
+	return "oidc:authorize:" + requestID
+}
+
+// introspectionKey caches results by the hash of the token, so the keys
+// cannot be used as tokens
+func (s *OIDCService) introspectionKey(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return "oidc:introspect:" + hex.EncodeToString(sum[:])
+}
+
+// codeKey stores codes by their hash, so the keys alone cannot be
+// exchanged
+func (s *OIDCService) codeKey(code string) string {
+	sum := sha256.Sum256([]byte(code))
+	return "oidc:code:" + hex.EncodeToString(sum[:])
+}
+
+// userClaims returns the claims about the user the scope grants
+func userClaims(user *entities.User, scope string) map[string]interface{} {
+	claims := map[string]interface{}{}
+	if entities.HasScope(scope, entities.ScopePhone) {
+		claims["phone_number"] = user.PhoneNumber
+		// The number was proven by an OTP, unless a recovery code login
+		// left it to be confirmed again
+		claims["phone_number_verified"] = !user.PhoneConfirmationRequired
+	}
+	if entities.HasScope(scope, entities.ScopeProfile) {
+		claims["name"] = user.Name
+	}
+	return claims
+}
+
+// isAccessToken tells access tokens, which are JWTs, from opaque refresh
+// tokens
+func isAccessToken(token string) bool {
+	return strings.Count(token, ".") == 2
+}
+
+// verifyCodeChallenge checks a PKCE code verifier against the S256 code
+// challenge of the authorization request (RFC 7636)
+func verifyCodeChallenge(verifier, challenge string) bool {
+	if len(verifier) < 43 || len(verifier) > 128 {
+		return false
+	}
+	sum := sha256.Sum256([]byte(verifier))
+	computed := base64.RawURLEncoding.EncodeToString(sum[:])
+	return subtle.ConstantTimeCompare([]byte(computed), []byte(challenge)) == 1
+}
+
+func authorizationRedirect(redirectURI string, params url.Values) string {
+	parsed, err := url.Parse(redirectURI)
+	if err != nil {
+		return redirectURI
+	}
+
+	query := parsed.Query()
+	for name, values := range params {
+		query[name] = values
+	}
+	parsed.RawQuery = query.Encode()
+
+	return parsed.String()
+}
+
+// randomToken returns 256 random bits, base64url encoded
+func randomToken() (string, error) {
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(raw), nil
+}
+
+// hashClientSecret returns the hash stored in place of a client secret.
+// Secrets carry 256 random bits, so an unkeyed hash is enough.
+func hashClientSecret(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
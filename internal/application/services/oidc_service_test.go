@@ -0,0 +1,82 @@
+package services
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"otp-server/internal/domain/entities"
+	"otp-server/internal/domain/errors"
+)
+
+// The verifier and challenge of RFC 7636 appendix B
+const (
+	rfc7636Verifier  = "dBjftJeZ4CVP-mB92K27uhbUJU1p1r_wW1gFWFOEjXk"
+	rfc7636Challenge = "E9Melhoa2OwvFrEMTJguCHaoeK1t8URWbuGJSstw-cM"
+)
+
+func TestVerifyCodeChallenge(t *testing.T) {
+	tests := []struct {
+		name      string
+		verifier  string
+		challenge string
+		want      bool
+	}{
+		{"rfc 7636 appendix b", rfc7636Verifier, rfc7636Challenge, true},
+		{"wrong verifier", strings.Replace(rfc7636Verifier, "d", "e", 1), rfc7636Challenge, false},
+		{"plain challenge", rfc7636Verifier, rfc7636Verifier, false},
+		{"verifier too short", rfc7636Verifier[:42], rfc7636Challenge, false},
+		{"verifier too long", strings.Repeat("a", 129), rfc7636Challenge, false},
+		{"empty verifier", "", rfc7636Challenge, false},
+		{"empty challenge", rfc7636Verifier, "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := verifyCodeChallenge(tt.verifier, tt.challenge); got != tt.want {
+				t.Errorf("verifyCodeChallenge = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStartAuthorizationRejectsBadCodeChallenge(t *testing.T) {
+	s := &OIDCService{}
+	client := &entities.OAuthClient{
+		ClientID: "client",
+		Scopes:   []string{entities.ScopeOpenID},
+		IsActive: true,
+	}
+
+	tests := []struct {
+		name      string
+		challenge string
+		method    string
+	}{
+		{"missing challenge", "", "S256"},
+		{"missing method", rfc7636Challenge, ""},
+		{"plain method", rfc7636Challenge, "plain"},
+		{"challenge too short", rfc7636Challenge[:42], "S256"},
+		{"challenge too long", rfc7636Challenge + "A", "S256"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			params := &AuthorizationParams{
+				ResponseType:        "code",
+				Scope:               entities.ScopeOpenID,
+				CodeChallenge:       tt.challenge,
+				CodeChallengeMethod: tt.method,
+			}
+
+			_, err := s.StartAuthorization(context.Background(), client, "https://app.example.com/callback", params)
+			oauthErr, ok := errors.AsOAuthError(err)
+			if !ok {
+				t.Fatalf("StartAuthorization error = %v, want an OAuth error", err)
+			}
+			if oauthErr.Code != "invalid_request" {
+				t.Errorf("error code = %s, want invalid_request", oauthErr.Code)
+			}
+		})
+	}
+}
@@ -0,0 +1,172 @@
+package services
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"strconv"
+	"strings"
+
+	"otp-server/internal/domain/entities"
+	"otp-server/internal/domain/errors"
+	"otp-server/internal/domain/repositories"
+	"otp-server/internal/infrastructure/config"
+	"otp-server/internal/infrastructure/logger"
+)
+
+const (
+	// recoveryCodeAlphabet is Crockford's base32: no i, l, o or u, so codes
+	// survive being read aloud or copied by hand
+	recoveryCodeAlphabet = "0123456789abcdefghjkmnpqrstvwxyz"
+	recoveryCodeLength   = 10
+)
+
+// RecoveryService manages single-use recovery codes, which let a user who
+// lost their phone log in without an SMS code
+type RecoveryService struct {
+	userRepo    repositories.UserRepository
+	codeRepo    repositories.RecoveryCodeRepository
+	cache       repositories.UserCacheRepository
+	config      *config.MFAConfig
+	logger      logger.Logger
+	usedHandler func(context.Context, *entities.User, int) error
+}
+
+// NewRecoveryService creates a new recovery service
+func NewRecoveryService(userRepo repositories.UserRepository, codeRepo repositories.RecoveryCodeRepository, cacheRepo repositories.UserCacheRepository, cfg *config.MFAConfig, logger logger.Logger) *RecoveryService {
+	return &RecoveryService{
+		userRepo: userRepo,
+		codeRepo: codeRepo,
+		cache:    cacheRepo,
+		config:   cfg,
+		logger:   logger,
+	}
+}
+
+// SetUsedHandler sets the handler called with the user and the number of
+// codes left whenever a recovery code is used
+func (s *RecoveryService) SetUsedHandler(handler func(context.Context, *entities.User, int) error) {
+	s.usedHandler = handler
+}
+
+// GenerateCodes replaces the user's recovery codes with a new set and returns
+// it. The codes are only ever returned here; afterwards only their hashes
+// are stored.
+func (s *RecoveryService) GenerateCodes(ctx context.Context, user *entities.User) ([]string, error) {
+	codes := make([]string, s.config.RecoveryCodeCount)
+	hashes := make([]string, len(codes))
+	for i := range codes {
+		code, err := generateRecoveryCode()
+		if err != nil {
+			return nil, err
+		}
+		codes[i] = code
+		hashes[i] = s.hashCode(user.ID, code)
+	}
+
+	if err := s.codeRepo.Replace(ctx, user.ID, hashes); err != nil {
+		return nil, err
+	}
+
+	s.logger.Info(ctx, "Recovery codes generated", logger.F("user_id", user.ID), logger.F("count", len(codes)))
+
+	return codes, nil
+}
+
+// RemainingCodes returns how many unused recovery codes the user has left
+func (s *RecoveryService) RemainingCodes(ctx context.Context, user *entities.User) (int, error) {
+	return s.codeRepo.CountUnused(ctx, user.ID)
+}
+
+// Redeem spends a recovery code of the user with the phone number in the
+// context's tenant. Whoever holds a recovery code may no longer hold the
+// phone, so the account is blocked until a phone number is confirmed again.
+func (s *RecoveryService) Redeem(ctx context.Context, phoneNumber, code string) (*entities.User, error) {
+	user, err := s.userRepo.GetByPhoneNumber(ctx, entities.TenantIDFromContext(ctx), phoneNumber)
+	if err != nil {
+		return nil, err
+	}
+
+	used, err := s.codeRepo.Use(ctx, user.ID, s.hashCode(user.ID, code))
+	if err != nil {
+		return nil, err
+	}
+	if !used {
+		return nil, errors.ErrOTPInvalid
+	}
+
+	user.RequirePhoneConfirmation()
+	if err := s.userRepo.Update(ctx, user); err != nil {
+		return nil, err
+	}
+	s.invalidate(ctx, user.ID)
+
+	remaining, err := s.codeRepo.CountUnused(ctx, user.ID)
+	if err != nil {
+		s.logger.Error(ctx, "Failed to count recovery codes", logger.F("error", err), logger.F("user_id", user.ID))
+	}
+
+	s.logger.Warn(ctx, "Recovery code used", logger.F("user_id", user.ID), logger.F("remaining", remaining))
+
+	if s.usedHandler != nil {
+		s.usedHandler(ctx, user, remaining)
+	}
+
+	return user, nil
+}
+
+// ConfirmPhoneNumber stores a phone number the user proved to control and
+// lifts a pending phone confirmation
+func (s *RecoveryService) ConfirmPhoneNumber(ctx context.Context, user *entities.User, phoneNumber string) error {
+	user.ConfirmPhoneNumber(phoneNumber)
+	if err := s.userRepo.UpdatePhoneNumber(ctx, user); err != nil {
+		return err
+	}
+	s.invalidate(ctx, user.ID)
+
+	s.logger.Info(ctx, "Phone number confirmed", logger.F("user_id", user.ID))
+	return nil
+}
+
+func (s *RecoveryService) invalidate(ctx context.Context, userID int) {
+	if err := s.cache.InvalidateUser(ctx, userID); err != nil {
+		s.logger.Error(ctx, "failed to invalidate user cache", logger.F("userID", userID), logger.F("error", err))
+	}
+}
+
+// hashCode returns the keyed hash stored in place of the code. The user ID
+// is mixed in so equal codes of different users never produce equal hashes.
+func (s *RecoveryService) hashCode(userID int, code string) string {
+	key := sha256.Sum256([]byte("mfa-recovery:" + s.config.EncryptionKey))
+	mac := hmac.New(sha256.New, key[:])
+	mac.Write([]byte(strconv.Itoa(userID) + ":" + normalizeRecoveryCode(code)))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// generateRecoveryCode returns a random code formatted as xxxxx-xxxxx
+func generateRecoveryCode() (string, error) {
+	raw := make([]byte, recoveryCodeLength)
+	if _, err := rand.Read(raw); err != nil {
+		return "", err
+	}
+
+	var code strings.Builder
+	for i, b := range raw {
+		if i == recoveryCodeLength/2 {
+			code.WriteByte('-')
+		}
+		// 256 is a multiple of 32, so every character is equally likely
+		code.WriteByte(recoveryCodeAlphabet[int(b)%len(recoveryCodeAlphabet)])
+	}
+
+	return code.String(), nil
+}
+
+// normalizeRecoveryCode drops separators and case, and maps the characters
+// Crockford's base32 leaves out to the digits they are mistaken for
+func normalizeRecoveryCode(code string) string {
+	replacer := strings.NewReplacer("-", "", " ", "", "o", "0", "i", "1", "l", "1")
+	return replacer.Replace(strings.ToLower(strings.TrimSpace(code)))
+}
@@ -0,0 +1,169 @@
+package services
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	"otp-server/internal/domain/entities"
+	"otp-server/internal/domain/errors"
+	"otp-server/internal/domain/repositories"
+	"otp-server/internal/infrastructure/config"
+	"otp-server/internal/infrastructure/logger"
+)
+
+// RoleService hands out the roles of users and the permissions they grant.
+// Roles are checked on every request, so they are kept in memory and
+// reloaded periodically; changes made on another instance are picked up
+// within ReloadInterval.
+type RoleService struct {
+	roleRepo repositories.RoleRepository
+	config   *config.RBACConfig
+	logger   logger.Logger
+
+	mu          sync.RWMutex
+	roles       map[entities.UserRole]*entities.Role
+	permissions map[string]bool
+
+	stop chan struct{}
+	once sync.Once
+}
+
+// NewRoleService creates a new role service
+func NewRoleService(roleRepo repositories.RoleRepository, cfg *config.RBACConfig, logger logger.Logger) *RoleService {
+	if cfg.ReloadInterval <= 0 {
+		cfg.ReloadInterval = time.Minute
+	}
+
+	return &RoleService{
+		roleRepo:    roleRepo,
+		config:      cfg,
+		logger:      logger,
+		roles:       make(map[entities.UserRole]*entities.Role),
+		permissions: make(map[string]bool),
+		stop:        make(chan struct{}),
+	}
+}
+
+// Start loads the roles and keeps reloading them until Close
+func (s *RoleService) Start(ctx context.Context) error {
+	if err := s.reload(ctx); err != nil {
+		return err
+	}
+
+	go s.reloadRoutine()
+	return nil
+}
+
+// Close stops reloading the roles
+func (s *RoleService) Close() error {
+	s.once.Do(func() { close(s.stop) })
+	return nil
+}
+
+// GetRole returns a role by name
+func (s *RoleService) GetRole(name entities.UserRole) (*entities.Role, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	role, ok := s.roles[name]
+	if !ok {
+		return nil, errors.NewNotFound("role")
+	}
+	return role, nil
+}
+
+// IsRole checks if a role of the name exists
+func (s *RoleService) IsRole(name entities.UserRole) bool {
+	_, err := s.GetRole(name)
+	return err == nil
+}
+
+// ListRoles returns every role with its permissions
+func (s *RoleService) ListRoles(ctx context.Context) ([]*entities.Role, error) {
+	return s.roleRepo.List(ctx)
+}
+
+// ListPermissions returns every permission roles can grant
+func (s *RoleService) ListPermissions(ctx context.Context) ([]*entities.Permission, error) {
+	return s.roleRepo.ListPermissions(ctx)
+}
+
+// SaveRole creates a role or replaces the description and permissions of an
+// existing one. The admin role keeps every permission and cannot be changed,
+// so there is always a role able to undo a mistake.
+func (s *RoleService) SaveRole(ctx context.Context, role *entities.Role) error {
+	if !entities.IsValidRoleName(string(role.Name)) {
+		return errors.NewInvalidInput("name", role.Name)
+	}
+	if role.Name == entities.UserRoleAdmin {
+		return errors.NewInvalidInput("name", "the admin role cannot be changed")
+	}
+
+	role.Description = strings.TrimSpace(role.Description)
+
+	s.mu.RLock()
+	for _, permission := range role.Permissions {
+		if !s.permissions[permission] {
+			s.mu.RUnlock()
+			return errors.NewInvalidInput("permissions", permission)
+		}
+	}
+	s.mu.RUnlock()
+
+	if err := s.roleRepo.Save(ctx, role); err != nil {
+		return err
+	}
+
+	s.logger.Info(ctx, "Role saved", logger.F("role", role.Name), logger.F("permissions", role.Permissions))
+
+	return s.reload(ctx)
+}
+
+func (s *RoleService) reloadRoutine() {
+	ticker := time.NewTicker(s.config.ReloadInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.stop:
+			return
+		case <-ticker.C:
+			ctx := context.Background()
+			if err := s.reload(ctx); err != nil {
+				s.logger.Error(ctx, "Failed to reload roles", logger.F("error", err))
+			}
+		}
+	}
+}
+
+// reload loads every role and permission
+func (s *RoleService) reload(ctx context.Context) error {
+	roles, err := s.roleRepo.List(ctx)
+	if err != nil {
+		return err
+	}
+
+	permissions, err := s.roleRepo.ListPermissions(ctx)
+	if err != nil {
+		return err
+	}
+
+	byName := make(map[entities.UserRole]*entities.Role, len(roles))
+	for _, role := range roles {
+		byName[role.Name] = role
+	}
+
+	known := make(map[string]bool, len(permissions))
+	for _, permission := range permissions {
+		known[permission.Name] = true
+	}
+
+	s.mu.Lock()
+	s.roles = byName
+	s.permissions = known
+	s.mu.Unlock()
+
+	return nil
+}
@@ -0,0 +1,45 @@
+package services
+
+import (
+	"context"
+
+	"otp-server/internal/domain/entities"
+	"otp-server/internal/infrastructure/logger"
+)
+
+// SessionService lets users see where they are logged in and log out other
+// devices
+type SessionService struct {
+	tokenService *TokenService
+	logger       logger.Logger
+}
+
+// NewSessionService creates a new session service
+func NewSessionService(tokenService *TokenService, logger logger.Logger) *SessionService {
+	return &SessionService{
+		tokenService: tokenService,
+		logger:       logger,
+	}
+}
+
+// ListSessions returns the user's active sessions, newest first
+func (s *SessionService) ListSessions(ctx context.Context, user *entities.User) ([]*entities.Session, error) {
+	return s.tokenService.ListSessions(ctx, user.ID)
+}
+
+// RevokeSession logs out one of the user's sessions; its tokens stop working
+// at once
+func (s *SessionService) RevokeSession(ctx context.Context, user *entities.User, sessionID string) error {
+	if err := s.tokenService.RevokeSession(ctx, user.ID, sessionID); err != nil {
+		return err
+	}
+
+	s.logger.Info(ctx, "Session revoked", logger.F("user_id", user.ID), logger.F("session_id", sessionID))
+	return nil
+}
+
+// CurrentSessionID returns the session of an access token already verified
+// by the auth middleware
+func (s *SessionService) CurrentSessionID(tokenString string) string {
+	return s.tokenService.SessionIDFromToken(tokenString)
+}
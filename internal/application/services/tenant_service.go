@@ -0,0 +1,311 @@
+package services
+
+import (
+	"context"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"otp-server/internal/domain/entities"
+	"otp-server/internal/domain/errors"
+	"otp-server/internal/domain/repositories"
+	"otp-server/internal/infrastructure/config"
+	"otp-server/internal/infrastructure/logger"
+)
+
+// TenantService resolves requests to tenants and hands out the settings of
+// each tenant. Tenants are kept in memory and reloaded periodically, so
+// changes made on another instance are picked up within ReloadInterval.
+type TenantService struct {
+	tenantRepo      repositories.TenantRepository
+	config          *config.TenantsConfig
+	logger          logger.Logger
+	templateHandler func(int, *config.TemplateConfig) error
+
+	mu      sync.RWMutex
+	byID    map[int]*entities.Tenant
+	bySlug  map[string]*entities.Tenant
+	byHost  map[string]*entities.Tenant
+	configs map[int]*config.TenantConfig
+	// base holds the settings of tenants without overrides
+	base *config.TenantConfig
+
+	stop chan struct{}
+	once sync.Once
+}
+
+// NewTenantService creates a new tenant service
+func NewTenantService(tenantRepo repositories.TenantRepository, cfg *config.TenantsConfig, logger logger.Logger) *TenantService {
+	if cfg.ReloadInterval <= 0 {
+		cfg.ReloadInterval = time.Minute
+	}
+
+	return &TenantService{
+		tenantRepo: tenantRepo,
+		config:     cfg,
+		logger:     logger,
+		byID:       make(map[int]*entities.Tenant),
+		bySlug:     make(map[string]*entities.Tenant),
+		byHost:     make(map[string]*entities.Tenant),
+		configs:    make(map[int]*config.TenantConfig),
+		base:       config.ForTenant(nil),
+		stop:       make(chan struct{}),
+	}
+}
+
+// SetTemplateHandler sets the handler loading the message templates of
+// every tenant whose settings were loaded or changed
+func (s *TenantService) SetTemplateHandler(handler func(tenantID int, templates *config.TemplateConfig) error) {
+	s.templateHandler = handler
+}
+
+// Start loads the tenants and keeps reloading them until Close
+func (s *TenantService) Start(ctx context.Context) error {
+	if err := s.reload(ctx); err != nil {
+		return err
+	}
+
+	go s.reloadRoutine()
+	return nil
+}
+
+// Close stops reloading the tenants
+func (s *TenantService) Close() error {
+	s.once.Do(func() { close(s.stop) })
+	return nil
+}
+
+// Resolve returns the tenant of a request. The tenant named by slug wins,
+// then the one serving the host; requests naming neither belong to the
+// default tenant.
+func (s *TenantService) Resolve(host, slug string) (*entities.Tenant, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var tenant *entities.Tenant
+	switch {
+	case slug != "":
+		tenant = s.bySlug[strings.ToLower(slug)]
+	case s.byHost[normalizeHost(host)] != nil:
+		tenant = s.byHost[normalizeHost(host)]
+	default:
+		tenant = s.byID[entities.DefaultTenantID]
+	}
+
+	if tenant == nil || !tenant.IsActive {
+		return nil, errors.NewNotFound("tenant")
+	}
+
+	return tenant, nil
+}
+
+// GetTenant returns a tenant by ID
+func (s *TenantService) GetTenant(tenantID int) (*entities.Tenant, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	tenant, ok := s.byID[tenantID]
+	if !ok {
+		return nil, errors.NewNotFound("tenant")
+	}
+	return tenant, nil
+}
+
+// Config returns the settings of a tenant, or those of the environment for
+// a tenant that is not loaded
+func (s *TenantService) Config(tenantID int) *config.TenantConfig {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if cfg, ok := s.configs[tenantID]; ok {
+		return cfg
+	}
+	return s.base
+}
+
+// OTPConfig returns the OTP settings of a tenant
+func (s *TenantService) OTPConfig(tenantID int) *config.OTPConfig {
+	return &s.Config(tenantID).OTP
+}
+
+// CreateTenant creates a tenant with its hosts and setting overrides
+func (s *TenantService) CreateTenant(ctx context.Context, tenant *entities.Tenant) error {
+	if err := validateTenant(tenant); err != nil {
+		return err
+	}
+	if err := s.checkHosts(tenant); err != nil {
+		return err
+	}
+
+	if err := s.tenantRepo.Create(ctx, tenant); err != nil {
+		return err
+	}
+
+	s.logger.Info(ctx, "Tenant created", logger.F("tenant_id", tenant.ID), logger.F("slug", tenant.Slug))
+
+	return s.reload(ctx)
+}
+
+// ListTenants returns every tenant
+func (s *TenantService) ListTenants(ctx context.Context) ([]*entities.Tenant, error) {
+	return s.tenantRepo.List(ctx)
+}
+
+// UpdateTenant replaces the name, hosts, settings and status of a tenant.
+// The slug cannot change, and the default tenant cannot be deactivated.
+func (s *TenantService) UpdateTenant(ctx context.Context, tenant *entities.Tenant) error {
+	existing, err := s.tenantRepo.GetByID(ctx, tenant.ID)
+	if err != nil {
+		return err
+	}
+
+	tenant.Slug = existing.Slug
+	if err := validateTenant(tenant); err != nil {
+		return err
+	}
+	if tenant.ID == entities.DefaultTenantID && !tenant.IsActive {
+		return errors.NewInvalidInput("is_active", "the default tenant cannot be deactivated")
+	}
+	if err := s.checkHosts(tenant); err != nil {
+		return err
+	}
+
+	tenant.CreatedAt = existing.CreatedAt
+	if err := s.tenantRepo.Update(ctx, tenant); err != nil {
+		return err
+	}
+
+	s.logger.Info(ctx, "Tenant updated", logger.F("tenant_id", tenant.ID), logger.F("slug", tenant.Slug))
+
+	return s.reload(ctx)
+}
+
+func (s *TenantService) reloadRoutine() {
+	ticker := time.NewTicker(s.config.ReloadInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.stop:
+			return
+		case <-ticker.C:
+			ctx := context.Background()
+			if err := s.reload(ctx); err != nil {
+				s.logger.Error(ctx, "Failed to reload tenants", logger.F("error", err))
+			}
+		}
+	}
+}
+
+// reload loads every tenant, deriving the settings of those that are new or
+// changed since the last reload
+func (s *TenantService) reload(ctx context.Context) error {
+	tenants, err := s.tenantRepo.List(ctx)
+	if err != nil {
+		return err
+	}
+
+	byID := make(map[int]*entities.Tenant, len(tenants))
+	bySlug := make(map[string]*entities.Tenant, len(tenants))
+	byHost := make(map[string]*entities.Tenant)
+	configs := make(map[int]*config.TenantConfig, len(tenants))
+
+	for _, tenant := range tenants {
+		byID[tenant.ID] = tenant
+		bySlug[tenant.Slug] = tenant
+		for _, host := range tenant.Hosts {
+			byHost[normalizeHost(host)] = tenant
+		}
+
+		s.mu.RLock()
+		previous, known := s.byID[tenant.ID]
+		cfg := s.configs[tenant.ID]
+		s.mu.RUnlock()
+
+		if known && cfg != nil && previous.UpdatedAt.Equal(tenant.UpdatedAt) {
+			configs[tenant.ID] = cfg
+			continue
+		}
+
+		// A tenant whose settings fail to load keeps working on the defaults
+		// rather than taking the other tenants down with it
+		cfg = config.ForTenant(tenant.Settings)
+		if s.templateHandler != nil {
+			if err := s.templateHandler(tenant.ID, &cfg.Templates); err != nil {
+				s.logger.Error(ctx, "Failed to load tenant settings", logger.F("error", err), logger.F("tenant", tenant.Slug))
+			}
+		}
+		configs[tenant.ID] = cfg
+	}
+
+	s.mu.Lock()
+	s.byID = byID
+	s.bySlug = bySlug
+	s.byHost = byHost
+	s.configs = configs
+	s.mu.Unlock()
+
+	return nil
+}
+
+// checkHosts makes sure no other tenant serves the tenant's hosts, since a
+// host resolves to a single tenant
+func (s *TenantService) checkHosts(tenant *entities.Tenant) error {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, host := range tenant.Hosts {
+		if other, ok := s.byHost[host]; ok && other.ID != tenant.ID {
+			return errors.NewAlreadyExists("tenant host")
+		}
+	}
+	return nil
+}
+
+// validateTenant checks the slug, hosts and setting overrides of a tenant
+func validateTenant(tenant *entities.Tenant) error {
+	tenant.Name = strings.TrimSpace(tenant.Name)
+	if tenant.Name == "" {
+		return errors.NewInvalidInput("name", tenant.Name)
+	}
+
+	if !entities.IsValidTenantSlug(tenant.Slug) {
+		return errors.NewInvalidInput("slug", tenant.Slug)
+	}
+
+	if tenant.Hosts == nil {
+		tenant.Hosts = []string{}
+	}
+	for i, host := range tenant.Hosts {
+		tenant.Hosts[i] = normalizeHost(host)
+		if tenant.Hosts[i] == "" {
+			return errors.NewInvalidInput("hosts", host)
+		}
+	}
+
+	if tenant.Settings == nil {
+		tenant.Settings = map[string]string{}
+	}
+	for key, value := range tenant.Settings {
+		if !config.IsTenantSetting(key) {
+			return errors.NewInvalidInput("settings", key)
+		}
+		if key == "OTP_HASH_SECRET" {
+			if err := config.CheckSecret(key, value); err != nil {
+				return errors.NewInvalidInput("settings", err.Error())
+			}
+		}
+	}
+
+	return nil
+}
+
+// normalizeHost strips the port from a host and lowercases it
+func normalizeHost(host string) string {
+	host = strings.ToLower(strings.TrimSpace(host))
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	return host
+}
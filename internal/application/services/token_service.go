@@ -0,0 +1,546 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+
+	"otp-server/internal/domain/entities"
+	"otp-server/internal/domain/errors"
+	"otp-server/internal/domain/repositories"
+	"otp-server/internal/infrastructure/config"
+	"otp-server/internal/infrastructure/keyring"
+	"otp-server/internal/infrastructure/logger"
+	"otp-server/internal/infrastructure/redis"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+)
+
+// TokenService issues short-lived JWT access tokens together with opaque
+// refresh tokens, rotates refresh tokens on every use and revokes tokens
+// before they expire. Every login is a session, whose ID is both the
+// refresh token family and the sid claim of its access tokens.
+type TokenService struct {
+	userRepo    repositories.UserRepository
+	refreshRepo repositories.RefreshTokenRepository
+	sessionRepo repositories.SessionRepository
+	redisClient *redis.Client
+	keyring     *keyring.Keyring
+	config      *config.JWTConfig
+	logger      logger.Logger
+}
+
+// NewTokenService creates a new token service
+func NewTokenService(userRepo repositories.UserRepository, refreshRepo repositories.RefreshTokenRepository, sessionRepo repositories.SessionRepository, redisClient *redis.Client, keyring *keyring.Keyring, cfg *config.JWTConfig, logger logger.Logger) *TokenService {
+	return &TokenService{
+		userRepo:    userRepo,
+		refreshRepo: refreshRepo,
+		sessionRepo: sessionRepo,
+		redisClient: redisClient,
+		keyring:     keyring,
+		config:      cfg,
+		logger:      logger,
+	}
+}
+
+// AccessClaims are the claims of a verified access token
+type AccessClaims struct {
+	jwt.MapClaims
+	UserID      int
+	PhoneNumber string
+	// TenantID is the tenant the user or API client belongs to; tokens
+	// issued before tenants existed belong to the default tenant
+	TenantID int
+	// ID is the token's jti
+	ID string
+	// SessionID is the refresh token family the token was issued with
+	SessionID string
+	// ClientID and Scope are set for tokens issued to an OpenID Connect
+	// client
+	ClientID string
+	Scope    string
+	// APIClientID is set instead of the user for tokens issued to an API
+	// client, whose scopes are in Scope
+	APIClientID string
+	// AuthTime is when the user last authenticated, by logging in or by
+	// stepping up; AuthMethods and ACR say how
+	AuthTime    time.Time
+	AuthMethods []string
+	ACR         string
+	IssuedAt    time.Time
+	ExpiresAt   time.Time
+}
+
+// TokenPair is an access token and the refresh token to renew it with
+type TokenPair struct {
+	SessionID string
+	// Scope is the scope granted to the client, empty for direct logins
+	Scope            string
+	AccessToken      string
+	RefreshToken     string
+	ExpiresIn        time.Duration
+	RefreshExpiresIn time.Duration
+}
+
+// Issue starts a new session for the user, who authenticated on the device
+// with the methods
+func (s *TokenService) Issue(ctx context.Context, user *entities.User, device *entities.DeviceInfo, methods []string) (*TokenPair, error) {
+	return s.start(ctx, user, entities.NewSession(uuid.NewString(), user.ID, device, methods, s.config.RefreshExpiry))
+}
+
+// IssueForClient starts a new session for the user, who authenticated at
+// authTime with the methods, through an OpenID Connect client. Its access
+// tokens carry the client ID and the granted scope, and its refresh tokens
+// only work for that client.
+func (s *TokenService) IssueForClient(ctx context.Context, user *entities.User, device *entities.DeviceInfo, authTime time.Time, methods []string, clientID, scope string) (*TokenPair, error) {
+	session := entities.NewSession(uuid.NewString(), user.ID, device, methods, s.config.RefreshExpiry)
+	session.AuthTime = authTime
+	session.ClientID = clientID
+	session.Scope = scope
+
+	return s.start(ctx, user, session)
+}
+
+// Refresh exchanges a refresh token for a new pair of the same family. A
+// token that was already exchanged must have been copied, so presenting it
+// again revokes the whole family, cutting off whoever holds its successor.
+// Tokens of a session started through a client are only exchanged for that
+// client; clientID is empty for direct logins.
+func (s *TokenService) Refresh(ctx context.Context, refreshToken, clientID string) (*entities.User, *TokenPair, error) {
+	stored, err := s.refreshRepo.GetByHash(ctx, hashRefreshToken(refreshToken))
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return nil, nil, errors.ErrRefreshTokenInvalid
+		}
+		return nil, nil, err
+	}
+
+	if stored.IsRotated() && stored.RevokedAt == nil {
+		s.revokeReusedFamily(ctx, stored)
+		return nil, nil, errors.ErrRefreshTokenInvalid
+	}
+	if !stored.IsUsable() {
+		return nil, nil, errors.ErrRefreshTokenInvalid
+	}
+
+	session, err := s.sessionRepo.GetByID(ctx, stored.FamilyID)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return nil, nil, errors.ErrRefreshTokenInvalid
+		}
+		return nil, nil, err
+	}
+	if session.ClientID != clientID {
+		return nil, nil, errors.ErrRefreshTokenInvalid
+	}
+
+	rotated, err := s.refreshRepo.Rotate(ctx, stored.ID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !rotated {
+		// Another request exchanged the same token first
+		s.revokeReusedFamily(ctx, stored)
+		return nil, nil, errors.ErrRefreshTokenInvalid
+	}
+
+	user, err := s.userRepo.GetByID(ctx, stored.UserID)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return nil, nil, errors.ErrRefreshTokenInvalid
+		}
+		return nil, nil, err
+	}
+	if !user.IsActive() {
+		if err := s.refreshRepo.RevokeFamily(ctx, stored.FamilyID); err != nil {
+			s.logger.Error(ctx, "Failed to revoke refresh token family", logger.F("error", err), logger.F("user_id", user.ID))
+		}
+		return nil, nil, errors.ErrRefreshTokenInvalid
+	}
+
+	pair, err := s.issue(ctx, user, session)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if err := s.sessionRepo.Touch(ctx, stored.FamilyID, time.Now().Add(pair.RefreshExpiresIn)); err != nil {
+		s.logger.Error(ctx, "Failed to touch session", logger.F("error", err), logger.F("session_id", stored.FamilyID))
+	}
+
+	return user, pair, nil
+}
+
+// IssueElevated issues an access token for the session of claims, recording
+// that the user authenticated again just now with the methods. It lives for
+// StepUpExpiry only; no session is started and no refresh token issued, so
+// once it expires the session's regular tokens take over again.
+func (s *TokenService) IssueElevated(user *entities.User, claims *AccessClaims, methods []string) (string, time.Duration, error) {
+	session := &entities.Session{
+		ID:          claims.SessionID,
+		ClientID:    claims.ClientID,
+		Scope:       claims.Scope,
+		AuthTime:    time.Now(),
+		AuthMethods: methods,
+	}
+
+	token, err := s.generateAccessToken(user, session, s.config.StepUpExpiry)
+	if err != nil {
+		return "", 0, fmt.Errorf("failed to generate authentication token")
+	}
+
+	return token, s.config.StepUpExpiry, nil
+}
+
+// IssueClientToken issues an access token to an API client for the scope.
+// There is no session or refresh token; the client asks for a new token
+// once it expires.
+func (s *TokenService) IssueClientToken(client *entities.APIClient, scope string) (string, error) {
+	now := time.Now()
+	token, err := s.keyring.Sign(jwt.MapClaims{
+		"jti":           uuid.NewString(),
+		"api_client_id": client.ClientID,
+		"tenant_id":     client.TenantID,
+		"scope":         scope,
+		"exp":           now.Add(s.config.Expiry).Unix(),
+		"iat":           float64(now.UnixMilli()) / 1000,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to generate authentication token")
+	}
+
+	return token, nil
+}
+
+// LookupRefreshToken returns a refresh token that can still be exchanged
+// and the session it belongs to, without exchanging it
+func (s *TokenService) LookupRefreshToken(ctx context.Context, refreshToken string) (*entities.RefreshToken, *entities.Session, error) {
+	stored, err := s.refreshRepo.GetByHash(ctx, hashRefreshToken(refreshToken))
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return nil, nil, errors.ErrRefreshTokenInvalid
+		}
+		return nil, nil, err
+	}
+	if !stored.IsUsable() {
+		return nil, nil, errors.ErrRefreshTokenInvalid
+	}
+
+	session, err := s.sessionRepo.GetByID(ctx, stored.FamilyID)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return nil, nil, errors.ErrRefreshTokenInvalid
+		}
+		return nil, nil, err
+	}
+	if !session.IsActive() {
+		return nil, nil, errors.ErrRefreshTokenInvalid
+	}
+
+	return stored, session, nil
+}
+
+// ParseAccessToken verifies an access token and returns its claims. Tokens
+// that were revoked one by one, by session or all of a user's at once are
+// rejected; the checks cost a single Redis round trip.
+func (s *TokenService) ParseAccessToken(ctx context.Context, tokenString string) (*AccessClaims, error) {
+	token, err := jwt.Parse(tokenString, s.keyring.Keyfunc)
+
+	if err != nil {
+		return nil, fmt.Errorf("invalid token")
+	}
+
+	mapClaims, ok := token.Claims.(jwt.MapClaims)
+	if !ok || !token.Valid {
+		return nil, fmt.Errorf("invalid token")
+	}
+
+	claims := &AccessClaims{MapClaims: mapClaims}
+	userID, _ := mapClaims["user_id"].(float64)
+	claims.UserID = int(userID)
+	claims.PhoneNumber, _ = mapClaims["phone_number"].(string)
+	claims.TenantID = entities.DefaultTenantID
+	if tenantID, ok := mapClaims["tenant_id"].(float64); ok {
+		claims.TenantID = int(tenantID)
+	}
+	claims.ID, _ = mapClaims["jti"].(string)
+	claims.SessionID, _ = mapClaims["sid"].(string)
+	claims.ClientID, _ = mapClaims["client_id"].(string)
+	claims.Scope, _ = mapClaims["scope"].(string)
+	claims.APIClientID, _ = mapClaims["api_client_id"].(string)
+	claims.AuthTime = authTime(mapClaims)
+	claims.ACR, _ = mapClaims["acr"].(string)
+	if amr, ok := mapClaims["amr"].([]interface{}); ok {
+		for _, method := range amr {
+			if method, ok := method.(string); ok {
+				claims.AuthMethods = append(claims.AuthMethods, method)
+			}
+		}
+	}
+	// Read directly, since the jwt package rounds iat to whole seconds
+	if iat, ok := mapClaims["iat"].(float64); ok {
+		claims.IssuedAt = time.UnixMilli(int64(math.Round(iat * 1000)))
+	}
+	if exp, err := mapClaims.GetExpirationTime(); err == nil && exp != nil {
+		claims.ExpiresAt = exp.Time
+	}
+
+	if claims.ID == "" || claims.IssuedAt.IsZero() {
+		return nil, fmt.Errorf("invalid token")
+	}
+
+	if err := s.checkRevoked(ctx, claims); err != nil {
+		return nil, err
+	}
+
+	return claims, nil
+}
+
+// RevokeAccessToken revokes a single access token and the session it was
+// issued for, leaving the user's other sessions alone
+func (s *TokenService) RevokeAccessToken(ctx context.Context, claims *AccessClaims) error {
+	if ttl := time.Until(claims.ExpiresAt); ttl > 0 {
+		if err := s.redisClient.Set(ctx, s.deniedKey(claims.ID), 1, ttl); err != nil {
+			return err
+		}
+	}
+
+	if claims.SessionID != "" {
+		if err := s.RevokeSession(ctx, claims.UserID, claims.SessionID); err != nil && !errors.IsNotFound(err) {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// ListSessions returns the user's active sessions, newest first
+func (s *TokenService) ListSessions(ctx context.Context, userID int) ([]*entities.Session, error) {
+	return s.sessionRepo.ListActive(ctx, userID)
+}
+
+// RevokeSession revokes one active session of the user together with its
+// refresh tokens. Its access tokens are cut off by their sid, so the marker
+// only has to outlive the longest access token lifetime.
+func (s *TokenService) RevokeSession(ctx context.Context, userID int, sessionID string) error {
+	if err := s.sessionRepo.Revoke(ctx, userID, sessionID); err != nil {
+		return err
+	}
+
+	if err := s.refreshRepo.RevokeFamily(ctx, sessionID); err != nil {
+		return err
+	}
+
+	return s.redisClient.Set(ctx, s.sessionRevokedKey(sessionID), 1, s.maxAccessTokenExpiry())
+}
+
+// SessionIDFromToken returns the sid claim of an access token without
+// verifying it, so it must only be used on tokens verified before
+func (s *TokenService) SessionIDFromToken(tokenString string) string {
+	claims := jwt.MapClaims{}
+	if _, _, err := jwt.NewParser().ParseUnverified(tokenString, claims); err != nil {
+		return ""
+	}
+
+	sessionID, _ := claims["sid"].(string)
+	return sessionID
+}
+
+// AuthTimeFromToken returns the auth_time claim of an access token without
+// verifying it, so it must only be used on tokens verified before. Tokens
+// without one, issued before the claim existed, return the zero time.
+func (s *TokenService) AuthTimeFromToken(tokenString string) time.Time {
+	claims := jwt.MapClaims{}
+	if _, _, err := jwt.NewParser().ParseUnverified(tokenString, claims); err != nil {
+		return time.Time{}
+	}
+
+	return authTime(claims)
+}
+
+// RevokeAll revokes every access and refresh token issued to the user so
+// far. Access tokens are cut off by their issue time, so the marker only
+// has to outlive the longest access token lifetime.
+func (s *TokenService) RevokeAll(ctx context.Context, userID int) error {
+	if err := s.redisClient.Set(ctx, s.revokedBeforeKey(userID), time.Now().UnixMilli(), s.maxAccessTokenExpiry()); err != nil {
+		return err
+	}
+
+	if err := s.sessionRepo.RevokeUser(ctx, userID); err != nil {
+		return err
+	}
+
+	return s.refreshRepo.RevokeUser(ctx, userID)
+}
+
+func (s *TokenService) checkRevoked(ctx context.Context, claims *AccessClaims) error {
+	values, err := s.redisClient.MGet(ctx,
+		s.deniedKey(claims.ID),
+		s.sessionRevokedKey(claims.SessionID),
+		s.revokedBeforeKey(claims.UserID))
+	if err != nil {
+		s.logger.Error(ctx, "Failed to check token revocation", logger.F("error", err), logger.F("user_id", claims.UserID))
+		return fmt.Errorf("token revocation check failed")
+	}
+
+	if values[0] != nil || values[1] != nil {
+		return fmt.Errorf("token revoked")
+	}
+
+	if revokedBefore, ok := values[2].(string); ok {
+		cutoff, err := strconv.ParseInt(revokedBefore, 10, 64)
+		if err == nil && claims.IssuedAt.UnixMilli() <= cutoff {
+			return fmt.Errorf("token revoked")
+		}
+	}
+
+	return nil
+}
+
+// start stores a new session and issues its first tokens. Once the user has
+// more than MaxSessions sessions, the oldest ones are revoked.
+func (s *TokenService) start(ctx context.Context, user *entities.User, session *entities.Session) (*TokenPair, error) {
+	if err := s.sessionRepo.Create(ctx, session); err != nil {
+		return nil, err
+	}
+
+	pair, err := s.issue(ctx, user, session)
+	if err != nil {
+		return nil, err
+	}
+
+	s.evictSessions(ctx, user.ID)
+
+	return pair, nil
+}
+
+func (s *TokenService) issue(ctx context.Context, user *entities.User, session *entities.Session) (*TokenPair, error) {
+	accessToken, err := s.generateAccessToken(user, session, s.config.Expiry)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate authentication token")
+	}
+
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return nil, err
+	}
+	refreshToken := base64.RawURLEncoding.EncodeToString(raw)
+
+	stored := entities.NewRefreshToken(user.ID, session.ID, hashRefreshToken(refreshToken), s.config.RefreshExpiry)
+	if err := s.refreshRepo.Create(ctx, stored); err != nil {
+		return nil, err
+	}
+
+	return &TokenPair{
+		SessionID:        session.ID,
+		Scope:            session.Scope,
+		AccessToken:      accessToken,
+		RefreshToken:     refreshToken,
+		ExpiresIn:        s.config.Expiry,
+		RefreshExpiresIn: s.config.RefreshExpiry,
+	}, nil
+}
+
+func (s *TokenService) generateAccessToken(user *entities.User, session *entities.Session, expiry time.Duration) (string, error) {
+	now := time.Now()
+	claims := jwt.MapClaims{
+		"jti":          uuid.NewString(),
+		"sid":          session.ID,
+		"user_id":      user.ID,
+		"tenant_id":    user.TenantID,
+		"phone_number": user.PhoneNumber,
+		"name":         user.Name,
+		"role":         user.Role,
+		"auth_time":    session.AuthTime.Unix(),
+		"acr":          entities.AuthContextClass(session.AuthMethods),
+		"exp":          now.Add(expiry).Unix(),
+		// Millisecond precision, so revoking all tokens spares the ones
+		// issued right after
+		"iat": float64(now.UnixMilli()) / 1000,
+	}
+	if len(session.AuthMethods) > 0 {
+		claims["amr"] = session.AuthMethods
+	}
+	if session.ClientID != "" {
+		claims["client_id"] = session.ClientID
+		claims["scope"] = session.Scope
+	}
+
+	return s.keyring.Sign(claims)
+}
+
+// evictSessions revokes the user's oldest sessions beyond MaxSessions
+func (s *TokenService) evictSessions(ctx context.Context, userID int) {
+	if s.config.MaxSessions <= 0 {
+		return
+	}
+
+	sessions, err := s.sessionRepo.ListActive(ctx, userID)
+	if err != nil {
+		s.logger.Error(ctx, "Failed to list sessions", logger.F("error", err), logger.F("user_id", userID))
+		return
+	}
+
+	for i := s.config.MaxSessions; i < len(sessions); i++ {
+		if err := s.RevokeSession(ctx, userID, sessions[i].ID); err != nil && !errors.IsNotFound(err) {
+			s.logger.Error(ctx, "Failed to evict session", logger.F("error", err), logger.F("session_id", sessions[i].ID))
+			continue
+		}
+		s.logger.Info(ctx, "Session evicted", logger.F("user_id", userID), logger.F("session_id", sessions[i].ID))
+	}
+}
+
+func (s *TokenService) revokeReusedFamily(ctx context.Context, stored *entities.RefreshToken) {
+	s.logger.Warn(ctx, "Refresh token reused, revoking its family",
+		logger.F("user_id", stored.UserID),
+		logger.F("family_id", stored.FamilyID))
+
+	if err := s.refreshRepo.RevokeFamily(ctx, stored.FamilyID); err != nil {
+		s.logger.Error(ctx, "Failed to revoke refresh token family", logger.F("error", err), logger.F("family_id", stored.FamilyID))
+	}
+	if err := s.RevokeSession(ctx, stored.UserID, stored.FamilyID); err != nil && !errors.IsNotFound(err) {
+		s.logger.Error(ctx, "Failed to revoke session", logger.F("error", err), logger.F("session_id", stored.FamilyID))
+	}
+}
+
+// maxAccessTokenExpiry is the longest lifetime of an access token, which
+// elevated tokens may exceed the regular one with
+func (s *TokenService) maxAccessTokenExpiry() time.Duration {
+	if s.config.StepUpExpiry > s.config.Expiry {
+		return s.config.StepUpExpiry
+	}
+	return s.config.Expiry
+}
+
+func (s *TokenService) deniedKey(tokenID string) string {
+	return "auth:denied:" + tokenID
+}
+
+func (s *TokenService) sessionRevokedKey(sessionID string) string {
+	return "auth:session_revoked:" + sessionID
+}
+
+func (s *TokenService) revokedBeforeKey(userID int) string {
+	return "auth:revoked_before:" + strconv.Itoa(userID)
+}
+
+// authTime reads the auth_time claim
+func authTime(claims jwt.MapClaims) time.Time {
+	if authTime, ok := claims["auth_time"].(float64); ok {
+		return time.Unix(int64(authTime), 0)
+	}
+	return time.Time{}
+}
+
+// hashRefreshToken returns the hash stored in place of the token. Tokens
+// carry 256 random bits, so an unkeyed hash is enough.
+func hashRefreshToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
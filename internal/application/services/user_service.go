@@ -4,31 +4,75 @@ import (
 	"context"
 	"fmt"
 	"otp-server/internal/domain/entities"
+	"otp-server/internal/domain/errors"
 	"otp-server/internal/domain/repositories"
 	logger "otp-server/internal/infrastructure/logger"
 	"otp-server/internal/infrastructure/metrics"
 	"otp-server/internal/infrastructure/redis"
 	"strings"
+	"time"
+	"unicode/utf8"
 )
 
+// Actions of admins on users, reported to the admin action handler
+const (
+	AdminActionActivated         = "activated"
+	AdminActionDeactivated       = "deactivated"
+	AdminActionSuspended         = "suspended"
+	AdminActionBanned            = "banned"
+	AdminActionDeletionScheduled = "deletion_scheduled"
+	AdminActionRoleChanged       = "role_changed"
+	AdminActionLoggedOut         = "logged_out"
+)
+
+// statusActions are the admin actions setting each account status
+var statusActions = map[entities.UserStatus]string{
+	entities.UserStatusActive:          AdminActionActivated,
+	entities.UserStatusDeactivated:     AdminActionDeactivated,
+	entities.UserStatusSuspended:       AdminActionSuspended,
+	entities.UserStatusBanned:          AdminActionBanned,
+	entities.UserStatusPendingDeletion: AdminActionDeletionScheduled,
+}
+
+// maxStatusReasonLength is the length of the longest status reason stored
+const maxStatusReasonLength = 500
+
 type UserService struct {
-	userRepo    repositories.UserRepository
-	logger      logger.Logger
-	redisClient *redis.Client
-	cache       repositories.UserCacheRepository
-	metrics     *metrics.MetricsService
+	userRepo      repositories.UserRepository
+	tokenService  *TokenService
+	roleService   *RoleService
+	logger        logger.Logger
+	redisClient   *redis.Client
+	cache         repositories.UserCacheRepository
+	metrics       *metrics.MetricsService
+	actionHandler func(ctx context.Context, user *entities.User, action, actor string) error
+	phoneHandler  func(ctx context.Context, user *entities.User, oldPhoneNumber string) error
 }
 
-func NewUserService(userRepo repositories.UserRepository, logger logger.Logger, redisClient *redis.Client, cacheRepo repositories.UserCacheRepository, metricsService *metrics.MetricsService) *UserService {
+func NewUserService(userRepo repositories.UserRepository, tokenService *TokenService, roleService *RoleService, logger logger.Logger, redisClient *redis.Client, cacheRepo repositories.UserCacheRepository, metricsService *metrics.MetricsService) *UserService {
 	return &UserService{
-		userRepo:    userRepo,
-		logger:      logger,
-		redisClient: redisClient,
-		cache:       cacheRepo,
-		metrics:     metricsService,
+		userRepo:     userRepo,
+		tokenService: tokenService,
+		roleService:  roleService,
+		logger:       logger,
+		redisClient:  redisClient,
+		cache:        cacheRepo,
+		metrics:      metricsService,
 	}
 }
 
+// SetAdminActionHandler sets the handler notified whenever an admin or an
+// API client, named by actor, changes a user
+func (s *UserService) SetAdminActionHandler(handler func(ctx context.Context, user *entities.User, action, actor string) error) {
+	s.actionHandler = handler
+}
+
+// SetPhoneNumberChangedHandler sets the handler notified with the old number
+// whenever a user changes their phone number
+func (s *UserService) SetPhoneNumberChangedHandler(handler func(ctx context.Context, user *entities.User, oldPhoneNumber string) error) {
+	s.phoneHandler = handler
+}
+
 func (s *UserService) GetUserByID(ctx context.Context, userID int) (*entities.User, error) {
 	user, err := s.cache.GetUserByID(ctx, userID)
 	if err == nil {
@@ -56,7 +100,7 @@ func (s *UserService) IsAdmin(ctx context.Context, userID int) (bool, error) {
 }
 
 func (s *UserService) GetUserByPhoneNumber(ctx context.Context, phoneNumber string) (*entities.User, error) {
-	return s.userRepo.GetByPhoneNumber(ctx, phoneNumber)
+	return s.userRepo.GetByPhoneNumber(ctx, entities.TenantIDFromContext(ctx), phoneNumber)
 }
 
 func (s *UserService) UpdateUserProfile(ctx context.Context, userID int, name string) (*entities.User, error) {
@@ -65,7 +109,7 @@ func (s *UserService) UpdateUserProfile(ctx context.Context, userID int, name st
 		return nil, fmt.Errorf("user not found: %w", err)
 	}
 
-	user.UpdateProfile(name, user.PhoneNumber)
+	user.UpdateProfile(name)
 
 	err = s.userRepo.Update(ctx, user)
 	if err != nil {
@@ -79,6 +123,36 @@ func (s *UserService) UpdateUserProfile(ctx context.Context, userID int, name st
 	return user, nil
 }
 
+// ChangePhoneNumber gives the user a phone number they proved to control.
+// Tokens carry the phone number, so every session of the user is logged out,
+// and the cached lookups by the old number are dropped.
+func (s *UserService) ChangePhoneNumber(ctx context.Context, user *entities.User, phoneNumber string) error {
+	oldPhoneNumber := user.PhoneNumber
+
+	user.ConfirmPhoneNumber(phoneNumber)
+	if err := s.userRepo.UpdatePhoneNumber(ctx, user); err != nil {
+		return err
+	}
+
+	if err := s.cache.InvalidateUser(ctx, user.ID); err != nil {
+		s.logger.Error(ctx, "failed to invalidate user cache", logger.F("userID", user.ID), logger.F("error", err))
+	}
+
+	if err := s.tokenService.RevokeAll(ctx, user.ID); err != nil {
+		s.logger.Error(ctx, "failed to revoke tokens after phone number change", logger.F("userID", user.ID), logger.F("error", err))
+	}
+
+	s.logger.Info(ctx, "Phone number changed", logger.F("user_id", user.ID))
+
+	if s.phoneHandler != nil {
+		if err := s.phoneHandler(ctx, user, oldPhoneNumber); err != nil {
+			s.logger.Error(ctx, "failed to publish phone number change", logger.F("userID", user.ID), logger.F("error", err))
+		}
+	}
+
+	return nil
+}
+
 func (s *UserService) UpdateLastSeen(ctx context.Context, userID int) error {
 	user, err := s.userRepo.GetByID(ctx, userID)
 	if err != nil {
@@ -96,56 +170,189 @@ func (s *UserService) UpdateLastSeen(ctx context.Context, userID int) error {
 }
 
 // GetUsers is a unified method that handles both search and pagination
+// over the users of the context's tenant
 func (s *UserService) GetUsers(ctx context.Context, query string, offset, limit int) ([]*entities.User, int, error) {
-	users, total, err := s.cache.GetUsers(ctx, query, offset, limit)
+	tenantID := entities.TenantIDFromContext(ctx)
+
+	users, total, err := s.cache.GetUsers(ctx, tenantID, query, offset, limit)
 	if err == nil {
 		return users, total, nil
 	}
 
-	users, total, err = s.userRepo.GetUsersWithQuery(ctx, query, offset, limit)
+	users, total, err = s.userRepo.GetUsersWithQuery(ctx, tenantID, query, offset, limit)
 	if err != nil {
 		return nil, 0, err
 	}
 
-	if err := s.cache.SetUsers(ctx, query, offset, limit, users, total); err != nil {
+	if err := s.cache.SetUsers(ctx, tenantID, query, offset, limit, users, total); err != nil {
 		s.logger.Error(ctx, "failed to cache unified users", logger.F("query", query), logger.F("offset", offset), logger.F("limit", limit), logger.F("error", err))
 	}
 
 	return users, total, nil
 }
 
-func (s *UserService) ActivateUser(ctx context.Context, userID int) error {
+// ListUsers returns a page of the context tenant's users matching the
+// filter, bypassing the cache so admins always see the current state
+func (s *UserService) ListUsers(ctx context.Context, filter *repositories.UserFilter, offset, limit int) ([]*entities.User, int, error) {
+	if filter.Role != "" && !s.roleService.IsRole(filter.Role) {
+		return nil, 0, errors.NewInvalidInput("role", filter.Role)
+	}
+	if filter.Status != "" && !filter.Status.IsValid() {
+		return nil, 0, errors.NewInvalidInput("status", filter.Status)
+	}
+
+	return s.userRepo.ListUsers(ctx, entities.TenantIDFromContext(ctx), filter, offset, limit)
+}
+
+// GetUser returns a user of the context tenant
+func (s *UserService) GetUser(ctx context.Context, userID int) (*entities.User, error) {
 	user, err := s.userRepo.GetByID(ctx, userID)
 	if err != nil {
-		return fmt.Errorf("user not found: %w", err)
+		return nil, err
+	}
+	// Admins and API clients only manage the users of their own tenant
+	if user.TenantID != entities.TenantIDFromContext(ctx) {
+		return nil, errors.NewNotFound("user")
 	}
 
-	user.Activate()
+	return user, nil
+}
 
-	err = s.userRepo.Update(ctx, user)
+// ActivateUser lets a suspended, banned or deactivated user log in again
+func (s *UserService) ActivateUser(ctx context.Context, actor string, userID int) (*entities.User, error) {
+	return s.SetStatus(ctx, actor, userID, entities.UserStatusActive, "", nil)
+}
+
+// DeactivateUser blocks a user. Their tokens are rejected from then on and
+// they cannot log in until activated again.
+func (s *UserService) DeactivateUser(ctx context.Context, actor string, userID int) (*entities.User, error) {
+	return s.SetStatus(ctx, actor, userID, entities.UserStatusDeactivated, "", nil)
+}
+
+// SetStatus changes the status of a user and records it in the audit trail.
+// Suspensions need the time they lift at, which must be in the future. Users
+// who are no longer active are logged out everywhere, so their sessions do
+// not come back when they are activated again.
+func (s *UserService) SetStatus(ctx context.Context, actor string, userID int, status entities.UserStatus, reason string, until *time.Time) (*entities.User, error) {
+	if !status.IsValid() {
+		return nil, errors.NewInvalidInput("status", status)
+	}
+
+	reason = strings.TrimSpace(reason)
+	if utf8.RuneCountInString(reason) > maxStatusReasonLength {
+		return nil, errors.NewInvalidInput("reason", fmt.Sprintf("longer than %d characters", maxStatusReasonLength))
+	}
+
+	if status == entities.UserStatusSuspended {
+		if until == nil || !until.After(time.Now()) {
+			return nil, errors.NewInvalidInput("until", "a suspension must end in the future")
+		}
+	} else if until != nil {
+		return nil, errors.NewInvalidInput("until", "only suspensions end by themselves")
+	}
+
+	user, err := s.GetUser(ctx, userID)
 	if err != nil {
-		return fmt.Errorf("failed to activate user: %w", err)
+		return nil, err
 	}
 
-	return nil
+	change := user.SetStatus(status, reason, until, actor)
+
+	if err := s.userRepo.UpdateStatus(ctx, user, change); err != nil {
+		return nil, fmt.Errorf("failed to change user status: %w", err)
+	}
+
+	if !user.IsActive() {
+		if err := s.tokenService.RevokeAll(ctx, user.ID); err != nil {
+			s.logger.Error(ctx, "failed to revoke tokens of inactive user", logger.F("userID", user.ID), logger.F("error", err))
+		}
+	}
+
+	s.logger.Info(ctx, "User status changed",
+		logger.F("user_id", user.ID),
+		logger.F("previous_status", change.PreviousStatus),
+		logger.F("status", status),
+		logger.F("actor", actor))
+
+	s.adminActionDone(ctx, user, statusActions[status], actor)
+	return user, nil
 }
 
-func (s *UserService) DeactivateUser(ctx context.Context, userID int) error {
-	user, err := s.userRepo.GetByID(ctx, userID)
+// GetStatusHistory returns the audit trail of a user's status changes,
+// newest first
+func (s *UserService) GetStatusHistory(ctx context.Context, userID int) ([]*entities.UserStatusChange, error) {
+	if _, err := s.GetUser(ctx, userID); err != nil {
+		return nil, err
+	}
+
+	return s.userRepo.ListStatusChanges(ctx, userID)
+}
+
+// ChangeRole gives a user another role, e.g. promoting them to admin.
+// Permissions are looked up by the stored role, so it applies at once.
+func (s *UserService) ChangeRole(ctx context.Context, actor string, userID int, role entities.UserRole) (*entities.User, error) {
+	if !s.roleService.IsRole(role) {
+		return nil, errors.NewInvalidInput("role", role)
+	}
+
+	user, err := s.GetUser(ctx, userID)
 	if err != nil {
-		return fmt.Errorf("user not found: %w", err)
+		return nil, err
+	}
+	if user.Role == role {
+		return user, nil
 	}
 
-	user.Deactivate()
+	user.UpdateRole(role)
 
 	err = s.userRepo.Update(ctx, user)
 	if err != nil {
-		return fmt.Errorf("failed to deactivate user: %w", err)
+		return nil, fmt.Errorf("failed to change user role: %w", err)
+	}
+
+	s.adminActionDone(ctx, user, AdminActionRoleChanged, actor)
+	return user, nil
+}
+
+// ForceLogout revokes every token of a user, ending all their sessions
+func (s *UserService) ForceLogout(ctx context.Context, actor string, userID int) error {
+	user, err := s.GetUser(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	if err := s.tokenService.RevokeAll(ctx, userID); err != nil {
+		return err
 	}
 
+	s.adminActionDone(ctx, user, AdminActionLoggedOut, actor)
 	return nil
 }
 
+// adminActionDone invalidates the cached user and reports the action
+func (s *UserService) adminActionDone(ctx context.Context, user *entities.User, action, actor string) {
+	if err := s.cache.InvalidateUser(ctx, user.ID); err != nil {
+		s.logger.Error(ctx, "failed to invalidate user cache", logger.F("userID", user.ID), logger.F("error", err))
+	}
+
+	s.logger.Info(ctx, "User changed by admin", logger.F("user_id", user.ID), logger.F("action", action), logger.F("actor", actor))
+
+	if s.actionHandler != nil {
+		if err := s.actionHandler(ctx, user, action, actor); err != nil {
+			s.logger.Error(ctx, "failed to publish admin action", logger.F("userID", user.ID), logger.F("action", action), logger.F("error", err))
+		}
+	}
+}
+
+// checkUserStatus returns an inactive account error for users who may not
+// log in
+func checkUserStatus(user *entities.User) error {
+	if user.IsActive() {
+		return nil
+	}
+	return errors.NewAccountInactive(string(user.CurrentStatus()), user.StatusUntil)
+}
+
 func contains(s, substr string) bool {
 	return len(s) >= len(substr) &&
 		(s == substr ||
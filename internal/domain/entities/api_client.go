@@ -0,0 +1,55 @@
+package entities
+
+import (
+	"strings"
+	"time"
+)
+
+// API client scopes
+const (
+	ScopeOTPSend    = "otp:send"
+	ScopeUsersRead  = "users:read"
+	ScopeUsersAdmin = "users:admin"
+)
+
+// APIClientScopes are the scopes API clients can be granted
+var APIClientScopes = []string{ScopeOTPSend, ScopeUsersRead, ScopeUsersAdmin}
+
+// APIClient represents a backend service calling the API on its own behalf
+// rather than for a user. The secret is both its API key and its client
+// secret for the client credentials grant; only a hash of it is kept.
+type APIClient struct {
+	ID         int      `json:"id" db:"id"`
+	TenantID   int      `json:"tenant_id" db:"tenant_id"`
+	ClientID   string   `json:"client_id" db:"client_id"`
+	SecretHash string   `json:"-" db:"secret_hash"`
+	Name       string   `json:"name" db:"name"`
+	Scopes     []string `json:"scopes" db:"scopes"`
+	// RateLimit is the number of requests allowed per rate limit window,
+	// zero for the default
+	RateLimit int       `json:"rate_limit" db:"rate_limit"`
+	IsActive  bool      `json:"is_active" db:"is_active"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// HasScopes checks if the client was granted every scope. users:admin
+// includes users:read.
+func (c *APIClient) HasScopes(scopes ...string) bool {
+	return ScopeIncludes(strings.Join(c.Scopes, " "), scopes...)
+}
+
+// ScopeIncludes checks if the space-separated list of API client scopes
+// includes every scope, counting users:read as part of users:admin
+func ScopeIncludes(scope string, wanted ...string) bool {
+	for _, w := range wanted {
+		if HasScope(scope, w) {
+			continue
+		}
+		if w == ScopeUsersRead && HasScope(scope, ScopeUsersAdmin) {
+			continue
+		}
+		return false
+	}
+	return true
+}
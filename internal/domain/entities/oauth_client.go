@@ -0,0 +1,66 @@
+package entities
+
+import (
+	"strings"
+	"time"
+)
+
+// OpenID Connect scopes
+const (
+	ScopeOpenID  = "openid"
+	ScopePhone   = "phone"
+	ScopeProfile = "profile"
+)
+
+// OAuthClient represents an application logging users in through the
+// OpenID Connect provider. Only a hash of the client secret is kept; public
+// clients have no secret and must use PKCE.
+type OAuthClient struct {
+	ID           int       `json:"id" db:"id"`
+	ClientID     string    `json:"client_id" db:"client_id"`
+	SecretHash   string    `json:"-" db:"client_secret_hash"`
+	Name         string    `json:"name" db:"name"`
+	RedirectURIs []string  `json:"redirect_uris" db:"redirect_uris"`
+	Scopes       []string  `json:"scopes" db:"scopes"`
+	IsActive     bool      `json:"is_active" db:"is_active"`
+	CreatedAt    time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// IsPublic checks if the client has no secret, like a single-page or
+// mobile app
+func (c *OAuthClient) IsPublic() bool {
+	return c.SecretHash == ""
+}
+
+// HasRedirectURI checks if the redirect URI is registered for the client.
+// Only exact matches count.
+func (c *OAuthClient) HasRedirectURI(uri string) bool {
+	for _, registered := range c.RedirectURIs {
+		if registered == uri {
+			return true
+		}
+	}
+	return false
+}
+
+// AllowsScope checks if the client may request every scope of the
+// space-separated list
+func (c *OAuthClient) AllowsScope(scope string) bool {
+	for _, requested := range strings.Fields(scope) {
+		if !HasScope(strings.Join(c.Scopes, " "), requested) {
+			return false
+		}
+	}
+	return true
+}
+
+// HasScope checks if the space-separated scope list contains the scope
+func HasScope(scope, wanted string) bool {
+	for _, s := range strings.Fields(scope) {
+		if s == wanted {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,134 @@
+package entities
+
+import (
+	"time"
+)
+
+// OTPChallengeStatus represents the possible states of an OTP challenge
+type OTPChallengeStatus string
+
+const (
+	OTPChallengeStatusPending  OTPChallengeStatus = "pending"
+	OTPChallengeStatusVerified OTPChallengeStatus = "verified"
+	OTPChallengeStatusFailed   OTPChallengeStatus = "failed"
+	OTPChallengeStatusExpired  OTPChallengeStatus = "expired"
+)
+
+// OTPPurpose represents what a verified OTP challenge may be used for
+type OTPPurpose string
+
+const (
+	OTPPurposeLogin           OTPPurpose = "login"
+	OTPPurposePhoneChange     OTPPurpose = "phone_change"
+	OTPPurposeAccountDeletion OTPPurpose = "account_deletion"
+	OTPPurposeTransaction     OTPPurpose = "transaction"
+	// OTPPurposeStepUp codes prove a logged in user authenticated recently
+	OTPPurposeStepUp OTPPurpose = "step_up"
+)
+
+// IsValid checks if the purpose is one of the known purposes
+func (p OTPPurpose) IsValid() bool {
+	switch p {
+	case OTPPurposeLogin, OTPPurposePhoneChange, OTPPurposeAccountDeletion, OTPPurposeTransaction, OTPPurposeStepUp:
+		return true
+	}
+	return false
+}
+
+// OTPChannel represents the channel an OTP code is delivered over
+type OTPChannel string
+
+const (
+	OTPChannelSMS      OTPChannel = "sms"
+	OTPChannelVoice    OTPChannel = "voice"
+	OTPChannelWhatsApp OTPChannel = "whatsapp"
+	OTPChannelEmail    OTPChannel = "email"
+)
+
+// IsValid checks if the channel is one of the known channels
+func (c OTPChannel) IsValid() bool {
+	switch c {
+	case OTPChannelSMS, OTPChannelVoice, OTPChannelWhatsApp, OTPChannelEmail:
+		return true
+	}
+	return false
+}
+
+// OTPRequest describes a new OTP challenge to start
+type OTPRequest struct {
+	PhoneNumber string
+	Purpose     OTPPurpose // defaults to login
+	Channel     OTPChannel // preferred channel, optional
+	Email       string     // recipient for the email channel, optional
+	Locales     []string   // preferred message locales, most preferred first
+	// Transaction is the operation a transaction code signs; required for,
+	// and only allowed with, the transaction purpose
+	Transaction *TransactionPayload
+	// UserID binds the challenge to an account when the code goes to a
+	// number the account does not own yet, e.g. a new phone number; optional
+	UserID int
+}
+
+// OTPChallenge represents a single OTP verification flow.
+// Each challenge has its own code, so one phone number can run several
+// independent flows at the same time.
+type OTPChallenge struct {
+	ID             string             `json:"id"`
+	TenantID       int                `json:"tenant_id"`
+	PhoneNumber    string             `json:"phone_number"`
+	Purpose        OTPPurpose         `json:"purpose"`
+	Channel        OTPChannel         `json:"channel"`
+	Channels       []OTPChannel       `json:"channels"`
+	Email          string             `json:"email,omitempty"`
+	Locale         string             `json:"locale"`
+	Status         OTPChallengeStatus `json:"status"`
+	Attempts       int                `json:"attempts"`
+	Resends        int                `json:"resends"`
+	Provider       string             `json:"provider,omitempty"`
+	MessageID      string             `json:"message_id,omitempty"`
+	DeliveryStatus DeliveryStatus     `json:"delivery_status,omitempty"`
+	Operation      string             `json:"operation,omitempty"`
+	PayloadHash    string             `json:"payload_hash,omitempty"`
+	Summary        string             `json:"summary,omitempty"`
+	UserID         int                `json:"user_id,omitempty"`
+	CreatedAt      time.Time          `json:"created_at"`
+	LastSentAt     time.Time          `json:"last_sent_at"`
+	ExpiresAt      time.Time          `json:"expires_at"`
+}
+
+// NewOTPChallenge creates a new pending OTP challenge that is delivered over
+// the given channels in order
+func NewOTPChallenge(id, phoneNumber string, purpose OTPPurpose, channels []OTPChannel, expiry time.Duration) *OTPChallenge {
+	now := time.Now()
+	return &OTPChallenge{
+		ID:          id,
+		PhoneNumber: phoneNumber,
+		Purpose:     purpose,
+		Channel:     channels[0],
+		Channels:    channels,
+		Status:      OTPChallengeStatusPending,
+		CreatedAt:   now,
+		LastSentAt:  now,
+		ExpiresAt:   now.Add(expiry),
+	}
+}
+
+// IsPending checks if the challenge can still be verified
+func (c *OTPChallenge) IsPending() bool {
+	return c.Status == OTPChallengeStatusPending
+}
+
+// NextChannel returns the channel after the current one in the fallback chain
+func (c *OTPChallenge) NextChannel() (OTPChannel, bool) {
+	for i, channel := range c.Channels {
+		if channel == c.Channel && i+1 < len(c.Channels) {
+			return c.Channels[i+1], true
+		}
+	}
+	return "", false
+}
+
+// IsVerified checks if the challenge has been verified
+func (c *OTPChallenge) IsVerified() bool {
+	return c.Status == OTPChallengeStatusVerified
+}
@@ -0,0 +1,79 @@
+package entities
+
+import (
+	"time"
+)
+
+// DeliveryErrorClass classifies why an OTP delivery failed
+type DeliveryErrorClass string
+
+const (
+	DeliveryErrorNone          DeliveryErrorClass = ""
+	DeliveryErrorTemporary     DeliveryErrorClass = "temporary"
+	DeliveryErrorPermanent     DeliveryErrorClass = "permanent"
+	DeliveryErrorTimeout       DeliveryErrorClass = "timeout"
+	DeliveryErrorCircuitOpen   DeliveryErrorClass = "circuit_open"
+	DeliveryErrorConfiguration DeliveryErrorClass = "configuration"
+)
+
+// DeliveryStatus represents how far an OTP message got towards the handset
+type DeliveryStatus string
+
+const (
+	DeliveryStatusQueued    DeliveryStatus = "queued"
+	DeliveryStatusSent      DeliveryStatus = "sent"
+	DeliveryStatusDelivered DeliveryStatus = "delivered"
+	DeliveryStatusFailed    DeliveryStatus = "failed"
+	DeliveryStatusExpired   DeliveryStatus = "expired"
+)
+
+// IsFinal checks if no further status can follow
+func (s DeliveryStatus) IsFinal() bool {
+	return s == DeliveryStatusDelivered || s == DeliveryStatusFailed || s == DeliveryStatusExpired
+}
+
+// Precedes checks if the status comes before next in a message's lifecycle.
+// Receipts can arrive out of order; a status never moves backwards.
+func (s DeliveryStatus) Precedes(next DeliveryStatus) bool {
+	rank := func(status DeliveryStatus) int {
+		switch {
+		case status.IsFinal():
+			return 3
+		case status == DeliveryStatusSent:
+			return 2
+		case status == DeliveryStatusQueued:
+			return 1
+		}
+		return 0
+	}
+	return rank(s) < rank(next)
+}
+
+// DeliveryEvent represents one status transition of a delivered OTP message,
+// either reported by the provider when sending or by a delivery receipt
+type DeliveryEvent struct {
+	Provider  string         `json:"provider"`
+	MessageID string         `json:"message_id"`
+	Channel   OTPChannel     `json:"channel,omitempty"`
+	Status    DeliveryStatus `json:"status"`
+	ErrorCode string         `json:"error_code,omitempty"`
+	At        time.Time      `json:"at"`
+}
+
+// DeliveryResult represents the outcome of handing an OTP message to a provider
+type DeliveryResult struct {
+	Provider   string             `json:"provider"`
+	Channel    OTPChannel         `json:"channel"`
+	MessageID  string             `json:"message_id,omitempty"`
+	Cost       float64            `json:"cost,omitempty"`
+	Currency   string             `json:"currency,omitempty"`
+	Attempts   int                `json:"attempts"`
+	Status     DeliveryStatus     `json:"status,omitempty"`
+	ErrorClass DeliveryErrorClass `json:"error_class,omitempty"`
+	SentAt     time.Time          `json:"sent_at"`
+}
+
+// IsAccepted checks if the provider accepted the message
+func (r *DeliveryResult) IsAccepted() bool {
+	return r.ErrorClass == DeliveryErrorNone
+}
@@ -0,0 +1,89 @@
+package entities
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+var (
+	operationPattern = regexp.MustCompile(`^[a-z][a-z0-9_]{0,31}$`)
+	amountPattern    = regexp.MustCompile(`^[0-9]{1,18}(\.[0-9]{1,8})?$`)
+	currencyPattern  = regexp.MustCompile(`^[A-Z]{3}$`)
+)
+
+const maxTransactionFieldLength = 64
+
+// TransactionPayload describes the operation a transaction OTP signs.
+// The code is bound to the payload's canonical hash (dynamic linking), so it
+// only confirms this exact operation.
+type TransactionPayload struct {
+	Operation string `json:"operation"`
+	Amount    string `json:"amount"`
+	Currency  string `json:"currency"`
+	Recipient string `json:"recipient"`
+	Reference string `json:"reference,omitempty"`
+}
+
+// Canonical validates the payload and returns it in canonical form: fields
+// trimmed, currency upper-cased and the amount without redundant zeros, so
+// "0100.50" and "100.5" sign the same operation
+func (p *TransactionPayload) Canonical() (*TransactionPayload, error) {
+	c := &TransactionPayload{
+		Operation: strings.ToLower(strings.TrimSpace(p.Operation)),
+		Amount:    canonicalAmount(strings.TrimSpace(p.Amount)),
+		Currency:  strings.ToUpper(strings.TrimSpace(p.Currency)),
+		Recipient: strings.Join(strings.Fields(p.Recipient), " "),
+		Reference: strings.Join(strings.Fields(p.Reference), " "),
+	}
+
+	switch {
+	case !operationPattern.MatchString(c.Operation):
+		return nil, fmt.Errorf("operation must be a lowercase identifier")
+	case !amountPattern.MatchString(strings.TrimSpace(p.Amount)):
+		return nil, fmt.Errorf("amount must be a non-negative decimal number")
+	case !currencyPattern.MatchString(c.Currency):
+		return nil, fmt.Errorf("currency must be an ISO 4217 code")
+	case c.Recipient == "" || len(c.Recipient) > maxTransactionFieldLength:
+		return nil, fmt.Errorf("recipient is required and at most %d characters", maxTransactionFieldLength)
+	case len(c.Reference) > maxTransactionFieldLength:
+		return nil, fmt.Errorf("reference must be at most %d characters", maxTransactionFieldLength)
+	}
+
+	return c, nil
+}
+
+// Hash returns the hex SHA-256 of the payload's JSON encoding. Call it on a
+// canonical payload.
+func (p *TransactionPayload) Hash() string {
+	data, _ := json.Marshal(p)
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// Summary returns the human-readable description put into the OTP message
+func (p *TransactionPayload) Summary() string {
+	summary := fmt.Sprintf("%s %s %s to %s", strings.ReplaceAll(p.Operation, "_", " "), p.Amount, p.Currency, p.Recipient)
+	if p.Reference != "" {
+		summary += fmt.Sprintf(" (ref %s)", p.Reference)
+	}
+	return summary
+}
+
+// canonicalAmount strips leading zeros of the integer part and trailing
+// zeros of the fraction
+func canonicalAmount(amount string) string {
+	whole, fraction, _ := strings.Cut(amount, ".")
+	whole = strings.TrimLeft(whole, "0")
+	if whole == "" {
+		whole = "0"
+	}
+	fraction = strings.TrimRight(fraction, "0")
+	if fraction == "" {
+		return whole
+	}
+	return whole + "." + fraction
+}
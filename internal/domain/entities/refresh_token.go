@@ -0,0 +1,40 @@
+package entities
+
+import (
+	"time"
+)
+
+// RefreshToken represents a stored refresh token. Only a hash of the opaque
+// token handed to the client is kept.
+type RefreshToken struct {
+	ID        int        `json:"id" db:"id"`
+	UserID    int        `json:"user_id" db:"user_id"`
+	FamilyID  string     `json:"family_id" db:"family_id"`
+	TokenHash string     `json:"-" db:"token_hash"`
+	ExpiresAt time.Time  `json:"expires_at" db:"expires_at"`
+	RotatedAt *time.Time `json:"rotated_at,omitempty" db:"rotated_at"`
+	RevokedAt *time.Time `json:"revoked_at,omitempty" db:"revoked_at"`
+	CreatedAt time.Time  `json:"created_at" db:"created_at"`
+}
+
+// NewRefreshToken creates a new refresh token of the given family
+func NewRefreshToken(userID int, familyID, tokenHash string, expiry time.Duration) *RefreshToken {
+	now := time.Now()
+	return &RefreshToken{
+		UserID:    userID,
+		FamilyID:  familyID,
+		TokenHash: tokenHash,
+		ExpiresAt: now.Add(expiry),
+		CreatedAt: now,
+	}
+}
+
+// IsRotated checks if the token was already exchanged for its successor
+func (t *RefreshToken) IsRotated() bool {
+	return t.RotatedAt != nil
+}
+
+// IsUsable checks if the token can still be exchanged
+func (t *RefreshToken) IsUsable() bool {
+	return t.RotatedAt == nil && t.RevokedAt == nil && time.Now().Before(t.ExpiresAt)
+}
@@ -0,0 +1,63 @@
+package entities
+
+import (
+	"regexp"
+	"time"
+)
+
+// Permissions checked by the API routes, granted to users through their role
+const (
+	PermissionOTPSend      = "otp:send"
+	PermissionUsersRead    = "users:read"
+	PermissionUsersWrite   = "users:write"
+	PermissionRolesRead    = "roles:read"
+	PermissionRolesWrite   = "roles:write"
+	PermissionClientsRead  = "clients:read"
+	PermissionClientsWrite = "clients:write"
+	PermissionTenantsRead  = "tenants:read"
+	PermissionTenantsWrite = "tenants:write"
+	PermissionLogsRead     = "logs:read"
+)
+
+var roleNamePattern = regexp.MustCompile(`^[a-z][a-z0-9_-]{0,49}$`)
+
+// Permission represents an action on the API that roles can grant
+type Permission struct {
+	ID          int    `json:"id" db:"id"`
+	Name        string `json:"name" db:"name"`
+	Description string `json:"description" db:"description"`
+}
+
+// Role represents a named set of permissions. Every user holds exactly one
+// role; roles are shared by all tenants.
+type Role struct {
+	ID          int       `json:"id" db:"id"`
+	Name        UserRole  `json:"name" db:"name"`
+	Description string    `json:"description" db:"description"`
+	Permissions []string  `json:"permissions" db:"permissions"`
+	CreatedAt   time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// HasPermission checks if the role grants every permission
+func (r *Role) HasPermission(permissions ...string) bool {
+	for _, permission := range permissions {
+		granted := false
+		for _, p := range r.Permissions {
+			if p == permission {
+				granted = true
+				break
+			}
+		}
+		if !granted {
+			return false
+		}
+	}
+	return true
+}
+
+// IsValidRoleName checks if the name can identify a role: lowercase
+// letters, digits, dashes and underscores, starting with a letter
+func IsValidRoleName(name string) bool {
+	return roleNamePattern.MatchString(name)
+}
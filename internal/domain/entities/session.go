@@ -0,0 +1,109 @@
+package entities
+
+import (
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	maxDeviceNameLength = 64
+	maxUserAgentLength  = 512
+)
+
+// Authentication methods, listed in the amr claim of tokens
+const (
+	// AuthMethodOTP is a code sent to the user's phone number
+	AuthMethodOTP = "otp"
+	// AuthMethodTOTP is a code of an authenticator app
+	AuthMethodTOTP = "totp"
+	// AuthMethodRecovery is a recovery code
+	AuthMethodRecovery = "recovery"
+	// AuthMethodMFA marks a login with more than one factor
+	AuthMethodMFA = "mfa"
+)
+
+// Authentication context classes, carried in the acr claim of tokens
+const (
+	ACRSingleFactor = "1"
+	ACRMultiFactor  = "2"
+)
+
+// AuthContextClass returns the acr of an authentication with the methods
+func AuthContextClass(methods []string) string {
+	for _, method := range methods {
+		if method == AuthMethodMFA {
+			return ACRMultiFactor
+		}
+	}
+	return ACRSingleFactor
+}
+
+// DeviceInfo describes the client a login comes from
+type DeviceInfo struct {
+	Name      string
+	UserAgent string
+	IPAddress string
+}
+
+// Session represents a login of a user on one device. Its ID is shared by
+// the refresh token family and the sid claim of the access tokens issued
+// for it.
+type Session struct {
+	ID         string     `json:"id" db:"id"`
+	UserID     int        `json:"user_id" db:"user_id"`
+	DeviceName string     `json:"device_name" db:"device_name"`
+	UserAgent  string     `json:"user_agent" db:"user_agent"`
+	IPAddress  string     `json:"ip_address" db:"ip_address"`
+	CreatedAt  time.Time  `json:"created_at" db:"created_at"`
+	LastUsedAt time.Time  `json:"last_used_at" db:"last_used_at"`
+	ExpiresAt  time.Time  `json:"expires_at" db:"expires_at"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty" db:"revoked_at"`
+	// ClientID and Scope are set for sessions started through an OpenID
+	// Connect client
+	ClientID string `json:"client_id,omitempty" db:"client_id"`
+	Scope    string `json:"scope,omitempty" db:"scope"`
+	// AuthTime is when the user authenticated to start the session, and
+	// AuthMethods how
+	AuthTime    time.Time `json:"auth_time" db:"auth_time"`
+	AuthMethods []string  `json:"auth_methods" db:"auth_methods"`
+}
+
+// NewSession creates a new session for the user, who just authenticated on
+// the device with the methods
+func NewSession(id string, userID int, device *DeviceInfo, methods []string, expiry time.Duration) *Session {
+	now := time.Now()
+	session := &Session{
+		ID:          id,
+		UserID:      userID,
+		CreatedAt:   now,
+		LastUsedAt:  now,
+		ExpiresAt:   now.Add(expiry),
+		AuthTime:    now,
+		AuthMethods: methods,
+	}
+
+	if device != nil {
+		session.DeviceName = truncate(device.Name, maxDeviceNameLength)
+		session.UserAgent = truncate(device.UserAgent, maxUserAgentLength)
+		session.IPAddress = device.IPAddress
+	}
+
+	return session
+}
+
+// IsActive checks if the session can still be used
+func (s *Session) IsActive() bool {
+	return s.RevokedAt == nil && time.Now().Before(s.ExpiresAt)
+}
+
+// truncate cuts the value to at most length bytes without splitting a
+// UTF-8 character
+func truncate(value string, length int) string {
+	if len(value) <= length {
+		return value
+	}
+	for length > 0 && !utf8.RuneStart(value[length]) {
+		length--
+	}
+	return value[:length]
+}
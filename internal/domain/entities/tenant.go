@@ -0,0 +1,57 @@
+package entities
+
+import (
+	"context"
+	"regexp"
+	"time"
+)
+
+// DefaultTenantID is the tenant of requests that name no tenant, and of
+// every user and API client created before tenants existed
+const DefaultTenantID = 1
+
+var tenantSlugPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9-]{0,63}$`)
+
+// Tenant represents a brand served by the deployment. Users, OTP settings
+// and rate limits are kept apart per tenant, so one phone number can hold a
+// separate account with every tenant.
+type Tenant struct {
+	ID   int    `json:"id" db:"id"`
+	Slug string `json:"slug" db:"slug"`
+	Name string `json:"name" db:"name"`
+	// Hosts are the request hosts resolved to the tenant
+	Hosts []string `json:"hosts" db:"hosts"`
+	// Settings override the OTP, message template and rate limiting
+	// settings of the server, keyed by environment variable name, e.g.
+	// OTP_EXPIRY or RATE_LIMIT_OTP_REQUESTS
+	Settings  map[string]string `json:"settings" db:"settings"`
+	IsActive  bool              `json:"is_active" db:"is_active"`
+	CreatedAt time.Time         `json:"created_at" db:"created_at"`
+	UpdatedAt time.Time         `json:"updated_at" db:"updated_at"`
+}
+
+// IsValidTenantSlug checks if the slug can identify a tenant: lowercase
+// letters, digits and dashes
+func IsValidTenantSlug(slug string) bool {
+	return tenantSlugPattern.MatchString(slug)
+}
+
+// WithTenant returns a copy of ctx carrying the tenant
+func WithTenant(ctx context.Context, tenant *Tenant) context.Context {
+	return context.WithValue(ctx, "tenant", tenant)
+}
+
+// TenantFromContext returns the tenant the request was resolved to
+func TenantFromContext(ctx context.Context) (*Tenant, bool) {
+	tenant, ok := ctx.Value("tenant").(*Tenant)
+	return tenant, ok && tenant != nil
+}
+
+// TenantIDFromContext returns the ID of the tenant the request was resolved
+// to, or the default tenant
+func TenantIDFromContext(ctx context.Context) int {
+	if tenant, ok := TenantFromContext(ctx); ok {
+		return tenant.ID
+	}
+	return DefaultTenantID
+}
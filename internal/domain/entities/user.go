@@ -4,46 +4,94 @@ import (
 	"time"
 )
 
-// UserRole represents the possible user roles
+// UserRole names the role of a user, which grants its permissions
 type UserRole string
 
+// Default roles, seeded by the migrations
 const (
-	UserRoleUser  UserRole = "user"
-	UserRoleAdmin UserRole = "admin"
+	UserRoleUser    UserRole = "user"
+	UserRoleAdmin   UserRole = "admin"
+	UserRoleSupport UserRole = "support"
+	UserRoleAuditor UserRole = "auditor"
 )
 
+// UserStatus represents the status of an account; only active users can
+// log in or use their tokens
+type UserStatus string
+
+const (
+	UserStatusActive UserStatus = "active"
+	// UserStatusSuspended blocks the account until StatusUntil
+	UserStatusSuspended       UserStatus = "suspended"
+	UserStatusBanned          UserStatus = "banned"
+	UserStatusPendingDeletion UserStatus = "pending_deletion"
+	UserStatusDeactivated     UserStatus = "deactivated"
+)
+
+// IsValid checks if the status is a known one
+func (s UserStatus) IsValid() bool {
+	switch s {
+	case UserStatusActive, UserStatusSuspended, UserStatusBanned, UserStatusPendingDeletion, UserStatusDeactivated:
+		return true
+	}
+	return false
+}
+
 // User represents a user in the system
 type User struct {
-	ID          int       `json:"id" db:"id"`
-	PhoneNumber string    `json:"phone_number" db:"phone_number"`
-	Name        string    `json:"name" db:"name"`
-	Role        UserRole  `json:"role" db:"role"`
-	IsActive    bool      `json:"is_active" db:"is_active"`
-	CreatedAt   time.Time `json:"created_at" db:"created_at"`
-	UpdatedAt   time.Time `json:"updated_at" db:"updated_at"`
+	ID          int        `json:"id" db:"id"`
+	TenantID    int        `json:"tenant_id" db:"tenant_id"`
+	PhoneNumber string     `json:"phone_number" db:"phone_number"`
+	Name        string     `json:"name" db:"name"`
+	Role        UserRole   `json:"role" db:"role"`
+	Status      UserStatus `json:"status" db:"status"`
+	// StatusReason, StatusChangedBy and StatusChangedAt tell why, by whom
+	// and when the status was set; StatusUntil is when a suspension lifts
+	StatusReason              string     `json:"status_reason,omitempty" db:"status_reason"`
+	StatusUntil               *time.Time `json:"status_until,omitempty" db:"status_until"`
+	StatusChangedBy           string     `json:"status_changed_by,omitempty" db:"status_changed_by"`
+	StatusChangedAt           *time.Time `json:"status_changed_at,omitempty" db:"status_changed_at"`
+	PhoneConfirmationRequired bool       `json:"phone_confirmation_required" db:"phone_confirmation_required"`
+	CreatedAt                 time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt                 time.Time  `json:"updated_at" db:"updated_at"`
+}
+
+// UserStatusChange records a change of an account's status, for the audit
+// trail
+type UserStatusChange struct {
+	ID             int64      `json:"id" db:"id"`
+	UserID         int        `json:"user_id" db:"user_id"`
+	PreviousStatus UserStatus `json:"previous_status" db:"previous_status"`
+	Status         UserStatus `json:"status" db:"status"`
+	Reason         string     `json:"reason" db:"reason"`
+	Until          *time.Time `json:"until,omitempty" db:"until"`
+	Actor          string     `json:"actor" db:"actor"`
+	CreatedAt      time.Time  `json:"created_at" db:"created_at"`
 }
 
-// NewUser creates a new user instance
-func NewUser(phoneNumber, name string) *User {
+// NewUser creates a new user instance of the tenant
+func NewUser(tenantID int, phoneNumber, name string) *User {
 	now := time.Now()
 	return &User{
+		TenantID:    tenantID,
 		PhoneNumber: phoneNumber,
 		Name:        name,
 		Role:        UserRoleUser,
-		IsActive:    true,
+		Status:      UserStatusActive,
 		CreatedAt:   now,
 		UpdatedAt:   now,
 	}
 }
 
-// NewAdminUser creates a new admin user instance
-func NewAdminUser(phoneNumber, name string) *User {
+// NewAdminUser creates a new admin user instance of the tenant
+func NewAdminUser(tenantID int, phoneNumber, name string) *User {
 	now := time.Now()
 	return &User{
+		TenantID:    tenantID,
 		PhoneNumber: phoneNumber,
 		Name:        name,
 		Role:        UserRoleAdmin,
-		IsActive:    true,
+		Status:      UserStatusActive,
 		CreatedAt:   now,
 		UpdatedAt:   now,
 	}
@@ -54,10 +102,11 @@ func (u *User) UpdateLastSeen() {
 	u.UpdatedAt = time.Now()
 }
 
-// UpdateProfile updates the user's profile information
-func (u *User) UpdateProfile(name, phoneNumber string) {
+// UpdateProfile updates the user's profile information. The phone number
+// only changes once the user proved to control the new one, see
+// ConfirmPhoneNumber.
+func (u *User) UpdateProfile(name string) {
 	u.Name = name
-	u.PhoneNumber = phoneNumber
 	u.UpdatedAt = time.Now()
 }
 
@@ -77,14 +126,57 @@ func (u *User) IsUser() bool {
 	return u.Role == UserRoleUser
 }
 
-// Deactivate deactivates the user
-func (u *User) Deactivate() {
-	u.IsActive = false
+// CurrentStatus returns the status of the account now; a suspension whose
+// time has passed has lifted by itself
+func (u *User) CurrentStatus() UserStatus {
+	if u.Status == UserStatusSuspended && u.StatusUntil != nil && !time.Now().Before(*u.StatusUntil) {
+		return UserStatusActive
+	}
+	return u.Status
+}
+
+// IsActive checks if the user may log in and use their tokens
+func (u *User) IsActive() bool {
+	return u.CurrentStatus() == UserStatusActive
+}
+
+// SetStatus changes the status of the account and returns the record of the
+// change. until only applies to suspensions.
+func (u *User) SetStatus(status UserStatus, reason string, until *time.Time, actor string) *UserStatusChange {
+	now := time.Now()
+	change := &UserStatusChange{
+		UserID:         u.ID,
+		PreviousStatus: u.CurrentStatus(),
+		Status:         status,
+		Reason:         reason,
+		Actor:          actor,
+		CreatedAt:      now,
+	}
+	if status == UserStatusSuspended {
+		change.Until = until
+	}
+
+	u.Status = status
+	u.StatusReason = reason
+	u.StatusUntil = change.Until
+	u.StatusChangedBy = actor
+	u.StatusChangedAt = &now
+	u.UpdatedAt = now
+
+	return change
+}
+
+// RequirePhoneConfirmation blocks the account until the user confirms a
+// phone number they still control, e.g. after logging in with a recovery code
+func (u *User) RequirePhoneConfirmation() {
+	u.PhoneConfirmationRequired = true
 	u.UpdatedAt = time.Now()
 }
 
-// Activate activates the user
-func (u *User) Activate() {
-	u.IsActive = true
+// ConfirmPhoneNumber sets the user's verified phone number and lifts a
+// pending phone confirmation
+func (u *User) ConfirmPhoneNumber(phoneNumber string) {
+	u.PhoneNumber = phoneNumber
+	u.PhoneConfirmationRequired = false
 	u.UpdatedAt = time.Now()
 }
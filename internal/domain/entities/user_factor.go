@@ -0,0 +1,44 @@
+package entities
+
+import (
+	"time"
+)
+
+// FactorType represents the kind of second authentication factor
+type FactorType string
+
+const (
+	FactorTypeTOTP FactorType = "totp"
+)
+
+// UserFactor represents a second authentication factor enrolled by a user
+type UserFactor struct {
+	ID              int        `json:"id" db:"id"`
+	UserID          int        `json:"user_id" db:"user_id"`
+	Type            FactorType `json:"type" db:"type"`
+	Name            string     `json:"name" db:"name"`
+	SecretEncrypted []byte     `json:"-" db:"secret_encrypted"`
+	ConfirmedAt     *time.Time `json:"confirmed_at,omitempty" db:"confirmed_at"`
+	LastUsedStep    int64      `json:"-" db:"last_used_step"`
+	LastUsedAt      *time.Time `json:"last_used_at,omitempty" db:"last_used_at"`
+	CreatedAt       time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt       time.Time  `json:"updated_at" db:"updated_at"`
+}
+
+// NewTOTPFactor creates a new, unconfirmed TOTP factor
+func NewTOTPFactor(userID int, name string, secretEncrypted []byte) *UserFactor {
+	now := time.Now()
+	return &UserFactor{
+		UserID:          userID,
+		Type:            FactorTypeTOTP,
+		Name:            name,
+		SecretEncrypted: secretEncrypted,
+		CreatedAt:       now,
+		UpdatedAt:       now,
+	}
+}
+
+// IsConfirmed checks if enrollment was completed with a first valid code
+func (f *UserFactor) IsConfirmed() bool {
+	return f.ConfirmedAt != nil
+}
@@ -1,7 +1,10 @@
 package errors
 
 import (
+	stderrors "errors"
 	"fmt"
+	"math"
+	"time"
 )
 
 // Error types for different scenarios
@@ -17,6 +20,14 @@ var (
 	ErrConnectionError     = &AppError{Code: "CONNECTION_ERROR", Message: "Connection failed"}
 	ErrTimeoutError        = &AppError{Code: "TIMEOUT_ERROR", Message: "Operation timed out"}
 	ErrConstraintViolation = &AppError{Code: "CONSTRAINT_VIOLATION", Message: "Database constraint violated"}
+	ErrOTPInvalid          = &AppError{Code: "OTP_INVALID", Message: "Invalid or expired OTP"}
+	ErrOTPLocked           = &AppError{Code: "OTP_LOCKED", Message: "Too many failed OTP attempts"}
+	ErrOTPResendTooSoon    = &AppError{Code: "OTP_RESEND_TOO_SOON", Message: "OTP was sent too recently"}
+	ErrOTPResendLimit      = &AppError{Code: "OTP_RESEND_LIMIT", Message: "Maximum number of OTP resends reached"}
+	ErrOTPDeliveryFailed   = &AppError{Code: "OTP_DELIVERY_FAILED", Message: "Failed to deliver OTP"}
+	ErrSecondFactorInvalid = &AppError{Code: "SECOND_FACTOR_INVALID", Message: "Invalid or expired second factor code"}
+	ErrRefreshTokenInvalid = &AppError{Code: "REFRESH_TOKEN_INVALID", Message: "Invalid, expired or revoked refresh token"}
+	ErrAccountInactive     = &AppError{Code: "ACCOUNT_INACTIVE", Message: "The account is not active"}
 )
 
 // AppError represents a custom application error
@@ -62,10 +73,7 @@ func (e *AppError) WithError(err error) *AppError {
 
 // IsNotFound checks if the error is a not found error
 func IsNotFound(err error) bool {
-	if err != nil && err.Error() == ErrNotFound.Error() {
-		return true
-	}
-	return false
+	return hasCode(err, ErrNotFound.Code)
 }
 
 // IsAlreadyExists checks if the error is an already exists error
@@ -78,10 +86,7 @@ func IsAlreadyExists(err error) bool {
 
 // IsInvalidInput checks if the error is an invalid input error
 func IsInvalidInput(err error) bool {
-	if err != nil && err.Error() == ErrInvalidInput.Error() {
-		return true
-	}
-	return false
+	return hasCode(err, ErrInvalidInput.Code)
 }
 
 // IsDatabaseError checks if the error is a database error
@@ -100,6 +105,87 @@ func IsConstraintViolation(err error) bool {
 	return false
 }
 
+// IsUnauthorized checks if the error is an unauthorized error
+func IsUnauthorized(err error) bool {
+	return hasCode(err, ErrUnauthorized.Code)
+}
+
+// IsOTPInvalid checks if the error is an invalid or expired OTP error
+func IsOTPInvalid(err error) bool {
+	return hasCode(err, ErrOTPInvalid.Code)
+}
+
+// IsOTPLocked checks if the error is an OTP lockout error
+func IsOTPLocked(err error) bool {
+	return hasCode(err, ErrOTPLocked.Code)
+}
+
+// IsOTPResendTooSoon checks if the error is a resend cooldown error
+func IsOTPResendTooSoon(err error) bool {
+	return hasCode(err, ErrOTPResendTooSoon.Code)
+}
+
+// IsOTPResendLimit checks if the error is a resend limit error
+func IsOTPResendLimit(err error) bool {
+	return hasCode(err, ErrOTPResendLimit.Code)
+}
+
+// IsOTPDeliveryFailed checks if the error is an OTP delivery error
+func IsOTPDeliveryFailed(err error) bool {
+	return hasCode(err, ErrOTPDeliveryFailed.Code)
+}
+
+// IsSecondFactorInvalid checks if the error is an invalid second factor error
+func IsSecondFactorInvalid(err error) bool {
+	return hasCode(err, ErrSecondFactorInvalid.Code)
+}
+
+// IsRefreshTokenInvalid checks if the error is an invalid refresh token error
+func IsRefreshTokenInvalid(err error) bool {
+	return hasCode(err, ErrRefreshTokenInvalid.Code)
+}
+
+// IsAccountInactive checks if the error is a suspended, banned or otherwise
+// inactive account error
+func IsAccountInactive(err error) bool {
+	return hasCode(err, ErrAccountInactive.Code)
+}
+
+// hasCode reports whether err is an AppError, or wraps one, with the given code
+func hasCode(err error, code string) bool {
+	var appErr *AppError
+	if stderrors.As(err, &appErr) {
+		return appErr.Code == code
+	}
+	return false
+}
+
+// OAuthError is an error of the OAuth 2.0 and OpenID Connect endpoints,
+// reported to clients with its RFC 6749 error code
+type OAuthError struct {
+	Code        string
+	Description string
+}
+
+// Error implements the error interface
+func (e *OAuthError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Code, e.Description)
+}
+
+// NewOAuthError creates a new OAuth error
+func NewOAuthError(code, description string) *OAuthError {
+	return &OAuthError{Code: code, Description: description}
+}
+
+// AsOAuthError returns the OAuth error err is or wraps
+func AsOAuthError(err error) (*OAuthError, bool) {
+	var oauthErr *OAuthError
+	if stderrors.As(err, &oauthErr) {
+		return oauthErr, true
+	}
+	return nil, false
+}
+
 // NewNotFound creates a new not found error
 func NewNotFound(resource string) *AppError {
 	return ErrNotFound.WithDetails(fmt.Sprintf("%s not found", resource))
@@ -125,6 +211,25 @@ func NewConstraintViolation(constraint string, details string) *AppError {
 	return ErrConstraintViolation.WithDetails(fmt.Sprintf("Constraint '%s' violated: %s", constraint, details))
 }
 
+// NewOTPLocked creates a new OTP lockout error carrying the remaining lockout time
+func NewOTPLocked(retryAfter time.Duration) *AppError {
+	return ErrOTPLocked.WithDetails(fmt.Sprintf("try again in %d seconds", int(retryAfter.Seconds())))
+}
+
+// NewOTPResendTooSoon creates a new resend cooldown error carrying the remaining wait time
+func NewOTPResendTooSoon(retryAfter time.Duration) *AppError {
+	return ErrOTPResendTooSoon.WithDetails(fmt.Sprintf("try again in %d seconds", int(math.Ceil(retryAfter.Seconds()))))
+}
+
+// NewAccountInactive creates a new inactive account error carrying the
+// account status and, for suspensions, when it lifts
+func NewAccountInactive(status string, until *time.Time) *AppError {
+	if until != nil {
+		return ErrAccountInactive.WithDetails(fmt.Sprintf("%s until %s", status, until.UTC().Format(time.RFC3339)))
+	}
+	return ErrAccountInactive.WithDetails(status)
+}
+
 // WrapError wraps an error with additional context
 func WrapError(err error, context string) error {
 	if err == nil {
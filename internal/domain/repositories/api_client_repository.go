@@ -0,0 +1,24 @@
+package repositories
+
+import (
+	"context"
+	"otp-server/internal/domain/entities"
+)
+
+// APIClientRepository defines the interface for API client data operations
+type APIClientRepository interface {
+	// Create creates a new client
+	Create(ctx context.Context, client *entities.APIClient) error
+
+	// GetByClientID retrieves a client by its client ID
+	GetByClientID(ctx context.Context, clientID string) (*entities.APIClient, error)
+
+	// GetBySecretHash retrieves a client by the hash of its secret
+	GetBySecretHash(ctx context.Context, secretHash string) (*entities.APIClient, error)
+
+	// List retrieves every client of the tenant
+	List(ctx context.Context, tenantID int) ([]*entities.APIClient, error)
+
+	// Deactivate stops a client of the tenant from calling the API
+	Deactivate(ctx context.Context, tenantID int, clientID string) error
+}
@@ -0,0 +1,21 @@
+package repositories
+
+import (
+	"context"
+	"otp-server/internal/domain/entities"
+)
+
+// OAuthClientRepository defines the interface for OpenID Connect client data operations
+type OAuthClientRepository interface {
+	// Create creates a new client
+	Create(ctx context.Context, client *entities.OAuthClient) error
+
+	// GetByClientID retrieves a client by its client ID
+	GetByClientID(ctx context.Context, clientID string) (*entities.OAuthClient, error)
+
+	// List retrieves every client
+	List(ctx context.Context) ([]*entities.OAuthClient, error)
+
+	// Deactivate stops a client from logging users in
+	Deactivate(ctx context.Context, clientID string) error
+}
@@ -0,0 +1,19 @@
+package repositories
+
+import (
+	"context"
+)
+
+// RecoveryCodeRepository defines the interface for recovery code data operations.
+// Codes are only ever handled as hashes.
+type RecoveryCodeRepository interface {
+	// Replace replaces all of the user's recovery codes with the given set
+	Replace(ctx context.Context, userID int, codeHashes []string) error
+
+	// Use marks the user's unused code with the given hash as used. It returns
+	// false when there is no such code, so a code can only be used once.
+	Use(ctx context.Context, userID int, codeHash string) (bool, error)
+
+	// CountUnused retrieves the number of codes the user has left
+	CountUnused(ctx context.Context, userID int) (int, error)
+}
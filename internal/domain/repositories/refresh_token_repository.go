@@ -0,0 +1,26 @@
+package repositories
+
+import (
+	"context"
+	"otp-server/internal/domain/entities"
+)
+
+// RefreshTokenRepository defines the interface for refresh token data operations
+type RefreshTokenRepository interface {
+	// Create creates a new refresh token
+	Create(ctx context.Context, token *entities.RefreshToken) error
+
+	// GetByHash retrieves a refresh token by the hash of the token
+	GetByHash(ctx context.Context, tokenHash string) (*entities.RefreshToken, error)
+
+	// Rotate marks the token as exchanged for its successor. It returns false
+	// when the token was already rotated or revoked, so a token can only be
+	// exchanged once.
+	Rotate(ctx context.Context, id int) (bool, error)
+
+	// RevokeFamily revokes every token of the family
+	RevokeFamily(ctx context.Context, familyID string) error
+
+	// RevokeUser revokes every token of the user
+	RevokeUser(ctx context.Context, userID int) error
+}
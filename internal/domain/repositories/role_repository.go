@@ -0,0 +1,19 @@
+package repositories
+
+import (
+	"context"
+	"otp-server/internal/domain/entities"
+)
+
+// RoleRepository defines the interface for role and permission data operations
+type RoleRepository interface {
+	// List retrieves every role with its permissions
+	List(ctx context.Context) ([]*entities.Role, error)
+
+	// ListPermissions retrieves every permission
+	ListPermissions(ctx context.Context) ([]*entities.Permission, error)
+
+	// Save creates the role or updates the role of the same name, replacing
+	// its permissions
+	Save(ctx context.Context, role *entities.Role) error
+}
@@ -0,0 +1,30 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"otp-server/internal/domain/entities"
+)
+
+// SessionRepository defines the interface for session data operations
+type SessionRepository interface {
+	// Create creates a new session
+	Create(ctx context.Context, session *entities.Session) error
+
+	// GetByID retrieves a session by its ID
+	GetByID(ctx context.Context, id string) (*entities.Session, error)
+
+	// ListActive retrieves the user's sessions that are neither revoked nor
+	// expired, newest first
+	ListActive(ctx context.Context, userID int) ([]*entities.Session, error)
+
+	// Touch records that the session was used and extends it until expiresAt
+	Touch(ctx context.Context, id string, expiresAt time.Time) error
+
+	// Revoke revokes one active session of the user
+	Revoke(ctx context.Context, userID int, id string) error
+
+	// RevokeUser revokes every session of the user
+	RevokeUser(ctx context.Context, userID int) error
+}
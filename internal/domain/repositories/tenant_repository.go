@@ -0,0 +1,21 @@
+package repositories
+
+import (
+	"context"
+	"otp-server/internal/domain/entities"
+)
+
+// TenantRepository defines the interface for tenant data operations
+type TenantRepository interface {
+	// Create creates a new tenant
+	Create(ctx context.Context, tenant *entities.Tenant) error
+
+	// GetByID retrieves a tenant by ID
+	GetByID(ctx context.Context, id int) (*entities.Tenant, error)
+
+	// List retrieves every tenant
+	List(ctx context.Context) ([]*entities.Tenant, error)
+
+	// Update updates the name, hosts, settings and status of a tenant
+	Update(ctx context.Context, tenant *entities.Tenant) error
+}
@@ -0,0 +1,29 @@
+package repositories
+
+import (
+	"context"
+	"otp-server/internal/domain/entities"
+)
+
+// UserFactorRepository defines the interface for second factor data operations
+type UserFactorRepository interface {
+	// Create creates a new factor
+	Create(ctx context.Context, factor *entities.UserFactor) error
+
+	// GetByID retrieves a user's factor by ID
+	GetByID(ctx context.Context, userID, id int) (*entities.UserFactor, error)
+
+	// ListByUser retrieves all factors of a user, confirmed or not
+	ListByUser(ctx context.Context, userID int) ([]*entities.UserFactor, error)
+
+	// HasConfirmed checks if the user has at least one confirmed factor
+	HasConfirmed(ctx context.Context, userID int) (bool, error)
+
+	// UseStep records step as the factor's last accepted TOTP step, confirming
+	// the factor if needed. It returns false when step is not newer than the
+	// last accepted one, so a code can only be used once.
+	UseStep(ctx context.Context, id int, step int64) (bool, error)
+
+	// Delete deletes a user's factor by ID
+	Delete(ctx context.Context, userID, id int) error
+}
@@ -2,9 +2,23 @@ package repositories
 
 import (
 	"context"
+	"time"
+
 	"otp-server/internal/domain/entities"
 )
 
+// UserFilter narrows a listing of users; zero fields match every user
+type UserFilter struct {
+	// Query matches part of the phone number or name
+	Query string
+	Role  entities.UserRole
+	// Status matches the current status, counting lifted suspensions as
+	// active
+	Status        entities.UserStatus
+	CreatedAfter  time.Time
+	CreatedBefore time.Time
+}
+
 // UserRepository defines the interface for user data operations
 type UserRepository interface {
 	// Create creates a new user
@@ -13,24 +27,40 @@ type UserRepository interface {
 	// GetByID retrieves a user by ID
 	GetByID(ctx context.Context, id int) (*entities.User, error)
 
-	// GetByPhoneNumber retrieves a user by phone number
-	GetByPhoneNumber(ctx context.Context, phoneNumber string) (*entities.User, error)
+	// GetByPhoneNumber retrieves a user of the tenant by phone number
+	GetByPhoneNumber(ctx context.Context, tenantID int, phoneNumber string) (*entities.User, error)
 
-	// Update updates an existing user
+	// Update updates an existing user, except for the phone number and status
 	Update(ctx context.Context, user *entities.User) error
 
+	// UpdateStatus stores the user's status and records the change in the
+	// audit trail
+	UpdateStatus(ctx context.Context, user *entities.User, change *entities.UserStatusChange) error
+
+	// ListStatusChanges retrieves the audit trail of the user's status
+	// changes, newest first
+	ListStatusChanges(ctx context.Context, userID int) ([]*entities.UserStatusChange, error)
+
+	// UpdatePhoneNumber stores the user's phone number and phone confirmation
+	// flag; the number must not belong to another user of the tenant
+	UpdatePhoneNumber(ctx context.Context, user *entities.User) error
+
 	// Delete deletes a user by ID
 	Delete(ctx context.Context, id int) error
 
-	// GetUsers retrieves a paginated list of users
-	GetUsers(ctx context.Context, offset, limit int) ([]*entities.User, error)
+	// GetUsers retrieves a paginated list of the tenant's users
+	GetUsers(ctx context.Context, tenantID, offset, limit int) ([]*entities.User, error)
+
+	// GetTotalCount retrieves the total number of the tenant's users
+	GetTotalCount(ctx context.Context, tenantID int) (int, error)
 
-	// GetTotalCount retrieves the total number of users
-	GetTotalCount(ctx context.Context) (int, error)
+	// SearchUsers searches the tenant's users by phone number or name
+	SearchUsers(ctx context.Context, tenantID int, query string) ([]*entities.User, error)
 
-	// SearchUsers searches users by phone number or name
-	SearchUsers(ctx context.Context, query string) ([]*entities.User, error)
+	// GetUsersWithQuery retrieves the tenant's users with optional search and pagination in one query
+	GetUsersWithQuery(ctx context.Context, tenantID int, query string, offset, limit int) ([]*entities.User, int, error)
 
-	// GetUsersWithQuery retrieves users with optional search and pagination in one query
-	GetUsersWithQuery(ctx context.Context, query string, offset, limit int) ([]*entities.User, int, error)
+	// ListUsers retrieves a page of the tenant's users matching the filter,
+	// newest first, and the number of users matching it
+	ListUsers(ctx context.Context, tenantID int, filter *UserFilter, offset, limit int) ([]*entities.User, int, error)
 }
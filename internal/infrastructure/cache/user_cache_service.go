@@ -67,8 +67,8 @@ func (c *UserCacheService) SetUserByID(ctx context.Context, user *entities.User)
 	return c.redisClient.Set(ctx, key, string(data), c.ttl)
 }
 
-func (c *UserCacheService) GetUserByPhoneNumber(ctx context.Context, phoneNumber string) (*entities.User, error) {
-	key := fmt.Sprintf("user:phone:%s", phoneNumber)
+func (c *UserCacheService) GetUserByPhoneNumber(ctx context.Context, tenantID int, phoneNumber string) (*entities.User, error) {
+	key := fmt.Sprintf("user:phone:%d:%s", tenantID, phoneNumber)
 
 	data, err := c.redisClient.Get(ctx, key)
 	if err != nil || data == "" {
@@ -91,7 +91,7 @@ func (c *UserCacheService) GetUserByPhoneNumber(ctx context.Context, phoneNumber
 }
 
 func (c *UserCacheService) SetUserByPhoneNumber(ctx context.Context, user *entities.User) error {
-	key := fmt.Sprintf("user:phone:%s", user.PhoneNumber)
+	key := fmt.Sprintf("user:phone:%d:%s", user.TenantID, user.PhoneNumber)
 
 	data, err := json.Marshal(user)
 	if err != nil {
@@ -101,12 +101,12 @@ func (c *UserCacheService) SetUserByPhoneNumber(ctx context.Context, user *entit
 	return c.redisClient.Set(ctx, key, string(data), c.ttl)
 }
 
-func (c *UserCacheService) GetUsers(ctx context.Context, query string, offset, limit int) ([]*entities.User, int, error) {
+func (c *UserCacheService) GetUsers(ctx context.Context, tenantID int, query string, offset, limit int) ([]*entities.User, int, error) {
 	var key string
 	if query != "" {
-		key = fmt.Sprintf("users:search:%s:%d:%d", query, offset, limit)
+		key = fmt.Sprintf("users:search:%d:%s:%d:%d", tenantID, query, offset, limit)
 	} else {
-		key = fmt.Sprintf("users:list:%d:%d", offset, limit)
+		key = fmt.Sprintf("users:list:%d:%d:%d", tenantID, offset, limit)
 	}
 
 	data, err := c.redisClient.Get(ctx, key)
@@ -133,12 +133,12 @@ func (c *UserCacheService) GetUsers(ctx context.Context, query string, offset, l
 	return result.Users, result.Total, nil
 }
 
-func (c *UserCacheService) SetUsers(ctx context.Context, query string, offset, limit int, users []*entities.User, total int) error {
+func (c *UserCacheService) SetUsers(ctx context.Context, tenantID int, query string, offset, limit int, users []*entities.User, total int) error {
 	var key string
 	if query != "" {
-		key = fmt.Sprintf("users:search:%s:%d:%d", query, offset, limit)
+		key = fmt.Sprintf("users:search:%d:%s:%d:%d", tenantID, query, offset, limit)
 	} else {
-		key = fmt.Sprintf("users:list:%d:%d", offset, limit)
+		key = fmt.Sprintf("users:list:%d:%d:%d", tenantID, offset, limit)
 	}
 
 	result := struct {
@@ -22,8 +22,13 @@ type Config struct {
 	Metrics        MetricsConfig
 	Infrastructure InfrastructureConfig
 	OTP            OTPConfig
+	Delivery       DeliveryConfig
+	MFA            MFAConfig
+	OIDC           OIDCConfig
 	Events         EventsConfig
 	RateLimiting   RateLimitingConfig
+	Tenants        TenantsConfig
+	RBAC           RBACConfig
 }
 
 // InfrastructureConfig holds infrastructure provider configurations
@@ -82,9 +87,30 @@ type RedisConfig struct {
 
 // JWTConfig holds JWT configuration
 type JWTConfig struct {
-	Secret        string
+	// Algorithm is HS256, signing with Secret, or one of RS256, ES256,
+	// ES384, ES512 and EdDSA, signing with the keys of KeyFiles or KeyDir
+	Algorithm string
+	Secret    string
+	// KeyFiles are PEM keys; the first one signs, the others only verify
+	KeyFiles []string
+	// KeyDir is a directory of PEM keys named <unix time>-<name>.pem,
+	// reloaded every KeyReloadInterval; with a KeyRotationInterval, new keys
+	// are generated into it
+	KeyDir              string
+	KeyReloadInterval   time.Duration
+	KeyRotationInterval time.Duration
+	// Expiry is the access token lifetime; keep it short, since access
+	// tokens are renewed with refresh tokens
 	Expiry        time.Duration
 	RefreshExpiry time.Duration
+	// MaxSessions is how many concurrent sessions a user may have; logging
+	// in once more evicts the oldest. 0 means no limit.
+	MaxSessions int
+	// StepUpMaxAge is how long after authenticating a user may take
+	// sensitive actions before having to step up, and StepUpExpiry the
+	// lifetime of the access token issued by stepping up
+	StepUpMaxAge time.Duration
+	StepUpExpiry time.Duration
 }
 
 // LogConfig holds logging configuration
@@ -121,6 +147,136 @@ type OTPConfig struct {
 	Length         int
 	RedisKeyPrefix string
 	CodeCharset    string
+
+	// Per-purpose overrides of Expiry and Length (login, phone_change,
+	// account_deletion, transaction)
+	PurposeExpiry map[string]time.Duration
+	PurposeLength map[string]int
+
+	// How long a challenge stays queryable after its code has expired
+	ChallengeRetention time.Duration
+
+	// PhoneChangeVerifyOldNumber makes a phone number change confirm a code
+	// sent to the current number as well as one sent to the new number
+	PhoneChangeVerifyOldNumber bool
+
+	// Resending: within ResendReuseWindow of issuing a code, resends deliver
+	// the same code so a late SMS still works
+	ResendInterval    time.Duration
+	MaxResends        int
+	ResendReuseWindow time.Duration
+
+	// Channel fallback: ordered channels per country prefix ("default" applies
+	// when no prefix matches). A channel that fails, or is not confirmed
+	// delivered within FallbackTimeout, hands over to the next one.
+	FallbackChains  map[string][]string
+	FallbackTimeout time.Duration
+
+	// Codes are stored as HMAC-SHA256(HashSecret, purpose:challengeID:code),
+	// with the payload hash before the code for transaction codes
+	// (purpose:challengeID:payloadHash:code). Codes issued under
+	// PreviousHashSecret keep validating until they expire.
+	HashSecret         string
+	PreviousHashSecret string
+
+	// Brute-force protection
+	MaxAttempts          int
+	LockoutDuration      time.Duration
+	LockoutBackoffFactor float64
+	MaxLockoutDuration   time.Duration
+	LockoutHistoryTTL    time.Duration
+}
+
+// DeliveryConfig holds OTP delivery configuration
+type DeliveryConfig struct {
+	Provider string // http, file, log
+
+	// Provider per channel (sms, voice, whatsapp, email). Channels without a
+	// provider are skipped; when empty, sms goes through Provider.
+	ChannelProviders map[string]string
+
+	// Defaults for every provider; a provider's own Timeout takes precedence
+	Timeout     time.Duration
+	MaxAttempts int
+	RetryDelay  time.Duration
+
+	CircuitFailureThreshold int
+	CircuitTimeout          time.Duration
+
+	HTTP HTTPDeliveryConfig
+	File FileDeliveryConfig
+	Log  LogDeliveryConfig
+
+	Templates TemplateConfig
+}
+
+// TemplateConfig holds configuration for the localized OTP message templates
+type TemplateConfig struct {
+	Dir           string // one <locale>.json file per locale
+	DefaultLocale string
+	AppName       string
+
+	// Autofill: the Android SMS Retriever app hash and the WebOTP origin
+	// domain are appended to SMS messages when set
+	AndroidAppHash string
+	WebOTPDomain   string
+}
+
+// HTTPDeliveryConfig holds configuration for the generic HTTP/JSON SMS gateway
+type HTTPDeliveryConfig struct {
+	URL        string
+	APIKey     string
+	AuthHeader string
+	From       string
+	Timeout    time.Duration
+
+	// Delivery receipts are signed with HMAC-SHA256(WebhookSecret, body)
+	WebhookSecret          string
+	WebhookSignatureHeader string
+}
+
+// FileDeliveryConfig holds configuration for the file "dev inbox" provider
+type FileDeliveryConfig struct {
+	Path    string
+	Timeout time.Duration
+}
+
+// LogDeliveryConfig holds configuration for the log sink provider
+type LogDeliveryConfig struct {
+	Timeout time.Duration
+}
+
+// MFAConfig holds second factor (TOTP) configuration
+type MFAConfig struct {
+	Issuer string
+	// Secrets are stored encrypted with AES-256-GCM under a key derived
+	// from EncryptionKey
+	EncryptionKey string
+	Period        time.Duration
+	Digits        int
+	// Skew is the number of time steps accepted before and after the
+	// current one, tolerating clock drift on the device
+	Skew          int
+	EnrollmentTTL time.Duration
+	ChallengeTTL  time.Duration
+	MaxAttempts   int
+	// RecoveryCodeCount is the size of a generated recovery code set
+	RecoveryCodeCount int
+}
+
+// OIDCConfig holds OpenID Connect provider configuration
+type OIDCConfig struct {
+	// Issuer is the public base URL of the server, the iss of ID tokens
+	Issuer string
+	// AuthorizationTTL is how long the login page of an authorization
+	// request stays usable
+	AuthorizationTTL time.Duration
+	// CodeTTL is how long an authorization code can be exchanged
+	CodeTTL       time.Duration
+	IDTokenExpiry time.Duration
+	// IntrospectionCacheTTL is how long the user details of an introspected
+	// access token are cached; revocation is checked on every call
+	IntrospectionCacheTTL time.Duration
 }
 
 // EventsConfig holds event system configuration
@@ -136,11 +292,17 @@ type EventsConfig struct {
 
 // EventTypesConfig holds configuration for different event types
 type EventTypesConfig struct {
-	OTPGenerated EventTypeConfig
-	OTPVerified  EventTypeConfig
-	UserCreated  EventTypeConfig
-	UserLoggedIn EventTypeConfig
-	RateLimited  EventTypeConfig
+	OTPGenerated           EventTypeConfig
+	OTPVerified            EventTypeConfig
+	UserCreated            EventTypeConfig
+	UserLoggedIn           EventTypeConfig
+	RateLimited            EventTypeConfig
+	OTPLocked              EventTypeConfig
+	OTPChannelFallback     EventTypeConfig
+	OTPTransactionVerified EventTypeConfig
+	RecoveryCodeUsed       EventTypeConfig
+	UserAdminAction        EventTypeConfig
+	PhoneNumberChanged     EventTypeConfig
 }
 
 // EventTypeConfig holds configuration for a specific event type
@@ -156,6 +318,9 @@ type RateLimitingConfig struct {
 	Auth   RateLimitConfig
 	OTP    RateLimitConfig
 	User   RateLimitConfig
+	// Client is the default limit of API clients, counted per client;
+	// clients registered with a rate limit of their own use it instead
+	Client RateLimitConfig
 	Custom map[string]RateLimitConfig
 }
 
@@ -166,6 +331,39 @@ type RateLimitConfig struct {
 	Enabled  bool
 }
 
+// TenantsConfig holds multi-tenancy configuration
+type TenantsConfig struct {
+	// Header names the tenant by slug; requests without it are resolved by
+	// their host, then fall back to the default tenant
+	Header string
+	// ReloadInterval is how often tenants and their settings are reloaded
+	// from the database
+	ReloadInterval time.Duration
+}
+
+// RBACConfig holds role-based access control configuration
+type RBACConfig struct {
+	// ReloadInterval is how often roles and their permissions are reloaded
+	// from the database
+	ReloadInterval time.Duration
+}
+
+// TenantConfig holds the settings every tenant has its own copy of
+type TenantConfig struct {
+	OTP          OTPConfig
+	Templates    TemplateConfig
+	RateLimiting RateLimitingConfig
+}
+
+// templateSettings are the message template settings a tenant may override
+var templateSettings = map[string]bool{
+	"DELIVERY_TEMPLATES_DIR":    true,
+	"DELIVERY_DEFAULT_LOCALE":   true,
+	"DELIVERY_APP_NAME":         true,
+	"DELIVERY_ANDROID_APP_HASH": true,
+	"DELIVERY_WEBOTP_DOMAIN":    true,
+}
+
 // Load loads configuration from environment variables and config files
 func Load() (*Config, error) {
 	if err := godotenv.Load(); err != nil {
@@ -182,140 +380,350 @@ func Load() (*Config, error) {
 		// Config file not found, continue with environment variables
 	}
 
+	env := envSource(os.Getenv)
 	config := &Config{
 		Server: ServerConfig{
-			Port:        getEnv("SERVER_PORT", "8080"),
-			Host:        getEnv("SERVER_HOST", "localhost"),
-			Environment: getEnv("ENVIRONMENT", "development"),
+			Port:        env.getEnv("SERVER_PORT", "8080"),
+			Host:        env.getEnv("SERVER_HOST", "localhost"),
+			Environment: env.getEnv("ENVIRONMENT", "development"),
 		},
 		Database: DatabaseConfig{
-			Provider:        getEnv("DB_PROVIDER", "postgres"),
-			Host:            getEnv("POSTGRES_HOST", "localhost"),
-			Port:            getEnv("POSTGRES_PORT", "5432"),
-			User:            getEnv("POSTGRES_USER", "otp_server_user"),
-			Password:        getEnv("POSTGRES_PASSWORD", "otp_server_password"),
-			DBName:          getEnv("POSTGRES_DB", "otp_server_db"),
-			SSLMode:         getEnv("POSTGRES_SSL_MODE", "disable"),
-			Charset:         getEnv("MYSQL_CHARSET", "utf8mb4"),
-			FilePath:        getEnv("SQLITE_FILE_PATH", "./otp_server.db"),
-			MaxOpenConns:    getEnvAsInt("DB_MAX_OPEN_CONNS", 25),
-			MaxIdleConns:    getEnvAsInt("DB_MAX_IDLE_CONNS", 5),
-			ConnMaxLifetime: getEnvAsDuration("DB_CONN_MAX_LIFETIME", time.Hour),
+			Provider:        env.getEnv("DB_PROVIDER", "postgres"),
+			Host:            env.getEnv("POSTGRES_HOST", "localhost"),
+			Port:            env.getEnv("POSTGRES_PORT", "5432"),
+			User:            env.getEnv("POSTGRES_USER", "otp_server_user"),
+			Password:        env.getEnv("POSTGRES_PASSWORD", "otp_server_password"),
+			DBName:          env.getEnv("POSTGRES_DB", "otp_server_db"),
+			SSLMode:         env.getEnv("POSTGRES_SSL_MODE", "disable"),
+			Charset:         env.getEnv("MYSQL_CHARSET", "utf8mb4"),
+			FilePath:        env.getEnv("SQLITE_FILE_PATH", "./otp_server.db"),
+			MaxOpenConns:    env.getEnvAsInt("DB_MAX_OPEN_CONNS", 25),
+			MaxIdleConns:    env.getEnvAsInt("DB_MAX_IDLE_CONNS", 5),
+			ConnMaxLifetime: env.getEnvAsDuration("DB_CONN_MAX_LIFETIME", time.Hour),
 		},
 		Redis: RedisConfig{
-			Host:         getEnv("REDIS_HOST", "localhost"),
-			Port:         getEnv("REDIS_PORT", "6379"),
-			Password:     getEnv("REDIS_PASSWORD", ""),
-			DB:           getEnvAsInt("REDIS_DB", 0),
-			PoolSize:     getEnvAsInt("REDIS_POOL_SIZE", 10),
-			MinIdleConns: getEnvAsInt("REDIS_MIN_IDLE_CONNS", 5),
-			MaxRetries:   getEnvAsInt("REDIS_MAX_RETRIES", 3),
-			ClusterMode:  getEnvAsBool("REDIS_CLUSTER_MODE", false),
-			ClusterNodes: getEnvAsSlice("REDIS_CLUSTER_NODES", []string{}),
+			Host:         env.getEnv("REDIS_HOST", "localhost"),
+			Port:         env.getEnv("REDIS_PORT", "6379"),
+			Password:     env.getEnv("REDIS_PASSWORD", ""),
+			DB:           env.getEnvAsInt("REDIS_DB", 0),
+			PoolSize:     env.getEnvAsInt("REDIS_POOL_SIZE", 10),
+			MinIdleConns: env.getEnvAsInt("REDIS_MIN_IDLE_CONNS", 5),
+			MaxRetries:   env.getEnvAsInt("REDIS_MAX_RETRIES", 3),
+			ClusterMode:  env.getEnvAsBool("REDIS_CLUSTER_MODE", false),
+			ClusterNodes: env.getEnvAsSlice("REDIS_CLUSTER_NODES", []string{}),
 		},
 		JWT: JWTConfig{
-			Secret:        getEnv("JWT_SECRET", "your-super-secret-jwt-key-change-in-production"),
-			Expiry:        getEnvAsDuration("JWT_EXPIRY", 24000*time.Hour),
-			RefreshExpiry: getEnvAsDuration("JWT_REFRESH_EXPIRY", 42000*time.Hour),
+			Algorithm:           env.getEnv("JWT_ALGORITHM", "HS256"),
+			Secret:              env.getEnv("JWT_SECRET", "your-super-secret-jwt-key-change-in-production"),
+			KeyFiles:            env.getEnvAsSlice("JWT_KEY_FILES", []string{}),
+			KeyDir:              env.getEnv("JWT_KEY_DIR", ""),
+			KeyReloadInterval:   env.getEnvAsDuration("JWT_KEY_RELOAD_INTERVAL", time.Minute),
+			KeyRotationInterval: env.getEnvAsDuration("JWT_KEY_ROTATION_INTERVAL", 0),
+			Expiry:              env.getEnvAsDuration("JWT_EXPIRY", 15*time.Minute),
+			RefreshExpiry:       env.getEnvAsDuration("JWT_REFRESH_EXPIRY", 720*time.Hour),
+			MaxSessions:         env.getEnvAsInt("JWT_MAX_SESSIONS", 10),
+			StepUpMaxAge:        env.getEnvAsDuration("JWT_STEP_UP_MAX_AGE", 5*time.Minute),
+			StepUpExpiry:        env.getEnvAsDuration("JWT_STEP_UP_EXPIRY", 5*time.Minute),
 		},
 		Log: LogConfig{
-			Level:      getEnv("LOG_LEVEL", "info"),
-			Format:     getEnv("LOG_FORMAT", "json"),
-			Output:     getEnv("LOG_OUTPUT", "stdout"),
-			FilePath:   getEnv("LOG_FILE_PATH", "./logs/app.log"),
-			MaxSize:    getEnvAsInt("LOG_MAX_SIZE", 100),
-			MaxBackups: getEnvAsInt("LOG_MAX_BACKUPS", 3),
-			MaxAge:     getEnvAsInt("LOG_MAX_AGE", 28),
-			Compress:   getEnvAsBool("LOG_COMPRESS", true),
+			Level:      env.getEnv("LOG_LEVEL", "info"),
+			Format:     env.getEnv("LOG_FORMAT", "json"),
+			Output:     env.getEnv("LOG_OUTPUT", "stdout"),
+			FilePath:   env.getEnv("LOG_FILE_PATH", "./logs/app.log"),
+			MaxSize:    env.getEnvAsInt("LOG_MAX_SIZE", 100),
+			MaxBackups: env.getEnvAsInt("LOG_MAX_BACKUPS", 3),
+			MaxAge:     env.getEnvAsInt("LOG_MAX_AGE", 28),
+			Compress:   env.getEnvAsBool("LOG_COMPRESS", true),
 		},
 		CORS: CORSConfig{
-			AllowedOrigins: getEnvAsSlice("CORS_ALLOWED_ORIGINS", []string{"http://localhost:3000", "http://localhost:8080"}),
-			AllowedMethods: getEnvAsSlice("CORS_ALLOWED_METHODS", []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"}),
-			AllowedHeaders: getEnvAsSlice("CORS_ALLOWED_HEADERS", []string{"Content-Type", "Authorization", "X-Requested-With"}),
+			AllowedOrigins: env.getEnvAsSlice("CORS_ALLOWED_ORIGINS", []string{"http://localhost:3000", "http://localhost:8080"}),
+			AllowedMethods: env.getEnvAsSlice("CORS_ALLOWED_METHODS", []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"}),
+			AllowedHeaders: env.getEnvAsSlice("CORS_ALLOWED_HEADERS", []string{"Content-Type", "Authorization", "X-Requested-With"}),
 		},
 
 		Metrics: MetricsConfig{
-			Enabled:     getEnvAsBool("METRICS_ENABLED", true),
-			Provider:    getEnv("METRICS_PROVIDER", "prometheus"),
-			Endpoint:    getEnv("METRICS_ENDPOINT", "/metrics"),
-			ServiceName: getEnv("METRICS_SERVICE_NAME", "otp-server"),
-			Environment: getEnv("METRICS_ENVIRONMENT", "development"),
+			Enabled:     env.getEnvAsBool("METRICS_ENABLED", true),
+			Provider:    env.getEnv("METRICS_PROVIDER", "prometheus"),
+			Endpoint:    env.getEnv("METRICS_ENDPOINT", "/metrics"),
+			ServiceName: env.getEnv("METRICS_SERVICE_NAME", "otp-server"),
+			Environment: env.getEnv("METRICS_ENVIRONMENT", "development"),
 		},
 		Infrastructure: InfrastructureConfig{
-			DatabaseProvider: getEnv("DB_PROVIDER", "postgres"),
-			CacheProvider:    getEnv("CACHE_PROVIDER", "redis"),
-			StorageProvider:  getEnv("STORAGE_PROVIDER", "s3"),
+			DatabaseProvider: env.getEnv("DB_PROVIDER", "postgres"),
+			CacheProvider:    env.getEnv("CACHE_PROVIDER", "redis"),
+			StorageProvider:  env.getEnv("STORAGE_PROVIDER", "s3"),
+		},
+		OTP: loadOTPConfig(env),
+		Delivery: DeliveryConfig{
+			Provider:         env.getEnv("DELIVERY_PROVIDER", "log"),
+			ChannelProviders: env.getEnvAsMap("DELIVERY_CHANNEL_PROVIDERS", map[string]string{}),
+
+			Timeout:     env.getEnvAsDuration("DELIVERY_TIMEOUT", 10*time.Second),
+			MaxAttempts: env.getEnvAsInt("DELIVERY_MAX_ATTEMPTS", 3),
+			RetryDelay:  env.getEnvAsDuration("DELIVERY_RETRY_DELAY", 500*time.Millisecond),
+
+			CircuitFailureThreshold: env.getEnvAsInt("DELIVERY_CIRCUIT_FAILURE_THRESHOLD", 5),
+			CircuitTimeout:          env.getEnvAsDuration("DELIVERY_CIRCUIT_TIMEOUT", 30*time.Second),
+
+			HTTP: HTTPDeliveryConfig{
+				URL:        env.getEnv("DELIVERY_HTTP_URL", ""),
+				APIKey:     env.getEnv("DELIVERY_HTTP_API_KEY", ""),
+				AuthHeader: env.getEnv("DELIVERY_HTTP_AUTH_HEADER", "Authorization"),
+				From:       env.getEnv("DELIVERY_HTTP_FROM", ""),
+				Timeout:    env.getEnvAsDuration("DELIVERY_HTTP_TIMEOUT", 5*time.Second),
+
+				WebhookSecret:          env.getEnv("DELIVERY_HTTP_WEBHOOK_SECRET", ""),
+				WebhookSignatureHeader: env.getEnv("DELIVERY_HTTP_WEBHOOK_SIGNATURE_HEADER", "X-Signature"),
+			},
+			File: FileDeliveryConfig{
+				Path:    env.getEnv("DELIVERY_FILE_PATH", "./tmp/otp-inbox.jsonl"),
+				Timeout: env.getEnvAsDuration("DELIVERY_FILE_TIMEOUT", time.Second),
+			},
+			Log: LogDeliveryConfig{
+				Timeout: env.getEnvAsDuration("DELIVERY_LOG_TIMEOUT", time.Second),
+			},
+			Templates: loadTemplateConfig(env),
 		},
-		OTP: OTPConfig{
-			Expiry:         getEnvAsDuration("OTP_EXPIRY", 2*time.Minute),
-			Length:         getEnvAsInt("OTP_LENGTH", 6),
-			RedisKeyPrefix: getEnv("OTP_REDIS_KEY_PREFIX", "otp"),
-			CodeCharset:    getEnv("OTP_CODE_CHARSET", "0123456789"),
+		MFA: MFAConfig{
+			Issuer:            env.getEnv("MFA_TOTP_ISSUER", "OTP Server"),
+			EncryptionKey:     env.getEnv("MFA_ENCRYPTION_KEY", mfaEncryptionKeyPlaceholder),
+			Period:            env.getEnvAsDuration("MFA_TOTP_PERIOD", 30*time.Second),
+			Digits:            env.getEnvAsInt("MFA_TOTP_DIGITS", 6),
+			Skew:              env.getEnvAsInt("MFA_TOTP_SKEW", 1),
+			EnrollmentTTL:     env.getEnvAsDuration("MFA_ENROLLMENT_TTL", 10*time.Minute),
+			ChallengeTTL:      env.getEnvAsDuration("MFA_CHALLENGE_TTL", 5*time.Minute),
+			MaxAttempts:       env.getEnvAsInt("MFA_MAX_ATTEMPTS", 5),
+			RecoveryCodeCount: env.getEnvAsInt("MFA_RECOVERY_CODE_COUNT", 10),
+		},
+		OIDC: OIDCConfig{
+			Issuer:                strings.TrimSuffix(env.getEnv("OIDC_ISSUER", "http://localhost:8080"), "/"),
+			AuthorizationTTL:      env.getEnvAsDuration("OIDC_AUTHORIZATION_TTL", 10*time.Minute),
+			CodeTTL:               env.getEnvAsDuration("OIDC_CODE_TTL", time.Minute),
+			IDTokenExpiry:         env.getEnvAsDuration("OIDC_ID_TOKEN_EXPIRY", time.Hour),
+			IntrospectionCacheTTL: env.getEnvAsDuration("OIDC_INTROSPECTION_CACHE_TTL", 30*time.Second),
 		},
 		Events: EventsConfig{
-			Enabled:       getEnvAsBool("EVENTS_ENABLED", true),
-			RedisChannel:  getEnv("EVENTS_REDIS_CHANNEL", "events"),
-			BatchSize:     getEnvAsInt("EVENTS_BATCH_SIZE", 100),
-			FlushInterval: getEnvAsDuration("EVENTS_FLUSH_INTERVAL", 5*time.Second),
-			RetryAttempts: getEnvAsInt("EVENTS_RETRY_ATTEMPTS", 3),
-			RetryDelay:    getEnvAsDuration("EVENTS_RETRY_DELAY", time.Second),
+			Enabled:       env.getEnvAsBool("EVENTS_ENABLED", true),
+			RedisChannel:  env.getEnv("EVENTS_REDIS_CHANNEL", "events"),
+			BatchSize:     env.getEnvAsInt("EVENTS_BATCH_SIZE", 100),
+			FlushInterval: env.getEnvAsDuration("EVENTS_FLUSH_INTERVAL", 5*time.Second),
+			RetryAttempts: env.getEnvAsInt("EVENTS_RETRY_ATTEMPTS", 3),
+			RetryDelay:    env.getEnvAsDuration("EVENTS_RETRY_DELAY", time.Second),
 			EventTypes: EventTypesConfig{
 				OTPGenerated: EventTypeConfig{
-					Name:    getEnv("EVENT_OTP_GENERATED_NAME", "otp_generated"),
-					Enabled: getEnvAsBool("EVENT_OTP_GENERATED_ENABLED", true),
-					TTL:     getEnvAsDuration("EVENT_OTP_GENERATED_TTL", 24*time.Hour),
+					Name:    env.getEnv("EVENT_OTP_GENERATED_NAME", "otp_generated"),
+					Enabled: env.getEnvAsBool("EVENT_OTP_GENERATED_ENABLED", true),
+					TTL:     env.getEnvAsDuration("EVENT_OTP_GENERATED_TTL", 24*time.Hour),
 				},
 				OTPVerified: EventTypeConfig{
-					Name:    getEnv("EVENT_OTP_VERIFIED_NAME", "otp_verified"),
-					Enabled: getEnvAsBool("EVENT_OTP_VERIFIED_ENABLED", true),
-					TTL:     getEnvAsDuration("EVENT_OTP_VERIFIED_TTL", 24*time.Hour),
+					Name:    env.getEnv("EVENT_OTP_VERIFIED_NAME", "otp_verified"),
+					Enabled: env.getEnvAsBool("EVENT_OTP_VERIFIED_ENABLED", true),
+					TTL:     env.getEnvAsDuration("EVENT_OTP_VERIFIED_TTL", 24*time.Hour),
 				},
 				UserCreated: EventTypeConfig{
-					Name:    getEnv("EVENT_USER_CREATED_NAME", "user_created"),
-					Enabled: getEnvAsBool("EVENT_USER_CREATED_ENABLED", true),
-					TTL:     getEnvAsDuration("EVENT_USER_CREATED_TTL", 7*24*time.Hour),
+					Name:    env.getEnv("EVENT_USER_CREATED_NAME", "user_created"),
+					Enabled: env.getEnvAsBool("EVENT_USER_CREATED_ENABLED", true),
+					TTL:     env.getEnvAsDuration("EVENT_USER_CREATED_TTL", 7*24*time.Hour),
 				},
 				UserLoggedIn: EventTypeConfig{
-					Name:    getEnv("EVENT_USER_LOGGED_IN_NAME", "user_logged_in"),
-					Enabled: getEnvAsBool("EVENT_USER_LOGGED_IN_ENABLED", true),
-					TTL:     getEnvAsDuration("EVENT_USER_LOGGED_IN_TTL", 24*time.Hour),
+					Name:    env.getEnv("EVENT_USER_LOGGED_IN_NAME", "user_logged_in"),
+					Enabled: env.getEnvAsBool("EVENT_USER_LOGGED_IN_ENABLED", true),
+					TTL:     env.getEnvAsDuration("EVENT_USER_LOGGED_IN_TTL", 24*time.Hour),
 				},
 				RateLimited: EventTypeConfig{
-					Name:    getEnv("EVENT_RATE_LIMITED_NAME", "rate_limited"),
-					Enabled: getEnvAsBool("EVENT_RATE_LIMITED_ENABLED", true),
-					TTL:     getEnvAsDuration("EVENT_RATE_LIMITED_TTL", 24*time.Hour),
+					Name:    env.getEnv("EVENT_RATE_LIMITED_NAME", "rate_limited"),
+					Enabled: env.getEnvAsBool("EVENT_RATE_LIMITED_ENABLED", true),
+					TTL:     env.getEnvAsDuration("EVENT_RATE_LIMITED_TTL", 24*time.Hour),
+				},
+				OTPLocked: EventTypeConfig{
+					Name:    env.getEnv("EVENT_OTP_LOCKED_NAME", "otp_locked"),
+					Enabled: env.getEnvAsBool("EVENT_OTP_LOCKED_ENABLED", true),
+					TTL:     env.getEnvAsDuration("EVENT_OTP_LOCKED_TTL", 7*24*time.Hour),
+				},
+				OTPChannelFallback: EventTypeConfig{
+					Name:    env.getEnv("EVENT_OTP_CHANNEL_FALLBACK_NAME", "otp_channel_fallback"),
+					Enabled: env.getEnvAsBool("EVENT_OTP_CHANNEL_FALLBACK_ENABLED", true),
+					TTL:     env.getEnvAsDuration("EVENT_OTP_CHANNEL_FALLBACK_TTL", 24*time.Hour),
+				},
+				OTPTransactionVerified: EventTypeConfig{
+					Name:    env.getEnv("EVENT_OTP_TRANSACTION_VERIFIED_NAME", "otp_transaction_verified"),
+					Enabled: env.getEnvAsBool("EVENT_OTP_TRANSACTION_VERIFIED_ENABLED", true),
+					TTL:     env.getEnvAsDuration("EVENT_OTP_TRANSACTION_VERIFIED_TTL", 30*24*time.Hour),
+				},
+				RecoveryCodeUsed: EventTypeConfig{
+					Name:    env.getEnv("EVENT_RECOVERY_CODE_USED_NAME", "recovery_code_used"),
+					Enabled: env.getEnvAsBool("EVENT_RECOVERY_CODE_USED_ENABLED", true),
+					TTL:     env.getEnvAsDuration("EVENT_RECOVERY_CODE_USED_TTL", 720*time.Hour),
+				},
+				UserAdminAction: EventTypeConfig{
+					Name:    env.getEnv("EVENT_USER_ADMIN_ACTION_NAME", "user_admin_action"),
+					Enabled: env.getEnvAsBool("EVENT_USER_ADMIN_ACTION_ENABLED", true),
+					TTL:     env.getEnvAsDuration("EVENT_USER_ADMIN_ACTION_TTL", 720*time.Hour),
+				},
+				PhoneNumberChanged: EventTypeConfig{
+					Name:    env.getEnv("EVENT_PHONE_NUMBER_CHANGED_NAME", "phone_number_changed"),
+					Enabled: env.getEnvAsBool("EVENT_PHONE_NUMBER_CHANGED_ENABLED", true),
+					TTL:     env.getEnvAsDuration("EVENT_PHONE_NUMBER_CHANGED_TTL", 720*time.Hour),
 				},
 			},
 		},
-		RateLimiting: RateLimitingConfig{
-			Global: RateLimitConfig{
-				Requests: getEnvAsInt("RATE_LIMIT_GLOBAL_REQUESTS", 100),
-				Duration: getEnvAsDuration("RATE_LIMIT_GLOBAL_DURATION", time.Minute),
-				Enabled:  getEnvAsBool("RATE_LIMIT_GLOBAL_ENABLED", true),
-			},
-			Auth: RateLimitConfig{
-				Requests: getEnvAsInt("RATE_LIMIT_AUTH_REQUESTS", 20),
-				Duration: getEnvAsDuration("RATE_LIMIT_AUTH_DURATION", time.Minute),
-				Enabled:  getEnvAsBool("RATE_LIMIT_AUTH_ENABLED", true),
-			},
-			OTP: RateLimitConfig{
-				Requests: getEnvAsInt("RATE_LIMIT_OTP_REQUESTS", 3),
-				Duration: getEnvAsDuration("RATE_LIMIT_OTP_DURATION", 10*time.Minute),
-				Enabled:  getEnvAsBool("RATE_LIMIT_OTP_ENABLED", true),
-			},
-			User: RateLimitConfig{
-				Requests: getEnvAsInt("RATE_LIMIT_USER_REQUESTS", 50),
-				Duration: getEnvAsDuration("RATE_LIMIT_USER_DURATION", time.Minute),
-				Enabled:  getEnvAsBool("RATE_LIMIT_USER_ENABLED", true),
-			},
+		RateLimiting: loadRateLimitingConfig(env),
+		Tenants: TenantsConfig{
+			Header:         env.getEnv("TENANT_HEADER", "X-Tenant"),
+			ReloadInterval: env.getEnvAsDuration("TENANT_RELOAD_INTERVAL", time.Minute),
+		},
+		RBAC: RBACConfig{
+			ReloadInterval: env.getEnvAsDuration("RBAC_RELOAD_INTERVAL", time.Minute),
 		},
 	}
 
+	// Placeholder secrets are public, so only development may run with them
+	if config.Server.Environment != "development" {
+		if err := CheckSecret("OTP_HASH_SECRET", config.OTP.HashSecret); err != nil {
+			return nil, err
+		}
+		if err := CheckSecret("MFA_ENCRYPTION_KEY", config.MFA.EncryptionKey); err != nil {
+			return nil, err
+		}
+	}
+
 	return config, nil
 }
 
-func getEnvAsSlice(key string, defaultValue []string) []string {
-	if value := os.Getenv(key); value != "" {
+// MinSecretLength is the shortest secret accepted outside development
+const MinSecretLength = 32
+
+const (
+	otpHashSecretPlaceholder    = "your-otp-hash-pepper-change-in-production"
+	mfaEncryptionKeyPlaceholder = "your-mfa-encryption-key-change-in-production"
+)
+
+// secretPlaceholders are the defaults of secrets, which must be replaced
+var secretPlaceholders = map[string]string{
+	"OTP_HASH_SECRET":    otpHashSecretPlaceholder,
+	"MFA_ENCRYPTION_KEY": mfaEncryptionKeyPlaceholder,
+}
+
+// CheckSecret rejects a secret that was left at its placeholder, is empty
+// or is shorter than MinSecretLength
+func CheckSecret(name, value string) error {
+	switch {
+	case value == "" || value == secretPlaceholders[name]:
+		return fmt.Errorf("%s must be set", name)
+	case len(value) < MinSecretLength:
+		return fmt.Errorf("%s must be at least %d bytes long", name, MinSecretLength)
+	}
+	return nil
+}
+
+// ForTenant returns the OTP, message template and rate limiting settings of
+// a tenant. Its overrides are keyed by environment variable name and take
+// precedence over the environment, with the same parsing and defaults.
+func ForTenant(overrides map[string]string) *TenantConfig {
+	env := envSource(func(key string) string {
+		if value, ok := overrides[key]; ok {
+			return value
+		}
+		return os.Getenv(key)
+	})
+
+	return &TenantConfig{
+		OTP:          loadOTPConfig(env),
+		Templates:    loadTemplateConfig(env),
+		RateLimiting: loadRateLimitingConfig(env),
+	}
+}
+
+// IsTenantSetting checks if a tenant may override the environment variable:
+// the OTP_*, RATE_LIMIT_* and message template settings
+func IsTenantSetting(key string) bool {
+	return strings.HasPrefix(key, "OTP_") || strings.HasPrefix(key, "RATE_LIMIT_") || templateSettings[key]
+}
+
+// loadOTPConfig reads the OTP settings
+func loadOTPConfig(env envSource) OTPConfig {
+	return OTPConfig{
+		Expiry:         env.getEnvAsDuration("OTP_EXPIRY", 2*time.Minute),
+		Length:         env.getEnvAsInt("OTP_LENGTH", 6),
+		RedisKeyPrefix: env.getEnv("OTP_REDIS_KEY_PREFIX", "otp"),
+		CodeCharset:    env.getEnv("OTP_CODE_CHARSET", "0123456789"),
+
+		PurposeExpiry: env.getEnvAsDurationMap("OTP_PURPOSE_EXPIRY", map[string]time.Duration{
+			"phone_change":     5 * time.Minute,
+			"account_deletion": 5 * time.Minute,
+			"transaction":      5 * time.Minute,
+		}),
+		PurposeLength: env.getEnvAsIntMap("OTP_PURPOSE_LENGTH", map[string]int{}),
+
+		ChallengeRetention: env.getEnvAsDuration("OTP_CHALLENGE_RETENTION", 10*time.Minute),
+
+		PhoneChangeVerifyOldNumber: env.getEnvAsBool("OTP_PHONE_CHANGE_VERIFY_OLD_NUMBER", true),
+
+		ResendInterval:    env.getEnvAsDuration("OTP_RESEND_INTERVAL", 30*time.Second),
+		MaxResends:        env.getEnvAsInt("OTP_MAX_RESENDS", 3),
+		ResendReuseWindow: env.getEnvAsDuration("OTP_RESEND_REUSE_WINDOW", time.Minute),
+
+		FallbackChains:  env.getEnvAsListMap("OTP_FALLBACK_CHAINS", map[string][]string{"default": {"sms", "voice", "whatsapp", "email"}}),
+		FallbackTimeout: env.getEnvAsDuration("OTP_FALLBACK_TIMEOUT", 30*time.Second),
+
+		HashSecret:         env.getEnv("OTP_HASH_SECRET", otpHashSecretPlaceholder),
+		PreviousHashSecret: env.getEnv("OTP_PREVIOUS_HASH_SECRET", ""),
+
+		MaxAttempts:          env.getEnvAsInt("OTP_MAX_ATTEMPTS", 5),
+		LockoutDuration:      env.getEnvAsDuration("OTP_LOCKOUT_DURATION", 15*time.Minute),
+		LockoutBackoffFactor: env.getEnvAsFloat("OTP_LOCKOUT_BACKOFF_FACTOR", 2.0),
+		MaxLockoutDuration:   env.getEnvAsDuration("OTP_MAX_LOCKOUT_DURATION", 24*time.Hour),
+		LockoutHistoryTTL:    env.getEnvAsDuration("OTP_LOCKOUT_HISTORY_TTL", 24*time.Hour),
+	}
+}
+
+// loadTemplateConfig reads the message template settings
+func loadTemplateConfig(env envSource) TemplateConfig {
+	return TemplateConfig{
+		Dir:            env.getEnv("DELIVERY_TEMPLATES_DIR", "./templates/otp"),
+		DefaultLocale:  env.getEnv("DELIVERY_DEFAULT_LOCALE", "en"),
+		AppName:        env.getEnv("DELIVERY_APP_NAME", "OTP Server"),
+		AndroidAppHash: env.getEnv("DELIVERY_ANDROID_APP_HASH", ""),
+		WebOTPDomain:   env.getEnv("DELIVERY_WEBOTP_DOMAIN", ""),
+	}
+}
+
+// loadRateLimitingConfig reads the rate limiting settings
+func loadRateLimitingConfig(env envSource) RateLimitingConfig {
+	return RateLimitingConfig{
+		Global: RateLimitConfig{
+			Requests: env.getEnvAsInt("RATE_LIMIT_GLOBAL_REQUESTS", 100),
+			Duration: env.getEnvAsDuration("RATE_LIMIT_GLOBAL_DURATION", time.Minute),
+			Enabled:  env.getEnvAsBool("RATE_LIMIT_GLOBAL_ENABLED", true),
+		},
+		Auth: RateLimitConfig{
+			Requests: env.getEnvAsInt("RATE_LIMIT_AUTH_REQUESTS", 20),
+			Duration: env.getEnvAsDuration("RATE_LIMIT_AUTH_DURATION", time.Minute),
+			Enabled:  env.getEnvAsBool("RATE_LIMIT_AUTH_ENABLED", true),
+		},
+		OTP: RateLimitConfig{
+			Requests: env.getEnvAsInt("RATE_LIMIT_OTP_REQUESTS", 3),
+			Duration: env.getEnvAsDuration("RATE_LIMIT_OTP_DURATION", 10*time.Minute),
+			Enabled:  env.getEnvAsBool("RATE_LIMIT_OTP_ENABLED", true),
+		},
+		User: RateLimitConfig{
+			Requests: env.getEnvAsInt("RATE_LIMIT_USER_REQUESTS", 50),
+			Duration: env.getEnvAsDuration("RATE_LIMIT_USER_DURATION", time.Minute),
+			Enabled:  env.getEnvAsBool("RATE_LIMIT_USER_ENABLED", true),
+		},
+		Client: RateLimitConfig{
+			Requests: env.getEnvAsInt("RATE_LIMIT_CLIENT_REQUESTS", 300),
+			Duration: env.getEnvAsDuration("RATE_LIMIT_CLIENT_DURATION", time.Minute),
+			Enabled:  env.getEnvAsBool("RATE_LIMIT_CLIENT_ENABLED", true),
+		},
+	}
+}
+
+// envSource looks up settings by environment variable name
+type envSource func(key string) string
+
+func (e envSource) getEnvAsSlice(key string, defaultValue []string) []string {
+	if value := e(key); value != "" {
 		parts := strings.Split(value, ",")
 		result := make([]string, 0, len(parts))
 		for _, p := range parts {
@@ -331,16 +739,85 @@ func getEnvAsSlice(key string, defaultValue []string) []string {
 	return defaultValue
 }
 
-// Helper functions to get environment variables with defaults
-func getEnv(key, defaultValue string) string {
-	if value := os.Getenv(key); value != "" {
+// getEnvAsDurationMap parses "key=duration;key=duration" pairs
+func (e envSource) getEnvAsDurationMap(key string, defaultValue map[string]time.Duration) map[string]time.Duration {
+	pairs := e.getEnvAsMap(key, nil)
+	if pairs == nil {
+		return defaultValue
+	}
+
+	result := make(map[string]time.Duration, len(pairs))
+	for k, v := range pairs {
+		if duration, err := time.ParseDuration(v); err == nil {
+			result[k] = duration
+		}
+	}
+	return result
+}
+
+// getEnvAsIntMap parses "key=int;key=int" pairs
+func (e envSource) getEnvAsIntMap(key string, defaultValue map[string]int) map[string]int {
+	pairs := e.getEnvAsMap(key, nil)
+	if pairs == nil {
+		return defaultValue
+	}
+
+	result := make(map[string]int, len(pairs))
+	for k, v := range pairs {
+		if n, err := strconv.Atoi(v); err == nil {
+			result[k] = n
+		}
+	}
+	return result
+}
+
+// getEnvAsMap parses "key=value;key=value" pairs
+func (e envSource) getEnvAsMap(key string, defaultValue map[string]string) map[string]string {
+	value := e(key)
+	if value == "" {
+		return defaultValue
+	}
+
+	result := make(map[string]string)
+	for _, pair := range strings.Split(value, ";") {
+		k, v, found := strings.Cut(pair, "=")
+		if !found || strings.TrimSpace(k) == "" {
+			continue
+		}
+		result[strings.TrimSpace(k)] = strings.TrimSpace(v)
+	}
+	return result
+}
+
+// getEnvAsListMap parses "key=a,b,c;key=d,e" pairs
+func (e envSource) getEnvAsListMap(key string, defaultValue map[string][]string) map[string][]string {
+	pairs := e.getEnvAsMap(key, nil)
+	if pairs == nil {
+		return defaultValue
+	}
+
+	result := make(map[string][]string, len(pairs))
+	for k, v := range pairs {
+		var items []string
+		for _, item := range strings.Split(v, ",") {
+			if trimmed := strings.TrimSpace(item); trimmed != "" {
+				items = append(items, trimmed)
+			}
+		}
+		result[k] = items
+	}
+	return result
+}
+
+func (e envSource) getEnv(key, defaultValue string) string {
+	if value := e(key); value != "" {
 		return value
 	}
 	return defaultValue
 }
 
-func getEnvAsInt(key string, defaultValue int) int {
-	if value := os.Getenv(key); value != "" {
+func (e envSource) getEnvAsInt(key string, defaultValue int) int {
+	if value := e(key); value != "" {
 		if intValue, err := strconv.Atoi(value); err == nil {
 			return intValue
 		}
@@ -348,8 +825,8 @@ func getEnvAsInt(key string, defaultValue int) int {
 	return defaultValue
 }
 
-func getEnvAsBool(key string, defaultValue bool) bool {
-	if value := os.Getenv(key); value != "" {
+func (e envSource) getEnvAsBool(key string, defaultValue bool) bool {
+	if value := e(key); value != "" {
 		if boolValue, err := strconv.ParseBool(value); err == nil {
 			return boolValue
 		}
@@ -357,8 +834,17 @@ func getEnvAsBool(key string, defaultValue bool) bool {
 	return defaultValue
 }
 
-func getEnvAsDuration(key string, defaultValue time.Duration) time.Duration {
-	if value := os.Getenv(key); value != "" {
+func (e envSource) getEnvAsFloat(key string, defaultValue float64) float64 {
+	if value := e(key); value != "" {
+		if floatValue, err := strconv.ParseFloat(value, 64); err == nil {
+			return floatValue
+		}
+	}
+	return defaultValue
+}
+
+func (e envSource) getEnvAsDuration(key string, defaultValue time.Duration) time.Duration {
+	if value := e(key); value != "" {
 		if duration, err := time.ParseDuration(value); err == nil {
 			return duration
 		}
@@ -0,0 +1,164 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+
+	"otp-server/internal/domain/entities"
+	"otp-server/internal/domain/errors"
+	"otp-server/internal/domain/repositories"
+
+	"github.com/lib/pq"
+)
+
+// APIClientRepository implements the APIClientRepository interface using PostgreSQL
+type APIClientRepository struct {
+	db *sql.DB
+}
+
+// NewAPIClientRepository creates a new API client repository
+func NewAPIClientRepository(pool *PostgresPool) repositories.APIClientRepository {
+	return &APIClientRepository{
+		db: pool.db,
+	}
+}
+
+// Create creates a new client
+func (r *APIClientRepository) Create(ctx context.Context, client *entities.APIClient) error {
+	query := `
+		INSERT INTO api_clients (tenant_id, client_id, secret_hash, name, scopes, rate_limit, is_active)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING id, created_at, updated_at
+	`
+
+	err := r.db.QueryRowContext(ctx, query,
+		client.TenantID,
+		client.ClientID,
+		client.SecretHash,
+		client.Name,
+		pq.Array(client.Scopes),
+		sql.NullInt64{Int64: int64(client.RateLimit), Valid: client.RateLimit > 0},
+		client.IsActive,
+	).Scan(&client.ID, &client.CreatedAt, &client.UpdatedAt)
+
+	if err != nil {
+		if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == "23505" {
+			return errors.NewAlreadyExists("client").WithError(err)
+		}
+		return errors.NewDatabaseError("create client", err)
+	}
+
+	return nil
+}
+
+// GetByClientID retrieves a client by its client ID
+func (r *APIClientRepository) GetByClientID(ctx context.Context, clientID string) (*entities.APIClient, error) {
+	query := `
+		SELECT id, tenant_id, client_id, secret_hash, name, scopes, rate_limit, is_active, created_at, updated_at
+		FROM api_clients WHERE client_id = $1
+	`
+
+	client, err := scanAPIClient(r.db.QueryRowContext(ctx, query, clientID))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errors.NewNotFound("client")
+		}
+		return nil, errors.NewDatabaseError("get client", err)
+	}
+
+	return client, nil
+}
+
+// GetBySecretHash retrieves a client by the hash of its secret
+func (r *APIClientRepository) GetBySecretHash(ctx context.Context, secretHash string) (*entities.APIClient, error) {
+	query := `
+		SELECT id, tenant_id, client_id, secret_hash, name, scopes, rate_limit, is_active, created_at, updated_at
+		FROM api_clients WHERE secret_hash = $1
+	`
+
+	client, err := scanAPIClient(r.db.QueryRowContext(ctx, query, secretHash))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errors.NewNotFound("client")
+		}
+		return nil, errors.NewDatabaseError("get client", err)
+	}
+
+	return client, nil
+}
+
+// List retrieves every client of the tenant
+func (r *APIClientRepository) List(ctx context.Context, tenantID int) ([]*entities.APIClient, error) {
+	query := `
+		SELECT id, tenant_id, client_id, secret_hash, name, scopes, rate_limit, is_active, created_at, updated_at
+		FROM api_clients
+		WHERE tenant_id = $1
+		ORDER BY created_at
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, tenantID)
+	if err != nil {
+		return nil, errors.NewDatabaseError("list clients", err)
+	}
+	defer rows.Close()
+
+	var clients []*entities.APIClient
+	for rows.Next() {
+		client, err := scanAPIClient(rows)
+		if err != nil {
+			return nil, errors.NewDatabaseError("scan client", err)
+		}
+		clients = append(clients, client)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, errors.NewDatabaseError("iterate clients", err)
+	}
+
+	return clients, nil
+}
+
+// Deactivate stops a client of the tenant from calling the API
+func (r *APIClientRepository) Deactivate(ctx context.Context, tenantID int, clientID string) error {
+	query := `UPDATE api_clients SET is_active = false WHERE tenant_id = $1 AND client_id = $2`
+
+	result, err := r.db.ExecContext(ctx, query, tenantID, clientID)
+	if err != nil {
+		return errors.NewDatabaseError("deactivate client", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return errors.NewDatabaseError("get rows affected", err)
+	}
+
+	if rowsAffected == 0 {
+		return errors.NewNotFound("client")
+	}
+
+	return nil
+}
+
+func scanAPIClient(row rowScanner) (*entities.APIClient, error) {
+	var client entities.APIClient
+	var rateLimit sql.NullInt64
+	err := row.Scan(
+		&client.ID,
+		&client.TenantID,
+		&client.ClientID,
+		&client.SecretHash,
+		&client.Name,
+		pq.Array(&client.Scopes),
+		&rateLimit,
+		&client.IsActive,
+		&client.CreatedAt,
+		&client.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	client.RateLimit = int(rateLimit.Int64)
+
+	return &client, nil
+}
@@ -0,0 +1,145 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+
+	"otp-server/internal/domain/entities"
+	"otp-server/internal/domain/errors"
+	"otp-server/internal/domain/repositories"
+
+	"github.com/lib/pq"
+)
+
+// OAuthClientRepository implements the OAuthClientRepository interface using PostgreSQL
+type OAuthClientRepository struct {
+	db *sql.DB
+}
+
+// NewOAuthClientRepository creates a new OpenID Connect client repository
+func NewOAuthClientRepository(pool *PostgresPool) repositories.OAuthClientRepository {
+	return &OAuthClientRepository{
+		db: pool.db,
+	}
+}
+
+// Create creates a new client
+func (r *OAuthClientRepository) Create(ctx context.Context, client *entities.OAuthClient) error {
+	query := `
+		INSERT INTO oauth_clients (client_id, client_secret_hash, name, redirect_uris, scopes, is_active, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING id
+	`
+
+	err := r.db.QueryRowContext(ctx, query,
+		client.ClientID,
+		sql.NullString{String: client.SecretHash, Valid: client.SecretHash != ""},
+		client.Name,
+		pq.Array(client.RedirectURIs),
+		pq.Array(client.Scopes),
+		client.IsActive,
+		client.CreatedAt,
+		client.UpdatedAt,
+	).Scan(&client.ID)
+
+	if err != nil {
+		if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == "23505" {
+			return errors.NewAlreadyExists("client").WithError(err)
+		}
+		return errors.NewDatabaseError("create client", err)
+	}
+
+	return nil
+}
+
+// GetByClientID retrieves a client by its client ID
+func (r *OAuthClientRepository) GetByClientID(ctx context.Context, clientID string) (*entities.OAuthClient, error) {
+	query := `
+		SELECT id, client_id, client_secret_hash, name, redirect_uris, scopes, is_active, created_at, updated_at
+		FROM oauth_clients WHERE client_id = $1
+	`
+
+	client, err := scanOAuthClient(r.db.QueryRowContext(ctx, query, clientID))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errors.NewNotFound("client")
+		}
+		return nil, errors.NewDatabaseError("get client", err)
+	}
+
+	return client, nil
+}
+
+// List retrieves every client
+func (r *OAuthClientRepository) List(ctx context.Context) ([]*entities.OAuthClient, error) {
+	query := `
+		SELECT id, client_id, client_secret_hash, name, redirect_uris, scopes, is_active, created_at, updated_at
+		FROM oauth_clients
+		ORDER BY created_at
+	`
+
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, errors.NewDatabaseError("list clients", err)
+	}
+	defer rows.Close()
+
+	var clients []*entities.OAuthClient
+	for rows.Next() {
+		client, err := scanOAuthClient(rows)
+		if err != nil {
+			return nil, errors.NewDatabaseError("scan client", err)
+		}
+		clients = append(clients, client)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, errors.NewDatabaseError("iterate clients", err)
+	}
+
+	return clients, nil
+}
+
+// Deactivate stops a client from logging users in
+func (r *OAuthClientRepository) Deactivate(ctx context.Context, clientID string) error {
+	query := `UPDATE oauth_clients SET is_active = false WHERE client_id = $1`
+
+	result, err := r.db.ExecContext(ctx, query, clientID)
+	if err != nil {
+		return errors.NewDatabaseError("deactivate client", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return errors.NewDatabaseError("get rows affected", err)
+	}
+
+	if rowsAffected == 0 {
+		return errors.NewNotFound("client")
+	}
+
+	return nil
+}
+
+func scanOAuthClient(row rowScanner) (*entities.OAuthClient, error) {
+	var client entities.OAuthClient
+	var secretHash sql.NullString
+	err := row.Scan(
+		&client.ID,
+		&client.ClientID,
+		&secretHash,
+		&client.Name,
+		pq.Array(&client.RedirectURIs),
+		pq.Array(&client.Scopes),
+		&client.IsActive,
+		&client.CreatedAt,
+		&client.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	client.SecretHash = secretHash.String
+
+	return &client, nil
+}
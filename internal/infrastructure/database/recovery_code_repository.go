@@ -0,0 +1,83 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+
+	"otp-server/internal/domain/errors"
+	"otp-server/internal/domain/repositories"
+)
+
+// RecoveryCodeRepository implements the RecoveryCodeRepository interface using PostgreSQL
+type RecoveryCodeRepository struct {
+	db *sql.DB
+}
+
+// NewRecoveryCodeRepository creates a new recovery code repository
+func NewRecoveryCodeRepository(pool *PostgresPool) repositories.RecoveryCodeRepository {
+	return &RecoveryCodeRepository{
+		db: pool.db,
+	}
+}
+
+// Replace replaces all of the user's recovery codes with the given set in
+// one transaction, so the old set stops working exactly when the new one
+// starts
+func (r *RecoveryCodeRepository) Replace(ctx context.Context, userID int, codeHashes []string) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return errors.NewDatabaseError("begin transaction", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM recovery_codes WHERE user_id = $1`, userID); err != nil {
+		return errors.NewDatabaseError("delete recovery codes", err)
+	}
+
+	for _, codeHash := range codeHashes {
+		if _, err := tx.ExecContext(ctx, `INSERT INTO recovery_codes (user_id, code_hash) VALUES ($1, $2)`, userID, codeHash); err != nil {
+			return errors.NewDatabaseError("create recovery code", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return errors.NewDatabaseError("commit transaction", err)
+	}
+
+	return nil
+}
+
+// Use marks the user's unused code with the given hash as used. The check
+// happens in the UPDATE itself, so of two concurrent requests with the same
+// code only one succeeds.
+func (r *RecoveryCodeRepository) Use(ctx context.Context, userID int, codeHash string) (bool, error) {
+	query := `
+		UPDATE recovery_codes
+		SET used_at = NOW()
+		WHERE user_id = $1 AND code_hash = $2 AND used_at IS NULL
+	`
+
+	result, err := r.db.ExecContext(ctx, query, userID, codeHash)
+	if err != nil {
+		return false, errors.NewDatabaseError("use recovery code", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, errors.NewDatabaseError("get rows affected", err)
+	}
+
+	return rowsAffected == 1, nil
+}
+
+// CountUnused retrieves the number of codes the user has left
+func (r *RecoveryCodeRepository) CountUnused(ctx context.Context, userID int) (int, error) {
+	query := `SELECT COUNT(*) FROM recovery_codes WHERE user_id = $1 AND used_at IS NULL`
+
+	var count int
+	if err := r.db.QueryRowContext(ctx, query, userID).Scan(&count); err != nil {
+		return 0, errors.NewDatabaseError("count recovery codes", err)
+	}
+
+	return count, nil
+}
@@ -0,0 +1,127 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+
+	"otp-server/internal/domain/entities"
+	"otp-server/internal/domain/errors"
+	"otp-server/internal/domain/repositories"
+)
+
+// RefreshTokenRepository implements the RefreshTokenRepository interface using PostgreSQL
+type RefreshTokenRepository struct {
+	db *sql.DB
+}
+
+// NewRefreshTokenRepository creates a new refresh token repository
+func NewRefreshTokenRepository(pool *PostgresPool) repositories.RefreshTokenRepository {
+	return &RefreshTokenRepository{
+		db: pool.db,
+	}
+}
+
+// Create creates a new refresh token
+func (r *RefreshTokenRepository) Create(ctx context.Context, token *entities.RefreshToken) error {
+	query := `
+		INSERT INTO refresh_tokens (user_id, family_id, token_hash, expires_at, created_at)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id
+	`
+
+	err := r.db.QueryRowContext(ctx, query,
+		token.UserID,
+		token.FamilyID,
+		token.TokenHash,
+		token.ExpiresAt,
+		token.CreatedAt,
+	).Scan(&token.ID)
+
+	if err != nil {
+		return errors.NewDatabaseError("create refresh token", err)
+	}
+
+	return nil
+}
+
+// GetByHash retrieves a refresh token by the hash of the token
+func (r *RefreshTokenRepository) GetByHash(ctx context.Context, tokenHash string) (*entities.RefreshToken, error) {
+	query := `
+		SELECT id, user_id, family_id, token_hash, expires_at, rotated_at, revoked_at, created_at
+		FROM refresh_tokens WHERE token_hash = $1
+	`
+
+	var token entities.RefreshToken
+	var rotatedAt, revokedAt sql.NullTime
+	err := r.db.QueryRowContext(ctx, query, tokenHash).Scan(
+		&token.ID,
+		&token.UserID,
+		&token.FamilyID,
+		&token.TokenHash,
+		&token.ExpiresAt,
+		&rotatedAt,
+		&revokedAt,
+		&token.CreatedAt,
+	)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errors.NewNotFound("refresh token")
+		}
+		return nil, errors.NewDatabaseError("get refresh token", err)
+	}
+
+	if rotatedAt.Valid {
+		token.RotatedAt = &rotatedAt.Time
+	}
+	if revokedAt.Valid {
+		token.RevokedAt = &revokedAt.Time
+	}
+
+	return &token, nil
+}
+
+// Rotate marks the token as exchanged for its successor. The check happens
+// in the UPDATE itself, so of two concurrent requests with the same token
+// only one succeeds.
+func (r *RefreshTokenRepository) Rotate(ctx context.Context, id int) (bool, error) {
+	query := `
+		UPDATE refresh_tokens
+		SET rotated_at = NOW()
+		WHERE id = $1 AND rotated_at IS NULL AND revoked_at IS NULL
+	`
+
+	result, err := r.db.ExecContext(ctx, query, id)
+	if err != nil {
+		return false, errors.NewDatabaseError("rotate refresh token", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, errors.NewDatabaseError("get rows affected", err)
+	}
+
+	return rowsAffected == 1, nil
+}
+
+// RevokeFamily revokes every token of the family
+func (r *RefreshTokenRepository) RevokeFamily(ctx context.Context, familyID string) error {
+	query := `UPDATE refresh_tokens SET revoked_at = NOW() WHERE family_id = $1 AND revoked_at IS NULL`
+
+	if _, err := r.db.ExecContext(ctx, query, familyID); err != nil {
+		return errors.NewDatabaseError("revoke refresh token family", err)
+	}
+
+	return nil
+}
+
+// RevokeUser revokes every token of the user
+func (r *RefreshTokenRepository) RevokeUser(ctx context.Context, userID int) error {
+	query := `UPDATE refresh_tokens SET revoked_at = NOW() WHERE user_id = $1 AND revoked_at IS NULL`
+
+	if _, err := r.db.ExecContext(ctx, query, userID); err != nil {
+		return errors.NewDatabaseError("revoke user refresh tokens", err)
+	}
+
+	return nil
+}
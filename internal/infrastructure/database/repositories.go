@@ -6,15 +6,31 @@ import (
 
 // Repositories holds all repository interfaces
 type Repositories struct {
-	UserRepository      repositories.UserRepository
-	UserCacheRepository repositories.UserCacheRepository
+	UserRepository         repositories.UserRepository
+	UserCacheRepository    repositories.UserCacheRepository
+	UserFactorRepository   repositories.UserFactorRepository
+	RecoveryCodeRepository repositories.RecoveryCodeRepository
+	RefreshTokenRepository repositories.RefreshTokenRepository
+	SessionRepository      repositories.SessionRepository
+	OAuthClientRepository  repositories.OAuthClientRepository
+	APIClientRepository    repositories.APIClientRepository
+	TenantRepository       repositories.TenantRepository
+	RoleRepository         repositories.RoleRepository
 }
 
 // NewRepositories creates a new repositories instance
 func NewRepositories(postgresPool *PostgresPool, redisClient interface{}) *Repositories {
 	return &Repositories{
-		UserRepository:      NewUserRepository(postgresPool),
-		UserCacheRepository: nil,
+		UserRepository:         NewUserRepository(postgresPool),
+		UserCacheRepository:    nil,
+		UserFactorRepository:   NewUserFactorRepository(postgresPool),
+		RecoveryCodeRepository: NewRecoveryCodeRepository(postgresPool),
+		RefreshTokenRepository: NewRefreshTokenRepository(postgresPool),
+		SessionRepository:      NewSessionRepository(postgresPool),
+		OAuthClientRepository:  NewOAuthClientRepository(postgresPool),
+		APIClientRepository:    NewAPIClientRepository(postgresPool),
+		TenantRepository:       NewTenantRepository(postgresPool),
+		RoleRepository:         NewRoleRepository(postgresPool),
 	}
 }
 
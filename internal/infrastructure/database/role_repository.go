@@ -0,0 +1,144 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+
+	"otp-server/internal/domain/entities"
+	"otp-server/internal/domain/errors"
+	"otp-server/internal/domain/repositories"
+
+	"github.com/lib/pq"
+)
+
+// RoleRepository implements the RoleRepository interface using PostgreSQL
+type RoleRepository struct {
+	db *sql.DB
+}
+
+// NewRoleRepository creates a new role repository
+func NewRoleRepository(pool *PostgresPool) repositories.RoleRepository {
+	return &RoleRepository{
+		db: pool.db,
+	}
+}
+
+// List retrieves every role with its permissions
+func (r *RoleRepository) List(ctx context.Context) ([]*entities.Role, error) {
+	query := `
+		SELECT r.id, r.name, r.description,
+			COALESCE(array_agg(p.name ORDER BY p.name) FILTER (WHERE p.name IS NOT NULL), '{}'),
+			r.created_at, r.updated_at
+		FROM roles r
+		LEFT JOIN role_permissions rp ON rp.role_id = r.id
+		LEFT JOIN permissions p ON p.id = rp.permission_id
+		GROUP BY r.id
+		ORDER BY r.id
+	`
+
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, errors.NewDatabaseError("list roles", err)
+	}
+	defer rows.Close()
+
+	var roles []*entities.Role
+	for rows.Next() {
+		var role entities.Role
+		err := rows.Scan(
+			&role.ID,
+			&role.Name,
+			&role.Description,
+			pq.Array(&role.Permissions),
+			&role.CreatedAt,
+			&role.UpdatedAt,
+		)
+		if err != nil {
+			return nil, errors.NewDatabaseError("scan role", err)
+		}
+		roles = append(roles, &role)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, errors.NewDatabaseError("iterate roles", err)
+	}
+
+	return roles, nil
+}
+
+// ListPermissions retrieves every permission
+func (r *RoleRepository) ListPermissions(ctx context.Context) ([]*entities.Permission, error) {
+	query := `SELECT id, name, description FROM permissions ORDER BY name`
+
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, errors.NewDatabaseError("list permissions", err)
+	}
+	defer rows.Close()
+
+	var permissions []*entities.Permission
+	for rows.Next() {
+		var permission entities.Permission
+		if err := rows.Scan(&permission.ID, &permission.Name, &permission.Description); err != nil {
+			return nil, errors.NewDatabaseError("scan permission", err)
+		}
+		permissions = append(permissions, &permission)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, errors.NewDatabaseError("iterate permissions", err)
+	}
+
+	return permissions, nil
+}
+
+// Save creates the role or updates the role of the same name, replacing its
+// permissions in one transaction so users never see a partial set.
+// Permissions that do not exist are ignored.
+func (r *RoleRepository) Save(ctx context.Context, role *entities.Role) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return errors.NewDatabaseError("begin transaction", err)
+	}
+	defer tx.Rollback()
+
+	query := `
+		INSERT INTO roles (name, description)
+		VALUES ($1, $2)
+		ON CONFLICT (name) DO UPDATE SET description = EXCLUDED.description
+		RETURNING id, created_at, updated_at
+	`
+
+	err = tx.QueryRowContext(ctx, query, role.Name, role.Description).Scan(&role.ID, &role.CreatedAt, &role.UpdatedAt)
+	if err != nil {
+		return errors.NewDatabaseError("save role", err)
+	}
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM role_permissions WHERE role_id = $1`, role.ID); err != nil {
+		return errors.NewDatabaseError("delete role permissions", err)
+	}
+
+	query = `
+		INSERT INTO role_permissions (role_id, permission_id)
+		SELECT $1, id FROM permissions WHERE name = ANY($2)
+	`
+
+	if _, err := tx.ExecContext(ctx, query, role.ID, pq.Array(rolePermissions(role))); err != nil {
+		return errors.NewDatabaseError("create role permissions", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return errors.NewDatabaseError("commit transaction", err)
+	}
+
+	return nil
+}
+
+// rolePermissions returns the role's permissions, never nil since pq.Array
+// writes nil as NULL
+func rolePermissions(role *entities.Role) []string {
+	if role.Permissions == nil {
+		return []string{}
+	}
+	return role.Permissions
+}
@@ -0,0 +1,190 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"otp-server/internal/domain/entities"
+	"otp-server/internal/domain/errors"
+	"otp-server/internal/domain/repositories"
+
+	"github.com/lib/pq"
+)
+
+// SessionRepository implements the SessionRepository interface using PostgreSQL
+type SessionRepository struct {
+	db *sql.DB
+}
+
+// NewSessionRepository creates a new session repository
+func NewSessionRepository(pool *PostgresPool) repositories.SessionRepository {
+	return &SessionRepository{
+		db: pool.db,
+	}
+}
+
+// Create creates a new session
+func (r *SessionRepository) Create(ctx context.Context, session *entities.Session) error {
+	authMethods := session.AuthMethods
+	if authMethods == nil {
+		authMethods = []string{}
+	}
+
+	query := `
+		INSERT INTO sessions (id, user_id, device_name, user_agent, ip_address, created_at, last_used_at, expires_at, client_id, scope, auth_time, auth_methods)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+	`
+
+	_, err := r.db.ExecContext(ctx, query,
+		session.ID,
+		session.UserID,
+		session.DeviceName,
+		session.UserAgent,
+		session.IPAddress,
+		session.CreatedAt,
+		session.LastUsedAt,
+		session.ExpiresAt,
+		sql.NullString{String: session.ClientID, Valid: session.ClientID != ""},
+		sql.NullString{String: session.Scope, Valid: session.Scope != ""},
+		session.AuthTime,
+		pq.Array(authMethods),
+	)
+
+	if err != nil {
+		return errors.NewDatabaseError("create session", err)
+	}
+
+	return nil
+}
+
+// GetByID retrieves a session by its ID
+func (r *SessionRepository) GetByID(ctx context.Context, id string) (*entities.Session, error) {
+	query := `
+		SELECT id, user_id, device_name, user_agent, ip_address, created_at, last_used_at, expires_at, revoked_at, client_id, scope, auth_time, auth_methods
+		FROM sessions WHERE id = $1
+	`
+
+	session, err := scanSession(r.db.QueryRowContext(ctx, query, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errors.NewNotFound("session")
+		}
+		return nil, errors.NewDatabaseError("get session", err)
+	}
+
+	return session, nil
+}
+
+// ListActive retrieves the user's sessions that are neither revoked nor
+// expired, newest first
+func (r *SessionRepository) ListActive(ctx context.Context, userID int) ([]*entities.Session, error) {
+	query := `
+		SELECT id, user_id, device_name, user_agent, ip_address, created_at, last_used_at, expires_at, revoked_at, client_id, scope, auth_time, auth_methods
+		FROM sessions
+		WHERE user_id = $1 AND revoked_at IS NULL AND expires_at > NOW()
+		ORDER BY created_at DESC
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, errors.NewDatabaseError("list sessions", err)
+	}
+	defer rows.Close()
+
+	var sessions []*entities.Session
+	for rows.Next() {
+		session, err := scanSession(rows)
+		if err != nil {
+			return nil, errors.NewDatabaseError("scan session", err)
+		}
+		sessions = append(sessions, session)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, errors.NewDatabaseError("iterate sessions", err)
+	}
+
+	return sessions, nil
+}
+
+// Touch records that the session was used and extends it until expiresAt
+func (r *SessionRepository) Touch(ctx context.Context, id string, expiresAt time.Time) error {
+	query := `UPDATE sessions SET last_used_at = NOW(), expires_at = $2 WHERE id = $1 AND revoked_at IS NULL`
+
+	if _, err := r.db.ExecContext(ctx, query, id, expiresAt); err != nil {
+		return errors.NewDatabaseError("touch session", err)
+	}
+
+	return nil
+}
+
+// Revoke revokes one active session of the user
+func (r *SessionRepository) Revoke(ctx context.Context, userID int, id string) error {
+	query := `
+		UPDATE sessions SET revoked_at = NOW()
+		WHERE id = $1 AND user_id = $2 AND revoked_at IS NULL AND expires_at > NOW()
+	`
+
+	result, err := r.db.ExecContext(ctx, query, id, userID)
+	if err != nil {
+		return errors.NewDatabaseError("revoke session", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return errors.NewDatabaseError("get rows affected", err)
+	}
+
+	if rowsAffected == 0 {
+		return errors.NewNotFound("session")
+	}
+
+	return nil
+}
+
+// RevokeUser revokes every session of the user
+func (r *SessionRepository) RevokeUser(ctx context.Context, userID int) error {
+	query := `UPDATE sessions SET revoked_at = NOW() WHERE user_id = $1 AND revoked_at IS NULL`
+
+	if _, err := r.db.ExecContext(ctx, query, userID); err != nil {
+		return errors.NewDatabaseError("revoke user sessions", err)
+	}
+
+	return nil
+}
+
+func scanSession(row rowScanner) (*entities.Session, error) {
+	var session entities.Session
+	var deviceName, userAgent, ipAddress, clientID, scope sql.NullString
+	var revokedAt sql.NullTime
+	err := row.Scan(
+		&session.ID,
+		&session.UserID,
+		&deviceName,
+		&userAgent,
+		&ipAddress,
+		&session.CreatedAt,
+		&session.LastUsedAt,
+		&session.ExpiresAt,
+		&revokedAt,
+		&clientID,
+		&scope,
+		&session.AuthTime,
+		pq.Array(&session.AuthMethods),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	session.DeviceName = deviceName.String
+	session.UserAgent = userAgent.String
+	session.IPAddress = ipAddress.String
+	session.ClientID = clientID.String
+	session.Scope = scope.String
+	if revokedAt.Valid {
+		session.RevokedAt = &revokedAt.Time
+	}
+
+	return &session, nil
+}
@@ -0,0 +1,178 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+
+	"otp-server/internal/domain/entities"
+	"otp-server/internal/domain/errors"
+	"otp-server/internal/domain/repositories"
+
+	"github.com/lib/pq"
+)
+
+// TenantRepository implements the TenantRepository interface using PostgreSQL
+type TenantRepository struct {
+	db *sql.DB
+}
+
+// NewTenantRepository creates a new tenant repository
+func NewTenantRepository(pool *PostgresPool) repositories.TenantRepository {
+	return &TenantRepository{
+		db: pool.db,
+	}
+}
+
+// Create creates a new tenant
+func (r *TenantRepository) Create(ctx context.Context, tenant *entities.Tenant) error {
+	settings, err := json.Marshal(tenantSettings(tenant))
+	if err != nil {
+		return errors.NewDatabaseError("encode tenant settings", err)
+	}
+
+	query := `
+		INSERT INTO tenants (slug, name, hosts, settings, is_active)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id, created_at, updated_at
+	`
+
+	err = r.db.QueryRowContext(ctx, query,
+		tenant.Slug,
+		tenant.Name,
+		pq.Array(tenantHosts(tenant)),
+		settings,
+		tenant.IsActive,
+	).Scan(&tenant.ID, &tenant.CreatedAt, &tenant.UpdatedAt)
+
+	if err != nil {
+		if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == "23505" {
+			return errors.NewAlreadyExists("tenant").WithError(err)
+		}
+		return errors.NewDatabaseError("create tenant", err)
+	}
+
+	return nil
+}
+
+// GetByID retrieves a tenant by ID
+func (r *TenantRepository) GetByID(ctx context.Context, id int) (*entities.Tenant, error) {
+	query := `
+		SELECT id, slug, name, hosts, settings, is_active, created_at, updated_at
+		FROM tenants WHERE id = $1
+	`
+
+	tenant, err := scanTenant(r.db.QueryRowContext(ctx, query, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errors.NewNotFound("tenant")
+		}
+		return nil, errors.NewDatabaseError("get tenant", err)
+	}
+
+	return tenant, nil
+}
+
+// List retrieves every tenant
+func (r *TenantRepository) List(ctx context.Context) ([]*entities.Tenant, error) {
+	query := `
+		SELECT id, slug, name, hosts, settings, is_active, created_at, updated_at
+		FROM tenants
+		ORDER BY id
+	`
+
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, errors.NewDatabaseError("list tenants", err)
+	}
+	defer rows.Close()
+
+	var tenants []*entities.Tenant
+	for rows.Next() {
+		tenant, err := scanTenant(rows)
+		if err != nil {
+			return nil, errors.NewDatabaseError("scan tenant", err)
+		}
+		tenants = append(tenants, tenant)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, errors.NewDatabaseError("iterate tenants", err)
+	}
+
+	return tenants, nil
+}
+
+// Update updates the name, hosts, settings and status of a tenant
+func (r *TenantRepository) Update(ctx context.Context, tenant *entities.Tenant) error {
+	settings, err := json.Marshal(tenantSettings(tenant))
+	if err != nil {
+		return errors.NewDatabaseError("encode tenant settings", err)
+	}
+
+	query := `
+		UPDATE tenants
+		SET name = $1, hosts = $2, settings = $3, is_active = $4
+		WHERE id = $5
+		RETURNING updated_at
+	`
+
+	err = r.db.QueryRowContext(ctx, query,
+		tenant.Name,
+		pq.Array(tenantHosts(tenant)),
+		settings,
+		tenant.IsActive,
+		tenant.ID,
+	).Scan(&tenant.UpdatedAt)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return errors.NewNotFound("tenant")
+		}
+		return errors.NewDatabaseError("update tenant", err)
+	}
+
+	return nil
+}
+
+func scanTenant(row rowScanner) (*entities.Tenant, error) {
+	var tenant entities.Tenant
+	var settings []byte
+	err := row.Scan(
+		&tenant.ID,
+		&tenant.Slug,
+		&tenant.Name,
+		pq.Array(&tenant.Hosts),
+		&settings,
+		&tenant.IsActive,
+		&tenant.CreatedAt,
+		&tenant.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(settings, &tenant.Settings); err != nil {
+		return nil, err
+	}
+
+	return &tenant, nil
+}
+
+// tenantHosts returns the tenant's hosts, never nil since pq.Array writes
+// nil as NULL
+func tenantHosts(tenant *entities.Tenant) []string {
+	if tenant.Hosts == nil {
+		return []string{}
+	}
+	return tenant.Hosts
+}
+
+// tenantSettings returns the tenant's settings, never nil so they encode as
+// an empty object
+func tenantSettings(tenant *entities.Tenant) map[string]string {
+	if tenant.Settings == nil {
+		return map[string]string{}
+	}
+	return tenant.Settings
+}
@@ -0,0 +1,183 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+
+	"otp-server/internal/domain/entities"
+	"otp-server/internal/domain/errors"
+	"otp-server/internal/domain/repositories"
+)
+
+// UserFactorRepository implements the UserFactorRepository interface using PostgreSQL
+type UserFactorRepository struct {
+	db *sql.DB
+}
+
+// NewUserFactorRepository creates a new user factor repository
+func NewUserFactorRepository(pool *PostgresPool) repositories.UserFactorRepository {
+	return &UserFactorRepository{
+		db: pool.db,
+	}
+}
+
+// Create creates a new factor
+func (r *UserFactorRepository) Create(ctx context.Context, factor *entities.UserFactor) error {
+	query := `
+		INSERT INTO user_factors (user_id, type, name, secret_encrypted, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id
+	`
+
+	err := r.db.QueryRowContext(ctx, query,
+		factor.UserID,
+		factor.Type,
+		factor.Name,
+		factor.SecretEncrypted,
+		factor.CreatedAt,
+		factor.UpdatedAt,
+	).Scan(&factor.ID)
+
+	if err != nil {
+		return errors.NewDatabaseError("create user factor", err)
+	}
+
+	return nil
+}
+
+// GetByID retrieves a user's factor by ID
+func (r *UserFactorRepository) GetByID(ctx context.Context, userID, id int) (*entities.UserFactor, error) {
+	query := `
+		SELECT id, user_id, type, name, secret_encrypted, confirmed_at, last_used_step, last_used_at, created_at, updated_at
+		FROM user_factors WHERE id = $1 AND user_id = $2
+	`
+
+	factor, err := scanUserFactor(r.db.QueryRowContext(ctx, query, id, userID))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errors.NewNotFound("factor")
+		}
+		return nil, errors.NewDatabaseError("get user factor", err)
+	}
+
+	return factor, nil
+}
+
+// ListByUser retrieves all factors of a user, confirmed or not
+func (r *UserFactorRepository) ListByUser(ctx context.Context, userID int) ([]*entities.UserFactor, error) {
+	query := `
+		SELECT id, user_id, type, name, secret_encrypted, confirmed_at, last_used_step, last_used_at, created_at, updated_at
+		FROM user_factors WHERE user_id = $1
+		ORDER BY created_at
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, errors.NewDatabaseError("list user factors", err)
+	}
+	defer rows.Close()
+
+	var factors []*entities.UserFactor
+	for rows.Next() {
+		factor, err := scanUserFactor(rows)
+		if err != nil {
+			return nil, errors.NewDatabaseError("scan user factor", err)
+		}
+		factors = append(factors, factor)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, errors.NewDatabaseError("iterate user factors", err)
+	}
+
+	return factors, nil
+}
+
+// HasConfirmed checks if the user has at least one confirmed factor
+func (r *UserFactorRepository) HasConfirmed(ctx context.Context, userID int) (bool, error) {
+	query := `SELECT EXISTS (SELECT 1 FROM user_factors WHERE user_id = $1 AND confirmed_at IS NOT NULL)`
+
+	var exists bool
+	if err := r.db.QueryRowContext(ctx, query, userID).Scan(&exists); err != nil {
+		return false, errors.NewDatabaseError("check user factors", err)
+	}
+
+	return exists, nil
+}
+
+// UseStep records step as the factor's last accepted TOTP step. The
+// comparison happens in the UPDATE itself, so of two concurrent requests
+// with the same code only one succeeds.
+func (r *UserFactorRepository) UseStep(ctx context.Context, id int, step int64) (bool, error) {
+	query := `
+		UPDATE user_factors
+		SET last_used_step = $2, last_used_at = NOW(), confirmed_at = COALESCE(confirmed_at, NOW())
+		WHERE id = $1 AND last_used_step < $2
+	`
+
+	result, err := r.db.ExecContext(ctx, query, id, step)
+	if err != nil {
+		return false, errors.NewDatabaseError("use user factor step", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, errors.NewDatabaseError("get rows affected", err)
+	}
+
+	return rowsAffected == 1, nil
+}
+
+// Delete deletes a user's factor by ID
+func (r *UserFactorRepository) Delete(ctx context.Context, userID, id int) error {
+	query := `DELETE FROM user_factors WHERE id = $1 AND user_id = $2`
+
+	result, err := r.db.ExecContext(ctx, query, id, userID)
+	if err != nil {
+		return errors.NewDatabaseError("delete user factor", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return errors.NewDatabaseError("get rows affected", err)
+	}
+
+	if rowsAffected == 0 {
+		return errors.NewNotFound("factor")
+	}
+
+	return nil
+}
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanUserFactor(row rowScanner) (*entities.UserFactor, error) {
+	var factor entities.UserFactor
+	var confirmedAt, lastUsedAt sql.NullTime
+	err := row.Scan(
+		&factor.ID,
+		&factor.UserID,
+		&factor.Type,
+		&factor.Name,
+		&factor.SecretEncrypted,
+		&confirmedAt,
+		&factor.LastUsedStep,
+		&lastUsedAt,
+		&factor.CreatedAt,
+		&factor.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if confirmedAt.Valid {
+		factor.ConfirmedAt = &confirmedAt.Time
+	}
+	if lastUsedAt.Valid {
+		factor.LastUsedAt = &lastUsedAt.Time
+	}
+
+	return &factor, nil
+}
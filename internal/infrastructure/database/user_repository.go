@@ -3,6 +3,7 @@ package database
 import (
 	"context"
 	"database/sql"
+	"fmt"
 	"strings"
 
 	"otp-server/internal/domain/entities"
@@ -12,6 +13,13 @@ import (
 	"github.com/lib/pq"
 )
 
+// userColumns are the columns scanned by userFields, in order
+const userColumns = `id, tenant_id, phone_number, name, role, status, status_reason, status_until, status_changed_by, status_changed_at, phone_confirmation_required, created_at, updated_at`
+
+// currentStatusSQL is the status of a user now, counting suspensions whose
+// time has passed as lifted
+const currentStatusSQL = `(CASE WHEN status = 'suspended' AND status_until <= NOW() THEN 'active' ELSE status END)`
+
 // UserRepository implements the UserRepository interface using PostgreSQL
 type UserRepository struct {
 	db *sql.DB
@@ -27,17 +35,18 @@ func NewUserRepository(pool *PostgresPool) repositories.UserRepository {
 // Create creates a new user
 func (r *UserRepository) Create(ctx context.Context, user *entities.User) error {
 	query := `
-		INSERT INTO users (phone_number, name, role, is_active, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6)
+		INSERT INTO users (tenant_id, phone_number, name, role, status, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
 		RETURNING id
 	`
 
 	var id int
 	err := r.db.QueryRowContext(ctx, query,
+		user.TenantID,
 		user.PhoneNumber,
 		user.Name,
 		user.Role,
-		user.IsActive,
+		user.Status,
 		user.CreatedAt,
 		user.UpdatedAt,
 	).Scan(&id)
@@ -63,20 +72,12 @@ func (r *UserRepository) Create(ctx context.Context, user *entities.User) error
 // GetByID retrieves a user by ID
 func (r *UserRepository) GetByID(ctx context.Context, id int) (*entities.User, error) {
 	query := `
-		SELECT id, phone_number, name, role, is_active, created_at, updated_at
+		SELECT ` + userColumns + `
 		FROM users WHERE id = $1
 	`
 
 	var user entities.User
-	err := r.db.QueryRowContext(ctx, query, id).Scan(
-		&user.ID,
-		&user.PhoneNumber,
-		&user.Name,
-		&user.Role,
-		&user.IsActive,
-		&user.CreatedAt,
-		&user.UpdatedAt,
-	)
+	err := r.db.QueryRowContext(ctx, query, id).Scan(userFields(&user)...)
 
 	if err != nil {
 		if err == sql.ErrNoRows {
@@ -88,23 +89,15 @@ func (r *UserRepository) GetByID(ctx context.Context, id int) (*entities.User, e
 	return &user, nil
 }
 
-// GetByPhoneNumber retrieves a user by phone number
-func (r *UserRepository) GetByPhoneNumber(ctx context.Context, phoneNumber string) (*entities.User, error) {
+// GetByPhoneNumber retrieves a user of the tenant by phone number
+func (r *UserRepository) GetByPhoneNumber(ctx context.Context, tenantID int, phoneNumber string) (*entities.User, error) {
 	query := `
-		SELECT id, phone_number, name, role, is_active, created_at, updated_at
-		FROM users WHERE phone_number = $1
+		SELECT ` + userColumns + `
+		FROM users WHERE tenant_id = $1 AND phone_number = $2
 	`
 
 	var user entities.User
-	err := r.db.QueryRowContext(ctx, query, phoneNumber).Scan(
-		&user.ID,
-		&user.PhoneNumber,
-		&user.Name,
-		&user.Role,
-		&user.IsActive,
-		&user.CreatedAt,
-		&user.UpdatedAt,
-	)
+	err := r.db.QueryRowContext(ctx, query, tenantID, phoneNumber).Scan(userFields(&user)...)
 
 	if err != nil {
 		if err == sql.ErrNoRows {
@@ -116,18 +109,19 @@ func (r *UserRepository) GetByPhoneNumber(ctx context.Context, phoneNumber strin
 	return &user, nil
 }
 
-// Update updates an existing user
+// Update updates an existing user, except for the phone number and status
+// which change through UpdatePhoneNumber and UpdateStatus
 func (r *UserRepository) Update(ctx context.Context, user *entities.User) error {
 	query := `
 		UPDATE users 
-		SET name = $1, role = $2, is_active = $3, updated_at = $4
+		SET name = $1, role = $2, phone_confirmation_required = $3, updated_at = $4
 		WHERE id = $5
 	`
 
 	result, err := r.db.ExecContext(ctx, query,
 		user.Name,
 		user.Role,
-		user.IsActive,
+		user.PhoneConfirmationRequired,
 		user.UpdatedAt,
 		user.ID,
 	)
@@ -148,6 +142,145 @@ func (r *UserRepository) Update(ctx context.Context, user *entities.User) error
 	return nil
 }
 
+// UpdatePhoneNumber stores the user's phone number and phone confirmation flag
+func (r *UserRepository) UpdatePhoneNumber(ctx context.Context, user *entities.User) error {
+	query := `
+		UPDATE users
+		SET phone_number = $1, phone_confirmation_required = $2, updated_at = $3
+		WHERE id = $4
+	`
+
+	result, err := r.db.ExecContext(ctx, query,
+		user.PhoneNumber,
+		user.PhoneConfirmationRequired,
+		user.UpdatedAt,
+		user.ID,
+	)
+
+	if err != nil {
+		if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == "23505" {
+			return errors.NewAlreadyExists("user").WithError(err)
+		}
+		return errors.NewDatabaseError("update user phone number", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return errors.NewDatabaseError("get rows affected", err)
+	}
+
+	if rowsAffected == 0 {
+		return errors.NewNotFound("user")
+	}
+
+	return nil
+}
+
+// UpdateStatus stores the user's status and records the change in the audit
+// trail, in one transaction so no change goes unrecorded
+func (r *UserRepository) UpdateStatus(ctx context.Context, user *entities.User, change *entities.UserStatusChange) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return errors.NewDatabaseError("begin transaction", err)
+	}
+	defer tx.Rollback()
+
+	query := `
+		UPDATE users
+		SET status = $1, status_reason = $2, status_until = $3, status_changed_by = $4, status_changed_at = $5, updated_at = $6
+		WHERE id = $7
+	`
+
+	result, err := tx.ExecContext(ctx, query,
+		user.Status,
+		user.StatusReason,
+		user.StatusUntil,
+		user.StatusChangedBy,
+		user.StatusChangedAt,
+		user.UpdatedAt,
+		user.ID,
+	)
+	if err != nil {
+		return errors.NewDatabaseError("update user status", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return errors.NewDatabaseError("get rows affected", err)
+	}
+
+	if rowsAffected == 0 {
+		return errors.NewNotFound("user")
+	}
+
+	query = `
+		INSERT INTO user_status_changes (user_id, previous_status, status, reason, until, actor, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING id
+	`
+
+	err = tx.QueryRowContext(ctx, query,
+		change.UserID,
+		change.PreviousStatus,
+		change.Status,
+		change.Reason,
+		change.Until,
+		change.Actor,
+		change.CreatedAt,
+	).Scan(&change.ID)
+	if err != nil {
+		return errors.NewDatabaseError("create user status change", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return errors.NewDatabaseError("commit transaction", err)
+	}
+
+	return nil
+}
+
+// ListStatusChanges retrieves the audit trail of the user's status changes,
+// newest first
+func (r *UserRepository) ListStatusChanges(ctx context.Context, userID int) ([]*entities.UserStatusChange, error) {
+	query := `
+		SELECT id, user_id, previous_status, status, reason, until, actor, created_at
+		FROM user_status_changes
+		WHERE user_id = $1
+		ORDER BY created_at DESC, id DESC
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, errors.NewDatabaseError("list user status changes", err)
+	}
+	defer rows.Close()
+
+	var changes []*entities.UserStatusChange
+	for rows.Next() {
+		var change entities.UserStatusChange
+		err := rows.Scan(
+			&change.ID,
+			&change.UserID,
+			&change.PreviousStatus,
+			&change.Status,
+			&change.Reason,
+			&change.Until,
+			&change.Actor,
+			&change.CreatedAt,
+		)
+		if err != nil {
+			return nil, errors.NewDatabaseError("scan user status change", err)
+		}
+		changes = append(changes, &change)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, errors.NewDatabaseError("iterate user status changes", err)
+	}
+
+	return changes, nil
+}
+
 // Delete deletes a user by ID
 func (r *UserRepository) Delete(ctx context.Context, id int) error {
 	query := `DELETE FROM users WHERE id = $1`
@@ -169,16 +302,17 @@ func (r *UserRepository) Delete(ctx context.Context, id int) error {
 	return nil
 }
 
-// GetUsers retrieves a paginated list of users
-func (r *UserRepository) GetUsers(ctx context.Context, offset, limit int) ([]*entities.User, error) {
+// GetUsers retrieves a paginated list of the tenant's users
+func (r *UserRepository) GetUsers(ctx context.Context, tenantID, offset, limit int) ([]*entities.User, error) {
 	query := `
-		SELECT id, phone_number, name, role, is_active, created_at, updated_at
-		FROM users 
+		SELECT ` + userColumns + `
+		FROM users
+		WHERE tenant_id = $1
 		ORDER BY created_at DESC
-		LIMIT $1 OFFSET $2
+		LIMIT $2 OFFSET $3
 	`
 
-	rows, err := r.db.QueryContext(ctx, query, limit, offset)
+	rows, err := r.db.QueryContext(ctx, query, tenantID, limit, offset)
 	if err != nil {
 		return nil, errors.NewDatabaseError("get users", err)
 	}
@@ -187,15 +321,7 @@ func (r *UserRepository) GetUsers(ctx context.Context, offset, limit int) ([]*en
 	var users []*entities.User
 	for rows.Next() {
 		var user entities.User
-		err := rows.Scan(
-			&user.ID,
-			&user.PhoneNumber,
-			&user.Name,
-			&user.Role,
-			&user.IsActive,
-			&user.CreatedAt,
-			&user.UpdatedAt,
-		)
+		err := rows.Scan(userFields(&user)...)
 		if err != nil {
 			return nil, errors.NewDatabaseError("scan user", err)
 		}
@@ -209,12 +335,12 @@ func (r *UserRepository) GetUsers(ctx context.Context, offset, limit int) ([]*en
 	return users, nil
 }
 
-// GetTotalCount retrieves the total number of users
-func (r *UserRepository) GetTotalCount(ctx context.Context) (int, error) {
-	query := `SELECT COUNT(*) FROM users`
+// GetTotalCount retrieves the total number of the tenant's users
+func (r *UserRepository) GetTotalCount(ctx context.Context, tenantID int) (int, error) {
+	query := `SELECT COUNT(*) FROM users WHERE tenant_id = $1`
 
 	var count int
-	err := r.db.QueryRowContext(ctx, query).Scan(&count)
+	err := r.db.QueryRowContext(ctx, query, tenantID).Scan(&count)
 	if err != nil {
 		return 0, errors.NewDatabaseError("get user count", err)
 	}
@@ -222,18 +348,18 @@ func (r *UserRepository) GetTotalCount(ctx context.Context) (int, error) {
 	return count, nil
 }
 
-// SearchUsers searches users by phone number or name
-func (r *UserRepository) SearchUsers(ctx context.Context, query string) ([]*entities.User, error) {
+// SearchUsers searches the tenant's users by phone number or name
+func (r *UserRepository) SearchUsers(ctx context.Context, tenantID int, query string) ([]*entities.User, error) {
 	searchQuery := `
-		SELECT id, phone_number, name, role, is_active, created_at, updated_at
-		FROM users 
-		WHERE phone_number ILIKE $1 OR name ILIKE $1
+		SELECT ` + userColumns + `
+		FROM users
+		WHERE tenant_id = $1 AND (phone_number ILIKE $2 OR name ILIKE $2)
 		ORDER BY created_at DESC
 		LIMIT 50
 	`
 
 	searchPattern := "%" + strings.ToLower(query) + "%"
-	rows, err := r.db.QueryContext(ctx, searchQuery, searchPattern)
+	rows, err := r.db.QueryContext(ctx, searchQuery, tenantID, searchPattern)
 	if err != nil {
 		return nil, errors.NewDatabaseError("search users", err)
 	}
@@ -242,15 +368,7 @@ func (r *UserRepository) SearchUsers(ctx context.Context, query string) ([]*enti
 	var users []*entities.User
 	for rows.Next() {
 		var user entities.User
-		err := rows.Scan(
-			&user.ID,
-			&user.PhoneNumber,
-			&user.Name,
-			&user.Role,
-			&user.IsActive,
-			&user.CreatedAt,
-			&user.UpdatedAt,
-		)
+		err := rows.Scan(userFields(&user)...)
 		if err != nil {
 			return nil, errors.NewDatabaseError("scan user", err)
 		}
@@ -264,36 +382,37 @@ func (r *UserRepository) SearchUsers(ctx context.Context, query string) ([]*enti
 	return users, nil
 }
 
-// GetUsersWithQuery retrieves users with optional search and pagination in one query
-func (r *UserRepository) GetUsersWithQuery(ctx context.Context, query string, offset, limit int) ([]*entities.User, int, error) {
+// GetUsersWithQuery retrieves the tenant's users with optional search and pagination in one query
+func (r *UserRepository) GetUsersWithQuery(ctx context.Context, tenantID int, query string, offset, limit int) ([]*entities.User, int, error) {
 	var baseQuery string
 	var countQuery string
 	var args []interface{}
 
 	if query != "" {
 		baseQuery = `
-			SELECT id, phone_number, name, role, is_active, created_at, updated_at
-			FROM users 
-			WHERE phone_number ILIKE $1 OR name ILIKE $1
+			SELECT ` + userColumns + `
+			FROM users
+			WHERE tenant_id = $1 AND (phone_number ILIKE $2 OR name ILIKE $2)
 			ORDER BY created_at DESC
-			LIMIT $2 OFFSET $3
+			LIMIT $3 OFFSET $4
 		`
 		countQuery = `
 			SELECT COUNT(*)
-			FROM users 
-			WHERE phone_number ILIKE $1 OR name ILIKE $1
+			FROM users
+			WHERE tenant_id = $1 AND (phone_number ILIKE $2 OR name ILIKE $2)
 		`
 		searchPattern := "%" + strings.ToLower(query) + "%"
-		args = []interface{}{searchPattern, limit, offset}
+		args = []interface{}{tenantID, searchPattern, limit, offset}
 	} else {
 		baseQuery = `
-			SELECT id, phone_number, name, role, is_active, created_at, updated_at
-			FROM users 
+			SELECT ` + userColumns + `
+			FROM users
+			WHERE tenant_id = $1
 			ORDER BY created_at DESC
-			LIMIT $1 OFFSET $2
+			LIMIT $2 OFFSET $3
 		`
-		countQuery = `SELECT COUNT(*) FROM users`
-		args = []interface{}{limit, offset}
+		countQuery = `SELECT COUNT(*) FROM users WHERE tenant_id = $1`
+		args = []interface{}{tenantID, limit, offset}
 	}
 
 	var total int
@@ -311,15 +430,7 @@ func (r *UserRepository) GetUsersWithQuery(ctx context.Context, query string, of
 	var users []*entities.User
 	for rows.Next() {
 		var user entities.User
-		err := rows.Scan(
-			&user.ID,
-			&user.PhoneNumber,
-			&user.Name,
-			&user.Role,
-			&user.IsActive,
-			&user.CreatedAt,
-			&user.UpdatedAt,
-		)
+		err := rows.Scan(userFields(&user)...)
 		if err != nil {
 			return nil, 0, errors.NewDatabaseError("scan user", err)
 		}
@@ -332,3 +443,85 @@ func (r *UserRepository) GetUsersWithQuery(ctx context.Context, query string, of
 
 	return users, total, nil
 }
+
+// ListUsers retrieves a page of the tenant's users matching the filter,
+// newest first, and the number of users matching it
+func (r *UserRepository) ListUsers(ctx context.Context, tenantID int, filter *repositories.UserFilter, offset, limit int) ([]*entities.User, int, error) {
+	conditions := []string{"tenant_id = $1"}
+	args := []interface{}{tenantID}
+	where := func(condition string, arg interface{}) {
+		args = append(args, arg)
+		conditions = append(conditions, fmt.Sprintf(condition, len(args)))
+	}
+
+	if filter.Query != "" {
+		where("(phone_number ILIKE $%[1]d OR name ILIKE $%[1]d)", "%"+strings.ToLower(filter.Query)+"%")
+	}
+	if filter.Role != "" {
+		where("role = $%d", filter.Role)
+	}
+	if filter.Status != "" {
+		where(currentStatusSQL+" = $%d", filter.Status)
+	}
+	if !filter.CreatedAfter.IsZero() {
+		where("created_at >= $%d", filter.CreatedAfter)
+	}
+	if !filter.CreatedBefore.IsZero() {
+		where("created_at < $%d", filter.CreatedBefore)
+	}
+	clause := strings.Join(conditions, " AND ")
+
+	var total int
+	if err := r.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM users WHERE "+clause, args...).Scan(&total); err != nil {
+		return nil, 0, errors.NewDatabaseError("count users", err)
+	}
+
+	query := fmt.Sprintf(`
+		SELECT `+userColumns+`
+		FROM users
+		WHERE %s
+		ORDER BY created_at DESC
+		LIMIT $%d OFFSET $%d
+	`, clause, len(args)+1, len(args)+2)
+
+	rows, err := r.db.QueryContext(ctx, query, append(args, limit, offset)...)
+	if err != nil {
+		return nil, 0, errors.NewDatabaseError("list users", err)
+	}
+	defer rows.Close()
+
+	var users []*entities.User
+	for rows.Next() {
+		var user entities.User
+		err := rows.Scan(userFields(&user)...)
+		if err != nil {
+			return nil, 0, errors.NewDatabaseError("scan user", err)
+		}
+		users = append(users, &user)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, 0, errors.NewDatabaseError("iterate users", err)
+	}
+
+	return users, total, nil
+}
+
+// userFields returns the destinations of the userColumns of a row
+func userFields(user *entities.User) []interface{} {
+	return []interface{}{
+		&user.ID,
+		&user.TenantID,
+		&user.PhoneNumber,
+		&user.Name,
+		&user.Role,
+		&user.Status,
+		&user.StatusReason,
+		&user.StatusUntil,
+		&user.StatusChangedBy,
+		&user.StatusChangedAt,
+		&user.PhoneConfirmationRequired,
+		&user.CreatedAt,
+		&user.UpdatedAt,
+	}
+}
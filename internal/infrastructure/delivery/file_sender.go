@@ -0,0 +1,89 @@
+package delivery
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"otp-server/internal/domain/entities"
+	"otp-server/internal/infrastructure/config"
+	"otp-server/internal/infrastructure/logger"
+
+	"github.com/google/uuid"
+)
+
+// FileSender appends every message as a JSON line to a local "dev inbox"
+// file. Meant for development and end-to-end tests, never for production.
+type FileSender struct {
+	path   string
+	mu     sync.Mutex
+	logger logger.Logger
+}
+
+type inboxEntry struct {
+	MessageID   string    `json:"message_id"`
+	ChallengeID string    `json:"challenge_id"`
+	To          string    `json:"to"`
+	Channel     string    `json:"channel"`
+	Code        string    `json:"code"`
+	Body        string    `json:"body"`
+	SentAt      time.Time `json:"sent_at"`
+}
+
+// NewFileSender creates a new dev inbox provider
+func NewFileSender(cfg *config.DeliveryConfig, logger logger.Logger) (Sender, error) {
+	if cfg.File.Path == "" {
+		return nil, fmt.Errorf("DELIVERY_FILE_PATH is required for the file delivery provider")
+	}
+
+	if err := os.MkdirAll(filepath.Dir(cfg.File.Path), 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create dev inbox directory: %w", err)
+	}
+
+	return &FileSender{
+		path:   cfg.File.Path,
+		logger: logger,
+	}, nil
+}
+
+// Name returns the provider name
+func (s *FileSender) Name() string {
+	return "file"
+}
+
+// Send appends the message to the inbox file
+func (s *FileSender) Send(ctx context.Context, msg *Message) (*SendResult, error) {
+	entry := inboxEntry{
+		MessageID:   uuid.NewString(),
+		ChallengeID: msg.ChallengeID,
+		To:          msg.Recipient(),
+		Channel:     string(msg.Channel),
+		Code:        msg.Code,
+		Body:        msg.Body,
+		SentAt:      time.Now().UTC(),
+	}
+
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return nil, NewPermanentError(err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	f, err := os.OpenFile(s.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return nil, NewTemporaryError(err)
+	}
+	defer f.Close()
+
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		return nil, NewTemporaryError(err)
+	}
+
+	return &SendResult{MessageID: entry.MessageID, Status: entities.DeliveryStatusDelivered}, nil
+}
@@ -0,0 +1,219 @@
+package delivery
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"otp-server/internal/domain/entities"
+
+	"otp-server/internal/infrastructure/config"
+	"otp-server/internal/infrastructure/logger"
+)
+
+// HTTPSender delivers messages through a generic HTTP/JSON messaging gateway.
+// It POSTs {"to", "from", "channel", "message", "reference"} and reads
+// "message_id" (or "id"), "status", "cost" and "currency" from the response.
+// Delivery receipts are posted back as one object or an array of objects with
+// "message_id" (or "id"), "status", "error_code" and "timestamp", signed with
+// a hex HMAC-SHA256 of the body.
+type HTTPSender struct {
+	config *config.HTTPDeliveryConfig
+	client *http.Client
+	logger logger.Logger
+}
+
+type httpSendRequest struct {
+	To        string `json:"to"`
+	From      string `json:"from,omitempty"`
+	Channel   string `json:"channel"`
+	Message   string `json:"message"`
+	Reference string `json:"reference,omitempty"`
+}
+
+type httpReceipt struct {
+	MessageID string      `json:"message_id"`
+	ID        string      `json:"id"`
+	Status    string      `json:"status"`
+	ErrorCode string      `json:"error_code"`
+	Timestamp interface{} `json:"timestamp"`
+}
+
+type httpSendResponse struct {
+	MessageID string  `json:"message_id"`
+	ID        string  `json:"id"`
+	Status    string  `json:"status"`
+	Cost      float64 `json:"cost"`
+	Currency  string  `json:"currency"`
+}
+
+// NewHTTPSender creates a new HTTP gateway provider
+func NewHTTPSender(cfg *config.DeliveryConfig, logger logger.Logger) (Sender, error) {
+	if cfg.HTTP.URL == "" {
+		return nil, fmt.Errorf("DELIVERY_HTTP_URL is required for the http delivery provider")
+	}
+
+	return &HTTPSender{
+		config: &cfg.HTTP,
+		client: &http.Client{},
+		logger: logger,
+	}, nil
+}
+
+// Name returns the provider name
+func (s *HTTPSender) Name() string {
+	return "http"
+}
+
+// Send posts the message to the gateway
+func (s *HTTPSender) Send(ctx context.Context, msg *Message) (*SendResult, error) {
+	body, err := json.Marshal(httpSendRequest{
+		To:        msg.Recipient(),
+		From:      s.config.From,
+		Channel:   string(msg.Channel),
+		Message:   msg.Body,
+		Reference: msg.ChallengeID,
+	})
+	if err != nil {
+		return nil, NewPermanentError(err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.config.URL, bytes.NewReader(body))
+	if err != nil {
+		return nil, NewPermanentError(err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if s.config.APIKey != "" {
+		req.Header.Set(s.config.AuthHeader, s.authValue())
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		if errors.Is(err, context.DeadlineExceeded) {
+			return nil, NewTimeoutError(err)
+		}
+		return nil, NewTemporaryError(err)
+	}
+	defer resp.Body.Close()
+
+	respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 64*1024))
+
+	switch {
+	case resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500:
+		return nil, NewTemporaryError(fmt.Errorf("gateway returned %d: %s", resp.StatusCode, respBody))
+	case resp.StatusCode >= 300:
+		return nil, NewPermanentError(fmt.Errorf("gateway returned %d: %s", resp.StatusCode, respBody))
+	}
+
+	var parsed httpSendResponse
+	if len(respBody) > 0 {
+		if err := json.Unmarshal(respBody, &parsed); err != nil {
+			s.logger.Warn(ctx, "Could not parse SMS gateway response", logger.F("error", err))
+		}
+	}
+
+	messageID := parsed.MessageID
+	if messageID == "" {
+		messageID = parsed.ID
+	}
+
+	status, _ := ParseDeliveryStatus(parsed.Status)
+
+	return &SendResult{
+		MessageID: messageID,
+		Cost:      parsed.Cost,
+		Currency:  parsed.Currency,
+		Status:    status,
+	}, nil
+}
+
+// VerifyReceipt checks the receipt signature. Without a webhook secret every
+// receipt is rejected.
+func (s *HTTPSender) VerifyReceipt(header func(string) string, body []byte) error {
+	if s.config.WebhookSecret == "" {
+		return fmt.Errorf("%w: DELIVERY_HTTP_WEBHOOK_SECRET is not set", ErrInvalidSignature)
+	}
+
+	signature, err := hex.DecodeString(strings.TrimPrefix(header(s.config.WebhookSignatureHeader), "sha256="))
+	if err != nil || len(signature) == 0 {
+		return ErrInvalidSignature
+	}
+
+	mac := hmac.New(sha256.New, []byte(s.config.WebhookSecret))
+	mac.Write(body)
+	if !hmac.Equal(mac.Sum(nil), signature) {
+		return ErrInvalidSignature
+	}
+
+	return nil
+}
+
+// ParseReceipts reads one receipt or an array of receipts
+func (s *HTTPSender) ParseReceipts(body []byte) ([]*entities.DeliveryEvent, error) {
+	var raw []httpReceipt
+	trimmed := bytes.TrimSpace(body)
+	if len(trimmed) > 0 && trimmed[0] == '[' {
+		if err := json.Unmarshal(trimmed, &raw); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrMalformedReceipt, err)
+		}
+	} else {
+		var single httpReceipt
+		if err := json.Unmarshal(trimmed, &single); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrMalformedReceipt, err)
+		}
+		raw = append(raw, single)
+	}
+
+	receipts := make([]*entities.DeliveryEvent, 0, len(raw))
+	for _, r := range raw {
+		messageID := r.MessageID
+		if messageID == "" {
+			messageID = r.ID
+		}
+		status, ok := ParseDeliveryStatus(r.Status)
+		if messageID == "" || !ok {
+			return nil, fmt.Errorf("%w: missing message ID or unknown status %q", ErrMalformedReceipt, r.Status)
+		}
+
+		receipts = append(receipts, &entities.DeliveryEvent{
+			Provider:  s.Name(),
+			MessageID: messageID,
+			Status:    status,
+			ErrorCode: r.ErrorCode,
+			At:        receiptTime(r.Timestamp),
+		})
+	}
+
+	return receipts, nil
+}
+
+// receiptTime reads a Unix or RFC 3339 timestamp, defaulting to now
+func receiptTime(value interface{}) time.Time {
+	switch v := value.(type) {
+	case float64:
+		return time.Unix(int64(v), 0)
+	case string:
+		if t, err := time.Parse(time.RFC3339, v); err == nil {
+			return t
+		}
+	}
+	return time.Now()
+}
+
+// authValue returns the credential header value, as a bearer token when sent
+// in the Authorization header
+func (s *HTTPSender) authValue() string {
+	if http.CanonicalHeaderKey(s.config.AuthHeader) == "Authorization" {
+		return "Bearer " + s.config.APIKey
+	}
+	return s.config.APIKey
+}
@@ -0,0 +1,42 @@
+package delivery
+
+import (
+	"context"
+
+	"otp-server/internal/domain/entities"
+	"otp-server/internal/infrastructure/config"
+	"otp-server/internal/infrastructure/logger"
+
+	"github.com/google/uuid"
+)
+
+// LogSender writes every message, code included, to the application log.
+// Meant for local development only.
+type LogSender struct {
+	logger logger.Logger
+}
+
+// NewLogSender creates a new log sink provider
+func NewLogSender(cfg *config.DeliveryConfig, logger logger.Logger) (Sender, error) {
+	return &LogSender{logger: logger}, nil
+}
+
+// Name returns the provider name
+func (s *LogSender) Name() string {
+	return "log"
+}
+
+// Send logs the message
+func (s *LogSender) Send(ctx context.Context, msg *Message) (*SendResult, error) {
+	messageID := uuid.NewString()
+
+	s.logger.Info(ctx, "OTP message delivered to log sink",
+		logger.F("message_id", messageID),
+		logger.F("challenge_id", msg.ChallengeID),
+		logger.F("recipient", msg.Recipient()),
+		logger.F("channel", msg.Channel),
+		logger.F("code", msg.Code),
+		logger.F("body", msg.Body))
+
+	return &SendResult{MessageID: messageID, Status: entities.DeliveryStatusDelivered}, nil
+}
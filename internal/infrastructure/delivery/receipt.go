@@ -0,0 +1,41 @@
+package delivery
+
+import (
+	"errors"
+	"strings"
+
+	"otp-server/internal/domain/entities"
+)
+
+var (
+	// ErrInvalidSignature is returned for receipts whose signature does not verify
+	ErrInvalidSignature = errors.New("invalid delivery receipt signature")
+	// ErrReceiptsUnsupported is returned for providers that do not send receipts
+	ErrReceiptsUnsupported = errors.New("provider does not send delivery receipts")
+	// ErrMalformedReceipt is returned for receipts that cannot be parsed
+	ErrMalformedReceipt = errors.New("malformed delivery receipt")
+)
+
+// receiptStatuses maps the status names gateways commonly use, including
+// the SMPP receipt states, onto our delivery statuses
+var receiptStatuses = map[string]entities.DeliveryStatus{
+	"queued":      entities.DeliveryStatusQueued,
+	"accepted":    entities.DeliveryStatusQueued,
+	"enroute":     entities.DeliveryStatusQueued,
+	"sent":        entities.DeliveryStatusSent,
+	"submitted":   entities.DeliveryStatusSent,
+	"delivered":   entities.DeliveryStatusDelivered,
+	"delivrd":     entities.DeliveryStatusDelivered,
+	"failed":      entities.DeliveryStatusFailed,
+	"undelivered": entities.DeliveryStatusFailed,
+	"undeliv":     entities.DeliveryStatusFailed,
+	"rejected":    entities.DeliveryStatusFailed,
+	"rejectd":     entities.DeliveryStatusFailed,
+	"expired":     entities.DeliveryStatusExpired,
+}
+
+// ParseDeliveryStatus normalizes a provider's status name
+func ParseDeliveryStatus(status string) (entities.DeliveryStatus, bool) {
+	parsed, ok := receiptStatuses[strings.ToLower(strings.TrimSpace(status))]
+	return parsed, ok
+}
@@ -0,0 +1,66 @@
+package delivery
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+
+	"otp-server/internal/infrastructure/config"
+	"otp-server/internal/infrastructure/logger"
+)
+
+// Factory builds a Sender from the delivery configuration
+type Factory func(cfg *config.DeliveryConfig, logger logger.Logger) (Sender, error)
+
+// Registry maps provider names to the factories that build them
+type Registry struct {
+	factories map[string]Factory
+	mu        sync.RWMutex
+}
+
+// NewRegistry creates a registry with the built-in providers registered
+func NewRegistry() *Registry {
+	r := &Registry{
+		factories: make(map[string]Factory),
+	}
+
+	r.Register("http", NewHTTPSender)
+	r.Register("file", NewFileSender)
+	r.Register("log", NewLogSender)
+
+	return r
+}
+
+// Register adds or replaces a provider factory
+func (r *Registry) Register(name string, factory Factory) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.factories[name] = factory
+}
+
+// Build creates the named provider
+func (r *Registry) Build(name string, cfg *config.DeliveryConfig, logger logger.Logger) (Sender, error) {
+	r.mu.RLock()
+	factory, exists := r.factories[name]
+	r.mu.RUnlock()
+
+	if !exists {
+		return nil, fmt.Errorf("unknown delivery provider %q (available: %v)", name, r.Names())
+	}
+
+	return factory(cfg, logger)
+}
+
+// Names returns the registered provider names
+func (r *Registry) Names() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	names := make([]string, 0, len(r.factories))
+	for name := range r.factories {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,107 @@
+package delivery
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"otp-server/internal/domain/entities"
+)
+
+// Message represents a single OTP message handed to a provider
+type Message struct {
+	TenantID    int
+	ChallengeID string
+	PhoneNumber string
+	Email       string
+	Channel     entities.OTPChannel
+	Code        string
+	Body        string
+}
+
+// Recipient returns the address the message goes to on its channel
+func (m *Message) Recipient() string {
+	if m.Channel == entities.OTPChannelEmail {
+		return m.Email
+	}
+	return m.PhoneNumber
+}
+
+// SendResult represents what a provider reports for an accepted message
+type SendResult struct {
+	MessageID string
+	Cost      float64
+	Currency  string
+	// Status the provider reported for the message; empty means sent
+	Status entities.DeliveryStatus
+}
+
+// Sender delivers OTP messages through one provider
+type Sender interface {
+	Name() string
+	Send(ctx context.Context, msg *Message) (*SendResult, error)
+}
+
+// ReceiptHandler is implemented by providers that post delivery receipts
+// (DLRs) back to the webhook endpoint
+type ReceiptHandler interface {
+	// VerifyReceipt checks the request signature; header looks up a request header
+	VerifyReceipt(header func(string) string, body []byte) error
+	// ParseReceipts extracts the receipts carried by the request body
+	ParseReceipts(body []byte) ([]*entities.DeliveryEvent, error)
+}
+
+// ErrRetryable matches every SendError worth retrying
+var ErrRetryable = errors.New("retryable delivery error")
+
+// SendError represents a classified provider failure
+type SendError struct {
+	Class entities.DeliveryErrorClass
+	Err   error
+}
+
+func (e *SendError) Error() string {
+	return fmt.Sprintf("%s delivery error: %v", e.Class, e.Err)
+}
+
+func (e *SendError) Unwrap() error {
+	return e.Err
+}
+
+// Is lets errors.Is(err, ErrRetryable) pick out temporary failures
+func (e *SendError) Is(target error) bool {
+	return target == ErrRetryable && (e.Class == entities.DeliveryErrorTemporary || e.Class == entities.DeliveryErrorTimeout)
+}
+
+// NewTemporaryError creates an error for failures that may succeed when retried
+func NewTemporaryError(err error) *SendError {
+	return &SendError{Class: entities.DeliveryErrorTemporary, Err: err}
+}
+
+// NewPermanentError creates an error for failures that will not succeed when retried
+func NewPermanentError(err error) *SendError {
+	return &SendError{Class: entities.DeliveryErrorPermanent, Err: err}
+}
+
+// NewTimeoutError creates an error for sends the provider did not answer in time
+func NewTimeoutError(err error) *SendError {
+	return &SendError{Class: entities.DeliveryErrorTimeout, Err: err}
+}
+
+// ClassifyError returns the error class of a send error
+func ClassifyError(err error) entities.DeliveryErrorClass {
+	if err == nil {
+		return entities.DeliveryErrorNone
+	}
+
+	var sendErr *SendError
+	if errors.As(err, &sendErr) {
+		return sendErr.Class
+	}
+
+	if errors.Is(err, context.DeadlineExceeded) {
+		return entities.DeliveryErrorTimeout
+	}
+
+	return entities.DeliveryErrorTemporary
+}
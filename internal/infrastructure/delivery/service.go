@@ -0,0 +1,298 @@
+package delivery
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"otp-server/internal/domain/entities"
+	"otp-server/internal/infrastructure/circuitbreaker"
+	"otp-server/internal/infrastructure/config"
+	"otp-server/internal/infrastructure/logger"
+	"otp-server/internal/infrastructure/metrics"
+	"otp-server/internal/infrastructure/redis"
+	"otp-server/internal/infrastructure/retry"
+)
+
+// Service delivers OTP codes through the provider configured for each
+// channel. Every provider gets its own timeout and circuit breaker, and
+// temporary failures are retried.
+type Service struct {
+	config           *config.DeliveryConfig
+	registry         *Registry
+	providers        map[string]*provider
+	channelProviders map[entities.OTPChannel]string
+	templates        *Templates
+	tenantTemplates  map[int]*Templates
+	mu               sync.RWMutex
+	redisClient      *redis.Client
+	logger           logger.Logger
+	metrics          *metrics.MetricsService
+}
+
+type provider struct {
+	sender  Sender
+	timeout time.Duration
+	breaker *circuitbreaker.CircuitBreaker
+}
+
+// NewService creates a new delivery service for the configured providers
+func NewService(cfg *config.DeliveryConfig, redisClient *redis.Client, logger logger.Logger, metricsService *metrics.MetricsService) (*Service, error) {
+	templates, err := LoadTemplates(&cfg.Templates)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &Service{
+		config:           cfg,
+		templates:        templates,
+		tenantTemplates:  make(map[int]*Templates),
+		registry:         NewRegistry(),
+		providers:        make(map[string]*provider),
+		channelProviders: make(map[entities.OTPChannel]string),
+		redisClient:      redisClient,
+		logger:           logger,
+		metrics:          metricsService,
+	}
+
+	channelProviders := cfg.ChannelProviders
+	if len(channelProviders) == 0 {
+		channelProviders = map[string]string{string(entities.OTPChannelSMS): cfg.Provider}
+	}
+
+	for channel, name := range channelProviders {
+		if !entities.OTPChannel(channel).IsValid() {
+			return nil, fmt.Errorf("unknown delivery channel %q", channel)
+		}
+		if err := s.addProvider(name); err != nil {
+			return nil, err
+		}
+		s.channelProviders[entities.OTPChannel(channel)] = name
+	}
+
+	return s, nil
+}
+
+// SetTenantTemplates loads the message templates of a tenant, replacing the
+// ones it had. A broken template leaves the previous ones in place.
+func (s *Service) SetTenantTemplates(tenantID int, cfg *config.TemplateConfig) error {
+	templates, err := LoadTemplates(cfg)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	s.tenantTemplates[tenantID] = templates
+	s.mu.Unlock()
+
+	return nil
+}
+
+// ResolveLocale picks the message locale of the tenant for the preferred locales
+func (s *Service) ResolveLocale(tenantID int, preferences []string) string {
+	return s.templatesFor(tenantID).Resolve(preferences)
+}
+
+// templatesFor returns the message templates of the tenant, or the ones
+// configured for the server
+func (s *Service) templatesFor(tenantID int) *Templates {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if templates, ok := s.tenantTemplates[tenantID]; ok {
+		return templates
+	}
+	return s.templates
+}
+
+// SupportsChannel checks if a provider is configured for the channel
+func (s *Service) SupportsChannel(channel entities.OTPChannel) bool {
+	_, ok := s.channelProviders[channel]
+	return ok
+}
+
+// Deliver renders the OTP message for the challenge and sends it
+func (s *Service) Deliver(ctx context.Context, challenge *entities.OTPChallenge, code string) (*entities.DeliveryResult, error) {
+	name, ok := s.channelProviders[challenge.Channel]
+	if !ok {
+		err := &SendError{
+			Class: entities.DeliveryErrorConfiguration,
+			Err:   fmt.Errorf("no delivery provider configured for channel %q", challenge.Channel),
+		}
+		return &entities.DeliveryResult{Channel: challenge.Channel, ErrorClass: err.Class, SentAt: time.Now()}, err
+	}
+
+	body, err := s.templatesFor(challenge.TenantID).Render(challenge, code)
+	if err != nil {
+		sendErr := &SendError{Class: entities.DeliveryErrorConfiguration, Err: fmt.Errorf("failed to render message: %w", err)}
+		return &entities.DeliveryResult{Channel: challenge.Channel, ErrorClass: sendErr.Class, SentAt: time.Now()}, sendErr
+	}
+
+	msg := &Message{
+		TenantID:    challenge.TenantID,
+		ChallengeID: challenge.ID,
+		PhoneNumber: challenge.PhoneNumber,
+		Email:       challenge.Email,
+		Channel:     challenge.Channel,
+		Code:        code,
+		Body:        body,
+	}
+
+	return s.send(ctx, s.providers[name], msg)
+}
+
+// ParseReceipts verifies and parses a delivery receipt request posted by the
+// named provider
+func (s *Service) ParseReceipts(providerName string, header func(string) string, body []byte) ([]*entities.DeliveryEvent, error) {
+	p, ok := s.providers[providerName]
+	if !ok {
+		return nil, ErrReceiptsUnsupported
+	}
+
+	handler, ok := p.sender.(ReceiptHandler)
+	if !ok {
+		return nil, ErrReceiptsUnsupported
+	}
+
+	if err := handler.VerifyReceipt(header, body); err != nil {
+		return nil, err
+	}
+
+	return handler.ParseReceipts(body)
+}
+
+// addProvider builds the named provider once and wraps it in a circuit breaker
+func (s *Service) addProvider(name string) error {
+	if _, exists := s.providers[name]; exists {
+		return nil
+	}
+
+	sender, err := s.registry.Build(name, s.config, s.logger)
+	if err != nil {
+		return err
+	}
+
+	cbConfig := circuitbreaker.DefaultConfig()
+	if s.config.CircuitFailureThreshold > 0 {
+		cbConfig.FailureThreshold = s.config.CircuitFailureThreshold
+	}
+	if s.config.CircuitTimeout > 0 {
+		cbConfig.Timeout = s.config.CircuitTimeout
+	}
+
+	s.providers[name] = &provider{
+		sender:  sender,
+		timeout: s.providerTimeout(name),
+		breaker: circuitbreaker.NewCircuitBreaker(cbConfig, s.redisClient, "circuit_breaker:delivery:"+name, s.logger),
+	}
+
+	return nil
+}
+
+// providerTimeout returns the provider's own timeout, falling back to the default
+func (s *Service) providerTimeout(name string) time.Duration {
+	var timeout time.Duration
+	switch name {
+	case "http":
+		timeout = s.config.HTTP.Timeout
+	case "file":
+		timeout = s.config.File.Timeout
+	case "log":
+		timeout = s.config.Log.Timeout
+	}
+
+	if timeout <= 0 {
+		timeout = s.config.Timeout
+	}
+	return timeout
+}
+
+// send hands the message to the provider, retrying temporary failures.
+// Permanent failures say nothing about the provider's health, so they do
+// not count against its circuit breaker.
+func (s *Service) send(ctx context.Context, p *provider, msg *Message) (*entities.DeliveryResult, error) {
+	result := &entities.DeliveryResult{
+		Provider: p.sender.Name(),
+		Channel:  msg.Channel,
+		SentAt:   time.Now(),
+	}
+
+	retryConfig := retry.RetryWithExponentialBackoff(s.config.MaxAttempts, s.config.RetryDelay)
+	if retryConfig.MaxAttempts < 1 {
+		retryConfig.MaxAttempts = 1
+	}
+	retryConfig.RetryableErrors = []error{ErrRetryable}
+	retryConfig.OnRetry = func(attempt int, err error) {
+		s.logger.Warn(ctx, "Retrying OTP delivery",
+			logger.F("provider", result.Provider),
+			logger.F("challenge_id", msg.ChallengeID),
+			logger.F("attempt", attempt),
+			logger.F("error", err))
+	}
+
+	var sendResult *SendResult
+	err := retry.Retry(ctx, retryConfig, func() error {
+		result.Attempts++
+
+		var permanentErr error
+		err := p.breaker.Execute(ctx, func() error {
+			sendCtx, cancel := context.WithTimeout(ctx, p.timeout)
+			defer cancel()
+
+			res, err := p.sender.Send(sendCtx, msg)
+			if err != nil {
+				if ClassifyError(err) == entities.DeliveryErrorPermanent {
+					permanentErr = err
+					return nil
+				}
+				return err
+			}
+
+			sendResult = res
+			return nil
+		})
+
+		if errors.Is(err, circuitbreaker.ErrCircuitOpen) {
+			return &SendError{Class: entities.DeliveryErrorCircuitOpen, Err: err}
+		}
+		if permanentErr != nil {
+			return permanentErr
+		}
+		return err
+	})
+
+	if err != nil {
+		result.ErrorClass = ClassifyError(err)
+
+		s.logger.Error(ctx, "Failed to deliver OTP",
+			logger.F("provider", result.Provider),
+			logger.F("challenge_id", msg.ChallengeID),
+			logger.F("error_class", result.ErrorClass),
+			logger.F("attempts", result.Attempts),
+			logger.F("error", err))
+
+		if s.metrics != nil {
+			s.metrics.RecordOTPDelivery(msg.TenantID, result.Provider, string(result.Channel), string(result.ErrorClass))
+		}
+
+		return result, err
+	}
+
+	if sendResult != nil {
+		result.MessageID = sendResult.MessageID
+		result.Cost = sendResult.Cost
+		result.Currency = sendResult.Currency
+		result.Status = sendResult.Status
+	}
+	if result.Status == "" {
+		result.Status = entities.DeliveryStatusSent
+	}
+
+	if s.metrics != nil {
+		s.metrics.RecordOTPDelivery(msg.TenantID, result.Provider, string(result.Channel), "delivered")
+	}
+
+	return result, nil
+}
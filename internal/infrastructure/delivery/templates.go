@@ -0,0 +1,209 @@
+package delivery
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"text/template"
+	"time"
+
+	"otp-server/internal/domain/entities"
+	"otp-server/internal/infrastructure/config"
+)
+
+// defaultPurpose is the template used for purposes without their own
+const defaultPurpose = "default"
+
+var (
+	androidAppHashPattern = regexp.MustCompile(`^[A-Za-z0-9+/]{11}$`)
+	webOTPDomainPattern   = regexp.MustCompile(`^[a-z0-9.-]+(:[0-9]+)?$`)
+)
+
+// TemplateData holds the variables available to message templates
+type TemplateData struct {
+	Code          string
+	ExpiryMinutes int
+	AppName       string
+	Purpose       string
+	// Summary describes the operation a transaction code signs
+	Summary string
+}
+
+// Templates renders OTP messages from per-locale, per-purpose templates
+type Templates struct {
+	config    *config.TemplateConfig
+	templates map[string]map[string]*template.Template
+}
+
+// LoadTemplates reads every <locale>.json file in the template directory.
+// Each file maps a purpose, or "default", to a text/template. Every template
+// is rendered once with sample data, so a broken template fails startup
+// instead of a delivery.
+func LoadTemplates(cfg *config.TemplateConfig) (*Templates, error) {
+	t := &Templates{
+		config:    cfg,
+		templates: make(map[string]map[string]*template.Template),
+	}
+
+	files, err := filepath.Glob(filepath.Join(cfg.Dir, "*.json"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list message templates: %w", err)
+	}
+
+	for _, file := range files {
+		locale := normalizeLocale(strings.TrimSuffix(filepath.Base(file), ".json"))
+
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read message templates %s: %w", file, err)
+		}
+
+		var texts map[string]string
+		if err := json.Unmarshal(data, &texts); err != nil {
+			return nil, fmt.Errorf("invalid message templates %s: %w", file, err)
+		}
+
+		t.templates[locale] = make(map[string]*template.Template, len(texts))
+		for purpose, text := range texts {
+			tmpl, err := template.New(locale + "/" + purpose).Option("missingkey=error").Parse(text)
+			if err != nil {
+				return nil, fmt.Errorf("invalid message template %s/%s: %w", locale, purpose, err)
+			}
+			if err := validateTemplate(tmpl, purpose); err != nil {
+				return nil, fmt.Errorf("invalid message template %s/%s: %w", locale, purpose, err)
+			}
+			t.templates[locale][purpose] = tmpl
+		}
+	}
+
+	if _, ok := t.templates[t.defaultLocale()][defaultPurpose]; !ok {
+		return nil, fmt.Errorf("no %q message template for default locale %q in %s", defaultPurpose, t.defaultLocale(), cfg.Dir)
+	}
+	if _, ok := t.templates[t.defaultLocale()][string(entities.OTPPurposeTransaction)]; !ok {
+		return nil, fmt.Errorf("no %q message template for default locale %q in %s", entities.OTPPurposeTransaction, t.defaultLocale(), cfg.Dir)
+	}
+
+	if cfg.AndroidAppHash != "" && !androidAppHashPattern.MatchString(cfg.AndroidAppHash) {
+		return nil, fmt.Errorf("DELIVERY_ANDROID_APP_HASH must be the 11 character SMS Retriever app hash")
+	}
+	if cfg.WebOTPDomain != "" && !webOTPDomainPattern.MatchString(cfg.WebOTPDomain) {
+		return nil, fmt.Errorf("DELIVERY_WEBOTP_DOMAIN must be a bare lowercase host, e.g. example.com")
+	}
+
+	return t, nil
+}
+
+// Resolve returns the first preferred locale that has templates, trying the
+// base language of regional locales too, or the default locale
+func (t *Templates) Resolve(preferences []string) string {
+	for _, preference := range preferences {
+		locale := normalizeLocale(preference)
+		if _, ok := t.templates[locale]; ok {
+			return locale
+		}
+		if base, _, found := strings.Cut(locale, "-"); found {
+			if _, ok := t.templates[base]; ok {
+				return base
+			}
+		}
+	}
+	return t.defaultLocale()
+}
+
+// Render renders the message for the challenge. SMS messages get the
+// autofill lines: the Android app hash, then the WebOTP "@domain #code" line,
+// which must be the last line.
+func (t *Templates) Render(challenge *entities.OTPChallenge, code string) (string, error) {
+	minutes := int(math.Ceil(time.Until(challenge.ExpiresAt).Minutes()))
+	if minutes < 1 {
+		minutes = 1
+	}
+
+	var body bytes.Buffer
+	err := t.lookup(challenge.Locale, string(challenge.Purpose), challenge.Summary != "").Execute(&body, TemplateData{
+		Code:          code,
+		ExpiryMinutes: minutes,
+		AppName:       t.config.AppName,
+		Purpose:       string(challenge.Purpose),
+		Summary:       challenge.Summary,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	if challenge.Channel != entities.OTPChannelSMS || (t.config.AndroidAppHash == "" && t.config.WebOTPDomain == "") {
+		return body.String(), nil
+	}
+
+	body.WriteString("\n")
+	if t.config.AndroidAppHash != "" {
+		body.WriteString("\n" + t.config.AndroidAppHash)
+	}
+	if t.config.WebOTPDomain != "" {
+		body.WriteString("\n@" + t.config.WebOTPDomain + " #" + code)
+	}
+
+	return body.String(), nil
+}
+
+// lookup falls back from the locale's purpose template to its default
+// template, then to the default locale. Messages carrying a transaction
+// summary skip default templates, which would leave the summary out.
+func (t *Templates) lookup(locale, purpose string, withSummary bool) *template.Template {
+	for _, l := range []string{normalizeLocale(locale), t.defaultLocale()} {
+		if tmpl, ok := t.templates[l][purpose]; ok {
+			return tmpl
+		}
+		if withSummary {
+			continue
+		}
+		if tmpl, ok := t.templates[l][defaultPurpose]; ok {
+			return tmpl
+		}
+	}
+	if withSummary {
+		return t.templates[t.defaultLocale()][string(entities.OTPPurposeTransaction)]
+	}
+	return t.templates[t.defaultLocale()][defaultPurpose]
+}
+
+func (t *Templates) defaultLocale() string {
+	return normalizeLocale(t.config.DefaultLocale)
+}
+
+// validateTemplate renders the template with sample data and makes sure the
+// code, and for transaction templates the summary, ends up in the message
+func validateTemplate(tmpl *template.Template, purpose string) error {
+	const (
+		sampleCode    = "918273"
+		sampleSummary = "transfer 100 EUR to DE89370400440532013000"
+	)
+
+	var out bytes.Buffer
+	err := tmpl.Execute(&out, TemplateData{
+		Code:          sampleCode,
+		ExpiryMinutes: 2,
+		AppName:       "App",
+		Purpose:       purpose,
+		Summary:       sampleSummary,
+	})
+	if err != nil {
+		return err
+	}
+	if !strings.Contains(out.String(), sampleCode) {
+		return fmt.Errorf("template does not include {{.Code}}")
+	}
+	if purpose == string(entities.OTPPurposeTransaction) && !strings.Contains(out.String(), sampleSummary) {
+		return fmt.Errorf("transaction template does not include {{.Summary}}")
+	}
+	return nil
+}
+
+func normalizeLocale(locale string) string {
+	return strings.ToLower(strings.ReplaceAll(strings.TrimSpace(locale), "_", "-"))
+}
@@ -22,26 +22,70 @@ func NewEventListener(cfg *config.EventsConfig, logger logger.Logger) *EventList
 func (el *EventListener) HandleOTPEvent(ctx context.Context, event *Event) error {
 	if event.Type == el.config.EventTypes.OTPGenerated.Name {
 		phoneNumber, _ := event.Payload["phone_number"].(string)
-		otpCode, _ := event.Payload["otp_code"].(string)
+		purpose, _ := event.Payload["purpose"].(string)
 
-		fmt.Printf("OTP Generated: %s for %s\n", otpCode, phoneNumber)
+		fmt.Printf("OTP Generated for %s (%s)\n", phoneNumber, purpose)
 
 		el.logger.Info(ctx, "OTP event processed",
 			logger.F("event_type", event.Type),
 			logger.F("phone_number", phoneNumber),
+			logger.F("purpose", purpose),
 			logger.F("event_id", event.ID))
 	}
 
 	if event.Type == el.config.EventTypes.OTPVerified.Name {
 		phoneNumber, _ := event.Payload["phone_number"].(string)
-		userID, _ := event.Payload["user_id"].(int)
+		challengeID, _ := event.Payload["challenge_id"].(string)
+		purpose, _ := event.Payload["purpose"].(string)
 
-		fmt.Printf("OTP Verified for %s, User ID: %d\n", phoneNumber, userID)
+		fmt.Printf("OTP Verified for %s (%s)\n", phoneNumber, purpose)
 
 		el.logger.Info(ctx, "OTP verified event processed",
 			logger.F("event_type", event.Type),
 			logger.F("phone_number", phoneNumber),
-			logger.F("user_id", userID),
+			logger.F("challenge_id", challengeID),
+			logger.F("purpose", purpose),
+			logger.F("event_id", event.ID))
+	}
+
+	if event.Type == el.config.EventTypes.OTPLocked.Name {
+		phoneNumber, _ := event.Payload["phone_number"].(string)
+		lockoutSeconds, _ := event.Payload["lockout_seconds"].(float64)
+		lockoutCount, _ := event.Payload["lockout_count"].(float64)
+
+		el.logger.Warn(ctx, "OTP lockout event processed",
+			logger.F("event_type", event.Type),
+			logger.F("phone_number", phoneNumber),
+			logger.F("lockout_seconds", int(lockoutSeconds)),
+			logger.F("lockout_count", int(lockoutCount)),
+			logger.F("event_id", event.ID))
+	}
+
+	if event.Type == el.config.EventTypes.OTPChannelFallback.Name {
+		phoneNumber, _ := event.Payload["phone_number"].(string)
+		fromChannel, _ := event.Payload["from_channel"].(string)
+		toChannel, _ := event.Payload["to_channel"].(string)
+		reason, _ := event.Payload["reason"].(string)
+
+		el.logger.Info(ctx, "OTP channel fallback event processed",
+			logger.F("event_type", event.Type),
+			logger.F("phone_number", phoneNumber),
+			logger.F("from_channel", fromChannel),
+			logger.F("to_channel", toChannel),
+			logger.F("reason", reason),
+			logger.F("event_id", event.ID))
+	}
+
+	if event.Type == el.config.EventTypes.OTPTransactionVerified.Name {
+		challengeID, _ := event.Payload["challenge_id"].(string)
+		operation, _ := event.Payload["operation"].(string)
+		payloadHash, _ := event.Payload["payload_hash"].(string)
+
+		el.logger.Info(ctx, "OTP transaction verified event processed",
+			logger.F("event_type", event.Type),
+			logger.F("challenge_id", challengeID),
+			logger.F("operation", operation),
+			logger.F("payload_hash", payloadHash),
 			logger.F("event_id", event.ID))
 	}
 
@@ -75,6 +119,45 @@ func (el *EventListener) HandleUserEvent(ctx context.Context, event *Event) erro
 			logger.F("event_id", event.ID))
 	}
 
+	if event.Type == el.config.EventTypes.RecoveryCodeUsed.Name {
+		phoneNumber, _ := event.Payload["phone_number"].(string)
+		userID, _ := event.Payload["user_id"].(float64)
+		remaining, _ := event.Payload["remaining"].(float64)
+
+		el.logger.Warn(ctx, "Recovery code used event processed",
+			logger.F("event_type", event.Type),
+			logger.F("phone_number", phoneNumber),
+			logger.F("user_id", int(userID)),
+			logger.F("remaining", int(remaining)),
+			logger.F("event_id", event.ID))
+	}
+
+	if event.Type == el.config.EventTypes.UserAdminAction.Name {
+		userID, _ := event.Payload["user_id"].(float64)
+		action, _ := event.Payload["action"].(string)
+		actor, _ := event.Payload["actor"].(string)
+
+		el.logger.Info(ctx, "User admin action event processed",
+			logger.F("event_type", event.Type),
+			logger.F("user_id", int(userID)),
+			logger.F("action", action),
+			logger.F("actor", actor),
+			logger.F("event_id", event.ID))
+	}
+
+	if event.Type == el.config.EventTypes.PhoneNumberChanged.Name {
+		userID, _ := event.Payload["user_id"].(float64)
+		oldPhoneNumber, _ := event.Payload["old_phone_number"].(string)
+		phoneNumber, _ := event.Payload["phone_number"].(string)
+
+		el.logger.Info(ctx, "Phone number changed event processed",
+			logger.F("event_type", event.Type),
+			logger.F("user_id", int(userID)),
+			logger.F("old_phone_number", oldPhoneNumber),
+			logger.F("phone_number", phoneNumber),
+			logger.F("event_id", event.ID))
+	}
+
 	return nil
 }
 
@@ -102,9 +185,9 @@ func (el *EventListener) HandleAllEvents(ctx context.Context, event *Event) erro
 		logger.F("payload", event.Payload))
 
 	switch event.Type {
-	case el.config.EventTypes.OTPGenerated.Name, el.config.EventTypes.OTPVerified.Name:
+	case el.config.EventTypes.OTPGenerated.Name, el.config.EventTypes.OTPVerified.Name, el.config.EventTypes.OTPLocked.Name, el.config.EventTypes.OTPChannelFallback.Name, el.config.EventTypes.OTPTransactionVerified.Name:
 		return el.HandleOTPEvent(ctx, event)
-	case el.config.EventTypes.UserCreated.Name, el.config.EventTypes.UserLoggedIn.Name:
+	case el.config.EventTypes.UserCreated.Name, el.config.EventTypes.UserLoggedIn.Name, el.config.EventTypes.RecoveryCodeUsed.Name, el.config.EventTypes.UserAdminAction.Name, el.config.EventTypes.PhoneNumberChanged.Name:
 		return el.HandleUserEvent(ctx, event)
 	case el.config.EventTypes.RateLimited.Name:
 		return el.HandleRateLimitEvent(ctx, event)
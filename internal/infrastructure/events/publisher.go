@@ -3,6 +3,8 @@ package events
 import (
 	"context"
 	"fmt"
+	"time"
+
 	"otp-server/internal/infrastructure/config"
 	"otp-server/internal/infrastructure/logger"
 	"otp-server/internal/infrastructure/redis"
@@ -44,18 +46,19 @@ func (p *Publisher) Publish(ctx context.Context, event *Event) error {
 	return nil
 }
 
-func (p *Publisher) PublishOTPGenerated(ctx context.Context, phoneNumber, otpCode string) error {
+func (p *Publisher) PublishOTPGenerated(ctx context.Context, phoneNumber, purpose string) error {
 	event := NewEvent(p.config.EventTypes.OTPGenerated.Name, map[string]interface{}{
 		"phone_number": phoneNumber,
-		"otp_code":     otpCode,
+		"purpose":      purpose,
 	})
 	return p.Publish(ctx, event)
 }
 
-func (p *Publisher) PublishOTPVerified(ctx context.Context, phoneNumber string, userID int) error {
+func (p *Publisher) PublishOTPVerified(ctx context.Context, phoneNumber, challengeID, purpose string) error {
 	event := NewEvent(p.config.EventTypes.OTPVerified.Name, map[string]interface{}{
 		"phone_number": phoneNumber,
-		"user_id":      userID,
+		"challenge_id": challengeID,
+		"purpose":      purpose,
 	})
 	return p.Publish(ctx, event)
 }
@@ -84,6 +87,68 @@ func (p *Publisher) PublishRateLimited(ctx context.Context, endpoint, identifier
 	return p.Publish(ctx, event)
 }
 
+func (p *Publisher) PublishOTPLocked(ctx context.Context, phoneNumber string, lockoutDuration time.Duration, lockoutCount int) error {
+	event := NewEvent(p.config.EventTypes.OTPLocked.Name, map[string]interface{}{
+		"phone_number":    phoneNumber,
+		"lockout_seconds": int(lockoutDuration.Seconds()),
+		"lockout_count":   lockoutCount,
+		"locked_until":    time.Now().Add(lockoutDuration),
+	})
+	return p.Publish(ctx, event)
+}
+
+func (p *Publisher) PublishOTPChannelFallback(ctx context.Context, phoneNumber, challengeID, fromChannel, toChannel, reason string) error {
+	event := NewEvent(p.config.EventTypes.OTPChannelFallback.Name, map[string]interface{}{
+		"phone_number": phoneNumber,
+		"challenge_id": challengeID,
+		"from_channel": fromChannel,
+		"to_channel":   toChannel,
+		"reason":       reason,
+	})
+	return p.Publish(ctx, event)
+}
+
+func (p *Publisher) PublishOTPTransactionVerified(ctx context.Context, phoneNumber, challengeID, operation, summary, payloadHash string) error {
+	event := NewEvent(p.config.EventTypes.OTPTransactionVerified.Name, map[string]interface{}{
+		"phone_number": phoneNumber,
+		"challenge_id": challengeID,
+		"operation":    operation,
+		"summary":      summary,
+		"payload_hash": payloadHash,
+	})
+	return p.Publish(ctx, event)
+}
+
+func (p *Publisher) PublishRecoveryCodeUsed(ctx context.Context, userID int, phoneNumber string, remaining int) error {
+	event := NewEvent(p.config.EventTypes.RecoveryCodeUsed.Name, map[string]interface{}{
+		"user_id":      userID,
+		"phone_number": phoneNumber,
+		"remaining":    remaining,
+	})
+	return p.Publish(ctx, event)
+}
+
+func (p *Publisher) PublishUserAdminAction(ctx context.Context, userID, tenantID int, phoneNumber, action, actor string) error {
+	event := NewEvent(p.config.EventTypes.UserAdminAction.Name, map[string]interface{}{
+		"user_id":      userID,
+		"tenant_id":    tenantID,
+		"phone_number": phoneNumber,
+		"action":       action,
+		"actor":        actor,
+	})
+	return p.Publish(ctx, event)
+}
+
+func (p *Publisher) PublishPhoneNumberChanged(ctx context.Context, userID, tenantID int, oldPhoneNumber, phoneNumber string) error {
+	event := NewEvent(p.config.EventTypes.PhoneNumberChanged.Name, map[string]interface{}{
+		"user_id":          userID,
+		"tenant_id":        tenantID,
+		"old_phone_number": oldPhoneNumber,
+		"phone_number":     phoneNumber,
+	})
+	return p.Publish(ctx, event)
+}
+
 func (p *Publisher) isEventEnabled(eventType string) bool {
 	switch eventType {
 	case p.config.EventTypes.OTPGenerated.Name:
@@ -96,6 +161,18 @@ func (p *Publisher) isEventEnabled(eventType string) bool {
 		return p.config.EventTypes.UserLoggedIn.Enabled
 	case p.config.EventTypes.RateLimited.Name:
 		return p.config.EventTypes.RateLimited.Enabled
+	case p.config.EventTypes.OTPLocked.Name:
+		return p.config.EventTypes.OTPLocked.Enabled
+	case p.config.EventTypes.OTPChannelFallback.Name:
+		return p.config.EventTypes.OTPChannelFallback.Enabled
+	case p.config.EventTypes.OTPTransactionVerified.Name:
+		return p.config.EventTypes.OTPTransactionVerified.Enabled
+	case p.config.EventTypes.RecoveryCodeUsed.Name:
+		return p.config.EventTypes.RecoveryCodeUsed.Enabled
+	case p.config.EventTypes.UserAdminAction.Name:
+		return p.config.EventTypes.UserAdminAction.Enabled
+	case p.config.EventTypes.PhoneNumberChanged.Name:
+		return p.config.EventTypes.PhoneNumberChanged.Enabled
 	default:
 		return true
 	}
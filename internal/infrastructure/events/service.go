@@ -2,6 +2,8 @@ package events
 
 import (
 	"context"
+	"time"
+
 	"otp-server/internal/infrastructure/config"
 	"otp-server/internal/infrastructure/logger"
 	"otp-server/internal/infrastructure/redis"
@@ -25,12 +27,12 @@ func (es *EventService) Publish(ctx context.Context, event *Event) error {
 	return es.publisher.Publish(ctx, event)
 }
 
-func (es *EventService) PublishOTPGenerated(ctx context.Context, phoneNumber, otpCode string) error {
-	return es.publisher.PublishOTPGenerated(ctx, phoneNumber, otpCode)
+func (es *EventService) PublishOTPGenerated(ctx context.Context, phoneNumber, purpose string) error {
+	return es.publisher.PublishOTPGenerated(ctx, phoneNumber, purpose)
 }
 
-func (es *EventService) PublishOTPVerified(ctx context.Context, phoneNumber string, userID int) error {
-	return es.publisher.PublishOTPVerified(ctx, phoneNumber, userID)
+func (es *EventService) PublishOTPVerified(ctx context.Context, phoneNumber, challengeID, purpose string) error {
+	return es.publisher.PublishOTPVerified(ctx, phoneNumber, challengeID, purpose)
 }
 
 func (es *EventService) PublishUserCreated(ctx context.Context, userID int, phoneNumber string) error {
@@ -45,6 +47,30 @@ func (es *EventService) PublishRateLimited(ctx context.Context, endpoint, identi
 	return es.publisher.PublishRateLimited(ctx, endpoint, identifier)
 }
 
+func (es *EventService) PublishOTPLocked(ctx context.Context, phoneNumber string, lockoutDuration time.Duration, lockoutCount int) error {
+	return es.publisher.PublishOTPLocked(ctx, phoneNumber, lockoutDuration, lockoutCount)
+}
+
+func (es *EventService) PublishOTPChannelFallback(ctx context.Context, phoneNumber, challengeID, fromChannel, toChannel, reason string) error {
+	return es.publisher.PublishOTPChannelFallback(ctx, phoneNumber, challengeID, fromChannel, toChannel, reason)
+}
+
+func (es *EventService) PublishOTPTransactionVerified(ctx context.Context, phoneNumber, challengeID, operation, summary, payloadHash string) error {
+	return es.publisher.PublishOTPTransactionVerified(ctx, phoneNumber, challengeID, operation, summary, payloadHash)
+}
+
+func (es *EventService) PublishRecoveryCodeUsed(ctx context.Context, userID int, phoneNumber string, remaining int) error {
+	return es.publisher.PublishRecoveryCodeUsed(ctx, userID, phoneNumber, remaining)
+}
+
+func (es *EventService) PublishUserAdminAction(ctx context.Context, userID, tenantID int, phoneNumber, action, actor string) error {
+	return es.publisher.PublishUserAdminAction(ctx, userID, tenantID, phoneNumber, action, actor)
+}
+
+func (es *EventService) PublishPhoneNumberChanged(ctx context.Context, userID, tenantID int, oldPhoneNumber, phoneNumber string) error {
+	return es.publisher.PublishPhoneNumberChanged(ctx, userID, tenantID, oldPhoneNumber, phoneNumber)
+}
+
 func (es *EventService) Subscribe(ctx context.Context, eventType string, handler EventHandler) error {
 	return es.subscriber.Subscribe(ctx, eventType, handler)
 }
@@ -0,0 +1,104 @@
+package keyring
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+)
+
+// JSONWebKey is the public part of a key in JWK form (RFC 7517)
+type JSONWebKey struct {
+	KeyType   string `json:"kty"`
+	KeyID     string `json:"kid"`
+	Use       string `json:"use"`
+	Algorithm string `json:"alg"`
+	Curve     string `json:"crv,omitempty"`
+	N         string `json:"n,omitempty"`
+	E         string `json:"e,omitempty"`
+	X         string `json:"x,omitempty"`
+	Y         string `json:"y,omitempty"`
+}
+
+// JWKSet is a JWK set, as served at /.well-known/jwks.json
+type JWKSet struct {
+	Keys []JSONWebKey `json:"keys"`
+}
+
+func publicJWK(key *Key) JSONWebKey {
+	jwk := requiredMembers(key.Public)
+	jwk.KeyID = key.ID
+	jwk.Use = "sig"
+	jwk.Algorithm = key.Algorithm
+	return jwk
+}
+
+// requiredMembers returns the members of the JWK that identify the key
+func requiredMembers(public crypto.PublicKey) JSONWebKey {
+	switch key := public.(type) {
+	case *rsa.PublicKey:
+		return JSONWebKey{
+			KeyType: "RSA",
+			N:       encode(key.N.Bytes()),
+			E:       encode(big.NewInt(int64(key.E)).Bytes()),
+		}
+	case *ecdsa.PublicKey:
+		size := (key.Curve.Params().BitSize + 7) / 8
+		return JSONWebKey{
+			KeyType: "EC",
+			Curve:   key.Curve.Params().Name,
+			X:       encode(key.X.FillBytes(make([]byte, size))),
+			Y:       encode(key.Y.FillBytes(make([]byte, size))),
+		}
+	case ed25519.PublicKey:
+		return JSONWebKey{
+			KeyType: "OKP",
+			Curve:   "Ed25519",
+			X:       encode(key),
+		}
+	}
+
+	return JSONWebKey{}
+}
+
+// thumbprint returns the RFC 7638 JWK thumbprint of the key, used as its
+// kid: the same key gets the same kid on every instance
+func thumbprint(public crypto.PublicKey) string {
+	jwk := requiredMembers(public)
+
+	// The required members in lexicographic order, without whitespace
+	var members interface{}
+	switch jwk.KeyType {
+	case "RSA":
+		members = struct {
+			E   string `json:"e"`
+			Kty string `json:"kty"`
+			N   string `json:"n"`
+		}{jwk.E, jwk.KeyType, jwk.N}
+	case "EC":
+		members = struct {
+			Crv string `json:"crv"`
+			Kty string `json:"kty"`
+			X   string `json:"x"`
+			Y   string `json:"y"`
+		}{jwk.Curve, jwk.KeyType, jwk.X, jwk.Y}
+	default:
+		members = struct {
+			Crv string `json:"crv"`
+			Kty string `json:"kty"`
+			X   string `json:"x"`
+		}{jwk.Curve, jwk.KeyType, jwk.X}
+	}
+
+	data, _ := json.Marshal(members)
+	sum := sha256.Sum256(data)
+	return encode(sum[:])
+}
+
+func encode(data []byte) string {
+	return base64.RawURLEncoding.EncodeToString(data)
+}
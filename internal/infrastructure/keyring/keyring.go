@@ -0,0 +1,559 @@
+package keyring
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"otp-server/internal/infrastructure/config"
+	"otp-server/internal/infrastructure/logger"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// Supported signing algorithms
+const (
+	AlgorithmHS256 = "HS256"
+	AlgorithmRS256 = "RS256"
+	AlgorithmES256 = "ES256"
+	AlgorithmES384 = "ES384"
+	AlgorithmES512 = "ES512"
+	AlgorithmEdDSA = "EdDSA"
+)
+
+const rsaKeyBits = 2048
+
+// Key is a key of the keyring. Keys loaded from a public key PEM only
+// verify tokens.
+type Key struct {
+	ID        string
+	Algorithm string
+	Private   crypto.Signer
+	Public    crypto.PublicKey
+	CreatedAt time.Time
+	path      string
+}
+
+// CanSign checks if the key holds a private key
+func (k *Key) CanSign() bool {
+	return k.Private != nil
+}
+
+// Keyring signs and verifies JWTs. With HS256 it uses the shared JWT_SECRET;
+// otherwise it holds asymmetric keys loaded from PEM files, one of which
+// signs while all of them verify, so other services only need the public
+// keys served as a JWKS.
+//
+// Keys come either from a fixed list of files, the first of which signs, or
+// from a directory that is reloaded periodically. With a rotation interval,
+// the keyring generates a new key in the directory whenever the newest one
+// is older than the interval. A new key only starts signing one reload
+// interval after it appeared, so every instance sharing the directory knows
+// it by then, and old keys are removed once no token they signed can still
+// be valid.
+type Keyring struct {
+	config *config.JWTConfig
+	logger logger.Logger
+
+	mu      sync.RWMutex
+	keys    map[string]*Key
+	signing *Key
+	// retain is how long a retired key keeps verifying: as long as the
+	// longest-lived token it may have signed
+	retain time.Duration
+
+	stop chan struct{}
+	once sync.Once
+}
+
+// New creates a keyring from the JWT configuration and, when keys come from
+// a directory, starts reloading it
+func New(cfg *config.JWTConfig, logger logger.Logger) (*Keyring, error) {
+	if cfg.KeyReloadInterval <= 0 {
+		cfg.KeyReloadInterval = time.Minute
+	}
+
+	k := &Keyring{
+		config: cfg,
+		logger: logger,
+		keys:   make(map[string]*Key),
+		retain: cfg.Expiry,
+		stop:   make(chan struct{}),
+	}
+
+	switch {
+	case cfg.Algorithm == AlgorithmHS256:
+		if cfg.Secret == "" {
+			return nil, fmt.Errorf("JWT_SECRET is required for %s", AlgorithmHS256)
+		}
+		return k, nil
+	case !isAsymmetric(cfg.Algorithm):
+		return nil, fmt.Errorf("unsupported JWT algorithm: %s", cfg.Algorithm)
+	case len(cfg.KeyFiles) > 0:
+		if err := k.loadFiles(cfg.KeyFiles); err != nil {
+			return nil, err
+		}
+		return k, nil
+	case cfg.KeyDir != "":
+		if err := k.refresh(context.Background()); err != nil {
+			return nil, err
+		}
+		go k.reloadRoutine()
+		return k, nil
+	}
+
+	return nil, fmt.Errorf("JWT_KEY_FILES or JWT_KEY_DIR is required for %s", cfg.Algorithm)
+}
+
+// Retain keeps retired keys for at least d, for tokens that live longer
+// than access tokens
+func (k *Keyring) Retain(d time.Duration) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	if d > k.retain {
+		k.retain = d
+	}
+}
+
+// Close stops reloading the key directory
+func (k *Keyring) Close() error {
+	k.once.Do(func() { close(k.stop) })
+	return nil
+}
+
+// Sign signs the claims with the current signing key, naming it in the kid
+// header
+func (k *Keyring) Sign(claims jwt.Claims) (string, error) {
+	if k.config.Algorithm == AlgorithmHS256 {
+		return jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(k.config.Secret))
+	}
+
+	k.mu.RLock()
+	key := k.signing
+	k.mu.RUnlock()
+
+	if key == nil {
+		return "", fmt.Errorf("no signing key available")
+	}
+
+	token := jwt.NewWithClaims(jwt.GetSigningMethod(key.Algorithm), claims)
+	token.Header["kid"] = key.ID
+	return token.SignedString(key.Private)
+}
+
+// Keyfunc returns the key verifying the token, for jwt.Parse
+func (k *Keyring) Keyfunc(token *jwt.Token) (interface{}, error) {
+	if k.config.Algorithm == AlgorithmHS256 {
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+		}
+		return []byte(k.config.Secret), nil
+	}
+
+	kid, _ := token.Header["kid"].(string)
+
+	k.mu.RLock()
+	key, ok := k.keys[kid]
+	k.mu.RUnlock()
+
+	if !ok {
+		return nil, fmt.Errorf("unknown key: %q", kid)
+	}
+	if token.Method.Alg() != key.Algorithm {
+		return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+	}
+
+	return key.Public, nil
+}
+
+// Algorithm returns the algorithm new tokens are signed with
+func (k *Keyring) Algorithm() string {
+	if k.config.Algorithm == AlgorithmHS256 {
+		return AlgorithmHS256
+	}
+
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+	if k.signing == nil {
+		return k.config.Algorithm
+	}
+	return k.signing.Algorithm
+}
+
+// JWKS returns the public keys of the keyring. It is empty with HS256,
+// whose secret must never be published.
+func (k *Keyring) JWKS() *JWKSet {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+
+	keys := make([]*Key, 0, len(k.keys))
+	for _, key := range k.keys {
+		keys = append(keys, key)
+	}
+	// Newest first, so the signing key and its successor come first
+	sort.Slice(keys, func(i, j int) bool { return keys[i].CreatedAt.After(keys[j].CreatedAt) })
+
+	set := &JWKSet{Keys: make([]JSONWebKey, 0, len(keys))}
+	for _, key := range keys {
+		set.Keys = append(set.Keys, publicJWK(key))
+	}
+
+	return set
+}
+
+// CacheMaxAge returns how long clients may cache the JWKS. A key is
+// published one reload interval before it signs, so clients that cache no
+// longer than that always know the signing key.
+func (k *Keyring) CacheMaxAge() time.Duration {
+	return k.config.KeyReloadInterval
+}
+
+// loadFiles loads a fixed list of keys; the first one signs
+func (k *Keyring) loadFiles(paths []string) error {
+	for i, path := range paths {
+		key, err := loadKey(path, time.Time{})
+		if err != nil {
+			return err
+		}
+
+		if i == 0 {
+			if !key.CanSign() {
+				return fmt.Errorf("signing key %s holds no private key", path)
+			}
+			if key.Algorithm != k.config.Algorithm {
+				return fmt.Errorf("signing key %s is a %s key, not %s", path, key.Algorithm, k.config.Algorithm)
+			}
+			k.signing = key
+		}
+		k.keys[key.ID] = key
+	}
+
+	k.logger.Info(context.Background(), "JWT keys loaded",
+		logger.F("algorithm", k.config.Algorithm),
+		logger.F("keys", len(k.keys)),
+		logger.F("signing_kid", k.signing.ID))
+
+	return nil
+}
+
+func (k *Keyring) reloadRoutine() {
+	ticker := time.NewTicker(k.config.KeyReloadInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-k.stop:
+			return
+		case <-ticker.C:
+			ctx := context.Background()
+			if err := k.refresh(ctx); err != nil {
+				k.logger.Error(ctx, "Failed to reload JWT keys", logger.F("error", err), logger.F("dir", k.config.KeyDir))
+			}
+		}
+	}
+}
+
+// refresh reloads the key directory, generating a new key when rotation is
+// due and removing retired keys whose tokens have all expired
+func (k *Keyring) refresh(ctx context.Context) error {
+	keys, err := loadDir(k.config.KeyDir)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	if k.config.KeyRotationInterval > 0 && (len(keys) == 0 || now.Sub(keys[0].CreatedAt) >= k.config.KeyRotationInterval) {
+		key, err := k.generate()
+		if err != nil {
+			return err
+		}
+		k.logger.Info(ctx, "JWT signing key generated", logger.F("kid", key.ID), logger.F("algorithm", key.Algorithm))
+		keys = append([]*Key{key}, keys...)
+	}
+
+	signing := selectSigningKey(keys, now.Add(-k.config.KeyReloadInterval), k.config.Algorithm)
+	if signing == nil {
+		return fmt.Errorf("no %s private key in %s", k.config.Algorithm, k.config.KeyDir)
+	}
+
+	if k.config.KeyRotationInterval > 0 {
+		keys = k.prune(ctx, keys, signing, now)
+	}
+
+	loaded := make(map[string]*Key, len(keys))
+	for _, key := range keys {
+		loaded[key.ID] = key
+	}
+
+	k.mu.Lock()
+	previous := k.signing
+	k.keys = loaded
+	k.signing = signing
+	k.mu.Unlock()
+
+	if previous == nil || previous.ID != signing.ID {
+		k.logger.Info(ctx, "JWT signing key activated", logger.F("kid", signing.ID), logger.F("keys", len(loaded)))
+	}
+
+	return nil
+}
+
+// prune deletes the files of keys that stopped signing longer ago than a
+// token lives. A key stops signing when a newer one is activated.
+func (k *Keyring) prune(ctx context.Context, keys []*Key, signing *Key, now time.Time) []*Key {
+	k.mu.RLock()
+	retain := k.retain + k.config.KeyReloadInterval
+	k.mu.RUnlock()
+	retired := signing.CreatedAt.Add(k.config.KeyReloadInterval)
+
+	kept := keys[:0]
+	for _, key := range keys {
+		if key.CreatedAt.Before(signing.CreatedAt) && now.Sub(retired) > retain {
+			if err := os.Remove(key.path); err != nil && !os.IsNotExist(err) {
+				k.logger.Error(ctx, "Failed to remove retired JWT key", logger.F("error", err), logger.F("kid", key.ID))
+			} else {
+				k.logger.Info(ctx, "Retired JWT key removed", logger.F("kid", key.ID))
+				continue
+			}
+		}
+		kept = append(kept, key)
+	}
+
+	return kept
+}
+
+// generate creates a new key of the configured algorithm and writes it to
+// the key directory. The file is renamed into place, so other instances
+// never read it half written.
+func (k *Keyring) generate() (*Key, error) {
+	var private crypto.Signer
+	var err error
+	switch k.config.Algorithm {
+	case AlgorithmRS256:
+		private, err = rsa.GenerateKey(rand.Reader, rsaKeyBits)
+	case AlgorithmES256:
+		private, err = ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	case AlgorithmES384:
+		private, err = ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	case AlgorithmES512:
+		private, err = ecdsa.GenerateKey(elliptic.P521(), rand.Reader)
+	case AlgorithmEdDSA:
+		_, private, err = ed25519.GenerateKey(rand.Reader)
+	default:
+		return nil, fmt.Errorf("cannot generate %s keys", k.config.Algorithm)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	der, err := x509.MarshalPKCS8PrivateKey(private)
+	if err != nil {
+		return nil, err
+	}
+
+	// Whole seconds, as the file name records the creation time
+	key, err := newKey(private, private.Public(), time.Now().Truncate(time.Second))
+	if err != nil {
+		return nil, err
+	}
+
+	name := fmt.Sprintf("%d-%s.pem", key.CreatedAt.Unix(), key.ID[:8])
+	tmp, err := os.CreateTemp(k.config.KeyDir, ".key-*")
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(tmp.Name())
+
+	if err := pem.Encode(tmp, &pem.Block{Type: "PRIVATE KEY", Bytes: der}); err != nil {
+		tmp.Close()
+		return nil, err
+	}
+	if err := tmp.Close(); err != nil {
+		return nil, err
+	}
+
+	key.path = filepath.Join(k.config.KeyDir, name)
+	if err := os.Rename(tmp.Name(), key.path); err != nil {
+		return nil, err
+	}
+
+	return key, nil
+}
+
+// selectSigningKey picks the newest private key of the algorithm published
+// before activeBefore, or the newest one at all if none was. Keys are
+// sorted newest first.
+func selectSigningKey(keys []*Key, activeBefore time.Time, algorithm string) *Key {
+	var newest *Key
+	for _, key := range keys {
+		if !key.CanSign() || key.Algorithm != algorithm {
+			continue
+		}
+		if !key.CreatedAt.After(activeBefore) {
+			return key
+		}
+		if newest == nil {
+			newest = key
+		}
+	}
+
+	// Only keys too new to be known everywhere: nothing else can sign
+	return newest
+}
+
+// loadDir loads every .pem file of the directory, newest first. The files
+// are named after the Unix time their key was created, like the generated
+// ones, since file times change whenever the directory is copied.
+func loadDir(dir string) ([]*Key, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var keys []*Key
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".pem") {
+			continue
+		}
+
+		createdAt, err := keyCreatedAt(entry.Name())
+		if err != nil {
+			return nil, err
+		}
+
+		key, err := loadKey(filepath.Join(dir, entry.Name()), createdAt)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+
+	// Keys of the same second are ordered by file name, so every instance
+	// picks the same one
+	sort.SliceStable(keys, func(i, j int) bool {
+		if !keys[i].CreatedAt.Equal(keys[j].CreatedAt) {
+			return keys[i].CreatedAt.After(keys[j].CreatedAt)
+		}
+		return keys[i].path > keys[j].path
+	})
+
+	return keys, nil
+}
+
+// keyCreatedAt reads the creation time from a key file name of the form
+// <unix time>-<anything>.pem
+func keyCreatedAt(name string) (time.Time, error) {
+	prefix, _, ok := strings.Cut(name, "-")
+	seconds, err := strconv.ParseInt(prefix, 10, 64)
+	if !ok || err != nil || seconds <= 0 {
+		return time.Time{}, fmt.Errorf("%s: key file names must start with the Unix time the key was created, e.g. 1705313700-main.pem", name)
+	}
+
+	return time.Unix(seconds, 0), nil
+}
+
+// loadKey loads a private key (PKCS#8, PKCS#1 or SEC 1) or a public key
+// (PKIX) from a PEM file
+func loadKey(path string, createdAt time.Time) (*Key, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("%s: no PEM block found", path)
+	}
+
+	var parsed interface{}
+	switch block.Type {
+	case "PRIVATE KEY":
+		parsed, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	case "RSA PRIVATE KEY":
+		parsed, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "EC PRIVATE KEY":
+		parsed, err = x509.ParseECPrivateKey(block.Bytes)
+	case "PUBLIC KEY":
+		parsed, err = x509.ParsePKIXPublicKey(block.Bytes)
+	default:
+		return nil, fmt.Errorf("%s: unsupported PEM block %q", path, block.Type)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	var key *Key
+	if signer, ok := parsed.(crypto.Signer); ok {
+		key, err = newKey(signer, signer.Public(), createdAt)
+	} else {
+		key, err = newKey(nil, parsed, createdAt)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	key.path = path
+	return key, nil
+}
+
+func newKey(private crypto.Signer, public crypto.PublicKey, createdAt time.Time) (*Key, error) {
+	algorithm, err := algorithmFor(public)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Key{
+		ID:        thumbprint(public),
+		Algorithm: algorithm,
+		Private:   private,
+		Public:    public,
+		CreatedAt: createdAt,
+	}, nil
+}
+
+// algorithmFor returns the JWS algorithm a public key verifies
+func algorithmFor(public crypto.PublicKey) (string, error) {
+	switch key := public.(type) {
+	case *rsa.PublicKey:
+		if key.N.BitLen() < rsaKeyBits {
+			return "", fmt.Errorf("RSA keys need at least %d bits", rsaKeyBits)
+		}
+		return AlgorithmRS256, nil
+	case *ecdsa.PublicKey:
+		switch key.Curve {
+		case elliptic.P256():
+			return AlgorithmES256, nil
+		case elliptic.P384():
+			return AlgorithmES384, nil
+		case elliptic.P521():
+			return AlgorithmES512, nil
+		}
+		return "", fmt.Errorf("unsupported curve %s", key.Curve.Params().Name)
+	case ed25519.PublicKey:
+		return AlgorithmEdDSA, nil
+	}
+
+	return "", fmt.Errorf("unsupported key type %T", public)
+}
+
+func isAsymmetric(algorithm string) bool {
+	switch algorithm {
+	case AlgorithmRS256, AlgorithmES256, AlgorithmES384, AlgorithmES512, AlgorithmEdDSA:
+		return true
+	}
+	return false
+}
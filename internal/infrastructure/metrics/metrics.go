@@ -3,6 +3,7 @@ package metrics
 import (
 	"context"
 	"net/http"
+	"strconv"
 	"sync"
 	"time"
 
@@ -32,6 +33,12 @@ type MetricsService struct {
 	userOperationsTotal  *prometheus.CounterVec
 	rateLimitExceeded    *prometheus.CounterVec
 	cacheOperationsTotal *prometheus.CounterVec
+	otpLockoutsTotal     *prometheus.CounterVec
+	otpResendsTotal      *prometheus.CounterVec
+	otpDeliveriesTotal   *prometheus.CounterVec
+	otpFallbacksTotal    *prometheus.CounterVec
+	otpReceiptsTotal     *prometheus.CounterVec
+	otpTimeToDeliver     *prometheus.HistogramVec
 }
 
 func NewMetricsService(logger logger.Logger) *MetricsService {
@@ -57,7 +64,7 @@ func NewMetricsService(logger logger.Logger) *MetricsService {
 			Name: "otp_operations_total",
 			Help: "Total number of OTP operations",
 		},
-		[]string{"operation", "success"},
+		[]string{"operation", "success", "purpose", "tenant"},
 	)
 
 	userOperationsTotal := prometheus.NewCounterVec(
@@ -65,7 +72,7 @@ func NewMetricsService(logger logger.Logger) *MetricsService {
 			Name: "user_operations_total",
 			Help: "Total number of user operations",
 		},
-		[]string{"operation"},
+		[]string{"operation", "tenant"},
 	)
 
 	rateLimitExceeded := prometheus.NewCounterVec(
@@ -73,7 +80,7 @@ func NewMetricsService(logger logger.Logger) *MetricsService {
 			Name: "rate_limit_exceeded_total",
 			Help: "Total number of rate limit violations",
 		},
-		[]string{"endpoint_type"},
+		[]string{"endpoint_type", "tenant"},
 	)
 
 	cacheOperationsTotal := prometheus.NewCounterVec(
@@ -84,7 +91,56 @@ func NewMetricsService(logger logger.Logger) *MetricsService {
 		[]string{"cache_type", "result"},
 	)
 
-	prometheus.MustRegister(httpRequestsTotal, httpRequestDuration, otpOperationsTotal, userOperationsTotal, rateLimitExceeded, cacheOperationsTotal)
+	otpLockoutsTotal := prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "otp_lockouts_total",
+			Help: "Total number of phone numbers locked out after too many failed OTP attempts",
+		},
+		[]string{"repeated", "tenant"},
+	)
+
+	otpResendsTotal := prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "otp_resends_total",
+			Help: "Total number of OTP resends",
+		},
+		[]string{"reused", "tenant"},
+	)
+
+	otpDeliveriesTotal := prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "otp_deliveries_total",
+			Help: "Total number of OTP messages handed to delivery providers",
+		},
+		[]string{"provider", "channel", "result", "tenant"},
+	)
+
+	otpFallbacksTotal := prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "otp_channel_fallbacks_total",
+			Help: "Total number of OTP challenges handed over to another delivery channel",
+		},
+		[]string{"from", "to", "reason", "tenant"},
+	)
+
+	otpReceiptsTotal := prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "otp_delivery_receipts_total",
+			Help: "Total number of OTP delivery receipts by reported status",
+		},
+		[]string{"provider", "country", "status"},
+	)
+
+	otpTimeToDeliver := prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "otp_time_to_deliver_seconds",
+			Help:    "Time from handing an OTP message to the provider until its delivery receipt",
+			Buckets: []float64{1, 2, 5, 10, 20, 30, 60, 120, 300, 600},
+		},
+		[]string{"provider", "country"},
+	)
+
+	prometheus.MustRegister(httpRequestsTotal, httpRequestDuration, otpOperationsTotal, userOperationsTotal, rateLimitExceeded, cacheOperationsTotal, otpLockoutsTotal, otpResendsTotal, otpDeliveriesTotal, otpFallbacksTotal, otpReceiptsTotal, otpTimeToDeliver)
 
 	return &MetricsService{
 		logger:    logger,
@@ -97,6 +153,12 @@ func NewMetricsService(logger logger.Logger) *MetricsService {
 		userOperationsTotal:  userOperationsTotal,
 		rateLimitExceeded:    rateLimitExceeded,
 		cacheOperationsTotal: cacheOperationsTotal,
+		otpLockoutsTotal:     otpLockoutsTotal,
+		otpResendsTotal:      otpResendsTotal,
+		otpDeliveriesTotal:   otpDeliveriesTotal,
+		otpFallbacksTotal:    otpFallbacksTotal,
+		otpReceiptsTotal:     otpReceiptsTotal,
+		otpTimeToDeliver:     otpTimeToDeliver,
 	}
 }
 
@@ -136,19 +198,25 @@ func (m *MetricsService) RecordRequest(method, path string, statusCode int, dura
 	m.httpRequestDuration.WithLabelValues(method, path).Observe(duration.Seconds())
 }
 
-func (m *MetricsService) RecordOTPGenerated(phoneNumber string) {
+func (m *MetricsService) RecordOTPGenerated(tenantID int, phoneNumber, purpose string) {
+	tenant := strconv.Itoa(tenantID)
 	labels := map[string]string{
 		"operation": "generate",
+		"purpose":   purpose,
+		"tenant":    tenant,
 	}
 	m.recordMetric("otp_operations_total", 1, labels, "counter")
 
-	m.otpOperationsTotal.WithLabelValues("generate", "true").Inc()
+	m.otpOperationsTotal.WithLabelValues("generate", "true", purpose, tenant).Inc()
 }
 
-func (m *MetricsService) RecordOTPVerified(phoneNumber string, success bool) {
+func (m *MetricsService) RecordOTPVerified(tenantID int, phoneNumber, purpose string, success bool) {
+	tenant := strconv.Itoa(tenantID)
 	labels := map[string]string{
 		"operation": "verify",
 		"success":   string(rune(map[bool]int{true: 1, false: 0}[success])),
+		"purpose":   purpose,
+		"tenant":    tenant,
 	}
 	m.recordMetric("otp_operations_total", 1, labels, "counter")
 
@@ -156,34 +224,124 @@ func (m *MetricsService) RecordOTPVerified(phoneNumber string, success bool) {
 	if !success {
 		successStr = "false"
 	}
-	m.otpOperationsTotal.WithLabelValues("verify", successStr).Inc()
+	m.otpOperationsTotal.WithLabelValues("verify", successStr, purpose, tenant).Inc()
+}
+
+func (m *MetricsService) RecordOTPLockout(tenantID int, phoneNumber string, lockoutCount int) {
+	repeated := "false"
+	if lockoutCount > 1 {
+		repeated = "true"
+	}
+
+	tenant := strconv.Itoa(tenantID)
+	labels := map[string]string{
+		"repeated": repeated,
+		"tenant":   tenant,
+	}
+	m.recordMetric("otp_lockouts_total", 1, labels, "counter")
+
+	m.otpLockoutsTotal.WithLabelValues(repeated, tenant).Inc()
+}
+
+func (m *MetricsService) RecordOTPResent(tenantID int, phoneNumber string, reused bool) {
+	reusedStr := "false"
+	if reused {
+		reusedStr = "true"
+	}
+
+	tenant := strconv.Itoa(tenantID)
+	labels := map[string]string{
+		"reused": reusedStr,
+		"tenant": tenant,
+	}
+	m.recordMetric("otp_resends_total", 1, labels, "counter")
+
+	m.otpResendsTotal.WithLabelValues(reusedStr, tenant).Inc()
+}
+
+// RecordOTPDelivery records a delivery outcome; result is "delivered" or the error class
+func (m *MetricsService) RecordOTPDelivery(tenantID int, provider, channel, result string) {
+	tenant := strconv.Itoa(tenantID)
+	labels := map[string]string{
+		"provider": provider,
+		"channel":  channel,
+		"result":   result,
+		"tenant":   tenant,
+	}
+	m.recordMetric("otp_deliveries_total", 1, labels, "counter")
+
+	m.otpDeliveriesTotal.WithLabelValues(provider, channel, result, tenant).Inc()
+}
+
+// RecordOTPChannelFallback records a challenge moving to the next delivery channel
+func (m *MetricsService) RecordOTPChannelFallback(tenantID int, from, to, reason string) {
+	tenant := strconv.Itoa(tenantID)
+	labels := map[string]string{
+		"from":   from,
+		"to":     to,
+		"reason": reason,
+		"tenant": tenant,
+	}
+	m.recordMetric("otp_channel_fallbacks_total", 1, labels, "counter")
+
+	m.otpFallbacksTotal.WithLabelValues(from, to, reason, tenant).Inc()
+}
+
+// RecordOTPDeliveryReceipt records a delivery receipt. The delivery success
+// rate is the share of "delivered" among the final statuses.
+func (m *MetricsService) RecordOTPDeliveryReceipt(provider, country, status string) {
+	labels := map[string]string{
+		"provider": provider,
+		"country":  country,
+		"status":   status,
+	}
+	m.recordMetric("otp_delivery_receipts_total", 1, labels, "counter")
+
+	m.otpReceiptsTotal.WithLabelValues(provider, country, status).Inc()
+}
+
+// RecordOTPTimeToDeliver records how long a message took to reach the handset
+func (m *MetricsService) RecordOTPTimeToDeliver(provider, country string, duration time.Duration) {
+	labels := map[string]string{
+		"provider": provider,
+		"country":  country,
+	}
+	m.recordMetric("otp_time_to_deliver_seconds", duration.Seconds(), labels, "histogram")
+
+	m.otpTimeToDeliver.WithLabelValues(provider, country).Observe(duration.Seconds())
 }
 
-func (m *MetricsService) RecordUserRegistration(userID int, phoneNumber string) {
+func (m *MetricsService) RecordUserRegistration(tenantID, userID int, phoneNumber string) {
+	tenant := strconv.Itoa(tenantID)
 	labels := map[string]string{
 		"operation": "register",
+		"tenant":    tenant,
 	}
 	m.recordMetric("user_operations_total", 1, labels, "counter")
 
-	m.userOperationsTotal.WithLabelValues("register").Inc()
+	m.userOperationsTotal.WithLabelValues("register", tenant).Inc()
 }
 
-func (m *MetricsService) RecordUserLogin(userID int, phoneNumber string) {
+func (m *MetricsService) RecordUserLogin(tenantID, userID int, phoneNumber string) {
+	tenant := strconv.Itoa(tenantID)
 	labels := map[string]string{
 		"operation": "login",
+		"tenant":    tenant,
 	}
 	m.recordMetric("user_operations_total", 1, labels, "counter")
 
-	m.userOperationsTotal.WithLabelValues("login").Inc()
+	m.userOperationsTotal.WithLabelValues("login", tenant).Inc()
 }
 
-func (m *MetricsService) RecordRateLimitExceeded(endpointType, identifier string) {
+func (m *MetricsService) RecordRateLimitExceeded(tenantID int, endpointType, identifier string) {
+	tenant := strconv.Itoa(tenantID)
 	labels := map[string]string{
 		"endpoint_type": endpointType,
+		"tenant":        tenant,
 	}
 	m.recordMetric("rate_limit_exceeded_total", 1, labels, "counter")
 
-	m.rateLimitExceeded.WithLabelValues(endpointType).Inc()
+	m.rateLimitExceeded.WithLabelValues(endpointType, tenant).Inc()
 }
 
 func (m *MetricsService) RecordCacheHit(cacheType, key string) {
@@ -203,7 +361,6 @@ func (m *MetricsService) RecordCacheMiss(cacheType, key string) {
 	}
 	m.recordMetric("cache_operations_total", 1, labels, "counter")
 
-	
 	m.cacheOperationsTotal.WithLabelValues(cacheType, "miss").Inc()
 }
 
@@ -2,23 +2,49 @@ package redis
 
 import (
 	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
 	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
 	"fmt"
+	"math"
 	"math/big"
+	"strconv"
+	"strings"
 	"time"
 
+	"otp-server/internal/domain/entities"
+	"otp-server/internal/domain/errors"
 	"otp-server/internal/infrastructure/config"
 	"otp-server/internal/infrastructure/logger"
 	"otp-server/internal/infrastructure/metrics"
+	"otp-server/lib"
+
+	"github.com/google/uuid"
 )
 
+// OTPDeliverer sends plaintext OTP codes over the challenge's current channel
+type OTPDeliverer interface {
+	Deliver(ctx context.Context, challenge *entities.OTPChallenge, code string) (*entities.DeliveryResult, error)
+	SupportsChannel(channel entities.OTPChannel) bool
+	ResolveLocale(tenantID int, preferences []string) string
+}
+
 // OTPService handles OTP generation and validation using Redis
 type OTPService struct {
-	client       *Client
-	logger       logger.Logger
-	config       *config.OTPConfig
-	eventHandler func(context.Context, string, string) error
-	metrics      *metrics.MetricsService
+	client          *Client
+	logger          logger.Logger
+	config          *config.OTPConfig
+	tenantConfig    func(int) *config.OTPConfig
+	eventHandler    func(context.Context, string, string) error
+	verifyHandler   func(context.Context, *entities.OTPChallenge) error
+	deliverer       OTPDeliverer
+	lockoutHandler  func(context.Context, string, time.Duration, int) error
+	fallbackHandler func(context.Context, string, string, string, string, string) error
+	metrics         *metrics.MetricsService
 }
 
 // NewOTPService creates a new Redis-based OTP service
@@ -31,71 +57,1049 @@ func NewOTPService(client *Client, cfg *config.OTPConfig, logger logger.Logger,
 	}
 }
 
-// SetEventHandler sets the event handler for OTP events
+// SetTenantConfig sets the lookup of a tenant's OTP settings. Without it,
+// every tenant gets the settings the service was created with.
+func (s *OTPService) SetTenantConfig(lookup func(tenantID int) *config.OTPConfig) {
+	s.tenantConfig = lookup
+}
+
+// SetEventHandler sets the event handler for OTP events. It receives the
+// phone number and purpose, never the code itself.
 func (s *OTPService) SetEventHandler(handler func(context.Context, string, string) error) {
 	s.eventHandler = handler
 }
 
-// GenerateOTP generates a new OTP for the given phone number
+// SetVerifyHandler sets the handler called when a challenge is verified.
+// It receives the verified challenge, which never holds the code.
+func (s *OTPService) SetVerifyHandler(handler func(context.Context, *entities.OTPChallenge) error) {
+	s.verifyHandler = handler
+}
+
+// SetDeliverer sets the delivery layer that sends the plaintext code
+func (s *OTPService) SetDeliverer(deliverer OTPDeliverer) {
+	s.deliverer = deliverer
+}
+
+// SetLockoutHandler sets the handler called when a phone number gets locked out
+func (s *OTPService) SetLockoutHandler(handler func(context.Context, string, time.Duration, int) error) {
+	s.lockoutHandler = handler
+}
+
+// SetFallbackHandler sets the handler called when a challenge moves to the
+// next delivery channel. It receives the phone number, challenge ID, old and
+// new channel and the reason ("failed" or "unconfirmed").
+func (s *OTPService) SetFallbackHandler(handler func(context.Context, string, string, string, string, string) error) {
+	s.fallbackHandler = handler
+}
+
+// GenerateOTP creates a new OTP challenge for the requested phone number and
+// delivers its code over the first channel of its fallback chain. Every call
+// starts an independent challenge, within the tenant of the context.
 // Note: Rate limiting is now handled by middleware, not here
-func (s *OTPService) GenerateOTP(ctx context.Context, phoneNumber string) (string, error) {
-	code, err := s.generateRandomCode(s.config.Length)
+func (s *OTPService) GenerateOTP(ctx context.Context, req *entities.OTPRequest) (*entities.OTPChallenge, error) {
+	tenantID := entities.TenantIDFromContext(ctx)
+	cfg := s.configFor(tenantID)
+
+	phoneNumber := req.PhoneNumber
+	if err := s.checkLockout(ctx, tenantID, phoneNumber); err != nil {
+		return nil, err
+	}
+
+	purpose := req.Purpose
+	if purpose == "" {
+		purpose = entities.OTPPurposeLogin
+	}
+	if !purpose.IsValid() {
+		return nil, errors.NewInvalidInput("purpose", purpose)
+	}
+
+	var transaction *entities.TransactionPayload
+	if purpose == entities.OTPPurposeTransaction || req.Transaction != nil {
+		if purpose != entities.OTPPurposeTransaction || req.Transaction == nil {
+			return nil, errors.NewInvalidInput("transaction", "a payload is required for, and only allowed with, the transaction purpose")
+		}
+		canonical, err := req.Transaction.Canonical()
+		if err != nil {
+			return nil, errors.NewInvalidInput("transaction", err)
+		}
+		transaction = canonical
+	}
+
+	channels, err := s.channelChain(cfg, req)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
-	otpKey := fmt.Sprintf("%s:%s", s.config.RedisKeyPrefix, phoneNumber)
-	err = s.client.Set(ctx, otpKey, code, s.config.Expiry)
+	challenge := entities.NewOTPChallenge(uuid.NewString(), phoneNumber, purpose, channels, expiryFor(cfg, purpose))
+	challenge.TenantID = tenantID
+	challenge.Email = req.Email
+	challenge.UserID = req.UserID
+	if transaction != nil {
+		challenge.Operation = transaction.Operation
+		challenge.PayloadHash = transaction.Hash()
+		challenge.Summary = transaction.Summary()
+	}
+	if s.deliverer != nil {
+		challenge.Locale = s.deliverer.ResolveLocale(tenantID, req.Locales)
+	}
+	if err := s.saveChallenge(ctx, challenge); err != nil {
+		return nil, err
+	}
+
+	code, err := s.issueCode(ctx, challenge)
 	if err != nil {
-		return "", err
+		return nil, err
+	}
+
+	if cfg.ResendInterval > 0 {
+		s.client.Set(ctx, s.cooldownKey(tenantID, challenge.ID), 1, cfg.ResendInterval)
 	}
 
 	if s.metrics != nil {
-		s.metrics.RecordOTPGenerated(phoneNumber)
+		s.metrics.RecordOTPGenerated(tenantID, phoneNumber, string(purpose))
+	}
+
+	if err := s.deliverWithFallback(ctx, challenge, code); err != nil {
+		return nil, err
 	}
 
 	if s.eventHandler != nil {
-		s.eventHandler(ctx, phoneNumber, code)
+		s.eventHandler(ctx, phoneNumber, string(purpose))
+	}
+
+	return challenge, nil
+}
+
+// ResendOTP sends the challenge's code again. Within ResendReuseWindow of
+// issuing a code the same code is delivered; after that a fresh code
+// replaces it. Resends are spaced by ResendInterval and capped at MaxResends.
+func (s *OTPService) ResendOTP(ctx context.Context, challengeID string) (*entities.OTPChallenge, error) {
+	challenge, err := s.GetChallenge(ctx, challengeID)
+	if err != nil {
+		return nil, err
+	}
+
+	if challenge.Status != entities.OTPChallengeStatusPending && challenge.Status != entities.OTPChallengeStatusExpired {
+		return nil, errors.ErrOTPInvalid
+	}
+
+	tenantID := challenge.TenantID
+	cfg := s.configFor(tenantID)
+
+	if err := s.checkLockout(ctx, tenantID, challenge.PhoneNumber); err != nil {
+		return nil, err
+	}
+
+	if challenge.Resends >= cfg.MaxResends {
+		return nil, errors.ErrOTPResendLimit
+	}
+
+	if cfg.ResendInterval > 0 {
+		acquired, err := s.client.SetNX(ctx, s.cooldownKey(tenantID, challengeID), 1, cfg.ResendInterval)
+		if err != nil {
+			return nil, err
+		}
+		if !acquired {
+			ttl, _ := s.client.TTL(ctx, s.cooldownKey(tenantID, challengeID))
+			return nil, errors.NewOTPResendTooSoon(ttl)
+		}
+	}
+
+	// Concurrent resends can both pass the check above, the counter settles it
+	resends, err := s.client.HIncrBy(ctx, s.challengeKey(tenantID, challengeID), "resends", 1)
+	if err != nil {
+		return nil, err
+	}
+	if int(resends) > cfg.MaxResends {
+		return nil, errors.ErrOTPResendLimit
+	}
+	challenge.Resends = int(resends)
+
+	code, reused := s.reusableCode(ctx, challenge)
+	if !reused {
+		code, err = s.issueCode(ctx, challenge)
+		if err != nil {
+			return nil, err
+		}
+		challenge.ExpiresAt = time.Now().Add(expiryFor(cfg, challenge.Purpose))
+	}
+
+	challenge.Status = entities.OTPChallengeStatusPending
+	challenge.LastSentAt = time.Now()
+	err = s.client.HSet(ctx, s.challengeKey(tenantID, challengeID),
+		"status", string(challenge.Status),
+		"last_sent_at", challenge.LastSentAt.Unix(),
+		"expires_at", challenge.ExpiresAt.Unix(),
+	)
+	if err != nil {
+		return nil, err
+	}
+	s.client.Expire(ctx, s.challengeKey(tenantID, challengeID), time.Until(challenge.ExpiresAt)+cfg.ChallengeRetention)
+
+	if s.metrics != nil {
+		s.metrics.RecordOTPResent(tenantID, challenge.PhoneNumber, reused)
+	}
+
+	if err := s.deliverWithFallback(ctx, challenge, code); err != nil {
+		return nil, err
+	}
+
+	return challenge, nil
+}
+
+// ResendAvailability returns how long until the challenge may be resent and
+// how many resends it has left
+func (s *OTPService) ResendAvailability(ctx context.Context, challenge *entities.OTPChallenge) (time.Duration, int) {
+	remaining := s.configFor(challenge.TenantID).MaxResends - challenge.Resends
+	if remaining < 0 {
+		remaining = 0
+	}
+
+	ttl, err := s.client.TTL(ctx, s.cooldownKey(challenge.TenantID, challenge.ID))
+	if err != nil || ttl < 0 {
+		ttl = 0
+	}
+
+	return ttl, remaining
+}
+
+// issueCode generates a fresh code for the challenge and stores its hash,
+// together with a sealed copy that lets resends deliver it again
+func (s *OTPService) issueCode(ctx context.Context, challenge *entities.OTPChallenge) (string, error) {
+	cfg := s.configFor(challenge.TenantID)
+
+	code, err := generateRandomCode(cfg, lengthFor(cfg, challenge.Purpose))
+	if err != nil {
+		return "", err
+	}
+
+	err = s.client.Set(ctx, s.codeKey(challenge), s.hashCode(cfg.HashSecret, challenge, code), expiryFor(cfg, challenge.Purpose))
+	if err != nil {
+		return "", err
+	}
+
+	if cfg.ResendReuseWindow > 0 {
+		sealed, err := sealCode(cfg, challenge.ID, code)
+		if err == nil {
+			err = s.client.Set(ctx, s.sealedCodeKey(challenge), sealed, cfg.ResendReuseWindow)
+		}
+		if err != nil {
+			s.logger.Error(ctx, "Failed to store resendable OTP", logger.F("error", err), logger.F("challenge_id", challenge.ID))
+		}
 	}
 
 	return code, nil
 }
 
-func (s *OTPService) ValidateOTP(ctx context.Context, phoneNumber, code string) error {
-	otpKey := fmt.Sprintf("%s:%s", s.config.RedisKeyPrefix, phoneNumber)
-	storedCode, err := s.client.Get(ctx, otpKey)
-	if err != nil || storedCode == "" {
+// reusableCode returns the current code if it may still be resent as is
+func (s *OTPService) reusableCode(ctx context.Context, challenge *entities.OTPChallenge) (string, bool) {
+	if !s.IsOTPValid(ctx, challenge) {
+		return "", false
+	}
+
+	sealed, err := s.client.Get(ctx, s.sealedCodeKey(challenge))
+	if err != nil || sealed == "" {
+		return "", false
+	}
+
+	code, err := openCode(s.configFor(challenge.TenantID), challenge.ID, sealed)
+	if err != nil {
+		return "", false
+	}
+
+	return code, true
+}
+
+// channelChain returns the channels to try for the request, in order. The
+// chain is the one configured for the longest matching country prefix, cut
+// down to the channels that can actually be delivered; a preferred channel
+// is moved to the front.
+func (s *OTPService) channelChain(cfg *config.OTPConfig, req *entities.OTPRequest) ([]entities.OTPChannel, error) {
+	configured := cfg.FallbackChains["default"]
+	matched := ""
+	phone := strings.TrimPrefix(req.PhoneNumber, "+")
+	for prefix, chain := range cfg.FallbackChains {
+		trimmed := strings.TrimPrefix(prefix, "+")
+		if prefix == "default" || !strings.HasPrefix(phone, trimmed) || len(trimmed) <= len(matched) {
+			continue
+		}
+		configured, matched = chain, trimmed
+	}
+	if len(configured) == 0 {
+		configured = []string{string(entities.OTPChannelSMS)}
+	}
+
+	var channels []entities.OTPChannel
+	for _, name := range configured {
+		channel := entities.OTPChannel(strings.TrimSpace(name))
+		if !channel.IsValid() || (channel == entities.OTPChannelEmail && req.Email == "") {
+			continue
+		}
+		if s.deliverer != nil && !s.deliverer.SupportsChannel(channel) {
+			continue
+		}
+		channels = append(channels, channel)
+	}
+
+	if req.Channel != "" {
+		preferred := []entities.OTPChannel{req.Channel}
+		found := false
+		for _, channel := range channels {
+			if channel == req.Channel {
+				found = true
+				continue
+			}
+			preferred = append(preferred, channel)
+		}
+		if !found {
+			return nil, errors.NewInvalidInput("channel", req.Channel)
+		}
+		channels = preferred
+	}
+
+	if len(channels) == 0 {
+		return nil, errors.ErrOTPDeliveryFailed.WithDetails("No delivery channel available for this phone number")
+	}
+
+	return channels, nil
+}
+
+// deliverWithFallback delivers the code over the challenge's current
+// channel, moving down the fallback chain while channels fail. When the
+// provider only accepted the message, a check is scheduled that moves on to
+// the next channel if delivery is not confirmed within FallbackTimeout.
+func (s *OTPService) deliverWithFallback(ctx context.Context, challenge *entities.OTPChallenge, code string) error {
+	for {
+		confirmed, err := s.deliver(ctx, challenge, code)
+		if err == nil {
+			if !confirmed {
+				s.scheduleFallback(challenge.TenantID, challenge.ID, challenge.Channel)
+			}
+			return nil
+		}
+
+		next, ok := challenge.NextChannel()
+		if !ok {
+			return err
+		}
+		s.switchChannel(ctx, challenge, next, "failed")
+	}
+}
+
+// scheduleFallback arranges the unconfirmed delivery check. The timer lives
+// in this process only; a restart simply skips the fallback.
+func (s *OTPService) scheduleFallback(tenantID int, challengeID string, channel entities.OTPChannel) {
+	timeout := s.configFor(tenantID).FallbackTimeout
+	if timeout <= 0 {
+		return
+	}
+
+	time.AfterFunc(timeout, func() {
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
+		defer cancel()
+
+		challenge, err := s.getChallenge(ctx, tenantID, challengeID)
+		if err != nil || !challenge.IsPending() || challenge.Channel != channel || challenge.DeliveryStatus == entities.DeliveryStatusDelivered {
+			return
+		}
+		s.fallback(ctx, challenge, "unconfirmed")
+	})
+}
+
+// fallback moves a pending challenge to its next channel and delivers the
+// code there, reissuing it if it can no longer be resent as is
+func (s *OTPService) fallback(ctx context.Context, challenge *entities.OTPChallenge, reason string) {
+	next, ok := challenge.NextChannel()
+	if !ok {
+		return
+	}
+
+	code, reused := s.reusableCode(ctx, challenge)
+	if !reused {
+		var err error
+		code, err = s.issueCode(ctx, challenge)
+		if err != nil {
+			s.logger.Error(ctx, "Failed to issue OTP for channel fallback", logger.F("error", err), logger.F("challenge_id", challenge.ID))
+			return
+		}
+		cfg := s.configFor(challenge.TenantID)
+		challenge.ExpiresAt = time.Now().Add(expiryFor(cfg, challenge.Purpose))
+		s.client.HSet(ctx, s.challengeKey(challenge.TenantID, challenge.ID), "expires_at", challenge.ExpiresAt.Unix())
+		s.client.Expire(ctx, s.challengeKey(challenge.TenantID, challenge.ID), expiryFor(cfg, challenge.Purpose)+cfg.ChallengeRetention)
+	}
+
+	s.switchChannel(ctx, challenge, next, reason)
+
+	if err := s.deliverWithFallback(ctx, challenge, code); err != nil {
+		s.logger.Error(ctx, "OTP channel fallback failed", logger.F("error", err), logger.F("challenge_id", challenge.ID))
+	}
+}
+
+// switchChannel moves the challenge to the given channel
+func (s *OTPService) switchChannel(ctx context.Context, challenge *entities.OTPChallenge, to entities.OTPChannel, reason string) {
+	from := challenge.Channel
+	challenge.Channel = to
+	challenge.DeliveryStatus = ""
+
+	if err := s.client.HSet(ctx, s.challengeKey(challenge.TenantID, challenge.ID), "channel", string(to), "delivery_status", ""); err != nil {
+		s.logger.Error(ctx, "Failed to switch OTP channel", logger.F("error", err), logger.F("challenge_id", challenge.ID))
+	}
+
+	s.logger.Info(ctx, "Falling back to next OTP channel",
+		logger.F("challenge_id", challenge.ID),
+		logger.F("from", from),
+		logger.F("to", to),
+		logger.F("reason", reason))
+
+	if s.metrics != nil {
+		s.metrics.RecordOTPChannelFallback(challenge.TenantID, string(from), string(to), reason)
+	}
+
+	if s.fallbackHandler != nil {
+		s.fallbackHandler(ctx, challenge.PhoneNumber, challenge.ID, string(from), string(to), reason)
+	}
+}
+
+// deliver hands the plaintext code to the delivery layer and records which
+// provider message carries it. It reports whether the provider already
+// confirmed delivery.
+func (s *OTPService) deliver(ctx context.Context, challenge *entities.OTPChallenge, code string) (bool, error) {
+	if s.deliverer == nil {
+		s.logger.Warn(ctx, "No OTP deliverer configured", logger.F("challenge_id", challenge.ID))
+		return true, nil
+	}
+
+	result, err := s.deliverer.Deliver(ctx, challenge, code)
+	if err != nil {
+		challenge.DeliveryStatus = entities.DeliveryStatusFailed
+		s.client.HSet(ctx, s.challengeKey(challenge.TenantID, challenge.ID), "delivery_status", string(challenge.DeliveryStatus))
+		if result != nil {
+			s.appendDeliveryEvent(ctx, challenge, &entities.DeliveryEvent{
+				Provider:  result.Provider,
+				Channel:   challenge.Channel,
+				Status:    entities.DeliveryStatusFailed,
+				ErrorCode: string(result.ErrorClass),
+				At:        result.SentAt,
+			})
+		}
+		return false, errors.ErrOTPDeliveryFailed.WithError(err)
+	}
+
+	challenge.Provider = result.Provider
+	challenge.MessageID = result.MessageID
+	challenge.DeliveryStatus = result.Status
+	err = s.client.HSet(ctx, s.challengeKey(challenge.TenantID, challenge.ID),
+		"provider", challenge.Provider,
+		"message_id", challenge.MessageID,
+		"delivery_status", string(challenge.DeliveryStatus),
+	)
+	if err != nil {
+		s.logger.Error(ctx, "Failed to record OTP delivery", logger.F("error", err), logger.F("challenge_id", challenge.ID))
+	}
+
+	if result.MessageID != "" {
+		// Lets delivery receipts, which only carry the provider's message ID,
+		// find their way back to the challenge and its tenant
+		key := s.messageKey(result.Provider, result.MessageID)
+		err = s.client.HSet(ctx, key,
+			"tenant_id", challenge.TenantID,
+			"challenge_id", challenge.ID,
+			"channel", string(challenge.Channel),
+			"status", string(result.Status),
+			"sent_at", result.SentAt.UnixMilli(),
+		)
+		if err != nil {
+			s.logger.Error(ctx, "Failed to record OTP message", logger.F("error", err), logger.F("challenge_id", challenge.ID))
+		}
+		s.client.Expire(ctx, key, time.Until(challenge.ExpiresAt)+s.configFor(challenge.TenantID).ChallengeRetention)
+	}
+
+	s.appendDeliveryEvent(ctx, challenge, &entities.DeliveryEvent{
+		Provider:  result.Provider,
+		MessageID: result.MessageID,
+		Channel:   challenge.Channel,
+		Status:    result.Status,
+		At:        result.SentAt,
+	})
+
+	return result.Status == entities.DeliveryStatusDelivered, nil
+}
+
+// RecordDeliveryReceipt applies a provider's delivery receipt to the message
+// it reports on. It returns false for duplicate or out-of-order receipts. A
+// failed or expired message moves its still pending challenge to the next
+// channel right away.
+func (s *OTPService) RecordDeliveryReceipt(ctx context.Context, receipt *entities.DeliveryEvent) (bool, error) {
+	key := s.messageKey(receipt.Provider, receipt.MessageID)
+	fields, err := s.client.HGetAll(ctx, key)
+	if err != nil {
+		return false, err
+	}
+	if len(fields) == 0 {
+		return false, errors.NewNotFound("OTP message")
+	}
+
+	if !entities.DeliveryStatus(fields["status"]).Precedes(receipt.Status) {
+		return false, nil
+	}
+	if err := s.client.HSet(ctx, key, "status", string(receipt.Status)); err != nil {
+		return false, err
+	}
+
+	// Receipts arrive outside of any tenant's requests
+	tenantID, err := strconv.Atoi(fields["tenant_id"])
+	if err != nil {
+		tenantID = entities.DefaultTenantID
+	}
+
+	challenge, err := s.getChallenge(ctx, tenantID, fields["challenge_id"])
+	if err != nil {
+		return false, err
+	}
+
+	receipt.Channel = entities.OTPChannel(fields["channel"])
+	s.appendDeliveryEvent(ctx, challenge, receipt)
+
+	if s.metrics != nil {
+		country := lib.CountryCallingCode(challenge.PhoneNumber)
+		s.metrics.RecordOTPDeliveryReceipt(receipt.Provider, country, string(receipt.Status))
+
+		sentAt, _ := strconv.ParseInt(fields["sent_at"], 10, 64)
+		if receipt.Status == entities.DeliveryStatusDelivered && sentAt > 0 {
+			if elapsed := receipt.At.Sub(time.UnixMilli(sentAt)); elapsed >= 0 {
+				s.metrics.RecordOTPTimeToDeliver(receipt.Provider, country, elapsed)
+			}
+		}
+	}
+
+	// Receipts for messages a fallback already replaced only go to the history
+	if receipt.Provider != challenge.Provider || receipt.MessageID != challenge.MessageID || !challenge.DeliveryStatus.Precedes(receipt.Status) {
+		return true, nil
+	}
+
+	challenge.DeliveryStatus = receipt.Status
+	if err := s.client.HSet(ctx, s.challengeKey(challenge.TenantID, challenge.ID), "delivery_status", string(challenge.DeliveryStatus)); err != nil {
+		return false, err
+	}
+
+	if (receipt.Status == entities.DeliveryStatusFailed || receipt.Status == entities.DeliveryStatusExpired) && challenge.IsPending() {
+		s.fallback(ctx, challenge, "failed")
+	}
+
+	return true, nil
+}
+
+// DeliveryHistory returns the delivery status transitions of the challenge,
+// oldest first
+func (s *OTPService) DeliveryHistory(ctx context.Context, challenge *entities.OTPChallenge) ([]*entities.DeliveryEvent, error) {
+	entries, err := s.client.LRange(ctx, s.deliveriesKey(challenge.TenantID, challenge.ID), 0, -1)
+	if err != nil {
+		return nil, err
+	}
+
+	events := make([]*entities.DeliveryEvent, 0, len(entries))
+	for _, entry := range entries {
+		var event entities.DeliveryEvent
+		if err := json.Unmarshal([]byte(entry), &event); err != nil {
+			continue
+		}
+		events = append(events, &event)
+	}
+
+	return events, nil
+}
+
+// appendDeliveryEvent adds a status transition to the challenge's history
+func (s *OTPService) appendDeliveryEvent(ctx context.Context, challenge *entities.OTPChallenge, event *entities.DeliveryEvent) {
+	data, err := json.Marshal(event)
+	if err == nil {
+		err = s.client.RPush(ctx, s.deliveriesKey(challenge.TenantID, challenge.ID), string(data))
+	}
+	if err != nil {
+		s.logger.Error(ctx, "Failed to record OTP delivery status", logger.F("error", err), logger.F("challenge_id", challenge.ID))
+		return
+	}
+
+	s.client.Expire(ctx, s.deliveriesKey(challenge.TenantID, challenge.ID), time.Until(challenge.ExpiresAt)+s.configFor(challenge.TenantID).ChallengeRetention)
+}
+
+// ValidateOTP checks the code against the one issued for the challenge.
+// Wrong and expired codes, and challenges issued for another purpose, all
+// return ErrOTPInvalid; once MaxAttempts wrong guesses have been made the
+// challenge fails and the number is locked out.
+func (s *OTPService) ValidateOTP(ctx context.Context, challengeID string, purpose entities.OTPPurpose, code string) (*entities.OTPChallenge, error) {
+	return s.validate(ctx, challengeID, purpose, func(challenge *entities.OTPChallenge, storedHash string) bool {
+		return s.matchesCode(challenge, code, storedHash)
+	})
+}
+
+// ValidateTransactionOTP checks a transaction code. The payload must hash to
+// the one the code was issued for; a different payload counts as a wrong
+// guess, like a wrong code.
+func (s *OTPService) ValidateTransactionOTP(ctx context.Context, challengeID string, payload *entities.TransactionPayload, code string) (*entities.OTPChallenge, error) {
+	canonical, err := payload.Canonical()
+	if err != nil {
+		return nil, errors.NewInvalidInput("transaction", err)
+	}
+
+	payloadHash := canonical.Hash()
+	return s.validate(ctx, challengeID, entities.OTPPurposeTransaction, func(challenge *entities.OTPChallenge, storedHash string) bool {
+		// The code is bound to the payload hash, so checking it against the
+		// presented payload rejects a code lifted for another operation
+		presented := *challenge
+		presented.PayloadHash = payloadHash
+		if payloadHash != challenge.PayloadHash {
+			s.logger.Warn(ctx, "OTP challenge presented with another transaction payload",
+				logger.F("challenge_id", challengeID),
+				logger.F("operation", challenge.Operation))
+		}
+
+		return s.matchesCode(&presented, code, storedHash)
+	})
+}
+
+// ValidateAlternative completes a pending challenge with a credential other
+// than its code, such as a recovery code. accept is only called for a
+// pending challenge of the purpose, and a rejection counts as a wrong guess,
+// so the same attempt budget and lockout apply.
+func (s *OTPService) ValidateAlternative(ctx context.Context, challengeID string, purpose entities.OTPPurpose, accept func(*entities.OTPChallenge) bool) (*entities.OTPChallenge, error) {
+	return s.validate(ctx, challengeID, purpose, func(challenge *entities.OTPChallenge, _ string) bool {
+		return accept(challenge)
+	})
+}
+
+// CheckOTP checks the code like ValidateOTP, and counts a wrong one against
+// the same budget, but leaves a right one unspent so it can be validated
+// together with another code
+func (s *OTPService) CheckOTP(ctx context.Context, challengeID string, purpose entities.OTPPurpose, code string) error {
+	_, err := s.check(ctx, challengeID, purpose, func(challenge *entities.OTPChallenge, storedHash string) bool {
+		return s.matchesCode(challenge, code, storedHash)
+	})
+	return err
+}
+
+func (s *OTPService) validate(ctx context.Context, challengeID string, purpose entities.OTPPurpose, matches func(*entities.OTPChallenge, string) bool) (*entities.OTPChallenge, error) {
+	challenge, err := s.check(ctx, challengeID, purpose, matches)
+	if err != nil {
+		return nil, err
+	}
+
+	// Only the request that actually removes the code wins a concurrent race
+	deleted, err := s.client.DelCount(ctx, s.codeKey(challenge))
+	if err != nil {
+		return nil, err
+	}
+	if deleted == 0 {
+		return nil, errors.ErrOTPInvalid
+	}
+
+	if s.metrics != nil {
+		s.metrics.RecordOTPVerified(challenge.TenantID, challenge.PhoneNumber, string(purpose), true)
+	}
+
+	challenge.Status = entities.OTPChallengeStatusVerified
+	if err := s.client.HSet(ctx, s.challengeKey(challenge.TenantID, challengeID), "status", string(challenge.Status)); err != nil {
+		s.logger.Error(ctx, "Failed to update OTP challenge status", logger.F("error", err), logger.F("challenge_id", challengeID))
+	}
+
+	if err := s.client.Del(ctx, s.sealedCodeKey(challenge), s.failuresKey(challenge), s.lockoutsKey(challenge.TenantID, challenge.PhoneNumber)); err != nil {
+		return nil, err
+	}
+
+	if s.verifyHandler != nil {
+		s.verifyHandler(ctx, challenge)
+	}
+
+	return challenge, nil
+}
+
+// check loads a pending challenge of the purpose and matches the presented
+// credential against it, counting a mismatch as a wrong guess
+func (s *OTPService) check(ctx context.Context, challengeID string, purpose entities.OTPPurpose, matches func(*entities.OTPChallenge, string) bool) (*entities.OTPChallenge, error) {
+	challenge, err := s.GetChallenge(ctx, challengeID)
+	if err != nil {
 		if s.metrics != nil {
-			s.metrics.RecordOTPVerified(phoneNumber, false)
+			s.metrics.RecordOTPVerified(entities.TenantIDFromContext(ctx), "", string(purpose), false)
 		}
-		return fmt.Errorf("OTP not found or expired")
+		return nil, errors.ErrOTPInvalid
 	}
 
-	if storedCode != code {
+	if challenge.Purpose != purpose {
+		s.logger.Warn(ctx, "OTP challenge presented for another purpose",
+			logger.F("challenge_id", challengeID),
+			logger.F("purpose", challenge.Purpose),
+			logger.F("presented_purpose", purpose))
 		if s.metrics != nil {
-			s.metrics.RecordOTPVerified(phoneNumber, false)
+			s.metrics.RecordOTPVerified(challenge.TenantID, challenge.PhoneNumber, string(purpose), false)
 		}
-		return fmt.Errorf("invalid OTP code")
+		return nil, errors.ErrOTPInvalid
 	}
 
-	if s.metrics != nil {
-		s.metrics.RecordOTPVerified(phoneNumber, true)
+	if err := s.checkLockout(ctx, challenge.TenantID, challenge.PhoneNumber); err != nil {
+		if s.metrics != nil {
+			s.metrics.RecordOTPVerified(challenge.TenantID, challenge.PhoneNumber, string(purpose), false)
+		}
+		return nil, err
+	}
+
+	storedHash, err := s.client.Get(ctx, s.codeKey(challenge))
+	if !challenge.IsPending() || err != nil || storedHash == "" {
+		if s.metrics != nil {
+			s.metrics.RecordOTPVerified(challenge.TenantID, challenge.PhoneNumber, string(purpose), false)
+		}
+		return nil, errors.ErrOTPInvalid
+	}
+
+	if !matches(challenge, storedHash) {
+		if s.metrics != nil {
+			s.metrics.RecordOTPVerified(challenge.TenantID, challenge.PhoneNumber, string(purpose), false)
+		}
+		return nil, s.recordFailedAttempt(ctx, challenge)
+	}
+
+	return challenge, nil
+}
+
+// GetChallenge loads a challenge of the context's tenant by ID. A pending
+// challenge whose code is gone is reported as expired.
+func (s *OTPService) GetChallenge(ctx context.Context, challengeID string) (*entities.OTPChallenge, error) {
+	return s.getChallenge(ctx, entities.TenantIDFromContext(ctx), challengeID)
+}
+
+func (s *OTPService) getChallenge(ctx context.Context, tenantID int, challengeID string) (*entities.OTPChallenge, error) {
+	fields, err := s.client.HGetAll(ctx, s.challengeKey(tenantID, challengeID))
+	if err != nil {
+		return nil, err
+	}
+	if len(fields) == 0 {
+		return nil, errors.NewNotFound("OTP challenge")
+	}
+
+	attempts, _ := strconv.Atoi(fields["attempts"])
+	userID, _ := strconv.Atoi(fields["user_id"])
+	resends, _ := strconv.Atoi(fields["resends"])
+	createdAt, _ := strconv.ParseInt(fields["created_at"], 10, 64)
+	lastSentAt, _ := strconv.ParseInt(fields["last_sent_at"], 10, 64)
+	expiresAt, _ := strconv.ParseInt(fields["expires_at"], 10, 64)
+
+	var channels []entities.OTPChannel
+	for _, channel := range strings.Split(fields["channels"], ",") {
+		if channel != "" {
+			channels = append(channels, entities.OTPChannel(channel))
+		}
+	}
+
+	challenge := &entities.OTPChallenge{
+		ID:             challengeID,
+		TenantID:       tenantID,
+		PhoneNumber:    fields["phone_number"],
+		Purpose:        entities.OTPPurpose(fields["purpose"]),
+		Channel:        entities.OTPChannel(fields["channel"]),
+		Channels:       channels,
+		Email:          fields["email"],
+		Locale:         fields["locale"],
+		Status:         entities.OTPChallengeStatus(fields["status"]),
+		Attempts:       attempts,
+		Resends:        resends,
+		Provider:       fields["provider"],
+		MessageID:      fields["message_id"],
+		DeliveryStatus: entities.DeliveryStatus(fields["delivery_status"]),
+		Operation:      fields["operation"],
+		PayloadHash:    fields["payload_hash"],
+		Summary:        fields["summary"],
+		UserID:         userID,
+		CreatedAt:      time.Unix(createdAt, 0),
+		LastSentAt:     time.Unix(lastSentAt, 0),
+		ExpiresAt:      time.Unix(expiresAt, 0),
+	}
+
+	if challenge.IsPending() && !s.IsOTPValid(ctx, challenge) {
+		challenge.Status = entities.OTPChallengeStatusExpired
+	}
+
+	return challenge, nil
+}
+
+// configFor returns the OTP settings of the tenant
+func (s *OTPService) configFor(tenantID int) *config.OTPConfig {
+	if s.tenantConfig != nil {
+		if cfg := s.tenantConfig(tenantID); cfg != nil {
+			return cfg
+		}
+	}
+	return s.config
+}
+
+// PhoneChangeVerifiesOldNumber checks if the tenant's phone number changes
+// must be confirmed by the current number too
+func (s *OTPService) PhoneChangeVerifiesOldNumber(tenantID int) bool {
+	return s.configFor(tenantID).PhoneChangeVerifyOldNumber
+}
+
+// expiryFor returns how long codes issued for the purpose stay valid
+func expiryFor(cfg *config.OTPConfig, purpose entities.OTPPurpose) time.Duration {
+	if expiry, ok := cfg.PurposeExpiry[string(purpose)]; ok && expiry > 0 {
+		return expiry
+	}
+	return cfg.Expiry
+}
+
+// lengthFor returns the length of codes issued for the purpose
+func lengthFor(cfg *config.OTPConfig, purpose entities.OTPPurpose) int {
+	if length, ok := cfg.PurposeLength[string(purpose)]; ok && length > 0 {
+		return length
+	}
+	return cfg.Length
+}
+
+// AttemptsRemaining returns how many wrong guesses the challenge can still
+// take, counting the wrong guesses made on other challenges of the number
+func (s *OTPService) AttemptsRemaining(ctx context.Context, challenge *entities.OTPChallenge) int {
+	cfg := s.configFor(challenge.TenantID)
+	if cfg.MaxAttempts <= 0 || !challenge.IsPending() {
+		return 0
+	}
+
+	attempts := challenge.Attempts
+	if value, err := s.client.Get(ctx, s.failuresKey(challenge)); err == nil {
+		if failures, _ := strconv.Atoi(value); failures > attempts {
+			attempts = failures
+		}
+	}
+
+	if remaining := cfg.MaxAttempts - attempts; remaining > 0 {
+		return remaining
+	}
+	return 0
+}
+
+// saveChallenge stores the challenge record. It outlives the code by
+// ChallengeRetention so clients can still poll its final status.
+func (s *OTPService) saveChallenge(ctx context.Context, challenge *entities.OTPChallenge) error {
+	channels := make([]string, len(challenge.Channels))
+	for i, channel := range challenge.Channels {
+		channels[i] = string(channel)
 	}
 
-	err = s.client.Del(ctx, otpKey)
+	key := s.challengeKey(challenge.TenantID, challenge.ID)
+	err := s.client.HSet(ctx, key,
+		"phone_number", challenge.PhoneNumber,
+		"email", challenge.Email,
+		"locale", challenge.Locale,
+		"purpose", string(challenge.Purpose),
+		"channel", string(challenge.Channel),
+		"channels", strings.Join(channels, ","),
+		"operation", challenge.Operation,
+		"payload_hash", challenge.PayloadHash,
+		"summary", challenge.Summary,
+		"user_id", challenge.UserID,
+		"status", string(challenge.Status),
+		"attempts", challenge.Attempts,
+		"resends", challenge.Resends,
+		"created_at", challenge.CreatedAt.Unix(),
+		"last_sent_at", challenge.LastSentAt.Unix(),
+		"expires_at", challenge.ExpiresAt.Unix(),
+	)
 	if err != nil {
 		return err
 	}
 
-	return nil
+	cfg := s.configFor(challenge.TenantID)
+	return s.client.Expire(ctx, key, expiryFor(cfg, challenge.Purpose)+cfg.ChallengeRetention)
+}
+
+// recordFailedAttempt counts a wrong guess against the challenge and against
+// the phone number, and locks the number out once either budget is spent.
+// Counting per number keeps an attacker from multiplying their guesses by
+// opening several challenges.
+func (s *OTPService) recordFailedAttempt(ctx context.Context, challenge *entities.OTPChallenge) error {
+	cfg := s.configFor(challenge.TenantID)
+
+	attempts, err := s.client.HIncrBy(ctx, s.challengeKey(challenge.TenantID, challenge.ID), "attempts", 1)
+	if err != nil {
+		s.logger.Error(ctx, "Failed to count OTP attempt", logger.F("error", err), logger.F("challenge_id", challenge.ID))
+		return errors.ErrOTPInvalid
+	}
+	challenge.Attempts = int(attempts)
+
+	failuresKey := s.failuresKey(challenge)
+	failures, err := s.client.Incr(ctx, failuresKey)
+	if err != nil {
+		s.logger.Error(ctx, "Failed to count OTP failure", logger.F("error", err), logger.F("challenge_id", challenge.ID))
+	} else if failures == 1 {
+		s.client.Expire(ctx, failuresKey, cfg.LockoutDuration)
+	}
+
+	if cfg.MaxAttempts <= 0 || (int(attempts) < cfg.MaxAttempts && int(failures) < cfg.MaxAttempts) {
+		return errors.ErrOTPInvalid
+	}
+
+	if err := s.client.Del(ctx, failuresKey); err != nil {
+		s.logger.Error(ctx, "Failed to reset OTP failures", logger.F("error", err), logger.F("challenge_id", challenge.ID))
+	}
+
+	if err := s.client.Del(ctx, s.codeKey(challenge), s.sealedCodeKey(challenge)); err != nil {
+		s.logger.Error(ctx, "Failed to burn OTP code", logger.F("error", err), logger.F("challenge_id", challenge.ID))
+	}
+
+	challenge.Status = entities.OTPChallengeStatusFailed
+	if err := s.client.HSet(ctx, s.challengeKey(challenge.TenantID, challenge.ID), "status", string(challenge.Status)); err != nil {
+		s.logger.Error(ctx, "Failed to update OTP challenge status", logger.F("error", err), logger.F("challenge_id", challenge.ID))
+	}
+
+	return s.lockout(ctx, challenge.TenantID, challenge.PhoneNumber)
+}
+
+// lockout locks the phone number for a cooldown that grows with every
+// lockout seen within LockoutHistoryTTL
+func (s *OTPService) lockout(ctx context.Context, tenantID int, phoneNumber string) error {
+	cfg := s.configFor(tenantID)
+
+	lockoutsKey := s.lockoutsKey(tenantID, phoneNumber)
+	lockoutCount, err := s.client.Incr(ctx, lockoutsKey)
+	if err != nil {
+		lockoutCount = 1
+	}
+	s.client.Expire(ctx, lockoutsKey, cfg.LockoutHistoryTTL)
+
+	duration := lockoutDuration(cfg, int(lockoutCount))
+	if err := s.client.Set(ctx, s.lockKey(tenantID, phoneNumber), lockoutCount, duration); err != nil {
+		s.logger.Error(ctx, "Failed to lock out phone number", logger.F("error", err), logger.F("phone_number", phoneNumber))
+	}
+
+	s.logger.Warn(ctx, "Phone number locked out after too many failed OTP attempts",
+		logger.F("phone_number", phoneNumber),
+		logger.F("lockout_count", lockoutCount),
+		logger.F("duration", duration))
+
+	if s.metrics != nil {
+		s.metrics.RecordOTPLockout(tenantID, phoneNumber, int(lockoutCount))
+	}
+
+	if s.lockoutHandler != nil {
+		s.lockoutHandler(ctx, phoneNumber, duration, int(lockoutCount))
+	}
+
+	return errors.NewOTPLocked(duration)
+}
+
+// lockoutDuration returns the cooldown for the n-th lockout in a row
+func lockoutDuration(cfg *config.OTPConfig, lockoutCount int) time.Duration {
+	factor := cfg.LockoutBackoffFactor
+	if factor < 1 {
+		factor = 1
+	}
+
+	duration := time.Duration(float64(cfg.LockoutDuration) * math.Pow(factor, float64(lockoutCount-1)))
+	if cfg.MaxLockoutDuration > 0 && (duration > cfg.MaxLockoutDuration || duration <= 0) {
+		duration = cfg.MaxLockoutDuration
+	}
+
+	return duration
+}
+
+// checkLockout returns ErrOTPLocked while the phone number is locked out
+func (s *OTPService) checkLockout(ctx context.Context, tenantID int, phoneNumber string) error {
+	ttl, err := s.client.TTL(ctx, s.lockKey(tenantID, phoneNumber))
+	if err != nil || ttl <= 0 {
+		return nil
+	}
+
+	return errors.NewOTPLocked(ttl)
 }
 
 func (s *OTPService) CleanupExpiredOTPs(ctx context.Context) error {
 	return nil
 }
 
-func (s *OTPService) generateRandomCode(length int) (string, error) {
-	charset := s.config.CodeCharset
+// hashCode returns the keyed hash stored in place of the plaintext code.
+// The purpose and challenge ID, and the payload hash of transaction codes,
+// are mixed in so equal codes never produce equal hashes.
+func (s *OTPService) hashCode(secret string, challenge *entities.OTPChallenge, code string) string {
+	binding := string(challenge.Purpose) + ":" + challenge.ID
+	if challenge.PayloadHash != "" {
+		binding += ":" + challenge.PayloadHash
+	}
+
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(binding + ":" + code))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// matchesCode compares the code against the stored hash in constant time,
+// accepting hashes made with either the current or the previous secret
+func (s *OTPService) matchesCode(challenge *entities.OTPChallenge, code, storedHash string) bool {
+	stored, err := hex.DecodeString(storedHash)
+	if err != nil {
+		return false
+	}
+
+	cfg := s.configFor(challenge.TenantID)
+	secrets := []string{cfg.HashSecret}
+	if cfg.PreviousHashSecret != "" {
+		secrets = append(secrets, cfg.PreviousHashSecret)
+	}
+
+	matched := false
+	for _, secret := range secrets {
+		candidate, _ := hex.DecodeString(s.hashCode(secret, challenge, code))
+		if hmac.Equal(candidate, stored) {
+			matched = true
+		}
+	}
+
+	return matched
+}
+
+// sealCode encrypts the code with a key derived from HashSecret so it can be
+// resent without keeping the plaintext in Redis
+func sealCode(cfg *config.OTPConfig, challengeID, code string) (string, error) {
+	gcm, err := sealCipher(cfg)
+	if err != nil {
+		return "", err
+	}
+
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+
+	sealed := gcm.Seal(nonce, nonce, []byte(code), []byte(challengeID))
+	return hex.EncodeToString(sealed), nil
+}
+
+// openCode decrypts a code sealed by sealCode
+func openCode(cfg *config.OTPConfig, challengeID, sealedHex string) (string, error) {
+	gcm, err := sealCipher(cfg)
+	if err != nil {
+		return "", err
+	}
+
+	sealed, err := hex.DecodeString(sealedHex)
+	if err != nil || len(sealed) < gcm.NonceSize() {
+		return "", fmt.Errorf("malformed sealed code")
+	}
+
+	nonce, ciphertext := sealed[:gcm.NonceSize()], sealed[gcm.NonceSize():]
+	code, err := gcm.Open(nil, nonce, ciphertext, []byte(challengeID))
+	if err != nil {
+		return "", err
+	}
+
+	return string(code), nil
+}
+
+func sealCipher(cfg *config.OTPConfig) (cipher.AEAD, error) {
+	key := sha256.Sum256([]byte("otp-resend:" + cfg.HashSecret))
+	block, err := aes.NewCipher(key[:])
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+func generateRandomCode(cfg *config.OTPConfig, length int) (string, error) {
+	charset := cfg.CodeCharset
 	if charset == "" {
 		charset = "0123456789"
 	}
@@ -113,13 +1117,55 @@ func (s *OTPService) generateRandomCode(length int) (string, error) {
 	return code, nil
 }
 
-func (s *OTPService) IsOTPValid(ctx context.Context, phoneNumber string) bool {
-	otpKey := fmt.Sprintf("%s:%s", s.config.RedisKeyPrefix, phoneNumber)
-	storedCode, err := s.client.Get(ctx, otpKey)
-	return err == nil && storedCode != ""
+// IsOTPValid checks whether the challenge still has a code that can be verified
+func (s *OTPService) IsOTPValid(ctx context.Context, challenge *entities.OTPChallenge) bool {
+	exists, err := s.client.Exists(ctx, s.codeKey(challenge))
+	return err == nil && exists
+}
+
+// GetOTPTTL returns how long the challenge's code remains valid
+func (s *OTPService) GetOTPTTL(ctx context.Context, challenge *entities.OTPChallenge) (time.Duration, error) {
+	return s.client.TTL(ctx, s.codeKey(challenge))
+}
+
+// Challenges, codes and lockouts live in a keyspace per tenant, so the same
+// phone number is locked out and verified separately with every tenant
+func (s *OTPService) challengeKey(tenantID int, challengeID string) string {
+	return fmt.Sprintf("%s:%d:challenge:%s", s.config.RedisKeyPrefix, tenantID, challengeID)
+}
+
+// Codes live in a keyspace per purpose, so a code issued for one purpose can
+// never be found when verifying another
+func (s *OTPService) codeKey(challenge *entities.OTPChallenge) string {
+	return fmt.Sprintf("%s:%d:%s:challenge:%s:code", s.config.RedisKeyPrefix, challenge.TenantID, challenge.Purpose, challenge.ID)
+}
+
+func (s *OTPService) sealedCodeKey(challenge *entities.OTPChallenge) string {
+	return fmt.Sprintf("%s:%d:%s:challenge:%s:sealed", s.config.RedisKeyPrefix, challenge.TenantID, challenge.Purpose, challenge.ID)
+}
+
+func (s *OTPService) cooldownKey(tenantID int, challengeID string) string {
+	return fmt.Sprintf("%s:%d:challenge:%s:cooldown", s.config.RedisKeyPrefix, tenantID, challengeID)
+}
+
+func (s *OTPService) deliveriesKey(tenantID int, challengeID string) string {
+	return fmt.Sprintf("%s:%d:challenge:%s:deliveries", s.config.RedisKeyPrefix, tenantID, challengeID)
+}
+
+func (s *OTPService) messageKey(provider, messageID string) string {
+	return fmt.Sprintf("%s:message:%s:%s", s.config.RedisKeyPrefix, provider, messageID)
+}
+
+func (s *OTPService) lockKey(tenantID int, phoneNumber string) string {
+	return fmt.Sprintf("%s:%d:%s:locked", s.config.RedisKeyPrefix, tenantID, phoneNumber)
+}
+
+// Wrong guesses are counted per purpose, so failing a transaction code does
+// not use up the guesses of a login
+func (s *OTPService) failuresKey(challenge *entities.OTPChallenge) string {
+	return fmt.Sprintf("%s:%d:%s:%s:failures", s.config.RedisKeyPrefix, challenge.TenantID, challenge.Purpose, challenge.PhoneNumber)
 }
 
-func (s *OTPService) GetOTPTTL(ctx context.Context, phoneNumber string) (time.Duration, error) {
-	otpKey := fmt.Sprintf("%s:%s", s.config.RedisKeyPrefix, phoneNumber)
-	return s.client.TTL(ctx, otpKey)
+func (s *OTPService) lockoutsKey(tenantID int, phoneNumber string) string {
+	return fmt.Sprintf("%s:%d:%s:lockouts", s.config.RedisKeyPrefix, tenantID, phoneNumber)
 }
@@ -50,6 +50,28 @@ func (c *Client) Set(ctx context.Context, key string, value interface{}, expirat
 	return c.client.Set(ctx, key, value, expiration).Err()
 }
 
+// GetDel gets a value and deletes its key in one step, so only one caller
+// ever gets it
+func (c *Client) GetDel(ctx context.Context, key string) (string, error) {
+	return c.client.GetDel(ctx, key).Result()
+}
+
+// IsNil checks if err reports a missing key
+func IsNil(err error) bool {
+	return err == redis.Nil
+}
+
+// MGet gets the values of several keys in one round trip; missing keys
+// come back as nil
+func (c *Client) MGet(ctx context.Context, keys ...string) ([]interface{}, error) {
+	return c.client.MGet(ctx, keys...).Result()
+}
+
+// SetNX sets a key-value pair only if the key does not exist yet
+func (c *Client) SetNX(ctx context.Context, key string, value interface{}, expiration time.Duration) (bool, error) {
+	return c.client.SetNX(ctx, key, value, expiration).Result()
+}
+
 // Del deletes keys
 func (c *Client) Del(ctx context.Context, keys ...string) error {
 	return c.client.Del(ctx, keys...).Err()
@@ -71,6 +93,52 @@ func (c *Client) TTL(ctx context.Context, key string) (time.Duration, error) {
 	return c.client.TTL(ctx, key).Result()
 }
 
+// Incr increments the integer value of a key by one
+func (c *Client) Incr(ctx context.Context, key string) (int64, error) {
+	return c.client.Incr(ctx, key).Result()
+}
+
+// Expire sets a timeout on a key
+func (c *Client) Expire(ctx context.Context, key string, expiration time.Duration) error {
+	return c.client.Expire(ctx, key, expiration).Err()
+}
+
+// Exists checks whether a key exists
+func (c *Client) Exists(ctx context.Context, key string) (bool, error) {
+	n, err := c.client.Exists(ctx, key).Result()
+	return n > 0, err
+}
+
+// DelCount deletes keys and returns how many of them existed
+func (c *Client) DelCount(ctx context.Context, keys ...string) (int64, error) {
+	return c.client.Del(ctx, keys...).Result()
+}
+
+// HSet sets fields in a hash
+func (c *Client) HSet(ctx context.Context, key string, values ...interface{}) error {
+	return c.client.HSet(ctx, key, values...).Err()
+}
+
+// HGetAll gets all fields of a hash
+func (c *Client) HGetAll(ctx context.Context, key string) (map[string]string, error) {
+	return c.client.HGetAll(ctx, key).Result()
+}
+
+// HIncrBy increments the integer value of a hash field
+func (c *Client) HIncrBy(ctx context.Context, key, field string, incr int64) (int64, error) {
+	return c.client.HIncrBy(ctx, key, field, incr).Result()
+}
+
+// RPush appends values to a list
+func (c *Client) RPush(ctx context.Context, key string, values ...interface{}) error {
+	return c.client.RPush(ctx, key, values...).Err()
+}
+
+// LRange gets a range of list elements
+func (c *Client) LRange(ctx context.Context, key string, start, stop int64) ([]string, error) {
+	return c.client.LRange(ctx, key, start, stop).Result()
+}
+
 // Publish publishes a message to a channel
 func (c *Client) Publish(ctx context.Context, channel string, message string) error {
 	return c.client.Publish(ctx, channel, message).Err()
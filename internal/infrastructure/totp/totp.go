@@ -0,0 +1,113 @@
+package totp
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/subtle"
+	"encoding/base32"
+	"encoding/binary"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// secretSize is the RFC 4226 recommended shared secret length (160 bits)
+const secretSize = 20
+
+var encoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// Generator computes and checks RFC 6238 time-based codes (HMAC-SHA1, the
+// algorithm every authenticator app supports)
+type Generator struct {
+	Period time.Duration
+	Digits int
+	Skew   int
+}
+
+// NewGenerator creates a new TOTP generator, falling back to the RFC
+// defaults of 30 second steps and 6 digits
+func NewGenerator(period time.Duration, digits, skew int) *Generator {
+	if period <= 0 {
+		period = 30 * time.Second
+	}
+	if digits < 6 || digits > 8 {
+		digits = 6
+	}
+	if skew < 0 {
+		skew = 0
+	}
+	return &Generator{Period: period, Digits: digits, Skew: skew}
+}
+
+// GenerateSecret returns a new random secret
+func GenerateSecret() ([]byte, error) {
+	secret := make([]byte, secretSize)
+	if _, err := rand.Read(secret); err != nil {
+		return nil, err
+	}
+	return secret, nil
+}
+
+// EncodeSecret returns the base32 form of the secret users type into their app
+func EncodeSecret(secret []byte) string {
+	return encoding.EncodeToString(secret)
+}
+
+// URI returns the otpauth:// key URI authenticator apps scan as a QR code
+func (g *Generator) URI(issuer, account string, secret []byte) string {
+	params := url.Values{}
+	params.Set("secret", EncodeSecret(secret))
+	params.Set("issuer", issuer)
+	params.Set("algorithm", "SHA1")
+	params.Set("digits", fmt.Sprint(g.Digits))
+	params.Set("period", fmt.Sprint(int(g.Period.Seconds())))
+
+	label := url.PathEscape(issuer) + ":" + url.PathEscape(account)
+	return "otpauth://totp/" + label + "?" + params.Encode()
+}
+
+// Step returns the time step the instant falls into
+func (g *Generator) Step(t time.Time) int64 {
+	return t.Unix() / int64(g.Period.Seconds())
+}
+
+// Code returns the code for the time step
+func (g *Generator) Code(secret []byte, step int64) string {
+	var counter [8]byte
+	binary.BigEndian.PutUint64(counter[:], uint64(step))
+
+	mac := hmac.New(sha1.New, secret)
+	mac.Write(counter[:])
+	sum := mac.Sum(nil)
+
+	offset := sum[len(sum)-1] & 0x0f
+	value := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+
+	mod := uint32(1)
+	for i := 0; i < g.Digits; i++ {
+		mod *= 10
+	}
+	return fmt.Sprintf("%0*d", g.Digits, value%mod)
+}
+
+// Match checks the code against the steps within Skew of now and returns
+// the step it matched. Callers must reject steps at or before the last one
+// accepted for the secret, or a code could be replayed.
+func (g *Generator) Match(secret []byte, code string, now time.Time) (int64, bool) {
+	code = strings.TrimSpace(code)
+	if len(code) != g.Digits {
+		return 0, false
+	}
+
+	current := g.Step(now)
+	matched := int64(-1)
+	for step := current - int64(g.Skew); step <= current+int64(g.Skew); step++ {
+		if subtle.ConstantTimeCompare([]byte(g.Code(secret, step)), []byte(code)) == 1 && matched < 0 {
+			matched = step
+		}
+	}
+
+	return matched, matched >= 0
+}
@@ -0,0 +1,115 @@
+package totp
+
+import (
+	"testing"
+	"time"
+)
+
+// rfc6238Secret is the SHA-1 seed of the RFC 6238 appendix B test vectors
+var rfc6238Secret = []byte("12345678901234567890")
+
+func TestCodeRFC6238Vectors(t *testing.T) {
+	g := NewGenerator(30*time.Second, 8, 0)
+
+	tests := []struct {
+		unix int64
+		code string
+	}{
+		{59, "94287082"},
+		{1111111109, "07081804"},
+		{1111111111, "14050471"},
+		{1234567890, "89005924"},
+		{2000000000, "69279037"},
+		{20000000000, "65353130"},
+	}
+
+	for _, tt := range tests {
+		now := time.Unix(tt.unix, 0)
+		if got := g.Code(rfc6238Secret, g.Step(now)); got != tt.code {
+			t.Errorf("Code at %d = %s, want %s", tt.unix, got, tt.code)
+		}
+
+		step, ok := g.Match(rfc6238Secret, tt.code, now)
+		if !ok || step != g.Step(now) {
+			t.Errorf("Match at %d = (%d, %v), want (%d, true)", tt.unix, step, ok, g.Step(now))
+		}
+	}
+}
+
+func TestMatchSkew(t *testing.T) {
+	g := NewGenerator(30*time.Second, 6, 1)
+	now := time.Unix(1234567890, 0)
+	current := g.Step(now)
+
+	tests := []struct {
+		name   string
+		offset int64
+		want   bool
+	}{
+		{"two steps behind", -2, false},
+		{"one step behind", -1, true},
+		{"current step", 0, true},
+		{"one step ahead", 1, true},
+		{"two steps ahead", 2, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			code := g.Code(rfc6238Secret, current+tt.offset)
+			step, ok := g.Match(rfc6238Secret, code, now)
+			if ok != tt.want {
+				t.Fatalf("Match = %v, want %v", ok, tt.want)
+			}
+			if ok && step != current+tt.offset {
+				t.Errorf("Match step = %d, want %d", step, current+tt.offset)
+			}
+		})
+	}
+}
+
+func TestMatchRejectsMalformedCodes(t *testing.T) {
+	g := NewGenerator(30*time.Second, 6, 1)
+	now := time.Unix(1234567890, 0)
+	code := g.Code(rfc6238Secret, g.Step(now))
+
+	tests := []struct {
+		name string
+		code string
+		want bool
+	}{
+		{"valid", code, true},
+		{"surrounding spaces", " " + code + " ", true},
+		{"empty", "", false},
+		{"too short", code[:5], false},
+		{"too long", code + "0", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, ok := g.Match(rfc6238Secret, tt.code, now); ok != tt.want {
+				t.Errorf("Match(%q) = %v, want %v", tt.code, ok, tt.want)
+			}
+		})
+	}
+}
+
+func TestMatchReplayReturnsSameStep(t *testing.T) {
+	g := NewGenerator(30*time.Second, 6, 1)
+	now := time.Unix(1234567890, 0)
+	code := g.Code(rfc6238Secret, g.Step(now))
+
+	first, ok := g.Match(rfc6238Secret, code, now)
+	if !ok {
+		t.Fatal("first Match failed")
+	}
+
+	// Within the skew window the code keeps matching, always at the step it
+	// was generated for, which is what lets callers refuse the replay
+	second, ok := g.Match(rfc6238Secret, code, now.Add(g.Period))
+	if !ok {
+		t.Fatal("second Match failed")
+	}
+	if second != first {
+		t.Errorf("replayed code matched step %d, want %d", second, first)
+	}
+}
@@ -0,0 +1,463 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"otp-server/internal/application"
+	"otp-server/internal/domain/entities"
+	"otp-server/internal/domain/errors"
+	"otp-server/internal/domain/repositories"
+	"otp-server/internal/infrastructure/logger"
+	"otp-server/internal/interfaces/http/handlers/dto"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// AdminHandler handles user administration requests
+type AdminHandler struct {
+	userService application.UserServiceInterface
+	logger      logger.Logger
+}
+
+// NewAdminHandler creates a new admin handler
+func NewAdminHandler(userService application.UserServiceInterface, logger logger.Logger) *AdminHandler {
+	return &AdminHandler{
+		userService: userService,
+		logger:      logger,
+	}
+}
+
+// ListUsers lists the users of the tenant
+// @Summary List users
+// @Description List the users of the tenant, newest first, optionally filtered. Users with the users:read permission, or API clients with the users:read or users:admin scope.
+// @Tags Admin
+// @Produce json
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Param query query string false "Part of the phone number or name"
+// @Param role query string false "Role"
+// @Param status query string false "Status; suspensions that have lifted count as active" Enums(active, suspended, banned, pending_deletion, deactivated)
+// @Param created_after query string false "Created at or after, RFC 3339"
+// @Param created_before query string false "Created before, RFC 3339"
+// @Param offset query int false "Pagination offset (default: 0)"
+// @Param limit query int false "Pagination limit (default: 10, max: 100)"
+// @Success 200 {object} dto.UsersListResponse "Users retrieved successfully"
+// @Failure 400 {object} dto.ErrorResponse "Invalid filter"
+// @Failure 401 {object} dto.ErrorResponse "Unauthorized - invalid or missing JWT token"
+// @Failure 403 {object} dto.ErrorResponse "The users:read permission or scope required"
+// @Failure 429 {object} dto.ErrorResponse "Client rate limit exceeded"
+// @Failure 500 {object} dto.ErrorResponse "Internal server error"
+// @Router /api/v1/admin/users [get]
+func (h *AdminHandler) ListUsers(c *fiber.Ctx) error {
+	filter := &repositories.UserFilter{
+		Query:  c.Query("query"),
+		Role:   entities.UserRole(c.Query("role")),
+		Status: entities.UserStatus(c.Query("status")),
+	}
+
+	for param, target := range map[string]*time.Time{
+		"created_after":  &filter.CreatedAfter,
+		"created_before": &filter.CreatedBefore,
+	} {
+		if value := c.Query(param); value != "" {
+			t, err := time.Parse(time.RFC3339, value)
+			if err != nil {
+				return c.Status(http.StatusBadRequest).JSON(dto.ErrorResponse{
+					Error:   "Invalid request",
+					Message: param + " must be an RFC 3339 time",
+				})
+			}
+			*target = t
+		}
+	}
+
+	offset, err := strconv.Atoi(c.Query("offset", "0"))
+	if err != nil || offset < 0 {
+		return c.Status(http.StatusBadRequest).JSON(dto.ErrorResponse{
+			Error:   "Invalid offset parameter",
+			Message: "Offset must be a non-negative integer",
+		})
+	}
+
+	limit, err := strconv.Atoi(c.Query("limit", "10"))
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(dto.ErrorResponse{
+			Error:   "Invalid limit parameter",
+			Message: "Limit must be a valid integer",
+		})
+	}
+	if limit > 100 {
+		limit = 100
+	}
+	if limit < 1 {
+		limit = 10
+	}
+
+	users, total, err := h.userService.ListUsers(c.Context(), filter, offset, limit)
+	if err != nil {
+		if errors.IsInvalidInput(err) {
+			return c.Status(http.StatusBadRequest).JSON(dto.ErrorResponse{
+				Error:   "Invalid request",
+				Message: err.Error(),
+			})
+		}
+
+		h.logger.Error(c.Context(), "Failed to list users", logger.F("error", err))
+		return c.Status(http.StatusInternalServerError).JSON(dto.ErrorResponse{
+			Error:   "Failed to list users",
+			Message: err.Error(),
+		})
+	}
+
+	response := dto.UsersListResponse{
+		Users:  make([]dto.UserResponse, len(users)),
+		Total:  total,
+		Offset: offset,
+		Limit:  limit,
+	}
+	for i, user := range users {
+		response.Users[i] = userResponse(user)
+	}
+
+	return c.Status(http.StatusOK).JSON(response)
+}
+
+// GetUser returns a user of the tenant
+// @Summary Get user
+// @Description Get a user of the tenant. Users with the users:read permission, or API clients with the users:read or users:admin scope.
+// @Tags Admin
+// @Produce json
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Param id path int true "User ID"
+// @Success 200 {object} dto.UserResponse "User retrieved successfully"
+// @Failure 400 {object} dto.ErrorResponse "Invalid user ID"
+// @Failure 401 {object} dto.ErrorResponse "Unauthorized - invalid or missing JWT token"
+// @Failure 403 {object} dto.ErrorResponse "The users:read permission or scope required"
+// @Failure 404 {object} dto.ErrorResponse "User not found"
+// @Failure 500 {object} dto.ErrorResponse "Internal server error"
+// @Router /api/v1/admin/users/{id} [get]
+func (h *AdminHandler) GetUser(c *fiber.Ctx) error {
+	userID, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(dto.ErrorResponse{
+			Error:   "Invalid request",
+			Message: "id must be a number",
+		})
+	}
+
+	user, err := h.userService.GetUser(c.Context(), userID)
+	if err != nil {
+		return h.userError(c, err, "Failed to get user", userID)
+	}
+
+	return c.Status(http.StatusOK).JSON(userResponse(user))
+}
+
+// ActivateUser lets a suspended, banned or deactivated user log in again
+// @Summary Activate user
+// @Description Activate a suspended, banned, deactivated or pending deletion user, recording it in the status history. Users with the users:write permission, or API clients with the users:admin scope.
+// @Tags Admin
+// @Produce json
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Param id path int true "User ID"
+// @Success 200 {object} dto.UserResponse "User activated"
+// @Failure 400 {object} dto.ErrorResponse "Invalid user ID"
+// @Failure 401 {object} dto.ErrorResponse "Unauthorized - invalid or missing JWT token"
+// @Failure 403 {object} dto.ErrorResponse "The users:write permission or the users:admin scope required"
+// @Failure 404 {object} dto.ErrorResponse "User not found"
+// @Failure 500 {object} dto.ErrorResponse "Internal server error"
+// @Router /api/v1/admin/users/{id}/activate [post]
+func (h *AdminHandler) ActivateUser(c *fiber.Ctx) error {
+	userID, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(dto.ErrorResponse{
+			Error:   "Invalid request",
+			Message: "id must be a number",
+		})
+	}
+
+	user, err := h.userService.ActivateUser(c.Context(), requestActor(c), userID)
+	if err != nil {
+		return h.userError(c, err, "Failed to activate user", userID)
+	}
+
+	return c.Status(http.StatusOK).JSON(userResponse(user))
+}
+
+// DeactivateUser blocks a user
+// @Summary Deactivate user
+// @Description Deactivate a user: their tokens are revoked and they cannot log in or request OTPs until activated again. Users cannot deactivate themselves. Users with the users:write permission, or API clients with the users:admin scope.
+// @Tags Admin
+// @Produce json
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Param id path int true "User ID"
+// @Success 200 {object} dto.UserResponse "User deactivated"
+// @Failure 400 {object} dto.ErrorResponse "Invalid user ID, or the admin's own account"
+// @Failure 401 {object} dto.ErrorResponse "Unauthorized - invalid or missing JWT token"
+// @Failure 403 {object} dto.ErrorResponse "The users:write permission or the users:admin scope required"
+// @Failure 404 {object} dto.ErrorResponse "User not found"
+// @Failure 500 {object} dto.ErrorResponse "Internal server error"
+// @Router /api/v1/admin/users/{id}/deactivate [post]
+func (h *AdminHandler) DeactivateUser(c *fiber.Ctx) error {
+	userID, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(dto.ErrorResponse{
+			Error:   "Invalid request",
+			Message: "id must be a number",
+		})
+	}
+
+	if isRequestUser(c, userID) {
+		return c.Status(http.StatusBadRequest).JSON(dto.ErrorResponse{
+			Error:   "Invalid request",
+			Message: "users cannot deactivate themselves",
+		})
+	}
+
+	user, err := h.userService.DeactivateUser(c.Context(), requestActor(c), userID)
+	if err != nil {
+		return h.userError(c, err, "Failed to deactivate user", userID)
+	}
+
+	return c.Status(http.StatusOK).JSON(userResponse(user))
+}
+
+// ChangeRole promotes or demotes a user
+// @Summary Change user role
+// @Description Give a user another role, e.g. promote them to admin or demote them to a regular user; it applies to their current tokens at once. Users cannot change their own role. Users with the roles:write permission, or API clients with the users:admin scope.
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Param id path int true "User ID"
+// @Param request body dto.ChangeRoleRequest true "New role"
+// @Success 200 {object} dto.UserResponse "Role changed"
+// @Failure 400 {object} dto.ErrorResponse "Invalid user ID or role, or the admin's own account"
+// @Failure 401 {object} dto.ErrorResponse "Unauthorized - invalid or missing JWT token"
+// @Failure 403 {object} dto.ErrorResponse "The roles:write permission or the users:admin scope required"
+// @Failure 404 {object} dto.ErrorResponse "User not found"
+// @Failure 500 {object} dto.ErrorResponse "Internal server error"
+// @Router /api/v1/admin/users/{id}/role [put]
+func (h *AdminHandler) ChangeRole(c *fiber.Ctx) error {
+	userID, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(dto.ErrorResponse{
+			Error:   "Invalid request",
+			Message: "id must be a number",
+		})
+	}
+
+	var req dto.ChangeRoleRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(http.StatusBadRequest).JSON(dto.ErrorResponse{
+			Error:   "Invalid request",
+			Message: err.Error(),
+		})
+	}
+
+	if isRequestUser(c, userID) {
+		return c.Status(http.StatusBadRequest).JSON(dto.ErrorResponse{
+			Error:   "Invalid request",
+			Message: "users cannot change their own role",
+		})
+	}
+
+	user, err := h.userService.ChangeRole(c.Context(), requestActor(c), userID, entities.UserRole(req.Role))
+	if err != nil {
+		return h.userError(c, err, "Failed to change user role", userID)
+	}
+
+	return c.Status(http.StatusOK).JSON(userResponse(user))
+}
+
+// SetUserStatus changes the status of a user
+// @Summary Set user status
+// @Description Suspend a user until a given time, ban them, schedule their account for deletion, deactivate or activate them, with an optional reason. Users who are not active have their tokens revoked and cannot log in or request OTPs; suspensions lift by themselves once their time passes. Every change is recorded in the status history. Users cannot change their own status. Users with the users:write permission, or API clients with the users:admin scope.
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Param id path int true "User ID"
+// @Param request body dto.SetUserStatusRequest true "New status"
+// @Success 200 {object} dto.UserResponse "Status changed"
+// @Failure 400 {object} dto.ErrorResponse "Invalid user ID, status, reason or until, or the admin's own account"
+// @Failure 401 {object} dto.ErrorResponse "Unauthorized - invalid or missing JWT token"
+// @Failure 403 {object} dto.ErrorResponse "The users:write permission or the users:admin scope required"
+// @Failure 404 {object} dto.ErrorResponse "User not found"
+// @Failure 429 {object} dto.ErrorResponse "Client rate limit exceeded"
+// @Failure 500 {object} dto.ErrorResponse "Internal server error"
+// @Router /api/v1/admin/users/{id}/status [put]
+func (h *AdminHandler) SetUserStatus(c *fiber.Ctx) error {
+	userID, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(dto.ErrorResponse{
+			Error:   "Invalid request",
+			Message: "id must be a number",
+		})
+	}
+
+	var req dto.SetUserStatusRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(http.StatusBadRequest).JSON(dto.ErrorResponse{
+			Error:   "Invalid request",
+			Message: err.Error(),
+		})
+	}
+
+	if isRequestUser(c, userID) {
+		return c.Status(http.StatusBadRequest).JSON(dto.ErrorResponse{
+			Error:   "Invalid request",
+			Message: "users cannot change their own status",
+		})
+	}
+
+	user, err := h.userService.SetStatus(c.Context(), requestActor(c), userID, entities.UserStatus(req.Status), req.Reason, req.Until)
+	if err != nil {
+		return h.userError(c, err, "Failed to change user status", userID)
+	}
+
+	return c.Status(http.StatusOK).JSON(userResponse(user))
+}
+
+// GetUserStatusHistory returns the status changes of a user
+// @Summary Get user status history
+// @Description Get the audit trail of a user's status changes, newest first, with who made each change and why. Users with the logs:read permission, or API clients with the users:read or users:admin scope.
+// @Tags Admin
+// @Produce json
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Param id path int true "User ID"
+// @Success 200 {object} dto.UserStatusHistoryResponse "Status history"
+// @Failure 400 {object} dto.ErrorResponse "Invalid user ID"
+// @Failure 401 {object} dto.ErrorResponse "Unauthorized - invalid or missing JWT token"
+// @Failure 403 {object} dto.ErrorResponse "The logs:read permission or the users:read scope required"
+// @Failure 404 {object} dto.ErrorResponse "User not found"
+// @Failure 500 {object} dto.ErrorResponse "Internal server error"
+// @Router /api/v1/admin/users/{id}/status-history [get]
+func (h *AdminHandler) GetUserStatusHistory(c *fiber.Ctx) error {
+	userID, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(dto.ErrorResponse{
+			Error:   "Invalid request",
+			Message: "id must be a number",
+		})
+	}
+
+	changes, err := h.userService.GetStatusHistory(c.Context(), userID)
+	if err != nil {
+		return h.userError(c, err, "Failed to get user status history", userID)
+	}
+
+	response := dto.UserStatusHistoryResponse{Changes: make([]dto.UserStatusChangeResponse, len(changes))}
+	for i, change := range changes {
+		response.Changes[i] = dto.UserStatusChangeResponse{
+			PreviousStatus: string(change.PreviousStatus),
+			Status:         string(change.Status),
+			Reason:         change.Reason,
+			Until:          change.Until,
+			Actor:          change.Actor,
+			CreatedAt:      change.CreatedAt,
+		}
+	}
+
+	return c.Status(http.StatusOK).JSON(response)
+}
+
+// RevokeUserTokens revokes every token of a user
+// @Summary Revoke all tokens of a user
+// @Description Revoke every access and refresh token issued to the user so far, logging them out on all devices. Users with the users:write permission, or API clients with the users:admin scope.
+// @Tags Admin
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Param id path int true "User ID"
+// @Success 204 "Tokens revoked"
+// @Failure 400 {object} dto.ErrorResponse "Invalid user ID"
+// @Failure 401 {object} dto.ErrorResponse "Unauthorized - invalid or missing JWT token"
+// @Failure 403 {object} dto.ErrorResponse "The users:write permission or the users:admin scope required"
+// @Failure 404 {object} dto.ErrorResponse "User not found"
+// @Failure 429 {object} dto.ErrorResponse "Client rate limit exceeded"
+// @Failure 500 {object} dto.ErrorResponse "Internal server error"
+// @Router /api/v1/admin/users/{id}/revoke-tokens [post]
+func (h *AdminHandler) RevokeUserTokens(c *fiber.Ctx) error {
+	userID, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(dto.ErrorResponse{
+			Error:   "Invalid request",
+			Message: "id must be a number",
+		})
+	}
+
+	if err := h.userService.ForceLogout(c.Context(), requestActor(c), userID); err != nil {
+		return h.userError(c, err, "Failed to revoke tokens", userID)
+	}
+
+	return c.SendStatus(http.StatusNoContent)
+}
+
+// userError answers a failed user administration request
+func (h *AdminHandler) userError(c *fiber.Ctx, err error, message string, userID int) error {
+	switch {
+	case errors.IsNotFound(err):
+		return c.Status(http.StatusNotFound).JSON(dto.ErrorResponse{
+			Error:   "User not found",
+			Message: err.Error(),
+		})
+	case errors.IsInvalidInput(err):
+		return c.Status(http.StatusBadRequest).JSON(dto.ErrorResponse{
+			Error:   "Invalid request",
+			Message: err.Error(),
+		})
+	}
+
+	h.logger.Error(c.Context(), message, logger.F("error", err), logger.F("user_id", userID))
+	return c.Status(http.StatusInternalServerError).JSON(dto.ErrorResponse{
+		Error:   message,
+		Message: err.Error(),
+	})
+}
+
+// userResponse converts a user for the admin API
+func userResponse(user *entities.User) dto.UserResponse {
+	response := dto.UserResponse{
+		ID:          user.ID,
+		PhoneNumber: user.PhoneNumber,
+		Name:        user.Name,
+		Role:        string(user.Role),
+		IsActive:    user.IsActive(),
+		Status:      string(user.CurrentStatus()),
+		CreatedAt:   user.CreatedAt,
+		UpdatedAt:   user.UpdatedAt,
+	}
+	// A lifted suspension's reason no longer applies
+	if !user.IsActive() {
+		response.StatusReason = user.StatusReason
+		response.StatusUntil = user.StatusUntil
+	}
+	return response
+}
+
+// isRequestUser checks if the request was made by the user with the ID
+func isRequestUser(c *fiber.Ctx, userID int) bool {
+	user, ok := c.Locals("user").(*entities.User)
+	return ok && user.ID == userID
+}
+
+// requestActor names who made a request let through by Auth, for the logs:
+// the user, or the API client
+func requestActor(c *fiber.Ctx) string {
+	if client, ok := c.Locals("api_client").(*entities.APIClient); ok {
+		return "api_client:" + client.ClientID
+	}
+	if user, ok := c.Locals("user").(*entities.User); ok {
+		return fmt.Sprintf("user:%d", user.ID)
+	}
+	return "unknown"
+}
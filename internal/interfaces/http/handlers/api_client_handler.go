@@ -0,0 +1,145 @@
+package handlers
+
+import (
+	"net/http"
+
+	"otp-server/internal/application"
+	"otp-server/internal/domain/entities"
+	"otp-server/internal/domain/errors"
+	"otp-server/internal/infrastructure/logger"
+	"otp-server/internal/interfaces/http/handlers/dto"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// APIClientHandler handles the registration of API clients
+type APIClientHandler struct {
+	apiClientService application.APIClientServiceInterface
+	logger           logger.Logger
+}
+
+// NewAPIClientHandler creates a new API client handler
+func NewAPIClientHandler(apiClientService application.APIClientServiceInterface, logger logger.Logger) *APIClientHandler {
+	return &APIClientHandler{
+		apiClientService: apiClientService,
+		logger:           logger,
+	}
+}
+
+// RegisterClient registers a new API client
+// @Summary Register API client
+// @Description Register a backend service that calls the API on its own behalf. The client secret is returned only once; send it as the X-API-Key header, or exchange it for an access token with the client credentials grant. Users with the clients:write permission only.
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body dto.RegisterAPIClientRequest true "Client to register"
+// @Success 201 {object} dto.APIClientResponse "Client registered"
+// @Failure 400 {object} dto.ErrorResponse "Invalid name, scope or rate limit"
+// @Failure 401 {object} dto.ErrorResponse "Unauthorized - invalid or missing JWT token"
+// @Failure 403 {object} dto.ErrorResponse "The clients:write permission required"
+// @Failure 500 {object} dto.ErrorResponse "Internal server error"
+// @Router /api/v1/admin/api-clients [post]
+func (h *APIClientHandler) RegisterClient(c *fiber.Ctx) error {
+	var req dto.RegisterAPIClientRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(http.StatusBadRequest).JSON(dto.ErrorResponse{
+			Error:   "Invalid request",
+			Message: err.Error(),
+		})
+	}
+
+	client, secret, err := h.apiClientService.RegisterClient(c.Context(), req.Name, req.Scopes, req.RateLimit)
+	if err != nil {
+		if errors.IsInvalidInput(err) {
+			return c.Status(http.StatusBadRequest).JSON(dto.ErrorResponse{
+				Error:   "Invalid request",
+				Message: err.Error(),
+			})
+		}
+
+		h.logger.Error(c.Context(), "Failed to register API client", logger.F("error", err))
+		return c.Status(http.StatusInternalServerError).JSON(dto.ErrorResponse{
+			Error:   "Failed to register client",
+			Message: err.Error(),
+		})
+	}
+
+	response := apiClientResponse(client)
+	response.ClientSecret = secret
+
+	return c.Status(http.StatusCreated).JSON(response)
+}
+
+// ListClients lists the registered API clients
+// @Summary List API clients
+// @Description List every registered API client, including deactivated ones. Users with the clients:read permission only.
+// @Tags Admin
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} dto.APIClientsResponse "Registered clients"
+// @Failure 401 {object} dto.ErrorResponse "Unauthorized - invalid or missing JWT token"
+// @Failure 403 {object} dto.ErrorResponse "The clients:read permission required"
+// @Failure 500 {object} dto.ErrorResponse "Internal server error"
+// @Router /api/v1/admin/api-clients [get]
+func (h *APIClientHandler) ListClients(c *fiber.Ctx) error {
+	clients, err := h.apiClientService.ListClients(c.Context())
+	if err != nil {
+		h.logger.Error(c.Context(), "Failed to list API clients", logger.F("error", err))
+		return c.Status(http.StatusInternalServerError).JSON(dto.ErrorResponse{
+			Error:   "Failed to list clients",
+			Message: err.Error(),
+		})
+	}
+
+	response := dto.APIClientsResponse{Clients: make([]dto.APIClientResponse, 0, len(clients))}
+	for _, client := range clients {
+		response.Clients = append(response.Clients, apiClientResponse(client))
+	}
+
+	return c.Status(http.StatusOK).JSON(response)
+}
+
+// DeactivateClient deactivates an API client
+// @Summary Deactivate API client
+// @Description Stop a client from calling the API. Its API key and the access tokens it holds stop working at once. Users with the clients:write permission only.
+// @Tags Admin
+// @Security BearerAuth
+// @Param client_id path string true "Client ID"
+// @Success 204 "Client deactivated"
+// @Failure 401 {object} dto.ErrorResponse "Unauthorized - invalid or missing JWT token"
+// @Failure 403 {object} dto.ErrorResponse "The clients:write permission required"
+// @Failure 404 {object} dto.ErrorResponse "Client not found"
+// @Failure 500 {object} dto.ErrorResponse "Internal server error"
+// @Router /api/v1/admin/api-clients/{client_id} [delete]
+func (h *APIClientHandler) DeactivateClient(c *fiber.Ctx) error {
+	clientID := c.Params("client_id")
+
+	if err := h.apiClientService.DeactivateClient(c.Context(), clientID); err != nil {
+		if errors.IsNotFound(err) {
+			return c.Status(http.StatusNotFound).JSON(dto.ErrorResponse{
+				Error:   "Client not found",
+				Message: err.Error(),
+			})
+		}
+
+		h.logger.Error(c.Context(), "Failed to deactivate API client", logger.F("error", err), logger.F("client_id", clientID))
+		return c.Status(http.StatusInternalServerError).JSON(dto.ErrorResponse{
+			Error:   "Failed to deactivate client",
+			Message: err.Error(),
+		})
+	}
+
+	return c.SendStatus(http.StatusNoContent)
+}
+
+func apiClientResponse(client *entities.APIClient) dto.APIClientResponse {
+	return dto.APIClientResponse{
+		ClientID:  client.ClientID,
+		Name:      client.Name,
+		Scopes:    client.Scopes,
+		RateLimit: client.RateLimit,
+		IsActive:  client.IsActive,
+		CreatedAt: client.CreatedAt,
+	}
+}
@@ -3,8 +3,13 @@ package handlers
 import (
 	"net/http"
 	"otp-server/lib"
+	"strings"
+	"time"
 
 	"otp-server/internal/application"
+	"otp-server/internal/application/services"
+	"otp-server/internal/domain/entities"
+	"otp-server/internal/domain/errors"
 	"otp-server/internal/infrastructure/logger"
 	"otp-server/internal/interfaces/http/handlers/dto"
 
@@ -27,15 +32,18 @@ func NewAuthHandler(authService application.AuthServiceInterface, logger logger.
 
 // SendOTP sends OTP to the user's phone number
 // @Summary Send OTP
-// @Description Send a one-time password (OTP) to the provided phone number for authentication
+// @Description Send a one-time password (OTP) to the provided phone number for authentication. The code goes out over the preferred channel, or the first configured one, and falls back to the next channel when delivery fails or is not confirmed in time.
 // @Tags Authentication
 // @Accept json
 // @Produce json
 // @Param request body dto.SendOTPRequest true "Send OTP request with phone number"
+// @Param Accept-Language header string false "Preferred message languages" example(fa-IR,fa;q=0.9,en;q=0.8)
 // @Success 200 {object} dto.SendOTPResponse "OTP sent successfully"
-// @Failure 400 {object} dto.ErrorResponse "Invalid phone number format"
-// @Failure 429 {object} dto.ErrorResponse "Too many OTP requests"
+// @Failure 400 {object} dto.ErrorResponse "Invalid phone number format or unavailable channel"
+// @Failure 403 {object} dto.ErrorResponse "The account of the phone number is suspended, banned or deactivated"
+// @Failure 429 {object} dto.ErrorResponse "Too many OTP requests or phone number locked out"
 // @Failure 500 {object} dto.ErrorResponse "Internal server error"
+// @Failure 502 {object} dto.ErrorResponse "OTP delivery provider failed"
 // @Router /api/v1/auth/send-otp [post]
 func (h *AuthHandler) SendOTP(c *fiber.Ctx) error {
 	var req dto.SendOTPRequest
@@ -54,8 +62,62 @@ func (h *AuthHandler) SendOTP(c *fiber.Ctx) error {
 		})
 	}
 
-	err = h.authService.SendOTP(c.Context(), req.PhoneNumber)
+	channel := entities.OTPChannel(req.Channel)
+	if channel != "" && !channel.IsValid() {
+		return c.Status(http.StatusBadRequest).JSON(dto.ErrorResponse{
+			Error:   "Invalid request",
+			Message: "channel must be one of sms, voice, whatsapp, email",
+		})
+	}
+
+	if channel == entities.OTPChannelEmail && req.Email == "" {
+		return c.Status(http.StatusBadRequest).JSON(dto.ErrorResponse{
+			Error:   "Invalid request",
+			Message: "email is required for the email channel",
+		})
+	}
+
+	locales := lib.ParseAcceptLanguage(c.Get(fiber.HeaderAcceptLanguage))
+	if req.Locale != "" {
+		locales = append([]string{req.Locale}, locales...)
+	}
+
+	challenge, err := h.authService.SendOTP(c.Context(), &entities.OTPRequest{
+		PhoneNumber: req.PhoneNumber,
+		Channel:     channel,
+		Email:       req.Email,
+		Locales:     locales,
+	})
 	if err != nil {
+		if errors.IsAccountInactive(err) {
+			return c.Status(http.StatusForbidden).JSON(dto.ErrorResponse{
+				Error:   "account_inactive",
+				Message: err.Error(),
+			})
+		}
+
+		if errors.IsInvalidInput(err) {
+			return c.Status(http.StatusBadRequest).JSON(dto.ErrorResponse{
+				Error:   "Invalid request",
+				Message: "channel is not available for this phone number",
+			})
+		}
+
+		if errors.IsOTPLocked(err) {
+			return c.Status(http.StatusTooManyRequests).JSON(dto.ErrorResponse{
+				Error:   "otp_locked",
+				Message: err.Error(),
+			})
+		}
+
+		if errors.IsOTPDeliveryFailed(err) {
+			h.logger.Error(c.Context(), "Failed to deliver OTP", logger.F("error", err), logger.F("phone_number", req.PhoneNumber))
+			return c.Status(http.StatusBadGateway).JSON(dto.ErrorResponse{
+				Error:   "delivery_failed",
+				Message: "Could not deliver the OTP, please try again",
+			})
+		}
+
 		h.logger.Error(c.Context(), "Failed to send OTP", logger.F("error", err), logger.F("phone_number", req.PhoneNumber))
 		return c.Status(http.StatusInternalServerError).JSON(dto.ErrorResponse{
 			Error:   "Failed to send OTP",
@@ -64,23 +126,207 @@ func (h *AuthHandler) SendOTP(c *fiber.Ctx) error {
 	}
 
 	return c.Status(http.StatusOK).JSON(dto.SendOTPResponse{
-		Message:     "OTP sent successfully",
-		PhoneNumber: req.PhoneNumber,
-		Timestamp:   c.Get("Date"),
+		Message:        "OTP sent successfully",
+		VerificationID: challenge.ID,
+		ExpiresIn:      int(time.Until(challenge.ExpiresAt).Seconds()),
+		Channel:        string(challenge.Channel),
+		Locale:         challenge.Locale,
+		PhoneNumber:    req.PhoneNumber,
+		Timestamp:      c.Get("Date"),
+	})
+}
+
+// SendLoginOTP sends a login OTP on behalf of the user of a phone number
+// @Summary Send OTP on behalf of a user
+// @Description Send a login OTP to a phone number like /api/v1/auth/send-otp does, for backend services driving the login of their users. Not subject to the per-IP auth limit; the per-phone OTP limit and the client's own rate limit apply. API clients with the otp:send scope, or users with the otp:send permission.
+// @Tags Authentication
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Param request body dto.SendOTPRequest true "Send OTP request with phone number"
+// @Param Accept-Language header string false "Preferred message languages" example(fa-IR,fa;q=0.9,en;q=0.8)
+// @Success 200 {object} dto.SendOTPResponse "OTP sent successfully"
+// @Failure 400 {object} dto.ErrorResponse "Invalid phone number format or unavailable channel"
+// @Failure 401 {object} dto.ErrorResponse "Invalid API key or token"
+// @Failure 403 {object} dto.ErrorResponse "The otp:send scope or permission required, or the account of the phone number is suspended, banned or deactivated"
+// @Failure 429 {object} dto.ErrorResponse "Too many OTP requests, client rate limit exceeded or phone number locked out"
+// @Failure 500 {object} dto.ErrorResponse "Internal server error"
+// @Failure 502 {object} dto.ErrorResponse "OTP delivery provider failed"
+// @Router /api/v1/otp/login [post]
+func (h *AuthHandler) SendLoginOTP(c *fiber.Ctx) error {
+	return h.SendOTP(c)
+}
+
+// ResendOTP resends the code of an existing OTP challenge
+// @Summary Resend OTP
+// @Description Resend the code of an OTP challenge. Within the reuse window the same code is sent again, afterwards a new code replaces it.
+// @Tags Authentication
+// @Accept json
+// @Produce json
+// @Param request body dto.ResendOTPRequest true "Resend OTP request with verification ID"
+// @Success 200 {object} dto.ResendOTPResponse "OTP resent successfully"
+// @Failure 400 {object} dto.ErrorResponse "Invalid request or challenge already completed"
+// @Failure 403 {object} dto.ErrorResponse "The account of the phone number is suspended, banned or deactivated"
+// @Failure 404 {object} dto.ErrorResponse "Unknown or no longer retained verification ID"
+// @Failure 429 {object} dto.ErrorResponse "Resent too recently, resend limit reached or phone number locked out"
+// @Failure 500 {object} dto.ErrorResponse "Internal server error"
+// @Failure 502 {object} dto.ErrorResponse "OTP delivery provider failed"
+// @Router /api/v1/auth/resend-otp [post]
+func (h *AuthHandler) ResendOTP(c *fiber.Ctx) error {
+	var req dto.ResendOTPRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(http.StatusBadRequest).JSON(dto.ErrorResponse{
+			Error:   "Invalid request",
+			Message: err.Error(),
+		})
+	}
+
+	if req.VerificationID == "" {
+		return c.Status(http.StatusBadRequest).JSON(dto.ErrorResponse{
+			Error:   "Invalid request",
+			Message: "verification_id is required",
+		})
+	}
+
+	status, err := h.authService.ResendOTP(c.Context(), req.VerificationID)
+	if err != nil {
+		switch {
+		case errors.IsNotFound(err):
+			return c.Status(http.StatusNotFound).JSON(dto.ErrorResponse{
+				Error:   "Not found",
+				Message: "OTP challenge not found",
+			})
+		case errors.IsOTPInvalid(err):
+			return c.Status(http.StatusBadRequest).JSON(dto.ErrorResponse{
+				Error:   "Invalid request",
+				Message: "OTP challenge is already completed",
+			})
+		case errors.IsAccountInactive(err):
+			return c.Status(http.StatusForbidden).JSON(dto.ErrorResponse{
+				Error:   "account_inactive",
+				Message: err.Error(),
+			})
+		case errors.IsOTPResendTooSoon(err):
+			return c.Status(http.StatusTooManyRequests).JSON(dto.ErrorResponse{
+				Error:   "resend_too_soon",
+				Message: err.Error(),
+			})
+		case errors.IsOTPResendLimit(err):
+			return c.Status(http.StatusTooManyRequests).JSON(dto.ErrorResponse{
+				Error:   "resend_limit_reached",
+				Message: err.Error(),
+			})
+		case errors.IsOTPLocked(err):
+			return c.Status(http.StatusTooManyRequests).JSON(dto.ErrorResponse{
+				Error:   "otp_locked",
+				Message: err.Error(),
+			})
+		case errors.IsOTPDeliveryFailed(err):
+			h.logger.Error(c.Context(), "Failed to deliver OTP", logger.F("error", err), logger.F("verification_id", req.VerificationID))
+			return c.Status(http.StatusBadGateway).JSON(dto.ErrorResponse{
+				Error:   "delivery_failed",
+				Message: "Could not deliver the OTP, please try again",
+			})
+		}
+
+		h.logger.Error(c.Context(), "Failed to resend OTP", logger.F("error", err), logger.F("verification_id", req.VerificationID))
+		return c.Status(http.StatusInternalServerError).JSON(dto.ErrorResponse{
+			Error:   "Failed to resend OTP",
+			Message: err.Error(),
+		})
+	}
+
+	return c.Status(http.StatusOK).JSON(dto.ResendOTPResponse{
+		Message:          "OTP resent successfully",
+		VerificationID:   status.Challenge.ID,
+		ExpiresIn:        int(status.ExpiresIn.Seconds()),
+		ResendIn:         int(status.ResendIn.Seconds()),
+		ResendsRemaining: status.ResendsRemaining,
+		Timestamp:        c.Get("Date"),
+	})
+}
+
+// GetOTPStatus returns the status of an OTP challenge
+// @Summary Get OTP status
+// @Description Get the status of an OTP verification challenge, including whether its code can still be verified
+// @Tags Authentication
+// @Produce json
+// @Param verification_id path string true "Verification ID returned by send-otp"
+// @Success 200 {object} dto.OTPStatusResponse "OTP challenge status"
+// @Failure 404 {object} dto.ErrorResponse "Unknown or no longer retained verification ID"
+// @Failure 500 {object} dto.ErrorResponse "Internal server error"
+// @Router /api/v1/auth/otp/{verification_id} [get]
+func (h *AuthHandler) GetOTPStatus(c *fiber.Ctx) error {
+	verificationID := c.Params("verification_id")
+
+	status, err := h.authService.GetOTPStatus(c.Context(), verificationID)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return c.Status(http.StatusNotFound).JSON(dto.ErrorResponse{
+				Error:   "Not found",
+				Message: "OTP challenge not found",
+			})
+		}
+
+		h.logger.Error(c.Context(), "Failed to get OTP status", logger.F("error", err), logger.F("verification_id", verificationID))
+		return c.Status(http.StatusInternalServerError).JSON(dto.ErrorResponse{
+			Error:   "Failed to get OTP status",
+			Message: err.Error(),
+		})
+	}
+
+	challenge := status.Challenge
+	channels := make([]string, len(challenge.Channels))
+	for i, channel := range challenge.Channels {
+		channels[i] = string(channel)
+	}
+
+	deliveries := make([]dto.DeliveryEventResponse, len(status.Deliveries))
+	for i, event := range status.Deliveries {
+		deliveries[i] = dto.DeliveryEventResponse{
+			Provider:  event.Provider,
+			MessageID: event.MessageID,
+			Channel:   string(event.Channel),
+			Status:    string(event.Status),
+			ErrorCode: event.ErrorCode,
+			At:        event.At.UTC().Format(time.RFC3339),
+		}
+	}
+
+	return c.Status(http.StatusOK).JSON(dto.OTPStatusResponse{
+		VerificationID:    challenge.ID,
+		Status:            string(challenge.Status),
+		Purpose:           string(challenge.Purpose),
+		Channel:           string(challenge.Channel),
+		Channels:          channels,
+		DeliveryStatus:    string(challenge.DeliveryStatus),
+		Locale:            challenge.Locale,
+		Deliveries:        deliveries,
+		PhoneNumber:       lib.MaskPhoneNumber(challenge.PhoneNumber),
+		Valid:             status.Valid,
+		ExpiresIn:         int(status.ExpiresIn.Seconds()),
+		Attempts:          challenge.Attempts,
+		AttemptsRemaining: status.AttemptsRemaining,
+		ResendIn:          int(status.ResendIn.Seconds()),
+		ResendsRemaining:  status.ResendsRemaining,
+		CreatedAt:         challenge.CreatedAt.UTC().Format(time.RFC3339),
 	})
 }
 
 // VerifyOTP verifies OTP and returns authentication tokens
 // @Summary Verify OTP
-// @Description Verify the one-time password (OTP) sent to the user's phone number and return JWT authentication tokens
+// @Description Verify the one-time password (OTP) sent to the user's phone number and return JWT authentication tokens. Users with an authenticator app enrolled get an MFA token instead, to present to verify-totp. Users who lost their phone can present a recovery code instead of the OTP; the account then has to confirm a new phone number before it can use the API.
 // @Tags Authentication
 // @Accept json
 // @Produce json
-// @Param request body dto.VerifyOTPRequest true "Verify OTP request with phone number and OTP code"
+// @Param request body dto.VerifyOTPRequest true "Verify OTP request with verification ID and OTP code"
 // @Success 200 {object} dto.AuthResponse "Authentication successful - returns access token, refresh token, and user info"
+// @Success 202 {object} dto.SecondFactorRequiredResponse "Second factor required"
 // @Failure 400 {object} dto.ErrorResponse "Invalid request format or missing required fields"
 // @Failure 401 {object} dto.ErrorResponse "Invalid OTP or expired OTP"
-// @Failure 429 {object} dto.ErrorResponse "Too many verification attempts"
+// @Failure 403 {object} dto.ErrorResponse "The account is suspended, banned or deactivated"
+// @Failure 429 {object} dto.ErrorResponse "Too many failed attempts - phone number is temporarily locked out"
 // @Failure 500 {object} dto.ErrorResponse "Internal server error during token generation"
 // @Router /api/v1/auth/verify-otp [post]
 func (h *AuthHandler) VerifyOTP(c *fiber.Ctx) error {
@@ -92,24 +338,644 @@ func (h *AuthHandler) VerifyOTP(c *fiber.Ctx) error {
 		})
 	}
 
-	user, token, err := h.authService.VerifyOTPAndAuthenticate(c.Context(), req.PhoneNumber, req.OTP, req.Name)
+	if req.VerificationID == "" || (req.OTP == "") == (req.RecoveryCode == "") {
+		return c.Status(http.StatusBadRequest).JSON(dto.ErrorResponse{
+			Error:   "Invalid request",
+			Message: "verification_id and exactly one of otp and recovery_code are required",
+		})
+	}
+
+	var result *services.AuthResult
+	var err error
+	if req.RecoveryCode != "" {
+		result, err = h.authService.VerifyRecoveryCodeAndAuthenticate(c.Context(), req.VerificationID, req.RecoveryCode, deviceInfo(c, req.DeviceName))
+	} else {
+		result, err = h.authService.VerifyOTPAndAuthenticate(c.Context(), req.VerificationID, req.OTP, req.Name, deviceInfo(c, req.DeviceName))
+	}
 	if err != nil {
-		h.logger.Error(c.Context(), "Failed to verify OTP", logger.F("error", err), logger.F("phone_number", req.PhoneNumber))
+		h.logger.Error(c.Context(), "Failed to verify OTP", logger.F("error", err), logger.F("verification_id", req.VerificationID))
+		if errors.IsAccountInactive(err) {
+			return c.Status(http.StatusForbidden).JSON(dto.ErrorResponse{
+				Error:   "account_inactive",
+				Message: err.Error(),
+			})
+		}
+
+		if errors.IsOTPLocked(err) {
+			return c.Status(http.StatusTooManyRequests).JSON(dto.ErrorResponse{
+				Error:   "otp_locked",
+				Message: err.Error(),
+			})
+		}
+
 		return c.Status(http.StatusUnauthorized).JSON(dto.ErrorResponse{
 			Error:   "Invalid OTP",
 			Message: err.Error(),
 		})
 	}
 
-	response := dto.AuthResponse{
-		Token: token,
+	if result.SecondFactorRequired {
+		return c.Status(http.StatusAccepted).JSON(dto.SecondFactorRequiredResponse{
+			SecondFactorRequired: true,
+			MFAToken:             result.MFAToken,
+			ExpiresIn:            int(result.MFAExpiresIn.Seconds()),
+			Factors:              []string{string(entities.FactorTypeTOTP)},
+		})
+	}
+
+	return c.Status(http.StatusOK).JSON(authResponse(result))
+}
+
+// VerifyTOTP completes a login with an authenticator app code
+// @Summary Verify second factor
+// @Description Complete a login that required a second factor with the current code from the user's authenticator app. Each code is accepted once.
+// @Tags Authentication
+// @Accept json
+// @Produce json
+// @Param request body dto.VerifyTOTPRequest true "MFA token from verify-otp and authenticator code"
+// @Success 200 {object} dto.AuthResponse "Authentication successful"
+// @Failure 400 {object} dto.ErrorResponse "Invalid request format or missing required fields"
+// @Failure 401 {object} dto.ErrorResponse "Invalid code, or expired or burned MFA token"
+// @Failure 403 {object} dto.ErrorResponse "The account is suspended, banned or deactivated"
+// @Failure 500 {object} dto.ErrorResponse "Internal server error"
+// @Router /api/v1/auth/verify-totp [post]
+func (h *AuthHandler) VerifyTOTP(c *fiber.Ctx) error {
+	var req dto.VerifyTOTPRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(http.StatusBadRequest).JSON(dto.ErrorResponse{
+			Error:   "Invalid request",
+			Message: err.Error(),
+		})
+	}
+
+	if req.MFAToken == "" || req.Code == "" {
+		return c.Status(http.StatusBadRequest).JSON(dto.ErrorResponse{
+			Error:   "Invalid request",
+			Message: "mfa_token and code are required",
+		})
+	}
+
+	result, err := h.authService.VerifySecondFactor(c.Context(), req.MFAToken, req.Code, deviceInfo(c, req.DeviceName))
+	if err != nil {
+		if errors.IsAccountInactive(err) {
+			return c.Status(http.StatusForbidden).JSON(dto.ErrorResponse{
+				Error:   "account_inactive",
+				Message: err.Error(),
+			})
+		}
+
+		if errors.IsSecondFactorInvalid(err) {
+			return c.Status(http.StatusUnauthorized).JSON(dto.ErrorResponse{
+				Error:   "invalid_second_factor",
+				Message: err.Error(),
+			})
+		}
+
+		h.logger.Error(c.Context(), "Failed to verify second factor", logger.F("error", err))
+		return c.Status(http.StatusInternalServerError).JSON(dto.ErrorResponse{
+			Error:   "Failed to verify second factor",
+			Message: err.Error(),
+		})
+	}
+
+	return c.Status(http.StatusOK).JSON(authResponse(result))
+}
+
+// RefreshToken exchanges a refresh token for a new token pair
+// @Summary Refresh token
+// @Description Exchange a refresh token for a new access token and a new refresh token. Refresh tokens are single-use: presenting one that was already exchanged is treated as theft and revokes every token descending from the same login.
+// @Tags Authentication
+// @Accept json
+// @Produce json
+// @Param request body dto.RefreshTokenRequest true "Refresh token"
+// @Success 200 {object} dto.RefreshTokenResponse "Tokens refreshed"
+// @Failure 400 {object} dto.ErrorResponse "Missing refresh token"
+// @Failure 401 {object} dto.ErrorResponse "Invalid, expired, reused or revoked refresh token"
+// @Failure 500 {object} dto.ErrorResponse "Internal server error"
+// @Router /api/v1/auth/refresh [post]
+func (h *AuthHandler) RefreshToken(c *fiber.Ctx) error {
+	var req dto.RefreshTokenRequest
+	if err := c.BodyParser(&req); err != nil || req.RefreshToken == "" {
+		return c.Status(http.StatusBadRequest).JSON(dto.ErrorResponse{
+			Error:   "Invalid request",
+			Message: "refresh_token is required",
+		})
+	}
+
+	result, err := h.authService.RefreshTokens(c.Context(), req.RefreshToken)
+	if err != nil {
+		if errors.IsRefreshTokenInvalid(err) {
+			return c.Status(http.StatusUnauthorized).JSON(dto.ErrorResponse{
+				Error:   "invalid_refresh_token",
+				Message: err.Error(),
+			})
+		}
+
+		h.logger.Error(c.Context(), "Failed to refresh token", logger.F("error", err))
+		return c.Status(http.StatusInternalServerError).JSON(dto.ErrorResponse{
+			Error:   "Failed to refresh token",
+			Message: err.Error(),
+		})
+	}
+
+	return c.Status(http.StatusOK).JSON(dto.RefreshTokenResponse{
+		AccessToken:      result.Token,
+		RefreshToken:     result.RefreshToken,
+		Message:          "Token refreshed successfully",
+		ExpiresIn:        int(result.ExpiresIn.Seconds()),
+		RefreshExpiresIn: int(result.RefreshExpiresIn.Seconds()),
+	})
+}
+
+// Logout ends the current session
+// @Summary Logout
+// @Description Revoke the access token of this request and the refresh tokens of its session. Other sessions stay logged in.
+// @Tags Authentication
+// @Security BearerAuth
+// @Success 204 "Logged out"
+// @Failure 401 {object} dto.ErrorResponse "Unauthorized - invalid or missing JWT token"
+// @Failure 500 {object} dto.ErrorResponse "Internal server error"
+// @Router /api/v1/auth/logout [post]
+func (h *AuthHandler) Logout(c *fiber.Ctx) error {
+	user := c.Locals("user").(*entities.User)
+
+	if err := h.authService.Logout(c.Context(), user, c.Locals("token").(string)); err != nil {
+		h.logger.Error(c.Context(), "Failed to log out", logger.F("error", err), logger.F("user_id", user.ID))
+		return c.Status(http.StatusInternalServerError).JSON(dto.ErrorResponse{
+			Error:   "Failed to log out",
+			Message: err.Error(),
+		})
+	}
+
+	return c.SendStatus(http.StatusNoContent)
+}
+
+// LogoutAll ends every session of the user
+// @Summary Logout from all sessions
+// @Description Revoke every access and refresh token issued to the authenticated user so far, on all devices
+// @Tags Authentication
+// @Security BearerAuth
+// @Success 204 "Logged out of all sessions"
+// @Failure 401 {object} dto.ErrorResponse "Unauthorized - invalid or missing JWT token"
+// @Failure 500 {object} dto.ErrorResponse "Internal server error"
+// @Router /api/v1/auth/logout-all [post]
+func (h *AuthHandler) LogoutAll(c *fiber.Ctx) error {
+	user := c.Locals("user").(*entities.User)
+
+	if err := h.authService.LogoutAll(c.Context(), user); err != nil {
+		h.logger.Error(c.Context(), "Failed to log out of all sessions", logger.F("error", err), logger.F("user_id", user.ID))
+		return c.Status(http.StatusInternalServerError).JSON(dto.ErrorResponse{
+			Error:   "Failed to log out",
+			Message: err.Error(),
+		})
+	}
+
+	return c.SendStatus(http.StatusNoContent)
+}
+
+// deviceInfo describes the client of the request for the session it logs in
+func deviceInfo(c *fiber.Ctx, name string) *entities.DeviceInfo {
+	return &entities.DeviceInfo{
+		Name:      strings.TrimSpace(name),
+		UserAgent: c.Get(fiber.HeaderUserAgent),
+		IPAddress: c.IP(),
+	}
+}
+
+func authResponse(result *services.AuthResult) dto.AuthResponse {
+	return dto.AuthResponse{
+		Token:            result.Token,
+		ExpiresIn:        int(result.ExpiresIn.Seconds()),
+		RefreshToken:     result.RefreshToken,
+		RefreshExpiresIn: int(result.RefreshExpiresIn.Seconds()),
 		User: dto.AuthUserResponse{
-			ID:          user.ID,
-			PhoneNumber: user.PhoneNumber,
-			Name:        user.Name,
-			Role:        string(user.Role),
+			ID:                        result.User.ID,
+			PhoneNumber:               result.User.PhoneNumber,
+			Name:                      result.User.Name,
+			Role:                      string(result.User.Role),
+			PhoneConfirmationRequired: result.User.PhoneConfirmationRequired,
 		},
 	}
+}
+
+// SendConfirmationOTP sends a code confirming a sensitive action
+// @Summary Send confirmation OTP
+// @Description Send a one-time password confirming a sensitive action (phone change, account deletion) to the authenticated user's phone number. Codes are scoped to their purpose and cannot be used for anything else.
+// @Tags Authentication
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body dto.SendConfirmationOTPRequest true "Action to confirm"
+// @Success 200 {object} dto.SendOTPResponse "OTP sent successfully"
+// @Failure 400 {object} dto.ErrorResponse "Invalid purpose or channel"
+// @Failure 401 {object} dto.ErrorResponse "Unauthorized - invalid or missing JWT token"
+// @Failure 429 {object} dto.ErrorResponse "Phone number locked out"
+// @Failure 500 {object} dto.ErrorResponse "Internal server error"
+// @Failure 502 {object} dto.ErrorResponse "OTP delivery provider failed"
+// @Router /api/v1/otp/send [post]
+func (h *AuthHandler) SendConfirmationOTP(c *fiber.Ctx) error {
+	user := c.Locals("user").(*entities.User)
+
+	var req dto.SendConfirmationOTPRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(http.StatusBadRequest).JSON(dto.ErrorResponse{
+			Error:   "Invalid request",
+			Message: err.Error(),
+		})
+	}
+
+	channel := entities.OTPChannel(req.Channel)
+	if channel != "" && !channel.IsValid() {
+		return c.Status(http.StatusBadRequest).JSON(dto.ErrorResponse{
+			Error:   "Invalid request",
+			Message: "channel must be one of sms, voice, whatsapp, email",
+		})
+	}
+
+	locales := lib.ParseAcceptLanguage(c.Get(fiber.HeaderAcceptLanguage))
+	if req.Locale != "" {
+		locales = append([]string{req.Locale}, locales...)
+	}
+
+	challenge, err := h.authService.SendConfirmationOTP(c.Context(), user, &entities.OTPRequest{
+		Purpose: entities.OTPPurpose(req.Purpose),
+		Channel: channel,
+		Locales: locales,
+	})
+	if err != nil {
+		switch {
+		case errors.IsInvalidInput(err):
+			return c.Status(http.StatusBadRequest).JSON(dto.ErrorResponse{
+				Error:   "Invalid request",
+				Message: err.Error(),
+			})
+		case errors.IsOTPLocked(err):
+			return c.Status(http.StatusTooManyRequests).JSON(dto.ErrorResponse{
+				Error:   "otp_locked",
+				Message: err.Error(),
+			})
+		case errors.IsOTPDeliveryFailed(err):
+			h.logger.Error(c.Context(), "Failed to deliver confirmation OTP", logger.F("error", err), logger.F("user_id", user.ID))
+			return c.Status(http.StatusBadGateway).JSON(dto.ErrorResponse{
+				Error:   "delivery_failed",
+				Message: "Could not deliver the OTP, please try again",
+			})
+		}
+
+		h.logger.Error(c.Context(), "Failed to send confirmation OTP", logger.F("error", err), logger.F("user_id", user.ID))
+		return c.Status(http.StatusInternalServerError).JSON(dto.ErrorResponse{
+			Error:   "Failed to send OTP",
+			Message: err.Error(),
+		})
+	}
+
+	return c.Status(http.StatusOK).JSON(dto.SendOTPResponse{
+		Message:        "OTP sent successfully",
+		VerificationID: challenge.ID,
+		ExpiresIn:      int(time.Until(challenge.ExpiresAt).Seconds()),
+		Channel:        string(challenge.Channel),
+		Locale:         challenge.Locale,
+		PhoneNumber:    lib.MaskPhoneNumber(challenge.PhoneNumber),
+		Timestamp:      c.Get("Date"),
+	})
+}
+
+// VerifyConfirmationOTP verifies a code confirming a sensitive action
+// @Summary Verify confirmation OTP
+// @Description Verify a confirmation code sent to the authenticated user. The purpose must match the one the code was sent for.
+// @Tags Authentication
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body dto.VerifyConfirmationOTPRequest true "Verification ID, purpose and code"
+// @Success 200 {object} dto.VerifyConfirmationOTPResponse "OTP verified successfully"
+// @Failure 400 {object} dto.ErrorResponse "Invalid request"
+// @Failure 401 {object} dto.ErrorResponse "Invalid or expired OTP, or wrong purpose"
+// @Failure 429 {object} dto.ErrorResponse "Phone number locked out"
+// @Router /api/v1/otp/verify [post]
+func (h *AuthHandler) VerifyConfirmationOTP(c *fiber.Ctx) error {
+	user := c.Locals("user").(*entities.User)
+
+	var req dto.VerifyConfirmationOTPRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(http.StatusBadRequest).JSON(dto.ErrorResponse{
+			Error:   "Invalid request",
+			Message: err.Error(),
+		})
+	}
+
+	if req.VerificationID == "" || req.Purpose == "" || req.OTP == "" {
+		return c.Status(http.StatusBadRequest).JSON(dto.ErrorResponse{
+			Error:   "Invalid request",
+			Message: "verification_id, purpose and otp are required",
+		})
+	}
+
+	challenge, err := h.authService.VerifyConfirmationOTP(c.Context(), user, req.VerificationID, entities.OTPPurpose(req.Purpose), req.OTP)
+	if err != nil {
+		if errors.IsOTPLocked(err) {
+			return c.Status(http.StatusTooManyRequests).JSON(dto.ErrorResponse{
+				Error:   "otp_locked",
+				Message: err.Error(),
+			})
+		}
+
+		return c.Status(http.StatusUnauthorized).JSON(dto.ErrorResponse{
+			Error:   "Invalid OTP",
+			Message: err.Error(),
+		})
+	}
+
+	return c.Status(http.StatusOK).JSON(dto.VerifyConfirmationOTPResponse{
+		Message:        "OTP verified successfully",
+		VerificationID: challenge.ID,
+		Purpose:        string(challenge.Purpose),
+	})
+}
+
+// SendStepUpOTP sends a code for authenticating again
+// @Summary Send step-up OTP
+// @Description Send a one-time password to the authenticated user's phone number for stepping up. Routes guarding sensitive actions answer step_up_required when the user last authenticated too long ago; verifying this code at /api/v1/auth/step-up/verify issues an elevated token for them.
+// @Tags Authentication
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body dto.StepUpRequest false "Delivery preferences"
+// @Success 200 {object} dto.SendOTPResponse "OTP sent successfully"
+// @Failure 400 {object} dto.ErrorResponse "Invalid channel"
+// @Failure 401 {object} dto.ErrorResponse "Unauthorized - invalid or missing JWT token"
+// @Failure 429 {object} dto.ErrorResponse "Phone number locked out"
+// @Failure 500 {object} dto.ErrorResponse "Internal server error"
+// @Failure 502 {object} dto.ErrorResponse "OTP delivery provider failed"
+// @Router /api/v1/auth/step-up [post]
+func (h *AuthHandler) SendStepUpOTP(c *fiber.Ctx) error {
+	user := c.Locals("user").(*entities.User)
+
+	var req dto.StepUpRequest
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&req); err != nil {
+			return c.Status(http.StatusBadRequest).JSON(dto.ErrorResponse{
+				Error:   "Invalid request",
+				Message: err.Error(),
+			})
+		}
+	}
+
+	channel := entities.OTPChannel(req.Channel)
+	if channel != "" && !channel.IsValid() {
+		return c.Status(http.StatusBadRequest).JSON(dto.ErrorResponse{
+			Error:   "Invalid request",
+			Message: "channel must be one of sms, voice, whatsapp, email",
+		})
+	}
+
+	locales := lib.ParseAcceptLanguage(c.Get(fiber.HeaderAcceptLanguage))
+	if req.Locale != "" {
+		locales = append([]string{req.Locale}, locales...)
+	}
+
+	challenge, err := h.authService.SendStepUpOTP(c.Context(), user, &entities.OTPRequest{
+		Channel: channel,
+		Locales: locales,
+	})
+	if err != nil {
+		switch {
+		case errors.IsOTPLocked(err):
+			return c.Status(http.StatusTooManyRequests).JSON(dto.ErrorResponse{
+				Error:   "otp_locked",
+				Message: err.Error(),
+			})
+		case errors.IsOTPDeliveryFailed(err):
+			h.logger.Error(c.Context(), "Failed to deliver step-up OTP", logger.F("error", err), logger.F("user_id", user.ID))
+			return c.Status(http.StatusBadGateway).JSON(dto.ErrorResponse{
+				Error:   "delivery_failed",
+				Message: "Could not deliver the OTP, please try again",
+			})
+		}
+
+		h.logger.Error(c.Context(), "Failed to send step-up OTP", logger.F("error", err), logger.F("user_id", user.ID))
+		return c.Status(http.StatusInternalServerError).JSON(dto.ErrorResponse{
+			Error:   "Failed to send OTP",
+			Message: err.Error(),
+		})
+	}
+
+	return c.Status(http.StatusOK).JSON(dto.SendOTPResponse{
+		Message:        "OTP sent successfully",
+		VerificationID: challenge.ID,
+		ExpiresIn:      int(time.Until(challenge.ExpiresAt).Seconds()),
+		Channel:        string(challenge.Channel),
+		Locale:         challenge.Locale,
+		PhoneNumber:    lib.MaskPhoneNumber(challenge.PhoneNumber),
+		Timestamp:      c.Get("Date"),
+	})
+}
+
+// VerifyStepUp verifies a step-up code and issues an elevated token
+// @Summary Verify step-up OTP
+// @Description Verify a step-up code sent to the authenticated user and issue a short-lived access token for the current session with a fresh auth_time. No new session is started and no refresh token is issued.
+// @Tags Authentication
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body dto.VerifyStepUpRequest true "Verification ID and code"
+// @Success 200 {object} dto.StepUpResponse "Elevated token issued"
+// @Failure 400 {object} dto.ErrorResponse "Invalid request"
+// @Failure 401 {object} dto.ErrorResponse "Invalid or expired OTP"
+// @Failure 429 {object} dto.ErrorResponse "Phone number locked out"
+// @Router /api/v1/auth/step-up/verify [post]
+func (h *AuthHandler) VerifyStepUp(c *fiber.Ctx) error {
+	user := c.Locals("user").(*entities.User)
+	tokenString, _ := c.Locals("token").(string)
+
+	var req dto.VerifyStepUpRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(http.StatusBadRequest).JSON(dto.ErrorResponse{
+			Error:   "Invalid request",
+			Message: err.Error(),
+		})
+	}
+
+	if req.VerificationID == "" || req.OTP == "" {
+		return c.Status(http.StatusBadRequest).JSON(dto.ErrorResponse{
+			Error:   "Invalid request",
+			Message: "verification_id and otp are required",
+		})
+	}
+
+	result, err := h.authService.StepUp(c.Context(), user, tokenString, req.VerificationID, req.OTP)
+	if err != nil {
+		if errors.IsOTPLocked(err) {
+			return c.Status(http.StatusTooManyRequests).JSON(dto.ErrorResponse{
+				Error:   "otp_locked",
+				Message: err.Error(),
+			})
+		}
+
+		return c.Status(http.StatusUnauthorized).JSON(dto.ErrorResponse{
+			Error:   "Invalid OTP",
+			Message: err.Error(),
+		})
+	}
+
+	return c.Status(http.StatusOK).JSON(dto.StepUpResponse{
+		Message:   "Authentication confirmed",
+		Token:     result.Token,
+		ExpiresIn: int(result.ExpiresIn.Seconds()),
+		AuthTime:  result.AuthTime.Unix(),
+	})
+}
+
+// SendTransactionOTP sends a code signing a transaction
+// @Summary Send transaction OTP
+// @Description Send a one-time password signing a specific operation (dynamic linking). The code is bound to the canonical hash of the payload and the message shows a summary of the operation; verifying requires the same payload.
+// @Tags Authentication
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body dto.SendTransactionOTPRequest true "Operation to sign"
+// @Success 200 {object} dto.SendTransactionOTPResponse "OTP sent successfully"
+// @Failure 400 {object} dto.ErrorResponse "Invalid payload or channel"
+// @Failure 401 {object} dto.ErrorResponse "Unauthorized - invalid or missing JWT token"
+// @Failure 429 {object} dto.ErrorResponse "Phone number locked out"
+// @Failure 500 {object} dto.ErrorResponse "Internal server error"
+// @Failure 502 {object} dto.ErrorResponse "OTP delivery provider failed"
+// @Router /api/v1/otp/transaction [post]
+func (h *AuthHandler) SendTransactionOTP(c *fiber.Ctx) error {
+	user := c.Locals("user").(*entities.User)
+
+	var req dto.SendTransactionOTPRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(http.StatusBadRequest).JSON(dto.ErrorResponse{
+			Error:   "Invalid request",
+			Message: err.Error(),
+		})
+	}
+
+	channel := entities.OTPChannel(req.Channel)
+	if channel != "" && !channel.IsValid() {
+		return c.Status(http.StatusBadRequest).JSON(dto.ErrorResponse{
+			Error:   "Invalid request",
+			Message: "channel must be one of sms, voice, whatsapp, email",
+		})
+	}
+
+	locales := lib.ParseAcceptLanguage(c.Get(fiber.HeaderAcceptLanguage))
+	if req.Locale != "" {
+		locales = append([]string{req.Locale}, locales...)
+	}
+
+	challenge, err := h.authService.SendTransactionOTP(c.Context(), user, &entities.OTPRequest{
+		Channel:     channel,
+		Locales:     locales,
+		Transaction: transactionPayload(req.Transaction),
+	})
+	if err != nil {
+		switch {
+		case errors.IsInvalidInput(err):
+			return c.Status(http.StatusBadRequest).JSON(dto.ErrorResponse{
+				Error:   "Invalid request",
+				Message: err.Error(),
+			})
+		case errors.IsOTPLocked(err):
+			return c.Status(http.StatusTooManyRequests).JSON(dto.ErrorResponse{
+				Error:   "otp_locked",
+				Message: err.Error(),
+			})
+		case errors.IsOTPDeliveryFailed(err):
+			h.logger.Error(c.Context(), "Failed to deliver transaction OTP", logger.F("error", err), logger.F("user_id", user.ID))
+			return c.Status(http.StatusBadGateway).JSON(dto.ErrorResponse{
+				Error:   "delivery_failed",
+				Message: "Could not deliver the OTP, please try again",
+			})
+		}
 
-	return c.Status(http.StatusOK).JSON(response)
+		h.logger.Error(c.Context(), "Failed to send transaction OTP", logger.F("error", err), logger.F("user_id", user.ID))
+		return c.Status(http.StatusInternalServerError).JSON(dto.ErrorResponse{
+			Error:   "Failed to send OTP",
+			Message: err.Error(),
+		})
+	}
+
+	return c.Status(http.StatusOK).JSON(dto.SendTransactionOTPResponse{
+		Message:        "OTP sent successfully",
+		VerificationID: challenge.ID,
+		ExpiresIn:      int(time.Until(challenge.ExpiresAt).Seconds()),
+		Channel:        string(challenge.Channel),
+		Locale:         challenge.Locale,
+		PhoneNumber:    lib.MaskPhoneNumber(challenge.PhoneNumber),
+		Summary:        challenge.Summary,
+		PayloadHash:    challenge.PayloadHash,
+		Timestamp:      c.Get("Date"),
+	})
+}
+
+// VerifyTransactionOTP verifies a transaction code against its payload
+// @Summary Verify transaction OTP
+// @Description Verify a transaction code. The payload must be the one the code was sent for; any difference fails verification and counts as a wrong attempt.
+// @Tags Authentication
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body dto.VerifyTransactionOTPRequest true "Verification ID, payload and code"
+// @Success 200 {object} dto.VerifyTransactionOTPResponse "Transaction confirmed"
+// @Failure 400 {object} dto.ErrorResponse "Invalid request or payload"
+// @Failure 401 {object} dto.ErrorResponse "Invalid or expired OTP, or a different payload"
+// @Failure 429 {object} dto.ErrorResponse "Phone number locked out"
+// @Router /api/v1/otp/transaction/verify [post]
+func (h *AuthHandler) VerifyTransactionOTP(c *fiber.Ctx) error {
+	user := c.Locals("user").(*entities.User)
+
+	var req dto.VerifyTransactionOTPRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(http.StatusBadRequest).JSON(dto.ErrorResponse{
+			Error:   "Invalid request",
+			Message: err.Error(),
+		})
+	}
+
+	if req.VerificationID == "" || req.OTP == "" {
+		return c.Status(http.StatusBadRequest).JSON(dto.ErrorResponse{
+			Error:   "Invalid request",
+			Message: "verification_id, transaction and otp are required",
+		})
+	}
+
+	challenge, err := h.authService.VerifyTransactionOTP(c.Context(), user, req.VerificationID, transactionPayload(req.Transaction), req.OTP)
+	if err != nil {
+		switch {
+		case errors.IsInvalidInput(err):
+			return c.Status(http.StatusBadRequest).JSON(dto.ErrorResponse{
+				Error:   "Invalid request",
+				Message: err.Error(),
+			})
+		case errors.IsOTPLocked(err):
+			return c.Status(http.StatusTooManyRequests).JSON(dto.ErrorResponse{
+				Error:   "otp_locked",
+				Message: err.Error(),
+			})
+		}
+
+		return c.Status(http.StatusUnauthorized).JSON(dto.ErrorResponse{
+			Error:   "Invalid OTP",
+			Message: err.Error(),
+		})
+	}
+
+	return c.Status(http.StatusOK).JSON(dto.VerifyTransactionOTPResponse{
+		Message:        "Transaction confirmed",
+		VerificationID: challenge.ID,
+		Operation:      challenge.Operation,
+		PayloadHash:    challenge.PayloadHash,
+	})
+}
+
+func transactionPayload(payload dto.TransactionPayload) *entities.TransactionPayload {
+	return &entities.TransactionPayload{
+		Operation: payload.Operation,
+		Amount:    payload.Amount,
+		Currency:  payload.Currency,
+		Recipient: payload.Recipient,
+		Reference: payload.Reference,
+	}
 }
@@ -0,0 +1,50 @@
+package dto
+
+import "time"
+
+// RegisterAPIClientRequest represents the request to register an API client
+// @Description API client to register
+type RegisterAPIClientRequest struct {
+	// @Description Client name
+	// @Example Billing Service
+	Name string `json:"name" example:"Billing Service"`
+	// @Description Scopes granted to the client: otp:send, users:read, users:admin
+	// @Example ["otp:send","users:read"]
+	Scopes []string `json:"scopes" example:"otp:send,users:read"`
+	// @Description Requests allowed per rate limit window; the default limit when omitted
+	// @Example 1000
+	RateLimit int `json:"rate_limit,omitempty" example:"1000"`
+}
+
+// APIClientResponse represents a registered API client
+// @Description API client
+type APIClientResponse struct {
+	// @Description Client ID
+	// @Example 8d1e4f2a-7c3b-4a9e-b5d6-0f1a2b3c4d5e
+	ClientID string `json:"client_id" example:"8d1e4f2a-7c3b-4a9e-b5d6-0f1a2b3c4d5e"`
+	// @Description Client secret, used as the API key and for the client credentials grant; only returned at registration
+	// @Example Zx3k9Qm1vT7bR2nW8yL4pC6hJ0dF5sA9gE1uK3oI7qM
+	ClientSecret string `json:"client_secret,omitempty" example:"Zx3k9Qm1vT7bR2nW8yL4pC6hJ0dF5sA9gE1uK3oI7qM"`
+	// @Description Client name
+	// @Example Billing Service
+	Name string `json:"name" example:"Billing Service"`
+	// @Description Scopes granted to the client
+	// @Example ["otp:send","users:read"]
+	Scopes []string `json:"scopes" example:"otp:send,users:read"`
+	// @Description Requests allowed per rate limit window; omitted for the default limit
+	// @Example 1000
+	RateLimit int `json:"rate_limit,omitempty" example:"1000"`
+	// @Description Whether the client may call the API
+	// @Example true
+	IsActive bool `json:"is_active" example:"true"`
+	// @Description Registration timestamp
+	// @Example 2024-01-01T00:00:00Z
+	CreatedAt time.Time `json:"created_at" example:"2024-01-01T00:00:00Z"`
+}
+
+// APIClientsResponse represents the list of registered API clients
+// @Description Registered API clients
+type APIClientsResponse struct {
+	// @Description Clients
+	Clients []APIClientResponse `json:"clients"`
+}
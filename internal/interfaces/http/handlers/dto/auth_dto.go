@@ -7,31 +7,54 @@ type SendOTPRequest struct {
 	// @Example +1234567890
 	// @Required
 	PhoneNumber string `json:"phone_number" binding:"required" example:"+1234567890"`
+	// @Description Preferred delivery channel; defaults to the first channel configured for the number
+	// @Example sms
+	Channel string `json:"channel,omitempty" example:"sms" enums:"sms,voice,whatsapp,email"`
+	// @Description Email address, required for the email channel
+	// @Example user@example.com
+	Email string `json:"email,omitempty" example:"user@example.com"`
+	// @Description Message locale; defaults to the Accept-Language header, then the default locale
+	// @Example fa
+	Locale string `json:"locale,omitempty" example:"fa"`
 }
 
 // VerifyOTPRequest represents the request to verify OTP
 // @Description Request to verify OTP and authenticate user
 type VerifyOTPRequest struct {
-	// @Description Phone number in international format
-	// @Example +1234567890
+	// @Description Verification ID returned by send-otp
+	// @Example 3f2b8c1e-7d4a-4e8b-9c1f-2a6d5e4b3c21
 	// @Required
-	PhoneNumber string `json:"phone_number" binding:"required" example:"+1234567890"`
-	// @Description One-time password (6 digits)
+	VerificationID string `json:"verification_id" binding:"required" example:"3f2b8c1e-7d4a-4e8b-9c1f-2a6d5e4b3c21"`
+	// @Description One-time password (6 digits); required unless a recovery code is given
 	// @Example 123456
-	// @Required
-	OTP string `json:"otp" binding:"required" example:"123456"`
+	OTP string `json:"otp,omitempty" example:"123456"`
+	// @Description Recovery code to log in with instead of the OTP, for users who lost their phone
+	// @Example 7k3mq-x9d2p
+	RecoveryCode string `json:"recovery_code,omitempty" example:"7k3mq-x9d2p"`
 	// @Description User's name (required for new user registration)
 	// @Example John Doe
 	// @Required
 	Name string `json:"name" binding:"required" example:"John Doe"`
+	// @Description Name of the device logging in, shown in the session list
+	// @Example Pixel 8
+	DeviceName string `json:"device_name,omitempty" example:"Pixel 8"`
 }
 
 // AuthResponse represents the authentication response
 // @Description Successful authentication response with token and user info
 type AuthResponse struct {
-	// @Description JWT token for API authentication
+	// @Description JWT access token for API authentication
 	// @Example eyJhbGciOiJIUzI1NiIsInR5cCI6IkpXVCJ9...
 	Token string `json:"token" example:"eyJhbGciOiJIUzI1NiIsInR5cCI6IkpXVCJ9..."`
+	// @Description Access token lifetime in seconds
+	// @Example 900
+	ExpiresIn int `json:"expires_in" example:"900"`
+	// @Description Opaque refresh token; exchange it at /api/v1/auth/refresh for a new pair
+	// @Example 8Jd3kQ0vX1mZr7YtP2cW9nB4sLhA6eGuF5iKoTqRjVw
+	RefreshToken string `json:"refresh_token" example:"8Jd3kQ0vX1mZr7YtP2cW9nB4sLhA6eGuF5iKoTqRjVw"`
+	// @Description Refresh token lifetime in seconds
+	// @Example 2592000
+	RefreshExpiresIn int `json:"refresh_expires_in" example:"2592000"`
 	// @Description User information
 	User AuthUserResponse `json:"user"`
 }
@@ -48,9 +71,12 @@ type AuthUserResponse struct {
 	// @Description User's full name
 	// @Example John Doe
 	Name string `json:"name" example:"John Doe"`
-	// @Description User's role in the system
+	// @Description Name of the user's role, which grants their permissions
 	// @Example user
-	Role string `json:"role" example:"user" enums:"user,admin"`
+	Role string `json:"role" example:"user"`
+	// @Description Whether the user must confirm a new phone number before using the API, after a recovery code login
+	// @Example false
+	PhoneConfirmationRequired bool `json:"phone_confirmation_required,omitempty" example:"false"`
 }
 
 // SendOTPResponse represents the response when OTP is sent successfully
@@ -59,6 +85,18 @@ type SendOTPResponse struct {
 	// @Description Success message
 	// @Example OTP sent successfully
 	Message string `json:"message" example:"OTP sent successfully"`
+	// @Description Verification ID to present to verify-otp together with the code
+	// @Example 3f2b8c1e-7d4a-4e8b-9c1f-2a6d5e4b3c21
+	VerificationID string `json:"verification_id" example:"3f2b8c1e-7d4a-4e8b-9c1f-2a6d5e4b3c21"`
+	// @Description Seconds until the code expires
+	// @Example 120
+	ExpiresIn int `json:"expires_in" example:"120"`
+	// @Description Channel the code was sent over
+	// @Example sms
+	Channel string `json:"channel" example:"sms"`
+	// @Description Locale the message was written in
+	// @Example en
+	Locale string `json:"locale" example:"en"`
 	// @Description Phone number where OTP was sent
 	// @Example +1234567890
 	PhoneNumber string `json:"phone_number" example:"+1234567890"`
@@ -67,16 +105,225 @@ type SendOTPResponse struct {
 	Timestamp string `json:"timestamp" example:"2024-01-15T10:30:00Z"`
 }
 
+// ResendOTPRequest represents the request to resend an OTP
+// @Description Request to resend the code of an existing OTP challenge
+type ResendOTPRequest struct {
+	// @Description Verification ID returned by send-otp
+	// @Example 3f2b8c1e-7d4a-4e8b-9c1f-2a6d5e4b3c21
+	// @Required
+	VerificationID string `json:"verification_id" binding:"required" example:"3f2b8c1e-7d4a-4e8b-9c1f-2a6d5e4b3c21"`
+}
+
+// ResendOTPResponse represents the response when OTP is resent successfully
+// @Description Response when OTP is resent successfully
+type ResendOTPResponse struct {
+	// @Description Success message
+	// @Example OTP resent successfully
+	Message string `json:"message" example:"OTP resent successfully"`
+	// @Description Verification ID of the challenge
+	// @Example 3f2b8c1e-7d4a-4e8b-9c1f-2a6d5e4b3c21
+	VerificationID string `json:"verification_id" example:"3f2b8c1e-7d4a-4e8b-9c1f-2a6d5e4b3c21"`
+	// @Description Seconds until the code expires
+	// @Example 120
+	ExpiresIn int `json:"expires_in" example:"120"`
+	// @Description Seconds until the next resend is allowed
+	// @Example 30
+	ResendIn int `json:"resend_in" example:"30"`
+	// @Description Number of resends left for this challenge
+	// @Example 2
+	ResendsRemaining int `json:"resends_remaining" example:"2"`
+	// @Description Timestamp when OTP was resent
+	// @Example 2024-01-15T10:30:00Z
+	Timestamp string `json:"timestamp" example:"2024-01-15T10:30:00Z"`
+}
+
+// OTPStatusResponse represents the current state of an OTP challenge
+// @Description Status of an OTP verification challenge
+type OTPStatusResponse struct {
+	// @Description Verification ID of the challenge
+	// @Example 3f2b8c1e-7d4a-4e8b-9c1f-2a6d5e4b3c21
+	VerificationID string `json:"verification_id" example:"3f2b8c1e-7d4a-4e8b-9c1f-2a6d5e4b3c21"`
+	// @Description Challenge status
+	// @Example pending
+	Status string `json:"status" example:"pending" enums:"pending,verified,failed,expired"`
+	// @Description What the verified challenge may be used for
+	// @Example login
+	Purpose string `json:"purpose" example:"login"`
+	// @Description Channel the code was sent over
+	// @Example sms
+	Channel string `json:"channel" example:"sms"`
+	// @Description Channels tried in order when delivery fails or is not confirmed
+	// @Example ["sms","voice"]
+	Channels []string `json:"channels" example:"sms,voice"`
+	// @Description Delivery state of the last message on the current channel
+	// @Example sent
+	DeliveryStatus string `json:"delivery_status,omitempty" example:"sent" enums:"queued,sent,delivered,failed,expired"`
+	// @Description Delivery status transitions of every message sent for the challenge, oldest first
+	Deliveries []DeliveryEventResponse `json:"deliveries"`
+	// @Description Locale of the message
+	// @Example en
+	Locale string `json:"locale" example:"en"`
+	// @Description Masked phone number the code was sent to
+	// @Example +1******7890
+	PhoneNumber string `json:"phone_number" example:"+1******7890"`
+	// @Description Whether the code can still be verified
+	// @Example true
+	Valid bool `json:"valid" example:"true"`
+	// @Description Seconds until the code expires (0 once it is no longer valid)
+	// @Example 95
+	ExpiresIn int `json:"expires_in" example:"95"`
+	// @Description Number of wrong codes submitted so far
+	// @Example 1
+	Attempts int `json:"attempts" example:"1"`
+	// @Description Number of wrong codes that may still be submitted, counting those sent to other challenges of the number
+	// @Example 4
+	AttemptsRemaining int `json:"attempts_remaining" example:"4"`
+	// @Description Seconds until the code may be resent
+	// @Example 0
+	ResendIn int `json:"resend_in" example:"0"`
+	// @Description Number of resends left for this challenge
+	// @Example 3
+	ResendsRemaining int `json:"resends_remaining" example:"3"`
+	// @Description When the challenge was created
+	// @Example 2024-01-15T10:30:00Z
+	CreatedAt string `json:"created_at" example:"2024-01-15T10:30:00Z"`
+}
+
+// RefreshTokenRequest represents the request to refresh an access token
+// @Description Request to exchange a refresh token for a new token pair
+type RefreshTokenRequest struct {
+	// @Description Refresh token from the last login or refresh
+	// @Example 8Jd3kQ0vX1mZr7YtP2cW9nB4sLhA6eGuF5iKoTqRjVw
+	// @Required
+	RefreshToken string `json:"refresh_token" binding:"required" example:"8Jd3kQ0vX1mZr7YtP2cW9nB4sLhA6eGuF5iKoTqRjVw"`
+}
+
 // RefreshTokenResponse represents the response when token is refreshed successfully
 // @Description Response when access token is refreshed successfully
 type RefreshTokenResponse struct {
 	// @Description New JWT access token
 	// @Example eyJhbGciOiJIUzI1NiIsInR5cCI6IkpXVCJ9...
 	AccessToken string `json:"access_token" example:"eyJhbGciOiJIUzI1NiIsInR5cCI6IkpXVCJ9..."`
+	// @Description New refresh token; the one presented no longer works
+	// @Example Qm5vT1xZ8aW3rE0pK7dJ2hF6cN9bL4sGyUiVoXtRjAe
+	RefreshToken string `json:"refresh_token" example:"Qm5vT1xZ8aW3rE0pK7dJ2hF6cN9bL4sGyUiVoXtRjAe"`
 	// @Description Success message
 	// @Example Token refreshed successfully
 	Message string `json:"message" example:"Token refreshed successfully"`
 	// @Description Token expiration time in seconds
-	// @Example 3600
-	ExpiresIn int `json:"expires_in" example:"3600"`
+	// @Example 900
+	ExpiresIn int `json:"expires_in" example:"900"`
+	// @Description Refresh token expiration time in seconds
+	// @Example 2592000
+	RefreshExpiresIn int `json:"refresh_expires_in" example:"2592000"`
+}
+
+// DeliveryEventResponse represents one delivery status transition of an OTP message
+// @Description Delivery status transition reported by a provider
+type DeliveryEventResponse struct {
+	// @Description Delivery provider
+	// @Example http
+	Provider string `json:"provider" example:"http"`
+	// @Description Provider's message ID
+	// @Example msg_01HF3Z6
+	MessageID string `json:"message_id,omitempty" example:"msg_01HF3Z6"`
+	// @Description Channel the message was sent over
+	// @Example sms
+	Channel string `json:"channel" example:"sms"`
+	// @Description Delivery status
+	// @Example delivered
+	Status string `json:"status" example:"delivered" enums:"queued,sent,delivered,failed,expired"`
+	// @Description Provider error code or delivery error class
+	// @Example timeout
+	ErrorCode string `json:"error_code,omitempty" example:"timeout"`
+	// @Description When the status was reached
+	// @Example 2024-01-15T10:30:04Z
+	At string `json:"at" example:"2024-01-15T10:30:04Z"`
+}
+
+// SendConfirmationOTPRequest represents the request to confirm a sensitive action by OTP
+// @Description Request to send a confirmation code to the authenticated user's phone number
+type SendConfirmationOTPRequest struct {
+	// @Description Action the code confirms
+	// @Example account_deletion
+	// @Required
+	Purpose string `json:"purpose" binding:"required" example:"account_deletion" enums:"phone_change,account_deletion"`
+	// @Description Preferred delivery channel
+	// @Example sms
+	Channel string `json:"channel,omitempty" example:"sms" enums:"sms,voice,whatsapp,email"`
+	// @Description Message locale; defaults to the Accept-Language header
+	// @Example en
+	Locale string `json:"locale,omitempty" example:"en"`
+}
+
+// VerifyConfirmationOTPRequest represents the request to verify a confirmation code
+// @Description Request to verify a confirmation code for a sensitive action
+type VerifyConfirmationOTPRequest struct {
+	// @Description Verification ID returned by the send request
+	// @Example 3f2b8c1e-7d4a-4e8b-9c1f-2a6d5e4b3c21
+	// @Required
+	VerificationID string `json:"verification_id" binding:"required" example:"3f2b8c1e-7d4a-4e8b-9c1f-2a6d5e4b3c21"`
+	// @Description Action the code confirms; must match the purpose it was sent for
+	// @Example account_deletion
+	// @Required
+	Purpose string `json:"purpose" binding:"required" example:"account_deletion" enums:"phone_change,account_deletion"`
+	// @Description One-time password
+	// @Example 123456
+	// @Required
+	OTP string `json:"otp" binding:"required" example:"123456"`
+}
+
+// VerifyConfirmationOTPResponse represents the response to a verified confirmation code
+// @Description Confirmation of a verified action code
+type VerifyConfirmationOTPResponse struct {
+	// @Description Success message
+	// @Example OTP verified successfully
+	Message string `json:"message" example:"OTP verified successfully"`
+	// @Description Verification ID of the verified challenge
+	// @Example 3f2b8c1e-7d4a-4e8b-9c1f-2a6d5e4b3c21
+	VerificationID string `json:"verification_id" example:"3f2b8c1e-7d4a-4e8b-9c1f-2a6d5e4b3c21"`
+	// @Description Action the code confirmed
+	// @Example account_deletion
+	Purpose string `json:"purpose" example:"account_deletion"`
+}
+
+// StepUpRequest represents the request to send a step-up code
+// @Description Request to send a code for authenticating again
+type StepUpRequest struct {
+	// @Description Preferred delivery channel
+	// @Example sms
+	Channel string `json:"channel,omitempty" example:"sms" enums:"sms,voice,whatsapp,email"`
+	// @Description Message locale; defaults to the Accept-Language header
+	// @Example en
+	Locale string `json:"locale,omitempty" example:"en"`
+}
+
+// VerifyStepUpRequest represents the request to verify a step-up code
+// @Description Request to verify a step-up code
+type VerifyStepUpRequest struct {
+	// @Description Verification ID returned by the send request
+	// @Example 3f2b8c1e-7d4a-4e8b-9c1f-2a6d5e4b3c21
+	// @Required
+	VerificationID string `json:"verification_id" binding:"required" example:"3f2b8c1e-7d4a-4e8b-9c1f-2a6d5e4b3c21"`
+	// @Description One-time password
+	// @Example 123456
+	// @Required
+	OTP string `json:"otp" binding:"required" example:"123456"`
+}
+
+// StepUpResponse represents the response to a verified step-up code
+// @Description Elevated access token for the current session
+type StepUpResponse struct {
+	// @Description Success message
+	// @Example Authentication confirmed
+	Message string `json:"message" example:"Authentication confirmed"`
+	// @Description Elevated JWT access token; use it for sensitive actions until it expires, then go on with the session's regular tokens
+	// @Example eyJhbGciOiJIUzI1NiIsInR5cCI6IkpXVCJ9...
+	Token string `json:"token" example:"eyJhbGciOiJIUzI1NiIsInR5cCI6IkpXVCJ9..."`
+	// @Description Elevated token lifetime in seconds
+	// @Example 300
+	ExpiresIn int `json:"expires_in" example:"300"`
+	// @Description Authentication time as Unix time, carried in the auth_time claim
+	// @Example 1705313700
+	AuthTime int64 `json:"auth_time" example:"1705313700"`
 }
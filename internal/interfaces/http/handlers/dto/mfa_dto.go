@@ -0,0 +1,103 @@
+package dto
+
+import "time"
+
+// SecondFactorRequiredResponse represents a login that needs a second factor
+// @Description Returned by verify-otp instead of a token when the user has a second factor enrolled
+type SecondFactorRequiredResponse struct {
+	// @Description Always true
+	// @Example true
+	SecondFactorRequired bool `json:"second_factor_required" example:"true"`
+	// @Description Token to present to verify-totp together with the code
+	// @Example 5f0c1e9a7b3d4c2e8f6a1b0d9c7e5f3a2b1c0d9e8f7a6b5c4d3e2f1a0b9c8d7e
+	MFAToken string `json:"mfa_token" example:"5f0c1e9a7b3d4c2e8f6a1b0d9c7e5f3a2b1c0d9e8f7a6b5c4d3e2f1a0b9c8d7e"`
+	// @Description Seconds until the MFA token expires
+	// @Example 300
+	ExpiresIn int `json:"expires_in" example:"300"`
+	// @Description Factor types the user can complete the login with
+	// @Example ["totp"]
+	Factors []string `json:"factors" example:"totp"`
+}
+
+// VerifyTOTPRequest represents the second step of a login
+// @Description Request to complete a login with an authenticator app code
+type VerifyTOTPRequest struct {
+	// @Description MFA token returned by verify-otp
+	// @Example 5f0c1e9a7b3d4c2e8f6a1b0d9c7e5f3a2b1c0d9e8f7a6b5c4d3e2f1a0b9c8d7e
+	// @Required
+	MFAToken string `json:"mfa_token" binding:"required" example:"5f0c1e9a7b3d4c2e8f6a1b0d9c7e5f3a2b1c0d9e8f7a6b5c4d3e2f1a0b9c8d7e"`
+	// @Description Current code from the authenticator app
+	// @Example 287082
+	// @Required
+	Code string `json:"code" binding:"required" example:"287082"`
+	// @Description Name of the device logging in, shown in the session list
+	// @Example Pixel 8
+	DeviceName string `json:"device_name,omitempty" example:"Pixel 8"`
+}
+
+// StartTOTPEnrollmentRequest represents the request to enroll an authenticator app
+// @Description Request to start enrolling an authenticator app
+type StartTOTPEnrollmentRequest struct {
+	// @Description Label for the factor, e.g. the device name
+	// @Example Work phone
+	Name string `json:"name,omitempty" example:"Work phone"`
+}
+
+// TOTPEnrollmentResponse represents a started enrollment
+// @Description Secret and key URI for the authenticator app; the secret is never shown again
+type TOTPEnrollmentResponse struct {
+	// @Description ID of the new, unconfirmed factor
+	// @Example 1
+	FactorID int `json:"factor_id" example:"1"`
+	// @Description Factor label
+	// @Example Work phone
+	Name string `json:"name" example:"Work phone"`
+	// @Description Base32 secret for manual entry
+	// @Example JBSWY3DPEHPK3PXPJBSWY3DPEHPK3PXP
+	Secret string `json:"secret" example:"JBSWY3DPEHPK3PXPJBSWY3DPEHPK3PXP"`
+	// @Description otpauth:// key URI, usually shown as a QR code
+	// @Example otpauth://totp/OTP%20Server:+1234567890?algorithm=SHA1&digits=6&issuer=OTP+Server&period=30&secret=JBSWY3DPEHPK3PXPJBSWY3DPEHPK3PXP
+	URI string `json:"otpauth_uri" example:"otpauth://totp/OTP%20Server:+1234567890?algorithm=SHA1&digits=6&issuer=OTP+Server&period=30&secret=JBSWY3DPEHPK3PXPJBSWY3DPEHPK3PXP"`
+	// @Description Seconds left to confirm the enrollment
+	// @Example 600
+	ExpiresIn int `json:"expires_in" example:"600"`
+}
+
+// ConfirmTOTPEnrollmentRequest represents the request to confirm an enrollment
+// @Description Request to confirm an enrollment with the first code from the authenticator app
+type ConfirmTOTPEnrollmentRequest struct {
+	// @Description Current code from the authenticator app
+	// @Example 287082
+	// @Required
+	Code string `json:"code" binding:"required" example:"287082"`
+}
+
+// FactorResponse represents an enrolled second factor
+// @Description Second factor enrolled by the user
+type FactorResponse struct {
+	// @Description Factor ID
+	// @Example 1
+	ID int `json:"id" example:"1"`
+	// @Description Factor type
+	// @Example totp
+	Type string `json:"type" example:"totp" enums:"totp"`
+	// @Description Factor label
+	// @Example Work phone
+	Name string `json:"name" example:"Work phone"`
+	// @Description Whether enrollment was confirmed; only confirmed factors are used at login
+	// @Example true
+	Confirmed bool `json:"confirmed" example:"true"`
+	// @Description When the last code was accepted
+	// @Example 2024-01-15T10:30:00Z
+	LastUsedAt *time.Time `json:"last_used_at,omitempty" example:"2024-01-15T10:30:00Z"`
+	// @Description Enrollment timestamp
+	// @Example 2024-01-01T00:00:00Z
+	CreatedAt time.Time `json:"created_at" example:"2024-01-01T00:00:00Z"`
+}
+
+// FactorsResponse represents the list of a user's second factors
+// @Description Second factors enrolled by the user
+type FactorsResponse struct {
+	// @Description Enrolled factors
+	Factors []FactorResponse `json:"factors"`
+}
@@ -0,0 +1,140 @@
+package dto
+
+import "time"
+
+// OAuthTokenResponse represents a token endpoint response (RFC 6749)
+// @Description Tokens issued to an OpenID Connect client or an API client
+type OAuthTokenResponse struct {
+	// @Description Access token
+	// @Example eyJhbGciOiJSUzI1NiIsImtpZCI6Ii4uLiJ9...
+	AccessToken string `json:"access_token" example:"eyJhbGciOiJSUzI1NiIsImtpZCI6Ii4uLiJ9..."`
+	// @Description Token type
+	// @Example Bearer
+	TokenType string `json:"token_type" example:"Bearer"`
+	// @Description Access token lifetime in seconds
+	// @Example 900
+	ExpiresIn int `json:"expires_in" example:"900"`
+	// @Description Refresh token, rotated on every use
+	// @Example 3q2-7wEjRWeJq83vASNFZ4mrze8BI0VniavN7wEjRWc
+	RefreshToken string `json:"refresh_token,omitempty" example:"3q2-7wEjRWeJq83vASNFZ4mrze8BI0VniavN7wEjRWc"`
+	// @Description ID token, only for an authorization code
+	// @Example eyJhbGciOiJSUzI1NiIsImtpZCI6Ii4uLiJ9...
+	IDToken string `json:"id_token,omitempty" example:"eyJhbGciOiJSUzI1NiIsImtpZCI6Ii4uLiJ9..."`
+	// @Description Granted scope
+	// @Example openid phone
+	Scope string `json:"scope,omitempty" example:"openid phone"`
+}
+
+// OAuthErrorResponse represents an OAuth 2.0 error response (RFC 6749)
+// @Description OAuth 2.0 error
+type OAuthErrorResponse struct {
+	// @Description Error code
+	// @Example invalid_grant
+	Error string `json:"error" example:"invalid_grant"`
+	// @Description Human-readable description
+	// @Example invalid or expired authorization code
+	ErrorDescription string `json:"error_description,omitempty" example:"invalid or expired authorization code"`
+}
+
+// IntrospectionResponse represents a token introspection response (RFC 7662)
+// @Description State of a token; only active is set for inactive tokens
+type IntrospectionResponse struct {
+	// @Description Whether the token is valid, unexpired, not revoked and its user active
+	// @Example true
+	Active bool `json:"active" example:"true"`
+	// @Description access_token or refresh_token
+	// @Example access_token
+	TokenType string `json:"token_type,omitempty" example:"access_token"`
+	// @Description User ID, or the client ID for tokens of API clients
+	// @Example 1
+	Subject string `json:"sub,omitempty" example:"1"`
+	// @Description Tenant of the user or API client
+	// @Example 1
+	TenantID int `json:"tenant_id,omitempty" example:"1"`
+	// @Description Client the token was issued to; empty for API logins
+	// @Example 5f0c8a52-6f4e-4c3b-9d7a-1e2b3c4d5e6f
+	ClientID string `json:"client_id,omitempty" example:"5f0c8a52-6f4e-4c3b-9d7a-1e2b3c4d5e6f"`
+	// @Description Granted scope; empty for API logins
+	// @Example openid phone
+	Scope string `json:"scope,omitempty" example:"openid phone"`
+	// @Description Expiry as Unix time
+	// @Example 1705314600
+	ExpiresAt int64 `json:"exp,omitempty" example:"1705314600"`
+	// @Description Issue time as Unix time
+	// @Example 1705313700
+	IssuedAt int64 `json:"iat,omitempty" example:"1705313700"`
+	// @Description Token ID, for access tokens
+	// @Example 0f8e7d6c-5b4a-4392-8170-6f5e4d3c2b1a
+	TokenID string `json:"jti,omitempty" example:"0f8e7d6c-5b4a-4392-8170-6f5e4d3c2b1a"`
+	// @Description Session ID
+	// @Example 9b2f4c1e-3a7d-4e8b-8c1f-2a6d5e4b3c21
+	SessionID string `json:"sid,omitempty" example:"9b2f4c1e-3a7d-4e8b-8c1f-2a6d5e4b3c21"`
+	// @Description When the user last authenticated, as Unix time
+	// @Example 1705313690
+	AuthTime int64 `json:"auth_time,omitempty" example:"1705313690"`
+	// @Description Authentication context class: 1 for one factor, 2 for multi-factor
+	// @Example 1
+	ACR string `json:"acr,omitempty" example:"1"`
+	// @Description Authentication methods: otp, totp, recovery, mfa
+	// @Example ["otp"]
+	AuthMethods []string `json:"amr,omitempty" example:"otp"`
+	// @Description Current role of the user
+	// @Example user
+	Role string `json:"role,omitempty" example:"user"`
+	// @Description Current phone number of the user
+	// @Example +1234567890
+	PhoneNumber string `json:"phone_number,omitempty" example:"+1234567890"`
+}
+
+// RegisterOAuthClientRequest represents the request to register a client
+// @Description OpenID Connect client to register
+type RegisterOAuthClientRequest struct {
+	// @Description Client name, shown on the login page
+	// @Example Example App
+	Name string `json:"name" example:"Example App"`
+	// @Description Redirect URIs; authorization requests must use one of them exactly
+	// @Example ["https://app.example.com/callback"]
+	RedirectURIs []string `json:"redirect_uris" example:"https://app.example.com/callback"`
+	// @Description Scopes the client may request; all supported scopes by default
+	// @Example ["openid","phone"]
+	Scopes []string `json:"scopes,omitempty" example:"openid,phone"`
+	// @Description Whether the client cannot keep a secret, like a single-page or mobile app
+	// @Example false
+	Public bool `json:"public" example:"false"`
+}
+
+// OAuthClientResponse represents a registered client
+// @Description OpenID Connect client
+type OAuthClientResponse struct {
+	// @Description Client ID
+	// @Example 5f0c8a52-6f4e-4c3b-9d7a-1e2b3c4d5e6f
+	ClientID string `json:"client_id" example:"5f0c8a52-6f4e-4c3b-9d7a-1e2b3c4d5e6f"`
+	// @Description Client secret, only returned at registration
+	// @Example Zx3k9Qm1vT7bR2nW8yL4pC6hJ0dF5sA9gE1uK3oI7qM
+	ClientSecret string `json:"client_secret,omitempty" example:"Zx3k9Qm1vT7bR2nW8yL4pC6hJ0dF5sA9gE1uK3oI7qM"`
+	// @Description Client name
+	// @Example Example App
+	Name string `json:"name" example:"Example App"`
+	// @Description Registered redirect URIs
+	// @Example ["https://app.example.com/callback"]
+	RedirectURIs []string `json:"redirect_uris" example:"https://app.example.com/callback"`
+	// @Description Scopes the client may request
+	// @Example ["openid","phone","profile"]
+	Scopes []string `json:"scopes" example:"openid,phone,profile"`
+	// @Description Whether the client has no secret
+	// @Example false
+	Public bool `json:"public" example:"false"`
+	// @Description Whether the client may log users in
+	// @Example true
+	IsActive bool `json:"is_active" example:"true"`
+	// @Description Registration timestamp
+	// @Example 2024-01-01T00:00:00Z
+	CreatedAt time.Time `json:"created_at" example:"2024-01-01T00:00:00Z"`
+}
+
+// OAuthClientsResponse represents the list of registered clients
+// @Description Registered OpenID Connect clients
+type OAuthClientsResponse struct {
+	// @Description Clients
+	Clients []OAuthClientResponse `json:"clients"`
+}
@@ -0,0 +1,64 @@
+package dto
+
+// StartPhoneChangeRequest represents the request to change the phone number
+// @Description Request to send codes confirming a change of the user's phone number
+type StartPhoneChangeRequest struct {
+	// @Description New phone number in international format
+	// @Example +1987654321
+	// @Required
+	PhoneNumber string `json:"phone_number" binding:"required" example:"+1987654321"`
+	// @Description Preferred delivery channel for the new number
+	// @Example sms
+	Channel string `json:"channel,omitempty" example:"sms" enums:"sms,voice,whatsapp,email"`
+	// @Description Message locale; defaults to the Accept-Language header
+	// @Example en
+	Locale string `json:"locale,omitempty" example:"en"`
+}
+
+// StartPhoneChangeResponse represents the codes sent to change the phone number
+// @Description Verification IDs of the codes sent to the new and, when required, the current phone number
+type StartPhoneChangeResponse struct {
+	// @Description Success message
+	// @Example OTP sent successfully
+	Message string `json:"message" example:"OTP sent successfully"`
+	// @Description Verification ID of the code sent to the new number
+	// @Example 3f2b8c1e-7d4a-4e8b-9c1f-2a6d5e4b3c21
+	VerificationID string `json:"verification_id" example:"3f2b8c1e-7d4a-4e8b-9c1f-2a6d5e4b3c21"`
+	// @Description Seconds until the code sent to the new number expires
+	// @Example 120
+	ExpiresIn int `json:"expires_in" example:"120"`
+	// @Description Channel the code was sent to the new number over
+	// @Example sms
+	Channel string `json:"channel" example:"sms"`
+	// @Description New phone number, masked
+	// @Example +1******4321
+	PhoneNumber string `json:"phone_number" example:"+1******4321"`
+	// @Description Verification ID of the code sent to the current number; omitted when the tenant does not require it
+	// @Example 9a4c2e6b-1f3d-4b8a-8e2c-7d5f1a3b9c64
+	OldVerificationID string `json:"old_verification_id,omitempty" example:"9a4c2e6b-1f3d-4b8a-8e2c-7d5f1a3b9c64"`
+	// @Description Seconds until the code sent to the current number expires
+	// @Example 120
+	OldExpiresIn int `json:"old_expires_in,omitempty" example:"120"`
+	// @Description Timestamp when the codes were sent
+	// @Example 2024-01-15T10:30:00Z
+	Timestamp string `json:"timestamp" example:"2024-01-15T10:30:00Z"`
+}
+
+// ChangePhoneNumberRequest represents the request to complete a phone number change
+// @Description Request to change the phone number with the codes sent to the new and current numbers
+type ChangePhoneNumberRequest struct {
+	// @Description Verification ID of the code sent to the new number
+	// @Example 3f2b8c1e-7d4a-4e8b-9c1f-2a6d5e4b3c21
+	// @Required
+	VerificationID string `json:"verification_id" binding:"required" example:"3f2b8c1e-7d4a-4e8b-9c1f-2a6d5e4b3c21"`
+	// @Description One-time password sent to the new number
+	// @Example 123456
+	// @Required
+	OTP string `json:"otp" binding:"required" example:"123456"`
+	// @Description Verification ID of the code sent to the current number, when one was sent
+	// @Example 9a4c2e6b-1f3d-4b8a-8e2c-7d5f1a3b9c64
+	OldVerificationID string `json:"old_verification_id,omitempty" example:"9a4c2e6b-1f3d-4b8a-8e2c-7d5f1a3b9c64"`
+	// @Description One-time password sent to the current number, when one was sent
+	// @Example 654321
+	OldOTP string `json:"old_otp,omitempty" example:"654321"`
+}
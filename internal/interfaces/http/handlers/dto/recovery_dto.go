@@ -0,0 +1,48 @@
+package dto
+
+// RecoveryCodesResponse represents a newly generated set of recovery codes
+// @Description New recovery codes; they are never shown again and the previous set no longer works
+type RecoveryCodesResponse struct {
+	// @Description Single-use recovery codes
+	// @Example ["7k3mq-x9d2p","c4hv8-2nrbt"]
+	Codes []string `json:"codes" example:"7k3mq-x9d2p,c4hv8-2nrbt"`
+	// @Description Number of codes in the set
+	// @Example 10
+	Remaining int `json:"remaining" example:"10"`
+}
+
+// RecoveryCodesStatusResponse represents how many recovery codes are left
+// @Description Number of unused recovery codes
+type RecoveryCodesStatusResponse struct {
+	// @Description Unused recovery codes left
+	// @Example 9
+	Remaining int `json:"remaining" example:"9"`
+}
+
+// SendPhoneConfirmationRequest represents the request to confirm a new phone number
+// @Description Request to send a code to the phone number to confirm after a recovery code login
+type SendPhoneConfirmationRequest struct {
+	// @Description Phone number to confirm in international format
+	// @Example +1234567890
+	// @Required
+	PhoneNumber string `json:"phone_number" binding:"required" example:"+1234567890"`
+	// @Description Preferred delivery channel
+	// @Example sms
+	Channel string `json:"channel,omitempty" example:"sms" enums:"sms,voice,whatsapp,email"`
+	// @Description Message locale; defaults to the Accept-Language header
+	// @Example en
+	Locale string `json:"locale,omitempty" example:"en"`
+}
+
+// ConfirmPhoneNumberRequest represents the request to confirm a new phone number
+// @Description Request to confirm a new phone number with the code sent to it
+type ConfirmPhoneNumberRequest struct {
+	// @Description Verification ID returned by the send request
+	// @Example 3f2b8c1e-7d4a-4e8b-9c1f-2a6d5e4b3c21
+	// @Required
+	VerificationID string `json:"verification_id" binding:"required" example:"3f2b8c1e-7d4a-4e8b-9c1f-2a6d5e4b3c21"`
+	// @Description One-time password sent to the new number
+	// @Example 123456
+	// @Required
+	OTP string `json:"otp" binding:"required" example:"123456"`
+}
@@ -0,0 +1,62 @@
+package dto
+
+import "time"
+
+// RoleRequest represents the request to create or update a role
+// @Description Role to create or update
+type RoleRequest struct {
+	// @Description Role description
+	// @Example Support staff viewing users
+	Description string `json:"description" example:"Support staff viewing users"`
+	// @Description Permissions granted by the role, replacing those it had
+	// @Example ["users:read"]
+	Permissions []string `json:"permissions" example:"users:read"`
+}
+
+// RoleResponse represents a role
+// @Description Role
+type RoleResponse struct {
+	// @Description Role ID
+	// @Example 3
+	ID int `json:"id" example:"3"`
+	// @Description Role name, as held by users
+	// @Example support
+	Name string `json:"name" example:"support"`
+	// @Description Role description
+	// @Example Support staff viewing users
+	Description string `json:"description" example:"Support staff viewing users"`
+	// @Description Permissions granted by the role
+	// @Example ["users:read"]
+	Permissions []string `json:"permissions" example:"users:read"`
+	// @Description Creation timestamp
+	// @Example 2024-01-01T00:00:00Z
+	CreatedAt time.Time `json:"created_at" example:"2024-01-01T00:00:00Z"`
+	// @Description Last update timestamp
+	// @Example 2024-01-01T00:00:00Z
+	UpdatedAt time.Time `json:"updated_at" example:"2024-01-01T00:00:00Z"`
+}
+
+// RolesResponse represents the list of roles
+// @Description Roles
+type RolesResponse struct {
+	// @Description Roles
+	Roles []RoleResponse `json:"roles"`
+}
+
+// PermissionResponse represents a permission
+// @Description Permission
+type PermissionResponse struct {
+	// @Description Permission name
+	// @Example users:read
+	Name string `json:"name" example:"users:read"`
+	// @Description What the permission allows
+	// @Example List, view and search users
+	Description string `json:"description" example:"List, view and search users"`
+}
+
+// PermissionsResponse represents the list of permissions
+// @Description Permissions
+type PermissionsResponse struct {
+	// @Description Permissions
+	Permissions []PermissionResponse `json:"permissions"`
+}
@@ -0,0 +1,42 @@
+package dto
+
+import "time"
+
+// SessionResponse represents a session of the user
+// @Description Login of the user on one device
+type SessionResponse struct {
+	// @Description Session ID
+	// @Example 9b2f4c1e-3a7d-4e8b-8c1f-2a6d5e4b3c21
+	ID string `json:"id" example:"9b2f4c1e-3a7d-4e8b-8c1f-2a6d5e4b3c21"`
+	// @Description Device name given at login
+	// @Example Pixel 8
+	DeviceName string `json:"device_name,omitempty" example:"Pixel 8"`
+	// @Description User-Agent of the login request
+	// @Example Mozilla/5.0 (Linux; Android 14; Pixel 8)
+	UserAgent string `json:"user_agent,omitempty" example:"Mozilla/5.0 (Linux; Android 14; Pixel 8)"`
+	// @Description Client IP of the login request
+	// @Example 203.0.113.7
+	IPAddress string `json:"ip_address,omitempty" example:"203.0.113.7"`
+	// @Description OpenID Connect client the user logged in to, if any
+	// @Example 5f0c8a52-6f4e-4c3b-9d7a-1e2b3c4d5e6f
+	ClientID string `json:"client_id,omitempty" example:"5f0c8a52-6f4e-4c3b-9d7a-1e2b3c4d5e6f"`
+	// @Description Whether this is the session of the request
+	// @Example true
+	Current bool `json:"current" example:"true"`
+	// @Description Login timestamp
+	// @Example 2024-01-01T00:00:00Z
+	CreatedAt time.Time `json:"created_at" example:"2024-01-01T00:00:00Z"`
+	// @Description When the session last refreshed its tokens
+	// @Example 2024-01-15T10:30:00Z
+	LastUsedAt time.Time `json:"last_used_at" example:"2024-01-15T10:30:00Z"`
+	// @Description When the session ends unless it refreshes its tokens
+	// @Example 2024-02-14T10:30:00Z
+	ExpiresAt time.Time `json:"expires_at" example:"2024-02-14T10:30:00Z"`
+}
+
+// SessionsResponse represents the list of a user's sessions
+// @Description Active sessions of the user
+type SessionsResponse struct {
+	// @Description Sessions, newest first
+	Sessions []SessionResponse `json:"sessions"`
+}
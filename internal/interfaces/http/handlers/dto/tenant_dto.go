@@ -0,0 +1,57 @@
+package dto
+
+import "time"
+
+// TenantRequest represents the request to create or update a tenant
+// @Description Tenant to create or update
+type TenantRequest struct {
+	// @Description Tenant identifier sent in the X-Tenant header: lowercase letters, digits and dashes; cannot be changed
+	// @Example acme
+	Slug string `json:"slug,omitempty" example:"acme"`
+	// @Description Tenant name
+	// @Example Acme
+	Name string `json:"name" example:"Acme"`
+	// @Description Request hosts resolved to the tenant
+	// @Example ["login.acme.com"]
+	Hosts []string `json:"hosts" example:"login.acme.com"`
+	// @Description Overrides of the OTP_*, RATE_LIMIT_* and message template settings, keyed by environment variable name
+	Settings map[string]string `json:"settings"`
+	// @Description Whether the tenant serves requests; defaults to true on creation
+	// @Example true
+	IsActive *bool `json:"is_active,omitempty" example:"true"`
+}
+
+// TenantResponse represents a tenant
+// @Description Tenant
+type TenantResponse struct {
+	// @Description Tenant ID
+	// @Example 2
+	ID int `json:"id" example:"2"`
+	// @Description Tenant identifier sent in the X-Tenant header
+	// @Example acme
+	Slug string `json:"slug" example:"acme"`
+	// @Description Tenant name
+	// @Example Acme
+	Name string `json:"name" example:"Acme"`
+	// @Description Request hosts resolved to the tenant
+	// @Example ["login.acme.com"]
+	Hosts []string `json:"hosts" example:"login.acme.com"`
+	// @Description Overrides of the server settings, keyed by environment variable name
+	Settings map[string]string `json:"settings"`
+	// @Description Whether the tenant serves requests
+	// @Example true
+	IsActive bool `json:"is_active" example:"true"`
+	// @Description Creation timestamp
+	// @Example 2024-01-01T00:00:00Z
+	CreatedAt time.Time `json:"created_at" example:"2024-01-01T00:00:00Z"`
+	// @Description Last update timestamp
+	// @Example 2024-01-01T00:00:00Z
+	UpdatedAt time.Time `json:"updated_at" example:"2024-01-01T00:00:00Z"`
+}
+
+// TenantsResponse represents the list of tenants
+// @Description Tenants
+type TenantsResponse struct {
+	// @Description Tenants
+	Tenants []TenantResponse `json:"tenants"`
+}
@@ -0,0 +1,104 @@
+package dto
+
+// TransactionPayload represents the operation a transaction OTP signs
+// @Description Operation a transaction code signs; the same payload must be presented to verify the code
+type TransactionPayload struct {
+	// @Description Operation type
+	// @Example transfer
+	// @Required
+	Operation string `json:"operation" binding:"required" example:"transfer"`
+	// @Description Amount as a decimal string
+	// @Example 100.50
+	// @Required
+	Amount string `json:"amount" binding:"required" example:"100.50"`
+	// @Description ISO 4217 currency code
+	// @Example EUR
+	// @Required
+	Currency string `json:"currency" binding:"required" example:"EUR"`
+	// @Description Payee, e.g. an IBAN or account name
+	// @Example DE89370400440532013000
+	// @Required
+	Recipient string `json:"recipient" binding:"required" example:"DE89370400440532013000"`
+	// @Description Optional payment reference
+	// @Example INV-2024-001
+	Reference string `json:"reference,omitempty" example:"INV-2024-001"`
+}
+
+// SendTransactionOTPRequest represents the request to sign a transaction by OTP
+// @Description Request to send a code signing a transaction to the authenticated user's phone number
+type SendTransactionOTPRequest struct {
+	// @Description Operation to sign
+	// @Required
+	Transaction TransactionPayload `json:"transaction" binding:"required"`
+	// @Description Preferred delivery channel
+	// @Example sms
+	Channel string `json:"channel,omitempty" example:"sms" enums:"sms,voice,whatsapp,email"`
+	// @Description Message locale; defaults to the Accept-Language header
+	// @Example en
+	Locale string `json:"locale,omitempty" example:"en"`
+}
+
+// SendTransactionOTPResponse represents the response when a transaction code is sent
+// @Description Response when a transaction code is sent
+type SendTransactionOTPResponse struct {
+	// @Description Success message
+	// @Example OTP sent successfully
+	Message string `json:"message" example:"OTP sent successfully"`
+	// @Description Verification ID to present with the code and the same payload
+	// @Example 3f2b8c1e-7d4a-4e8b-9c1f-2a6d5e4b3c21
+	VerificationID string `json:"verification_id" example:"3f2b8c1e-7d4a-4e8b-9c1f-2a6d5e4b3c21"`
+	// @Description Seconds until the code expires
+	// @Example 300
+	ExpiresIn int `json:"expires_in" example:"300"`
+	// @Description Channel the code was sent over
+	// @Example sms
+	Channel string `json:"channel" example:"sms"`
+	// @Description Locale the message was written in
+	// @Example en
+	Locale string `json:"locale" example:"en"`
+	// @Description Phone number where OTP was sent
+	// @Example +1234567890
+	PhoneNumber string `json:"phone_number" example:"+1234567890"`
+	// @Description Summary of the operation shown in the message
+	// @Example transfer 100.5 EUR to DE89370400440532013000 (ref INV-2024-001)
+	Summary string `json:"summary" example:"transfer 100.5 EUR to DE89370400440532013000 (ref INV-2024-001)"`
+	// @Description SHA-256 of the canonical payload the code is bound to
+	// @Example 9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08
+	PayloadHash string `json:"payload_hash" example:"9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08"`
+	// @Description Timestamp when OTP was sent
+	// @Example 2024-01-15T10:30:00Z
+	Timestamp string `json:"timestamp" example:"2024-01-15T10:30:00Z"`
+}
+
+// VerifyTransactionOTPRequest represents the request to verify a transaction code
+// @Description Request to verify a transaction code against the payload it signs
+type VerifyTransactionOTPRequest struct {
+	// @Description Verification ID returned by the send request
+	// @Example 3f2b8c1e-7d4a-4e8b-9c1f-2a6d5e4b3c21
+	// @Required
+	VerificationID string `json:"verification_id" binding:"required" example:"3f2b8c1e-7d4a-4e8b-9c1f-2a6d5e4b3c21"`
+	// @Description Operation being executed; must match the signed payload
+	// @Required
+	Transaction TransactionPayload `json:"transaction" binding:"required"`
+	// @Description One-time password
+	// @Example 123456
+	// @Required
+	OTP string `json:"otp" binding:"required" example:"123456"`
+}
+
+// VerifyTransactionOTPResponse represents the response to a verified transaction code
+// @Description Confirmation that the transaction was signed
+type VerifyTransactionOTPResponse struct {
+	// @Description Success message
+	// @Example Transaction confirmed
+	Message string `json:"message" example:"Transaction confirmed"`
+	// @Description Verification ID of the verified challenge
+	// @Example 3f2b8c1e-7d4a-4e8b-9c1f-2a6d5e4b3c21
+	VerificationID string `json:"verification_id" example:"3f2b8c1e-7d4a-4e8b-9c1f-2a6d5e4b3c21"`
+	// @Description Operation that was signed
+	// @Example transfer
+	Operation string `json:"operation" example:"transfer"`
+	// @Description SHA-256 of the canonical payload that was signed
+	// @Example 9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08
+	PayloadHash string `json:"payload_hash" example:"9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08"`
+}
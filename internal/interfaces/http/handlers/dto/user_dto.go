@@ -26,9 +26,18 @@ type UserResponse struct {
 	// @Description User's role in the system
 	// @Example user
 	Role string `json:"role" example:"user"`
-	// @Description Whether the user account is active
+	// @Description Whether the user can log in, i.e. the status is active
 	// @Example true
 	IsActive bool `json:"is_active" example:"true"`
+	// @Description Account status; a suspension whose time has passed shows as active
+	// @Example active
+	Status string `json:"status" example:"active" enums:"active,suspended,banned,pending_deletion,deactivated"`
+	// @Description Why the status was set; admin API only
+	// @Example Chargeback fraud
+	StatusReason string `json:"status_reason,omitempty" example:"Chargeback fraud"`
+	// @Description When the suspension lifts; admin API only
+	// @Example 2024-02-01T00:00:00Z
+	StatusUntil *time.Time `json:"status_until,omitempty" example:"2024-02-01T00:00:00Z"`
 	// @Description Account creation timestamp
 	// @Example 2024-01-01T00:00:00Z
 	CreatedAt time.Time `json:"created_at" example:"2024-01-01T00:00:00Z"`
@@ -83,3 +92,57 @@ type UnifiedUsersResponse struct {
 		Limit  int `json:"limit"`
 	} `json:"page"`
 }
+
+// ChangeRoleRequest represents the request to change a user's role
+// @Description Role to give the user
+type ChangeRoleRequest struct {
+	// @Description Name of the new role, e.g. user, admin, support or auditor
+	// @Example admin
+	// @Required
+	Role string `json:"role" binding:"required" example:"admin"`
+}
+
+// SetUserStatusRequest represents the request to change a user's status
+// @Description Status to give the user
+type SetUserStatusRequest struct {
+	// @Description New status
+	// @Example suspended
+	// @Required
+	Status string `json:"status" binding:"required" example:"suspended" enums:"active,suspended,banned,pending_deletion,deactivated"`
+	// @Description Why the status is set, up to 500 characters
+	// @Example Repeated spam reports
+	Reason string `json:"reason,omitempty" example:"Repeated spam reports"`
+	// @Description When the suspension lifts; required for, and only allowed with, the suspended status
+	// @Example 2024-02-01T00:00:00Z
+	Until *time.Time `json:"until,omitempty" example:"2024-02-01T00:00:00Z"`
+}
+
+// UserStatusChangeResponse represents an entry of the status audit trail
+// @Description Status change
+type UserStatusChangeResponse struct {
+	// @Description Status before the change
+	// @Example active
+	PreviousStatus string `json:"previous_status" example:"active"`
+	// @Description Status after the change
+	// @Example suspended
+	Status string `json:"status" example:"suspended"`
+	// @Description Why the status was set
+	// @Example Repeated spam reports
+	Reason string `json:"reason" example:"Repeated spam reports"`
+	// @Description When the suspension lifts
+	// @Example 2024-02-01T00:00:00Z
+	Until *time.Time `json:"until,omitempty" example:"2024-02-01T00:00:00Z"`
+	// @Description Who changed the status: user:<id> or api_client:<client_id>
+	// @Example user:1
+	Actor string `json:"actor" example:"user:1"`
+	// @Description When the status changed
+	// @Example 2024-01-15T10:30:00Z
+	CreatedAt time.Time `json:"created_at" example:"2024-01-15T10:30:00Z"`
+}
+
+// UserStatusHistoryResponse represents the status audit trail of a user
+// @Description Status changes, newest first
+type UserStatusHistoryResponse struct {
+	// @Description Status changes, newest first
+	Changes []UserStatusChangeResponse `json:"changes"`
+}
@@ -0,0 +1,12 @@
+package dto
+
+// DeliveryReceiptResponse represents the response to a delivery receipt webhook
+// @Description Outcome of a delivery receipt request
+type DeliveryReceiptResponse struct {
+	// @Description Receipts applied to a message
+	// @Example 1
+	Processed int `json:"processed" example:"1"`
+	// @Description Receipts for unknown messages, duplicates and out-of-order receipts
+	// @Example 0
+	Ignored int `json:"ignored" example:"0"`
+}
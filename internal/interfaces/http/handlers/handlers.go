@@ -6,16 +6,40 @@ import (
 )
 
 type Handlers struct {
-	AuthHandler *AuthHandler
-	UserHandler *UserHandler
-	logger      logger.Logger
+	AuthHandler        *AuthHandler
+	UserHandler        *UserHandler
+	MFAHandler         *MFAHandler
+	RecoveryHandler    *RecoveryHandler
+	PhoneHandler       *PhoneHandler
+	SessionHandler     *SessionHandler
+	AdminHandler       *AdminHandler
+	OIDCHandler        *OIDCHandler
+	OAuthClientHandler *OAuthClientHandler
+	APIClientHandler   *APIClientHandler
+	TenantHandler      *TenantHandler
+	RoleHandler        *RoleHandler
+	WebhookHandler     *WebhookHandler
+	WellKnownHandler   *WellKnownHandler
+	logger             logger.Logger
 }
 
 func NewHandlers(services *application.Services, logger logger.Logger) *Handlers {
 	return &Handlers{
-		AuthHandler: NewAuthHandler(services.AuthService, logger),
-		UserHandler: NewUserHandler(services.UserService, logger),
-		logger:      logger,
+		AuthHandler:        NewAuthHandler(services.AuthService, logger),
+		UserHandler:        NewUserHandler(services.UserService, logger),
+		MFAHandler:         NewMFAHandler(services.MFAService, logger),
+		RecoveryHandler:    NewRecoveryHandler(services.AuthService, services.RecoveryService, logger),
+		PhoneHandler:       NewPhoneHandler(services.AuthService, logger),
+		SessionHandler:     NewSessionHandler(services.SessionService, logger),
+		AdminHandler:       NewAdminHandler(services.UserService, logger),
+		OIDCHandler:        NewOIDCHandler(services.OIDCService, logger),
+		OAuthClientHandler: NewOAuthClientHandler(services.OIDCService, logger),
+		APIClientHandler:   NewAPIClientHandler(services.APIClientService, logger),
+		TenantHandler:      NewTenantHandler(services.TenantService, logger),
+		RoleHandler:        NewRoleHandler(services.RoleService, logger),
+		WebhookHandler:     NewWebhookHandler(services.ReceiptService, logger),
+		WellKnownHandler:   NewWellKnownHandler(services.Keyring, logger),
+		logger:             logger,
 	}
 }
 
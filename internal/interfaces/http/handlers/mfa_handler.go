@@ -0,0 +1,220 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"otp-server/internal/application"
+	"otp-server/internal/domain/entities"
+	"otp-server/internal/domain/errors"
+	"otp-server/internal/infrastructure/logger"
+	"otp-server/internal/interfaces/http/handlers/dto"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// MFAHandler handles second factor enrollment requests
+type MFAHandler struct {
+	mfaService application.MFAServiceInterface
+	logger     logger.Logger
+}
+
+// NewMFAHandler creates a new MFA handler
+func NewMFAHandler(mfaService application.MFAServiceInterface, logger logger.Logger) *MFAHandler {
+	return &MFAHandler{
+		mfaService: mfaService,
+		logger:     logger,
+	}
+}
+
+// StartTOTPEnrollment starts enrolling an authenticator app
+// @Summary Start TOTP enrollment
+// @Description Create an unconfirmed authenticator app factor and return its secret and otpauth:// URI. The secret is shown only once; the factor is used at login once confirmed with a first code.
+// @Tags MFA
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body dto.StartTOTPEnrollmentRequest false "Factor label"
+// @Success 201 {object} dto.TOTPEnrollmentResponse "Enrollment started"
+// @Failure 400 {object} dto.ErrorResponse "Invalid name or too many factors"
+// @Failure 401 {object} dto.ErrorResponse "Unauthorized - invalid or missing JWT token"
+// @Failure 500 {object} dto.ErrorResponse "Internal server error"
+// @Router /api/v1/users/mfa/totp [post]
+func (h *MFAHandler) StartTOTPEnrollment(c *fiber.Ctx) error {
+	user := c.Locals("user").(*entities.User)
+
+	var req dto.StartTOTPEnrollmentRequest
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&req); err != nil {
+			return c.Status(http.StatusBadRequest).JSON(dto.ErrorResponse{
+				Error:   "Invalid request",
+				Message: err.Error(),
+			})
+		}
+	}
+
+	enrollment, err := h.mfaService.StartTOTPEnrollment(c.Context(), user, req.Name)
+	if err != nil {
+		if errors.IsInvalidInput(err) {
+			return c.Status(http.StatusBadRequest).JSON(dto.ErrorResponse{
+				Error:   "Invalid request",
+				Message: err.Error(),
+			})
+		}
+
+		h.logger.Error(c.Context(), "Failed to start TOTP enrollment", logger.F("error", err), logger.F("user_id", user.ID))
+		return c.Status(http.StatusInternalServerError).JSON(dto.ErrorResponse{
+			Error:   "Failed to start enrollment",
+			Message: err.Error(),
+		})
+	}
+
+	return c.Status(http.StatusCreated).JSON(dto.TOTPEnrollmentResponse{
+		FactorID:  enrollment.Factor.ID,
+		Name:      enrollment.Factor.Name,
+		Secret:    enrollment.Secret,
+		URI:       enrollment.URI,
+		ExpiresIn: int(time.Until(enrollment.ExpiresAt).Seconds()),
+	})
+}
+
+// ConfirmTOTPEnrollment confirms an enrollment with a first code
+// @Summary Confirm TOTP enrollment
+// @Description Confirm an authenticator app enrollment with the first code it shows. From then on, logins require a code from the app.
+// @Tags MFA
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Factor ID"
+// @Param request body dto.ConfirmTOTPEnrollmentRequest true "Authenticator code"
+// @Success 200 {object} dto.FactorResponse "Factor confirmed"
+// @Failure 400 {object} dto.ErrorResponse "Invalid request, factor already confirmed or enrollment expired"
+// @Failure 401 {object} dto.ErrorResponse "Invalid code"
+// @Failure 404 {object} dto.ErrorResponse "Factor not found"
+// @Failure 500 {object} dto.ErrorResponse "Internal server error"
+// @Router /api/v1/users/mfa/totp/{id}/confirm [post]
+func (h *MFAHandler) ConfirmTOTPEnrollment(c *fiber.Ctx) error {
+	user := c.Locals("user").(*entities.User)
+
+	factorID, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(dto.ErrorResponse{
+			Error:   "Invalid request",
+			Message: "id must be a number",
+		})
+	}
+
+	var req dto.ConfirmTOTPEnrollmentRequest
+	if err := c.BodyParser(&req); err != nil || req.Code == "" {
+		return c.Status(http.StatusBadRequest).JSON(dto.ErrorResponse{
+			Error:   "Invalid request",
+			Message: "code is required",
+		})
+	}
+
+	factor, err := h.mfaService.ConfirmTOTPEnrollment(c.Context(), user, factorID, req.Code)
+	if err != nil {
+		return h.factorError(c, user, "Failed to confirm TOTP enrollment", err)
+	}
+
+	return c.Status(http.StatusOK).JSON(factorResponse(factor))
+}
+
+// ListFactors lists the user's second factors
+// @Summary List second factors
+// @Description List the second factors enrolled by the authenticated user, confirmed or not
+// @Tags MFA
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} dto.FactorsResponse "Enrolled factors"
+// @Failure 401 {object} dto.ErrorResponse "Unauthorized - invalid or missing JWT token"
+// @Failure 500 {object} dto.ErrorResponse "Internal server error"
+// @Router /api/v1/users/mfa/factors [get]
+func (h *MFAHandler) ListFactors(c *fiber.Ctx) error {
+	user := c.Locals("user").(*entities.User)
+
+	factors, err := h.mfaService.ListFactors(c.Context(), user)
+	if err != nil {
+		h.logger.Error(c.Context(), "Failed to list factors", logger.F("error", err), logger.F("user_id", user.ID))
+		return c.Status(http.StatusInternalServerError).JSON(dto.ErrorResponse{
+			Error:   "Failed to list factors",
+			Message: err.Error(),
+		})
+	}
+
+	response := dto.FactorsResponse{Factors: make([]dto.FactorResponse, 0, len(factors))}
+	for _, factor := range factors {
+		response.Factors = append(response.Factors, factorResponse(factor))
+	}
+
+	return c.Status(http.StatusOK).JSON(response)
+}
+
+// RemoveFactor removes one of the user's second factors
+// @Summary Remove second factor
+// @Description Remove a second factor. Once no confirmed factor is left, logins no longer ask for one. Users who last authenticated more than JWT_STEP_UP_MAX_AGE ago must step up first.
+// @Tags MFA
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Factor ID"
+// @Success 204 "Factor removed"
+// @Failure 400 {object} dto.ErrorResponse "Invalid factor ID"
+// @Failure 401 {object} dto.ErrorResponse "Unauthorized - invalid or missing JWT token, or step_up_required"
+// @Failure 404 {object} dto.ErrorResponse "Factor not found"
+// @Failure 500 {object} dto.ErrorResponse "Internal server error"
+// @Router /api/v1/users/mfa/factors/{id} [delete]
+func (h *MFAHandler) RemoveFactor(c *fiber.Ctx) error {
+	user := c.Locals("user").(*entities.User)
+
+	factorID, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(dto.ErrorResponse{
+			Error:   "Invalid request",
+			Message: "id must be a number",
+		})
+	}
+
+	if err := h.mfaService.RemoveFactor(c.Context(), user, factorID); err != nil {
+		return h.factorError(c, user, "Failed to remove factor", err)
+	}
+
+	return c.SendStatus(http.StatusNoContent)
+}
+
+func (h *MFAHandler) factorError(c *fiber.Ctx, user *entities.User, message string, err error) error {
+	switch {
+	case errors.IsNotFound(err):
+		return c.Status(http.StatusNotFound).JSON(dto.ErrorResponse{
+			Error:   "Factor not found",
+			Message: err.Error(),
+		})
+	case errors.IsInvalidInput(err):
+		return c.Status(http.StatusBadRequest).JSON(dto.ErrorResponse{
+			Error:   "Invalid request",
+			Message: err.Error(),
+		})
+	case errors.IsSecondFactorInvalid(err):
+		return c.Status(http.StatusUnauthorized).JSON(dto.ErrorResponse{
+			Error:   "invalid_second_factor",
+			Message: err.Error(),
+		})
+	}
+
+	h.logger.Error(c.Context(), message, logger.F("error", err), logger.F("user_id", user.ID))
+	return c.Status(http.StatusInternalServerError).JSON(dto.ErrorResponse{
+		Error:   message,
+		Message: err.Error(),
+	})
+}
+
+func factorResponse(factor *entities.UserFactor) dto.FactorResponse {
+	return dto.FactorResponse{
+		ID:         factor.ID,
+		Type:       string(factor.Type),
+		Name:       factor.Name,
+		Confirmed:  factor.IsConfirmed(),
+		LastUsedAt: factor.LastUsedAt,
+		CreatedAt:  factor.CreatedAt,
+	}
+}
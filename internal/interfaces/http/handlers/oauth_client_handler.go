@@ -0,0 +1,146 @@
+package handlers
+
+import (
+	"net/http"
+
+	"otp-server/internal/application"
+	"otp-server/internal/domain/entities"
+	"otp-server/internal/domain/errors"
+	"otp-server/internal/infrastructure/logger"
+	"otp-server/internal/interfaces/http/handlers/dto"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// OAuthClientHandler handles the registration of OpenID Connect clients
+type OAuthClientHandler struct {
+	oidcService application.OIDCServiceInterface
+	logger      logger.Logger
+}
+
+// NewOAuthClientHandler creates a new OAuth client handler
+func NewOAuthClientHandler(oidcService application.OIDCServiceInterface, logger logger.Logger) *OAuthClientHandler {
+	return &OAuthClientHandler{
+		oidcService: oidcService,
+		logger:      logger,
+	}
+}
+
+// RegisterClient registers a new OpenID Connect client
+// @Summary Register OAuth client
+// @Description Register an application that logs users in through the OpenID Connect provider. The client secret is returned only once; public clients get none and must use PKCE, which every client has to anyway. Users of the default tenant with the clients:write permission only.
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body dto.RegisterOAuthClientRequest true "Client to register"
+// @Success 201 {object} dto.OAuthClientResponse "Client registered"
+// @Failure 400 {object} dto.ErrorResponse "Invalid name, redirect URI or scope"
+// @Failure 401 {object} dto.ErrorResponse "Unauthorized - invalid or missing JWT token"
+// @Failure 403 {object} dto.ErrorResponse "The clients:write permission within the default tenant required"
+// @Failure 500 {object} dto.ErrorResponse "Internal server error"
+// @Router /api/v1/admin/oauth/clients [post]
+func (h *OAuthClientHandler) RegisterClient(c *fiber.Ctx) error {
+	var req dto.RegisterOAuthClientRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(http.StatusBadRequest).JSON(dto.ErrorResponse{
+			Error:   "Invalid request",
+			Message: err.Error(),
+		})
+	}
+
+	client, secret, err := h.oidcService.RegisterClient(c.Context(), req.Name, req.RedirectURIs, req.Scopes, req.Public)
+	if err != nil {
+		if errors.IsInvalidInput(err) {
+			return c.Status(http.StatusBadRequest).JSON(dto.ErrorResponse{
+				Error:   "Invalid request",
+				Message: err.Error(),
+			})
+		}
+
+		h.logger.Error(c.Context(), "Failed to register OAuth client", logger.F("error", err))
+		return c.Status(http.StatusInternalServerError).JSON(dto.ErrorResponse{
+			Error:   "Failed to register client",
+			Message: err.Error(),
+		})
+	}
+
+	response := oauthClientResponse(client)
+	response.ClientSecret = secret
+
+	return c.Status(http.StatusCreated).JSON(response)
+}
+
+// ListClients lists the registered OpenID Connect clients
+// @Summary List OAuth clients
+// @Description List every registered OpenID Connect client, including deactivated ones. Users of the default tenant with the clients:read permission only.
+// @Tags Admin
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} dto.OAuthClientsResponse "Registered clients"
+// @Failure 401 {object} dto.ErrorResponse "Unauthorized - invalid or missing JWT token"
+// @Failure 403 {object} dto.ErrorResponse "The clients:read permission within the default tenant required"
+// @Failure 500 {object} dto.ErrorResponse "Internal server error"
+// @Router /api/v1/admin/oauth/clients [get]
+func (h *OAuthClientHandler) ListClients(c *fiber.Ctx) error {
+	clients, err := h.oidcService.ListClients(c.Context())
+	if err != nil {
+		h.logger.Error(c.Context(), "Failed to list OAuth clients", logger.F("error", err))
+		return c.Status(http.StatusInternalServerError).JSON(dto.ErrorResponse{
+			Error:   "Failed to list clients",
+			Message: err.Error(),
+		})
+	}
+
+	response := dto.OAuthClientsResponse{Clients: make([]dto.OAuthClientResponse, 0, len(clients))}
+	for _, client := range clients {
+		response.Clients = append(response.Clients, oauthClientResponse(client))
+	}
+
+	return c.Status(http.StatusOK).JSON(response)
+}
+
+// DeactivateClient deactivates an OpenID Connect client
+// @Summary Deactivate OAuth client
+// @Description Stop a client from logging users in and from refreshing its tokens. Access tokens already issued stay valid until they expire. Users of the default tenant with the clients:write permission only.
+// @Tags Admin
+// @Security BearerAuth
+// @Param client_id path string true "Client ID"
+// @Success 204 "Client deactivated"
+// @Failure 401 {object} dto.ErrorResponse "Unauthorized - invalid or missing JWT token"
+// @Failure 403 {object} dto.ErrorResponse "The clients:write permission within the default tenant required"
+// @Failure 404 {object} dto.ErrorResponse "Client not found"
+// @Failure 500 {object} dto.ErrorResponse "Internal server error"
+// @Router /api/v1/admin/oauth/clients/{client_id} [delete]
+func (h *OAuthClientHandler) DeactivateClient(c *fiber.Ctx) error {
+	clientID := c.Params("client_id")
+
+	if err := h.oidcService.DeactivateClient(c.Context(), clientID); err != nil {
+		if errors.IsNotFound(err) {
+			return c.Status(http.StatusNotFound).JSON(dto.ErrorResponse{
+				Error:   "Client not found",
+				Message: err.Error(),
+			})
+		}
+
+		h.logger.Error(c.Context(), "Failed to deactivate OAuth client", logger.F("error", err), logger.F("client_id", clientID))
+		return c.Status(http.StatusInternalServerError).JSON(dto.ErrorResponse{
+			Error:   "Failed to deactivate client",
+			Message: err.Error(),
+		})
+	}
+
+	return c.SendStatus(http.StatusNoContent)
+}
+
+func oauthClientResponse(client *entities.OAuthClient) dto.OAuthClientResponse {
+	return dto.OAuthClientResponse{
+		ClientID:     client.ClientID,
+		Name:         client.Name,
+		RedirectURIs: client.RedirectURIs,
+		Scopes:       client.Scopes,
+		Public:       client.IsPublic(),
+		IsActive:     client.IsActive,
+		CreatedAt:    client.CreatedAt,
+	}
+}
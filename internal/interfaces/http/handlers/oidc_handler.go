@@ -0,0 +1,569 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/base64"
+	"html/template"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"otp-server/internal/application"
+	"otp-server/internal/application/services"
+	"otp-server/internal/domain/entities"
+	"otp-server/internal/domain/errors"
+	"otp-server/internal/infrastructure/logger"
+	"otp-server/internal/interfaces/http/handlers/dto"
+	"otp-server/lib"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// OIDCHandler serves the OpenID Connect provider: discovery, the login
+// page of the authorization endpoint, the token and the userinfo endpoint
+type OIDCHandler struct {
+	oidcService application.OIDCServiceInterface
+	logger      logger.Logger
+}
+
+// NewOIDCHandler creates a new OpenID Connect handler
+func NewOIDCHandler(oidcService application.OIDCServiceInterface, logger logger.Logger) *OIDCHandler {
+	return &OIDCHandler{
+		oidcService: oidcService,
+		logger:      logger,
+	}
+}
+
+// Discovery serves the OpenID Provider configuration
+// @Summary OpenID Provider configuration
+// @Description OpenID Connect discovery document listing the endpoints, scopes, claims and algorithms of the provider
+// @Tags OpenID Connect
+// @Produce json
+// @Success 200 {object} services.ProviderMetadata "Provider configuration"
+// @Router /.well-known/openid-configuration [get]
+func (h *OIDCHandler) Discovery(c *fiber.Ctx) error {
+	c.Set(fiber.HeaderCacheControl, "public, max-age=3600")
+	return c.Status(http.StatusOK).JSON(h.oidcService.Metadata())
+}
+
+// Authorize starts an authorization request and shows the login page
+// @Summary Authorization endpoint
+// @Description Start an OpenID Connect authorization code flow. Shows a login page where the user enters their phone number, the SMS code and, if enrolled, their TOTP code, then redirects back to the client with a code. PKCE with S256 is required.
+// @Tags OpenID Connect
+// @Produce html
+// @Param response_type query string true "Must be code"
+// @Param client_id query string true "Client ID"
+// @Param redirect_uri query string true "Registered redirect URI"
+// @Param scope query string true "Space-separated scopes, must include openid" example(openid phone)
+// @Param state query string false "Opaque value returned to the client"
+// @Param nonce query string false "Value copied into the ID token"
+// @Param code_challenge query string true "PKCE code challenge"
+// @Param code_challenge_method query string true "Must be S256"
+// @Success 200 {string} string "Login page"
+// @Success 302 "Redirect to the client with an error"
+// @Failure 400 {string} string "Unknown client or redirect URI"
+// @Router /oauth/authorize [get]
+func (h *OIDCHandler) Authorize(c *fiber.Ctx) error {
+	redirectURI := c.Query("redirect_uri")
+
+	client, err := h.oidcService.ResolveClient(c.Context(), c.Query("client_id"), redirectURI)
+	if err != nil {
+		if oauthErr, ok := errors.AsOAuthError(err); ok {
+			return h.renderLogin(c, http.StatusBadRequest, &loginPage{Step: "error", Error: oauthErr.Description})
+		}
+		h.logger.Error(c.Context(), "Failed to resolve OAuth client", logger.F("error", err))
+		return h.renderLogin(c, http.StatusInternalServerError, &loginPage{Step: "error", Error: "Something went wrong, please try again later."})
+	}
+
+	request, err := h.oidcService.StartAuthorization(c.Context(), client, redirectURI, &services.AuthorizationParams{
+		ResponseType:        c.Query("response_type"),
+		Scope:               c.Query("scope"),
+		State:               c.Query("state"),
+		Nonce:               c.Query("nonce"),
+		CodeChallenge:       c.Query("code_challenge"),
+		CodeChallengeMethod: c.Query("code_challenge_method"),
+	})
+	if err != nil {
+		oauthErr, ok := errors.AsOAuthError(err)
+		if !ok {
+			h.logger.Error(c.Context(), "Failed to start authorization", logger.F("error", err), logger.F("client_id", client.ClientID))
+			oauthErr = errors.NewOAuthError("server_error", "the authorization request could not be started")
+		}
+		return c.Redirect(services.AuthorizationErrorURL(redirectURI, c.Query("state"), oauthErr), http.StatusFound)
+	}
+
+	return h.renderLogin(c, http.StatusOK, &loginPage{
+		Step:       "phone",
+		RequestID:  request.ID,
+		ClientName: request.ClientName,
+	})
+}
+
+// SendLoginOTP sends the login code from the login page
+// @Summary Send login code
+// @Description Login page form: send the SMS code to the phone number entered for a pending authorization request
+// @Tags OpenID Connect
+// @Accept x-www-form-urlencoded
+// @Produce html
+// @Param request_id formData string true "Authorization request ID"
+// @Param phone_number formData string true "Phone number in E.164 format"
+// @Success 200 {string} string "Code entry page"
+// @Failure 400 {string} string "Invalid phone number or expired request"
+// @Router /oauth/authorize [post]
+func (h *OIDCHandler) SendLoginOTP(c *fiber.Ctx) error {
+	requestID := c.FormValue("request_id")
+	phoneNumber := strings.TrimSpace(c.FormValue("phone_number"))
+
+	request, err := h.oidcService.GetAuthorization(c.Context(), requestID)
+	if err != nil {
+		return h.loginError(c, nil, "phone", err)
+	}
+
+	if err := lib.ValidatePhoneNumber(phoneNumber); err != nil {
+		return h.renderLogin(c, http.StatusBadRequest, &loginPage{
+			Step:       "phone",
+			RequestID:  request.ID,
+			ClientName: request.ClientName,
+			Error:      "Enter your phone number in international format, like +14155550123.",
+		})
+	}
+
+	sent, _, err := h.oidcService.SendLoginOTP(c.Context(), requestID, &entities.OTPRequest{
+		PhoneNumber: phoneNumber,
+		Locales:     lib.ParseAcceptLanguage(c.Get(fiber.HeaderAcceptLanguage)),
+	})
+	if err != nil {
+		return h.loginError(c, request, "phone", err)
+	}
+
+	return h.renderLogin(c, http.StatusOK, &loginPage{
+		Step:        "code",
+		RequestID:   sent.ID,
+		ClientName:  sent.ClientName,
+		PhoneNumber: lib.MaskPhoneNumber(sent.PhoneNumber),
+	})
+}
+
+// VerifyLoginOTP checks the login code entered on the login page
+// @Summary Verify login code
+// @Description Login page form: verify the SMS code of a pending authorization request. Redirects back to the client with an authorization code, or asks for the TOTP code of users with a second factor.
+// @Tags OpenID Connect
+// @Accept x-www-form-urlencoded
+// @Produce html
+// @Param request_id formData string true "Authorization request ID"
+// @Param otp formData string true "SMS code"
+// @Success 200 {string} string "TOTP entry page"
+// @Success 303 "Redirect to the client"
+// @Failure 400 {string} string "Invalid code or expired request"
+// @Router /oauth/authorize/verify [post]
+func (h *OIDCHandler) VerifyLoginOTP(c *fiber.Ctx) error {
+	result, err := h.oidcService.VerifyLoginOTP(c.Context(), c.FormValue("request_id"), strings.TrimSpace(c.FormValue("otp")), deviceInfo(c, ""))
+	if err != nil {
+		return h.loginError(c, nil, "code", err)
+	}
+
+	return h.loginResult(c, result)
+}
+
+// VerifyLoginTOTP checks the TOTP code entered on the login page
+// @Summary Verify login second factor
+// @Description Login page form: verify the TOTP code of a pending authorization request and redirect back to the client with an authorization code
+// @Tags OpenID Connect
+// @Accept x-www-form-urlencoded
+// @Produce html
+// @Param request_id formData string true "Authorization request ID"
+// @Param code formData string true "TOTP code"
+// @Success 303 "Redirect to the client"
+// @Failure 400 {string} string "Invalid code or expired request"
+// @Router /oauth/authorize/totp [post]
+func (h *OIDCHandler) VerifyLoginTOTP(c *fiber.Ctx) error {
+	result, err := h.oidcService.VerifyLoginSecondFactor(c.Context(), c.FormValue("request_id"), strings.TrimSpace(c.FormValue("code")), deviceInfo(c, ""))
+	if err != nil {
+		return h.loginError(c, nil, "totp", err)
+	}
+
+	return h.loginResult(c, result)
+}
+
+// Token exchanges an authorization code or a refresh token for tokens, or
+// issues an API client an access token
+// @Summary Token endpoint
+// @Description Exchange an authorization code together with its PKCE code verifier, or a refresh token, for tokens. Confidential clients authenticate with HTTP Basic or client_id and client_secret form parameters; public clients send only client_id. API clients get an access token, without a refresh token, with the client_credentials grant.
+// @Tags OpenID Connect
+// @Accept x-www-form-urlencoded
+// @Produce json
+// @Param grant_type formData string true "authorization_code, refresh_token or client_credentials"
+// @Param code formData string false "Authorization code"
+// @Param redirect_uri formData string false "Redirect URI of the authorization request"
+// @Param code_verifier formData string false "PKCE code verifier"
+// @Param refresh_token formData string false "Refresh token"
+// @Param scope formData string false "Space-separated API client scopes for client_credentials; all scopes of the client by default"
+// @Param client_id formData string false "Client ID, unless sent with HTTP Basic"
+// @Param client_secret formData string false "Client secret, unless sent with HTTP Basic"
+// @Success 200 {object} dto.OAuthTokenResponse "Tokens"
+// @Failure 400 {object} dto.OAuthErrorResponse "Invalid request or grant"
+// @Failure 401 {object} dto.OAuthErrorResponse "Client authentication failed"
+// @Failure 500 {object} dto.OAuthErrorResponse "Internal server error"
+// @Router /oauth/token [post]
+func (h *OIDCHandler) Token(c *fiber.Ctx) error {
+	c.Set(fiber.HeaderCacheControl, "no-store")
+	c.Set(fiber.HeaderPragma, "no-cache")
+
+	clientID, clientSecret, basic, credentialsErr := clientCredentials(c)
+	if credentialsErr != nil {
+		return oauthError(c, http.StatusBadRequest, credentialsErr)
+	}
+
+	response, err := h.oidcService.Exchange(c.Context(), &services.TokenRequest{
+		GrantType:    c.FormValue("grant_type"),
+		ClientID:     clientID,
+		ClientSecret: clientSecret,
+		Code:         c.FormValue("code"),
+		RedirectURI:  c.FormValue("redirect_uri"),
+		CodeVerifier: c.FormValue("code_verifier"),
+		RefreshToken: c.FormValue("refresh_token"),
+		Scope:        c.FormValue("scope"),
+	})
+	if err != nil {
+		return h.clientRequestError(c, err, basic, "Failed to issue OAuth tokens", clientID)
+	}
+
+	return c.Status(http.StatusOK).JSON(dto.OAuthTokenResponse{
+		AccessToken:  response.AccessToken,
+		TokenType:    "Bearer",
+		ExpiresIn:    int(response.ExpiresIn.Seconds()),
+		RefreshToken: response.RefreshToken,
+		IDToken:      response.IDToken,
+		Scope:        response.Scope,
+	})
+}
+
+// Introspect reports whether a token is active and what it grants
+// @Summary Token introspection endpoint
+// @Description Report whether an access or refresh token is active (RFC 7662), for resource servers authenticating as confidential clients. Tokens that are invalid, expired, revoked or belong to a deactivated user are reported as {"active": false} and nothing else. Any token can be introspected, whether issued to a client or by the API login.
+// @Tags OpenID Connect
+// @Accept x-www-form-urlencoded
+// @Produce json
+// @Param token formData string true "Access or refresh token"
+// @Param token_type_hint formData string false "access_token or refresh_token; not needed, since the token format tells them apart"
+// @Param client_id formData string false "Client ID, unless sent with HTTP Basic"
+// @Param client_secret formData string false "Client secret, unless sent with HTTP Basic"
+// @Success 200 {object} dto.IntrospectionResponse "Token state"
+// @Failure 400 {object} dto.OAuthErrorResponse "Missing token or public client"
+// @Failure 401 {object} dto.OAuthErrorResponse "Client authentication failed"
+// @Failure 500 {object} dto.OAuthErrorResponse "Internal server error"
+// @Router /oauth/introspect [post]
+func (h *OIDCHandler) Introspect(c *fiber.Ctx) error {
+	c.Set(fiber.HeaderCacheControl, "no-store")
+
+	clientID, clientSecret, basic, credentialsErr := clientCredentials(c)
+	if credentialsErr != nil {
+		return oauthError(c, http.StatusBadRequest, credentialsErr)
+	}
+
+	result, err := h.oidcService.Introspect(c.Context(), clientID, clientSecret, c.FormValue("token"))
+	if err != nil {
+		return h.clientRequestError(c, err, basic, "Failed to introspect token", clientID)
+	}
+
+	if !result.Active {
+		return c.Status(http.StatusOK).JSON(dto.IntrospectionResponse{Active: false})
+	}
+
+	response := dto.IntrospectionResponse{
+		Active:      true,
+		TokenType:   result.TokenType,
+		Subject:     strconv.Itoa(result.UserID),
+		TenantID:    result.TenantID,
+		ClientID:    result.ClientID,
+		Scope:       result.Scope,
+		ExpiresAt:   result.ExpiresAt.Unix(),
+		IssuedAt:    result.IssuedAt.Unix(),
+		TokenID:     result.TokenID,
+		SessionID:   result.SessionID,
+		Role:        result.Role,
+		PhoneNumber: result.PhoneNumber,
+		ACR:         result.ACR,
+		AuthMethods: result.AuthMethods,
+	}
+	if result.UserID == 0 {
+		// Tokens of API clients have no user; the client is the subject
+		response.Subject = result.ClientID
+	}
+	if !result.AuthTime.IsZero() {
+		response.AuthTime = result.AuthTime.Unix()
+	}
+
+	return c.Status(http.StatusOK).JSON(response)
+}
+
+// Revoke revokes a token the client holds
+// @Summary Token revocation endpoint
+// @Description Revoke an access or refresh token issued to the client (RFC 7009), ending its session: the matching access and refresh tokens stop working too. Invalid, expired and already revoked tokens are ignored.
+// @Tags OpenID Connect
+// @Accept x-www-form-urlencoded
+// @Produce json
+// @Param token formData string true "Access or refresh token"
+// @Param token_type_hint formData string false "access_token or refresh_token; not needed, since the token format tells them apart"
+// @Param client_id formData string false "Client ID, unless sent with HTTP Basic"
+// @Param client_secret formData string false "Client secret, unless sent with HTTP Basic"
+// @Success 200 "Token revoked or invalid"
+// @Failure 400 {object} dto.OAuthErrorResponse "Missing token, or token of another client"
+// @Failure 401 {object} dto.OAuthErrorResponse "Client authentication failed"
+// @Failure 500 {object} dto.OAuthErrorResponse "Internal server error"
+// @Router /oauth/revoke [post]
+func (h *OIDCHandler) Revoke(c *fiber.Ctx) error {
+	clientID, clientSecret, basic, credentialsErr := clientCredentials(c)
+	if credentialsErr != nil {
+		return oauthError(c, http.StatusBadRequest, credentialsErr)
+	}
+
+	if err := h.oidcService.Revoke(c.Context(), clientID, clientSecret, c.FormValue("token")); err != nil {
+		return h.clientRequestError(c, err, basic, "Failed to revoke token", clientID)
+	}
+
+	return c.SendStatus(http.StatusOK)
+}
+
+// UserInfo returns the claims about the user of an access token
+// @Summary UserInfo endpoint
+// @Description Claims about the user the access token was issued for, as granted by its scope: sub always, phone_number and phone_number_verified with phone, name with profile. The token must have been issued to a client with the openid scope.
+// @Tags OpenID Connect
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} map[string]interface{} "User claims"
+// @Failure 401 {object} dto.OAuthErrorResponse "Invalid or expired access token"
+// @Failure 403 {object} dto.OAuthErrorResponse "Access token lacks the openid scope"
+// @Router /oauth/userinfo [get]
+// @Router /oauth/userinfo [post]
+func (h *OIDCHandler) UserInfo(c *fiber.Ctx) error {
+	authHeader := c.Get(fiber.HeaderAuthorization)
+	if !strings.HasPrefix(authHeader, "Bearer ") {
+		c.Set(fiber.HeaderWWWAuthenticate, `Bearer realm="otp-server"`)
+		return oauthError(c, http.StatusUnauthorized, errors.NewOAuthError("invalid_request", "bearer token required"))
+	}
+
+	info, err := h.oidcService.UserInfo(c.Context(), authHeader[len("Bearer "):])
+	if err != nil {
+		oauthErr, ok := errors.AsOAuthError(err)
+		if !ok {
+			h.logger.Error(c.Context(), "Failed to get user info", logger.F("error", err))
+			return oauthError(c, http.StatusInternalServerError, errors.NewOAuthError("server_error", "the user info could not be loaded"))
+		}
+
+		c.Set(fiber.HeaderWWWAuthenticate, `Bearer error="`+oauthErr.Code+`", error_description="`+oauthErr.Description+`"`)
+		if oauthErr.Code == "insufficient_scope" {
+			return oauthError(c, http.StatusForbidden, oauthErr)
+		}
+		return oauthError(c, http.StatusUnauthorized, oauthErr)
+	}
+
+	c.Set(fiber.HeaderCacheControl, "no-store")
+	return c.Status(http.StatusOK).JSON(info)
+}
+
+// clientRequestError answers a failed request of an authenticating client:
+// 401 when its authentication failed, 400 for other OAuth errors and 500
+// for anything else
+func (h *OIDCHandler) clientRequestError(c *fiber.Ctx, err error, basic bool, message, clientID string) error {
+	oauthErr, ok := errors.AsOAuthError(err)
+	if !ok {
+		h.logger.Error(c.Context(), message, logger.F("error", err), logger.F("client_id", clientID))
+		return oauthError(c, http.StatusInternalServerError, errors.NewOAuthError("server_error", "the request could not be processed"))
+	}
+
+	if oauthErr.Code == "invalid_client" {
+		if basic {
+			c.Set(fiber.HeaderWWWAuthenticate, `Basic realm="otp-server"`)
+		}
+		return oauthError(c, http.StatusUnauthorized, oauthErr)
+	}
+	return oauthError(c, http.StatusBadRequest, oauthErr)
+}
+
+// loginResult sends the user back to the client, or asks for their second
+// factor
+func (h *OIDCHandler) loginResult(c *fiber.Ctx, result *services.AuthorizationResult) error {
+	if result.SecondFactorRequired {
+		return h.renderLogin(c, http.StatusOK, &loginPage{
+			Step:       "totp",
+			RequestID:  result.Request.ID,
+			ClientName: result.Request.ClientName,
+		})
+	}
+
+	return c.Redirect(result.RedirectURL, http.StatusSeeOther)
+}
+
+// loginError shows the login page again with what went wrong. The request
+// is reloaded when not given, since failed steps do not return it.
+func (h *OIDCHandler) loginError(c *fiber.Ctx, request *services.AuthorizationRequest, step string, err error) error {
+	if errors.IsNotFound(err) && request == nil {
+		return h.renderLogin(c, http.StatusBadRequest, &loginPage{
+			Step:  "error",
+			Error: "This login has expired. Go back to the app and try again.",
+		})
+	}
+
+	if request == nil {
+		var loadErr error
+		request, loadErr = h.oidcService.GetAuthorization(c.Context(), c.FormValue("request_id"))
+		if loadErr != nil {
+			return h.renderLogin(c, http.StatusBadRequest, &loginPage{
+				Step:  "error",
+				Error: "This login has expired. Go back to the app and try again.",
+			})
+		}
+	}
+
+	page := &loginPage{
+		Step:        step,
+		RequestID:   request.ID,
+		ClientName:  request.ClientName,
+		PhoneNumber: lib.MaskPhoneNumber(request.PhoneNumber),
+	}
+	status := http.StatusBadRequest
+
+	switch {
+	case errors.IsOTPInvalid(err):
+		page.Error = "The code is invalid or has expired."
+	case errors.IsSecondFactorInvalid(err):
+		page.Error = "The authenticator code is invalid."
+	case errors.IsOTPLocked(err):
+		page.Step = "phone"
+		page.Error = "Too many failed attempts. Please try again later."
+		status = http.StatusTooManyRequests
+	case errors.IsOTPDeliveryFailed(err):
+		page.Step = "phone"
+		page.Error = "We could not send the code. Please try again."
+		status = http.StatusBadGateway
+	case errors.IsOTPResendTooSoon(err), errors.IsOTPResendLimit(err):
+		page.Error = "A code was sent recently. Please wait before requesting another one."
+		status = http.StatusTooManyRequests
+	case errors.IsAccountInactive(err):
+		page.Step = "error"
+		page.Error = "This account is suspended or closed and cannot log in."
+		status = http.StatusForbidden
+	default:
+		h.logger.Error(c.Context(), "OIDC login step failed", logger.F("error", err), logger.F("step", step))
+		page.Error = "Something went wrong, please try again."
+		status = http.StatusInternalServerError
+	}
+
+	return h.renderLogin(c, status, page)
+}
+
+func (h *OIDCHandler) renderLogin(c *fiber.Ctx, status int, page *loginPage) error {
+	var body bytes.Buffer
+	if err := loginPageTemplate.Execute(&body, page); err != nil {
+		h.logger.Error(c.Context(), "Failed to render login page", logger.F("error", err))
+		return c.SendStatus(http.StatusInternalServerError)
+	}
+
+	c.Set(fiber.HeaderCacheControl, "no-store")
+	c.Set(fiber.HeaderContentSecurityPolicy, "default-src 'none'; style-src 'unsafe-inline'; frame-ancestors 'none'")
+	c.Set(fiber.HeaderContentType, fiber.MIMETextHTMLCharsetUTF8)
+	return c.Status(status).Send(body.Bytes())
+}
+
+// clientCredentials returns the client ID and secret of a token request,
+// sent with HTTP Basic or as form parameters, and whether Basic was used
+func clientCredentials(c *fiber.Ctx) (clientID, clientSecret string, basic bool, err *errors.OAuthError) {
+	clientID = c.FormValue("client_id")
+	clientSecret = c.FormValue("client_secret")
+
+	authHeader := c.Get(fiber.HeaderAuthorization)
+	if !strings.HasPrefix(authHeader, "Basic ") {
+		return clientID, clientSecret, false, nil
+	}
+
+	malformed := errors.NewOAuthError("invalid_request", "malformed Basic authorization header")
+
+	decoded, decodeErr := base64.StdEncoding.DecodeString(authHeader[len("Basic "):])
+	user, password, found := strings.Cut(string(decoded), ":")
+	if decodeErr != nil || !found {
+		return "", "", true, malformed
+	}
+
+	// RFC 6749 form-encodes the credentials before Basic encoding them
+	user, userErr := url.QueryUnescape(user)
+	password, passwordErr := url.QueryUnescape(password)
+	if userErr != nil || passwordErr != nil {
+		return "", "", true, malformed
+	}
+	if (clientID != "" && clientID != user) || clientSecret != "" {
+		return "", "", true, errors.NewOAuthError("invalid_request", "client credentials sent more than once")
+	}
+
+	return user, password, true, nil
+}
+
+func oauthError(c *fiber.Ctx, status int, err *errors.OAuthError) error {
+	return c.Status(status).JSON(dto.OAuthErrorResponse{
+		Error:            err.Code,
+		ErrorDescription: err.Description,
+	})
+}
+
+// loginPage is what the login page shows: one of the phone, code and totp
+// steps, or an error the user cannot fix here
+type loginPage struct {
+	Step        string
+	RequestID   string
+	ClientName  string
+	PhoneNumber string
+	Error       string
+}
+
+var loginPageTemplate = template.Must(template.New("login").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>Log in</title>
+<style>
+body { font-family: system-ui, sans-serif; background: #f4f5f7; margin: 0; }
+main { max-width: 22rem; margin: 4rem auto; padding: 2rem; background: #fff; border-radius: 8px; box-shadow: 0 1px 4px rgba(0,0,0,.1); }
+h1 { font-size: 1.25rem; margin-top: 0; }
+label { display: block; margin-bottom: .5rem; }
+input { box-sizing: border-box; width: 100%; padding: .6rem; font-size: 1rem; margin-bottom: 1rem; border: 1px solid #ccc; border-radius: 4px; }
+button { width: 100%; padding: .6rem; font-size: 1rem; border: 0; border-radius: 4px; background: #2563eb; color: #fff; cursor: pointer; }
+.error { color: #b91c1c; }
+</style>
+</head>
+<body>
+<main>
+{{if eq .Step "error"}}
+<h1>Cannot log in</h1>
+<p class="error">{{.Error}}</p>
+{{else}}
+<h1>Log in to {{.ClientName}}</h1>
+{{if .Error}}<p class="error">{{.Error}}</p>{{end}}
+{{if eq .Step "phone"}}
+<form method="post" action="/oauth/authorize">
+<input type="hidden" name="request_id" value="{{.RequestID}}">
+<label for="phone_number">Phone number</label>
+<input id="phone_number" name="phone_number" type="tel" autocomplete="tel" placeholder="+14155550123" required autofocus>
+<button type="submit">Send code</button>
+</form>
+{{else if eq .Step "code"}}
+<form method="post" action="/oauth/authorize/verify">
+<input type="hidden" name="request_id" value="{{.RequestID}}">
+<label for="otp">Enter the code sent to {{.PhoneNumber}}</label>
+<input id="otp" name="otp" inputmode="numeric" autocomplete="one-time-code" required autofocus>
+<button type="submit">Verify</button>
+</form>
+{{else if eq .Step "totp"}}
+<form method="post" action="/oauth/authorize/totp">
+<input type="hidden" name="request_id" value="{{.RequestID}}">
+<label for="code">Enter the code from your authenticator app</label>
+<input id="code" name="code" inputmode="numeric" autocomplete="one-time-code" required autofocus>
+<button type="submit">Verify</button>
+</form>
+{{end}}
+{{end}}
+</main>
+</body>
+</html>
+`))
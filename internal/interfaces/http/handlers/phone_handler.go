@@ -0,0 +1,183 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"otp-server/internal/application"
+	"otp-server/internal/domain/entities"
+	"otp-server/internal/domain/errors"
+	"otp-server/internal/infrastructure/logger"
+	"otp-server/internal/interfaces/http/handlers/dto"
+	"otp-server/lib"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// PhoneHandler handles users changing their phone number
+type PhoneHandler struct {
+	authService application.AuthServiceInterface
+	logger      logger.Logger
+}
+
+// NewPhoneHandler creates a new phone handler
+func NewPhoneHandler(authService application.AuthServiceInterface, logger logger.Logger) *PhoneHandler {
+	return &PhoneHandler{
+		authService: authService,
+		logger:      logger,
+	}
+}
+
+// StartPhoneChange sends the codes confirming a phone number change
+// @Summary Start phone number change
+// @Description Send a code to the new phone number and, unless the tenant turned OTP_PHONE_CHANGE_VERIFY_OLD_NUMBER off, one to the current number. The number must not belong to another user. Requires a recent authentication.
+// @Tags Users
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body dto.StartPhoneChangeRequest true "New phone number"
+// @Success 200 {object} dto.StartPhoneChangeResponse "OTPs sent successfully"
+// @Failure 400 {object} dto.ErrorResponse "Invalid phone number or channel, or the current number"
+// @Failure 401 {object} dto.ErrorResponse "Unauthorized - invalid or missing JWT token, or step_up_required"
+// @Failure 409 {object} dto.ErrorResponse "Phone number belongs to another user"
+// @Failure 429 {object} dto.ErrorResponse "Phone number locked out"
+// @Failure 500 {object} dto.ErrorResponse "Internal server error"
+// @Failure 502 {object} dto.ErrorResponse "OTP delivery provider failed"
+// @Router /api/v1/users/phone/change [post]
+func (h *PhoneHandler) StartPhoneChange(c *fiber.Ctx) error {
+	user := c.Locals("user").(*entities.User)
+
+	var req dto.StartPhoneChangeRequest
+	if err := c.BodyParser(&req); err != nil || req.PhoneNumber == "" {
+		return c.Status(http.StatusBadRequest).JSON(dto.ErrorResponse{
+			Error:   "Invalid request",
+			Message: "phone_number is required",
+		})
+	}
+
+	channel := entities.OTPChannel(req.Channel)
+	if channel != "" && !channel.IsValid() {
+		return c.Status(http.StatusBadRequest).JSON(dto.ErrorResponse{
+			Error:   "Invalid request",
+			Message: "channel must be one of sms, voice, whatsapp, email",
+		})
+	}
+
+	locales := lib.ParseAcceptLanguage(c.Get(fiber.HeaderAcceptLanguage))
+	if req.Locale != "" {
+		locales = append([]string{req.Locale}, locales...)
+	}
+
+	change, err := h.authService.StartPhoneChange(c.Context(), user, &entities.OTPRequest{
+		PhoneNumber: req.PhoneNumber,
+		Channel:     channel,
+		Locales:     locales,
+	})
+	if err != nil {
+		switch {
+		case errors.IsInvalidInput(err):
+			return c.Status(http.StatusBadRequest).JSON(dto.ErrorResponse{
+				Error:   "Invalid request",
+				Message: err.Error(),
+			})
+		case errors.IsAlreadyExists(err):
+			return c.Status(http.StatusConflict).JSON(dto.ErrorResponse{
+				Error:   "phone_number_taken",
+				Message: "Phone number belongs to another user",
+			})
+		case errors.IsOTPLocked(err):
+			return c.Status(http.StatusTooManyRequests).JSON(dto.ErrorResponse{
+				Error:   "otp_locked",
+				Message: err.Error(),
+			})
+		case errors.IsOTPDeliveryFailed(err):
+			h.logger.Error(c.Context(), "Failed to deliver phone change OTP", logger.F("error", err), logger.F("user_id", user.ID))
+			return c.Status(http.StatusBadGateway).JSON(dto.ErrorResponse{
+				Error:   "delivery_failed",
+				Message: "Could not deliver the OTP, please try again",
+			})
+		}
+
+		h.logger.Error(c.Context(), "Failed to send phone change OTP", logger.F("error", err), logger.F("user_id", user.ID))
+		return c.Status(http.StatusInternalServerError).JSON(dto.ErrorResponse{
+			Error:   "Failed to send OTP",
+			Message: err.Error(),
+		})
+	}
+
+	response := dto.StartPhoneChangeResponse{
+		Message:        "OTP sent successfully",
+		VerificationID: change.New.ID,
+		ExpiresIn:      int(time.Until(change.New.ExpiresAt).Seconds()),
+		Channel:        string(change.New.Channel),
+		PhoneNumber:    lib.MaskPhoneNumber(change.New.PhoneNumber),
+		Timestamp:      c.Get("Date"),
+	}
+	if change.Old != nil {
+		response.OldVerificationID = change.Old.ID
+		response.OldExpiresIn = int(time.Until(change.Old.ExpiresAt).Seconds())
+	}
+
+	return c.Status(http.StatusOK).JSON(response)
+}
+
+// ChangePhoneNumber completes a phone number change
+// @Summary Change phone number
+// @Description Change the phone number with the codes sent by the start request. Every session is logged out, a phone_number_changed event lets the old number be notified, and a new token carrying the number is returned.
+// @Tags Users
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body dto.ChangePhoneNumberRequest true "Verification IDs and codes"
+// @Success 200 {object} dto.AuthResponse "Phone number changed"
+// @Failure 400 {object} dto.ErrorResponse "Invalid request or the code of the current number missing"
+// @Failure 401 {object} dto.ErrorResponse "Invalid or expired OTP, or step_up_required"
+// @Failure 409 {object} dto.ErrorResponse "Phone number belongs to another user"
+// @Failure 429 {object} dto.ErrorResponse "Phone number locked out"
+// @Failure 500 {object} dto.ErrorResponse "Internal server error"
+// @Router /api/v1/users/phone/change/verify [post]
+func (h *PhoneHandler) ChangePhoneNumber(c *fiber.Ctx) error {
+	user := c.Locals("user").(*entities.User)
+
+	var req dto.ChangePhoneNumberRequest
+	if err := c.BodyParser(&req); err != nil || req.VerificationID == "" || req.OTP == "" {
+		return c.Status(http.StatusBadRequest).JSON(dto.ErrorResponse{
+			Error:   "Invalid request",
+			Message: "verification_id and otp are required",
+		})
+	}
+
+	result, err := h.authService.ChangePhoneNumber(c.Context(), user, req.VerificationID, req.OTP, req.OldVerificationID, req.OldOTP, deviceInfo(c, ""))
+	if err != nil {
+		switch {
+		case errors.IsInvalidInput(err):
+			return c.Status(http.StatusBadRequest).JSON(dto.ErrorResponse{
+				Error:   "Invalid request",
+				Message: err.Error(),
+			})
+		case errors.IsOTPInvalid(err):
+			return c.Status(http.StatusUnauthorized).JSON(dto.ErrorResponse{
+				Error:   "Invalid OTP",
+				Message: err.Error(),
+			})
+		case errors.IsAlreadyExists(err):
+			return c.Status(http.StatusConflict).JSON(dto.ErrorResponse{
+				Error:   "phone_number_taken",
+				Message: "Phone number belongs to another user",
+			})
+		case errors.IsOTPLocked(err):
+			return c.Status(http.StatusTooManyRequests).JSON(dto.ErrorResponse{
+				Error:   "otp_locked",
+				Message: err.Error(),
+			})
+		}
+
+		h.logger.Error(c.Context(), "Failed to change phone number", logger.F("error", err), logger.F("user_id", user.ID))
+		return c.Status(http.StatusInternalServerError).JSON(dto.ErrorResponse{
+			Error:   "Failed to change phone number",
+			Message: err.Error(),
+		})
+	}
+
+	return c.Status(http.StatusOK).JSON(authResponse(result))
+}
@@ -0,0 +1,234 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"otp-server/internal/application"
+	"otp-server/internal/domain/entities"
+	"otp-server/internal/domain/errors"
+	"otp-server/internal/infrastructure/logger"
+	"otp-server/internal/interfaces/http/handlers/dto"
+	"otp-server/lib"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// RecoveryHandler handles recovery code requests and the phone confirmation
+// that follows a recovery code login
+type RecoveryHandler struct {
+	authService     application.AuthServiceInterface
+	recoveryService application.RecoveryServiceInterface
+	logger          logger.Logger
+}
+
+// NewRecoveryHandler creates a new recovery handler
+func NewRecoveryHandler(authService application.AuthServiceInterface, recoveryService application.RecoveryServiceInterface, logger logger.Logger) *RecoveryHandler {
+	return &RecoveryHandler{
+		authService:     authService,
+		recoveryService: recoveryService,
+		logger:          logger,
+	}
+}
+
+// GenerateCodes generates a new set of recovery codes
+// @Summary Generate recovery codes
+// @Description Generate a new set of single-use recovery codes, which log in without the SMS code if the phone is lost. The codes are shown only once; generating a new set invalidates the previous one. Users who last authenticated more than JWT_STEP_UP_MAX_AGE ago must step up first.
+// @Tags MFA
+// @Produce json
+// @Security BearerAuth
+// @Success 201 {object} dto.RecoveryCodesResponse "Recovery codes generated"
+// @Failure 401 {object} dto.ErrorResponse "Unauthorized - invalid or missing JWT token, or step_up_required"
+// @Failure 500 {object} dto.ErrorResponse "Internal server error"
+// @Router /api/v1/users/mfa/recovery-codes [post]
+func (h *RecoveryHandler) GenerateCodes(c *fiber.Ctx) error {
+	user := c.Locals("user").(*entities.User)
+
+	codes, err := h.recoveryService.GenerateCodes(c.Context(), user)
+	if err != nil {
+		h.logger.Error(c.Context(), "Failed to generate recovery codes", logger.F("error", err), logger.F("user_id", user.ID))
+		return c.Status(http.StatusInternalServerError).JSON(dto.ErrorResponse{
+			Error:   "Failed to generate recovery codes",
+			Message: err.Error(),
+		})
+	}
+
+	return c.Status(http.StatusCreated).JSON(dto.RecoveryCodesResponse{
+		Codes:     codes,
+		Remaining: len(codes),
+	})
+}
+
+// GetCodesStatus returns how many recovery codes are left
+// @Summary Get recovery code status
+// @Description Get the number of unused recovery codes of the authenticated user
+// @Tags MFA
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} dto.RecoveryCodesStatusResponse "Recovery code status"
+// @Failure 401 {object} dto.ErrorResponse "Unauthorized - invalid or missing JWT token"
+// @Failure 500 {object} dto.ErrorResponse "Internal server error"
+// @Router /api/v1/users/mfa/recovery-codes [get]
+func (h *RecoveryHandler) GetCodesStatus(c *fiber.Ctx) error {
+	user := c.Locals("user").(*entities.User)
+
+	remaining, err := h.recoveryService.RemainingCodes(c.Context(), user)
+	if err != nil {
+		h.logger.Error(c.Context(), "Failed to count recovery codes", logger.F("error", err), logger.F("user_id", user.ID))
+		return c.Status(http.StatusInternalServerError).JSON(dto.ErrorResponse{
+			Error:   "Failed to get recovery codes",
+			Message: err.Error(),
+		})
+	}
+
+	return c.Status(http.StatusOK).JSON(dto.RecoveryCodesStatusResponse{Remaining: remaining})
+}
+
+// SendPhoneConfirmation sends a code to the phone number to confirm
+// @Summary Send phone confirmation OTP
+// @Description After a recovery code login the account is blocked until the user confirms a phone number they control. This sends a code to that number, which may be the old one or a new one not used by another account.
+// @Tags Users
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body dto.SendPhoneConfirmationRequest true "Phone number to confirm"
+// @Success 200 {object} dto.SendOTPResponse "OTP sent successfully"
+// @Failure 400 {object} dto.ErrorResponse "Invalid phone number or channel, or no confirmation pending"
+// @Failure 401 {object} dto.ErrorResponse "Unauthorized - invalid or missing JWT token"
+// @Failure 409 {object} dto.ErrorResponse "Phone number belongs to another user"
+// @Failure 429 {object} dto.ErrorResponse "Phone number locked out"
+// @Failure 500 {object} dto.ErrorResponse "Internal server error"
+// @Failure 502 {object} dto.ErrorResponse "OTP delivery provider failed"
+// @Router /api/v1/users/phone/confirm/send [post]
+func (h *RecoveryHandler) SendPhoneConfirmation(c *fiber.Ctx) error {
+	user := c.Locals("user").(*entities.User)
+
+	var req dto.SendPhoneConfirmationRequest
+	if err := c.BodyParser(&req); err != nil || req.PhoneNumber == "" {
+		return c.Status(http.StatusBadRequest).JSON(dto.ErrorResponse{
+			Error:   "Invalid request",
+			Message: "phone_number is required",
+		})
+	}
+
+	channel := entities.OTPChannel(req.Channel)
+	if channel != "" && !channel.IsValid() {
+		return c.Status(http.StatusBadRequest).JSON(dto.ErrorResponse{
+			Error:   "Invalid request",
+			Message: "channel must be one of sms, voice, whatsapp, email",
+		})
+	}
+
+	locales := lib.ParseAcceptLanguage(c.Get(fiber.HeaderAcceptLanguage))
+	if req.Locale != "" {
+		locales = append([]string{req.Locale}, locales...)
+	}
+
+	challenge, err := h.authService.SendPhoneConfirmationOTP(c.Context(), user, &entities.OTPRequest{
+		PhoneNumber: req.PhoneNumber,
+		Channel:     channel,
+		Locales:     locales,
+	})
+	if err != nil {
+		switch {
+		case errors.IsInvalidInput(err):
+			return c.Status(http.StatusBadRequest).JSON(dto.ErrorResponse{
+				Error:   "Invalid request",
+				Message: err.Error(),
+			})
+		case errors.IsAlreadyExists(err):
+			return c.Status(http.StatusConflict).JSON(dto.ErrorResponse{
+				Error:   "phone_number_taken",
+				Message: "Phone number belongs to another user",
+			})
+		case errors.IsOTPLocked(err):
+			return c.Status(http.StatusTooManyRequests).JSON(dto.ErrorResponse{
+				Error:   "otp_locked",
+				Message: err.Error(),
+			})
+		case errors.IsOTPDeliveryFailed(err):
+			h.logger.Error(c.Context(), "Failed to deliver phone confirmation OTP", logger.F("error", err), logger.F("user_id", user.ID))
+			return c.Status(http.StatusBadGateway).JSON(dto.ErrorResponse{
+				Error:   "delivery_failed",
+				Message: "Could not deliver the OTP, please try again",
+			})
+		}
+
+		h.logger.Error(c.Context(), "Failed to send phone confirmation OTP", logger.F("error", err), logger.F("user_id", user.ID))
+		return c.Status(http.StatusInternalServerError).JSON(dto.ErrorResponse{
+			Error:   "Failed to send OTP",
+			Message: err.Error(),
+		})
+	}
+
+	return c.Status(http.StatusOK).JSON(dto.SendOTPResponse{
+		Message:        "OTP sent successfully",
+		VerificationID: challenge.ID,
+		ExpiresIn:      int(time.Until(challenge.ExpiresAt).Seconds()),
+		Channel:        string(challenge.Channel),
+		Locale:         challenge.Locale,
+		PhoneNumber:    lib.MaskPhoneNumber(challenge.PhoneNumber),
+		Timestamp:      c.Get("Date"),
+	})
+}
+
+// ConfirmPhone confirms the phone number with the code sent to it
+// @Summary Confirm phone number
+// @Description Confirm the phone number with the code sent to it. The number becomes the user's phone number, the account is unblocked and a new token carrying the number is returned.
+// @Tags Users
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body dto.ConfirmPhoneNumberRequest true "Verification ID and code"
+// @Success 200 {object} dto.AuthResponse "Phone number confirmed"
+// @Failure 400 {object} dto.ErrorResponse "Invalid request or no confirmation pending"
+// @Failure 401 {object} dto.ErrorResponse "Invalid or expired OTP"
+// @Failure 409 {object} dto.ErrorResponse "Phone number belongs to another user"
+// @Failure 429 {object} dto.ErrorResponse "Phone number locked out"
+// @Failure 500 {object} dto.ErrorResponse "Internal server error"
+// @Router /api/v1/users/phone/confirm/verify [post]
+func (h *RecoveryHandler) ConfirmPhone(c *fiber.Ctx) error {
+	user := c.Locals("user").(*entities.User)
+
+	var req dto.ConfirmPhoneNumberRequest
+	if err := c.BodyParser(&req); err != nil || req.VerificationID == "" || req.OTP == "" {
+		return c.Status(http.StatusBadRequest).JSON(dto.ErrorResponse{
+			Error:   "Invalid request",
+			Message: "verification_id and otp are required",
+		})
+	}
+
+	result, err := h.authService.ConfirmPhoneNumber(c.Context(), user, req.VerificationID, req.OTP, deviceInfo(c, ""))
+	if err != nil {
+		switch {
+		case errors.IsInvalidInput(err):
+			return c.Status(http.StatusBadRequest).JSON(dto.ErrorResponse{
+				Error:   "Invalid request",
+				Message: err.Error(),
+			})
+		case errors.IsOTPInvalid(err):
+			return c.Status(http.StatusUnauthorized).JSON(dto.ErrorResponse{
+				Error:   "Invalid OTP",
+				Message: err.Error(),
+			})
+		case errors.IsAlreadyExists(err):
+			return c.Status(http.StatusConflict).JSON(dto.ErrorResponse{
+				Error:   "phone_number_taken",
+				Message: "Phone number belongs to another user",
+			})
+		case errors.IsOTPLocked(err):
+			return c.Status(http.StatusTooManyRequests).JSON(dto.ErrorResponse{
+				Error:   "otp_locked",
+				Message: err.Error(),
+			})
+		}
+
+		h.logger.Error(c.Context(), "Failed to confirm phone number", logger.F("error", err), logger.F("user_id", user.ID))
+		return c.Status(http.StatusInternalServerError).JSON(dto.ErrorResponse{
+			Error:   "Failed to confirm phone number",
+			Message: err.Error(),
+		})
+	}
+
+	return c.Status(http.StatusOK).JSON(authResponse(result))
+}
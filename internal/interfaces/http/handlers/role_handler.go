@@ -0,0 +1,152 @@
+package handlers
+
+import (
+	"net/http"
+
+	"otp-server/internal/application"
+	"otp-server/internal/domain/entities"
+	"otp-server/internal/domain/errors"
+	"otp-server/internal/infrastructure/logger"
+	"otp-server/internal/interfaces/http/handlers/dto"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// RoleHandler handles the administration of roles and their permissions
+type RoleHandler struct {
+	roleService application.RoleServiceInterface
+	logger      logger.Logger
+}
+
+// NewRoleHandler creates a new role handler
+func NewRoleHandler(roleService application.RoleServiceInterface, logger logger.Logger) *RoleHandler {
+	return &RoleHandler{
+		roleService: roleService,
+		logger:      logger,
+	}
+}
+
+// ListRoles lists the roles
+// @Summary List roles
+// @Description List every role with the permissions it grants. Roles are shared by all tenants. Users with the roles:read permission only.
+// @Tags Admin
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} dto.RolesResponse "Roles"
+// @Failure 401 {object} dto.ErrorResponse "Unauthorized - invalid or missing JWT token"
+// @Failure 403 {object} dto.ErrorResponse "The roles:read permission required"
+// @Failure 500 {object} dto.ErrorResponse "Internal server error"
+// @Router /api/v1/admin/roles [get]
+func (h *RoleHandler) ListRoles(c *fiber.Ctx) error {
+	roles, err := h.roleService.ListRoles(c.Context())
+	if err != nil {
+		h.logger.Error(c.Context(), "Failed to list roles", logger.F("error", err))
+		return c.Status(http.StatusInternalServerError).JSON(dto.ErrorResponse{
+			Error:   "Failed to list roles",
+			Message: err.Error(),
+		})
+	}
+
+	response := dto.RolesResponse{Roles: make([]dto.RoleResponse, 0, len(roles))}
+	for _, role := range roles {
+		response.Roles = append(response.Roles, roleResponse(role))
+	}
+
+	return c.Status(http.StatusOK).JSON(response)
+}
+
+// ListPermissions lists the permissions
+// @Summary List permissions
+// @Description List every permission roles can grant. Users with the roles:read permission only.
+// @Tags Admin
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} dto.PermissionsResponse "Permissions"
+// @Failure 401 {object} dto.ErrorResponse "Unauthorized - invalid or missing JWT token"
+// @Failure 403 {object} dto.ErrorResponse "The roles:read permission required"
+// @Failure 500 {object} dto.ErrorResponse "Internal server error"
+// @Router /api/v1/admin/permissions [get]
+func (h *RoleHandler) ListPermissions(c *fiber.Ctx) error {
+	permissions, err := h.roleService.ListPermissions(c.Context())
+	if err != nil {
+		h.logger.Error(c.Context(), "Failed to list permissions", logger.F("error", err))
+		return c.Status(http.StatusInternalServerError).JSON(dto.ErrorResponse{
+			Error:   "Failed to list permissions",
+			Message: err.Error(),
+		})
+	}
+
+	response := dto.PermissionsResponse{Permissions: make([]dto.PermissionResponse, 0, len(permissions))}
+	for _, permission := range permissions {
+		response.Permissions = append(response.Permissions, dto.PermissionResponse{
+			Name:        permission.Name,
+			Description: permission.Description,
+		})
+	}
+
+	return c.Status(http.StatusOK).JSON(response)
+}
+
+// SaveRole creates or updates a role
+// @Summary Create or update role
+// @Description Create a role, or replace the description and permissions of an existing one. Changes reach every instance within RBAC_RELOAD_INTERVAL. The admin role cannot be changed. Users of the default tenant with the roles:write permission only.
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param name path string true "Role name"
+// @Param request body dto.RoleRequest true "Role description and permissions"
+// @Success 200 {object} dto.RoleResponse "Role saved"
+// @Failure 400 {object} dto.ErrorResponse "Invalid role name or unknown permission"
+// @Failure 401 {object} dto.ErrorResponse "Unauthorized - invalid or missing JWT token"
+// @Failure 403 {object} dto.ErrorResponse "The roles:write permission within the default tenant required"
+// @Failure 500 {object} dto.ErrorResponse "Internal server error"
+// @Router /api/v1/admin/roles/{name} [put]
+func (h *RoleHandler) SaveRole(c *fiber.Ctx) error {
+	var req dto.RoleRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(http.StatusBadRequest).JSON(dto.ErrorResponse{
+			Error:   "Invalid request",
+			Message: err.Error(),
+		})
+	}
+
+	role := &entities.Role{
+		Name:        entities.UserRole(c.Params("name")),
+		Description: req.Description,
+		Permissions: req.Permissions,
+	}
+
+	if err := h.roleService.SaveRole(c.Context(), role); err != nil {
+		if errors.IsInvalidInput(err) {
+			return c.Status(http.StatusBadRequest).JSON(dto.ErrorResponse{
+				Error:   "Invalid request",
+				Message: err.Error(),
+			})
+		}
+
+		h.logger.Error(c.Context(), "Failed to save role", logger.F("error", err), logger.F("role", role.Name))
+		return c.Status(http.StatusInternalServerError).JSON(dto.ErrorResponse{
+			Error:   "Failed to save role",
+			Message: err.Error(),
+		})
+	}
+
+	return c.Status(http.StatusOK).JSON(roleResponse(role))
+}
+
+func roleResponse(role *entities.Role) dto.RoleResponse {
+	permissions := role.Permissions
+	if permissions == nil {
+		permissions = []string{}
+	}
+
+	return dto.RoleResponse{
+		ID:          role.ID,
+		Name:        string(role.Name),
+		Description: role.Description,
+		Permissions: permissions,
+		CreatedAt:   role.CreatedAt,
+		UpdatedAt:   role.UpdatedAt,
+	}
+}
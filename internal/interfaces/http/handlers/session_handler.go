@@ -0,0 +1,102 @@
+package handlers
+
+import (
+	"net/http"
+
+	"otp-server/internal/application"
+	"otp-server/internal/domain/entities"
+	"otp-server/internal/domain/errors"
+	"otp-server/internal/infrastructure/logger"
+	"otp-server/internal/interfaces/http/handlers/dto"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// SessionHandler handles session and device management requests
+type SessionHandler struct {
+	sessionService application.SessionServiceInterface
+	logger         logger.Logger
+}
+
+// NewSessionHandler creates a new session handler
+func NewSessionHandler(sessionService application.SessionServiceInterface, logger logger.Logger) *SessionHandler {
+	return &SessionHandler{
+		sessionService: sessionService,
+		logger:         logger,
+	}
+}
+
+// ListSessions lists where the user is logged in
+// @Summary List sessions
+// @Description List the active sessions of the authenticated user, one per logged in device, newest first. The session of the request is marked as current.
+// @Tags Users
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} dto.SessionsResponse "Active sessions"
+// @Failure 401 {object} dto.ErrorResponse "Unauthorized - invalid or missing JWT token"
+// @Failure 500 {object} dto.ErrorResponse "Internal server error"
+// @Router /api/v1/users/sessions [get]
+func (h *SessionHandler) ListSessions(c *fiber.Ctx) error {
+	user := c.Locals("user").(*entities.User)
+
+	sessions, err := h.sessionService.ListSessions(c.Context(), user)
+	if err != nil {
+		h.logger.Error(c.Context(), "Failed to list sessions", logger.F("error", err), logger.F("user_id", user.ID))
+		return c.Status(http.StatusInternalServerError).JSON(dto.ErrorResponse{
+			Error:   "Failed to list sessions",
+			Message: err.Error(),
+		})
+	}
+
+	current := h.sessionService.CurrentSessionID(c.Locals("token").(string))
+
+	response := dto.SessionsResponse{Sessions: make([]dto.SessionResponse, 0, len(sessions))}
+	for _, session := range sessions {
+		response.Sessions = append(response.Sessions, dto.SessionResponse{
+			ID:         session.ID,
+			DeviceName: session.DeviceName,
+			UserAgent:  session.UserAgent,
+			IPAddress:  session.IPAddress,
+			ClientID:   session.ClientID,
+			Current:    session.ID == current,
+			CreatedAt:  session.CreatedAt,
+			LastUsedAt: session.LastUsedAt,
+			ExpiresAt:  session.ExpiresAt,
+		})
+	}
+
+	return c.Status(http.StatusOK).JSON(response)
+}
+
+// RevokeSession logs out one of the user's sessions
+// @Summary Revoke session
+// @Description Log out one of the authenticated user's sessions, for example a lost device. Its access and refresh tokens stop working at once.
+// @Tags Users
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Session ID"
+// @Success 204 "Session revoked"
+// @Failure 401 {object} dto.ErrorResponse "Unauthorized - invalid or missing JWT token"
+// @Failure 404 {object} dto.ErrorResponse "Session not found"
+// @Failure 500 {object} dto.ErrorResponse "Internal server error"
+// @Router /api/v1/users/sessions/{id} [delete]
+func (h *SessionHandler) RevokeSession(c *fiber.Ctx) error {
+	user := c.Locals("user").(*entities.User)
+
+	if err := h.sessionService.RevokeSession(c.Context(), user, c.Params("id")); err != nil {
+		if errors.IsNotFound(err) {
+			return c.Status(http.StatusNotFound).JSON(dto.ErrorResponse{
+				Error:   "Session not found",
+				Message: err.Error(),
+			})
+		}
+
+		h.logger.Error(c.Context(), "Failed to revoke session", logger.F("error", err), logger.F("user_id", user.ID))
+		return c.Status(http.StatusInternalServerError).JSON(dto.ErrorResponse{
+			Error:   "Failed to revoke session",
+			Message: err.Error(),
+		})
+	}
+
+	return c.SendStatus(http.StatusNoContent)
+}
@@ -0,0 +1,184 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"otp-server/internal/application"
+	"otp-server/internal/domain/entities"
+	"otp-server/internal/domain/errors"
+	"otp-server/internal/infrastructure/logger"
+	"otp-server/internal/interfaces/http/handlers/dto"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// TenantHandler handles the administration of tenants
+type TenantHandler struct {
+	tenantService application.TenantServiceInterface
+	logger        logger.Logger
+}
+
+// NewTenantHandler creates a new tenant handler
+func NewTenantHandler(tenantService application.TenantServiceInterface, logger logger.Logger) *TenantHandler {
+	return &TenantHandler{
+		tenantService: tenantService,
+		logger:        logger,
+	}
+}
+
+// CreateTenant creates a new tenant
+// @Summary Create tenant
+// @Description Create a tenant with its own users, OTP settings, message templates and rate limits. Requests are resolved to it by the X-Tenant header or one of its hosts. Users of the default tenant with the tenants:write permission only.
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body dto.TenantRequest true "Tenant to create"
+// @Success 201 {object} dto.TenantResponse "Tenant created"
+// @Failure 400 {object} dto.ErrorResponse "Invalid slug, name, host or setting"
+// @Failure 401 {object} dto.ErrorResponse "Unauthorized - invalid or missing JWT token"
+// @Failure 403 {object} dto.ErrorResponse "The tenants:write permission within the default tenant required"
+// @Failure 409 {object} dto.ErrorResponse "Slug already taken"
+// @Failure 500 {object} dto.ErrorResponse "Internal server error"
+// @Router /api/v1/admin/tenants [post]
+func (h *TenantHandler) CreateTenant(c *fiber.Ctx) error {
+	var req dto.TenantRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(http.StatusBadRequest).JSON(dto.ErrorResponse{
+			Error:   "Invalid request",
+			Message: err.Error(),
+		})
+	}
+
+	tenant := &entities.Tenant{
+		Slug:     req.Slug,
+		Name:     req.Name,
+		Hosts:    req.Hosts,
+		Settings: req.Settings,
+		IsActive: req.IsActive == nil || *req.IsActive,
+	}
+
+	if err := h.tenantService.CreateTenant(c.Context(), tenant); err != nil {
+		return h.tenantError(c, err, "Failed to create tenant")
+	}
+
+	return c.Status(http.StatusCreated).JSON(tenantResponse(tenant))
+}
+
+// ListTenants lists the tenants
+// @Summary List tenants
+// @Description List every tenant, including deactivated ones. Users of the default tenant with the tenants:read permission only.
+// @Tags Admin
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} dto.TenantsResponse "Tenants"
+// @Failure 401 {object} dto.ErrorResponse "Unauthorized - invalid or missing JWT token"
+// @Failure 403 {object} dto.ErrorResponse "The tenants:read permission within the default tenant required"
+// @Failure 500 {object} dto.ErrorResponse "Internal server error"
+// @Router /api/v1/admin/tenants [get]
+func (h *TenantHandler) ListTenants(c *fiber.Ctx) error {
+	tenants, err := h.tenantService.ListTenants(c.Context())
+	if err != nil {
+		h.logger.Error(c.Context(), "Failed to list tenants", logger.F("error", err))
+		return c.Status(http.StatusInternalServerError).JSON(dto.ErrorResponse{
+			Error:   "Failed to list tenants",
+			Message: err.Error(),
+		})
+	}
+
+	response := dto.TenantsResponse{Tenants: make([]dto.TenantResponse, 0, len(tenants))}
+	for _, tenant := range tenants {
+		response.Tenants = append(response.Tenants, tenantResponse(tenant))
+	}
+
+	return c.Status(http.StatusOK).JSON(response)
+}
+
+// UpdateTenant updates a tenant
+// @Summary Update tenant
+// @Description Replace the name, hosts, setting overrides and status of a tenant. Changes reach every instance within TENANT_RELOAD_INTERVAL. Users of the default tenant with the tenants:write permission only.
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Tenant ID"
+// @Param request body dto.TenantRequest true "Tenant settings"
+// @Success 200 {object} dto.TenantResponse "Tenant updated"
+// @Failure 400 {object} dto.ErrorResponse "Invalid name, host or setting"
+// @Failure 401 {object} dto.ErrorResponse "Unauthorized - invalid or missing JWT token"
+// @Failure 403 {object} dto.ErrorResponse "The tenants:write permission within the default tenant required"
+// @Failure 404 {object} dto.ErrorResponse "Tenant not found"
+// @Failure 500 {object} dto.ErrorResponse "Internal server error"
+// @Router /api/v1/admin/tenants/{id} [put]
+func (h *TenantHandler) UpdateTenant(c *fiber.Ctx) error {
+	tenantID, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(dto.ErrorResponse{
+			Error:   "Invalid request",
+			Message: "id must be a number",
+		})
+	}
+
+	var req dto.TenantRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(http.StatusBadRequest).JSON(dto.ErrorResponse{
+			Error:   "Invalid request",
+			Message: err.Error(),
+		})
+	}
+
+	tenant := &entities.Tenant{
+		ID:       tenantID,
+		Name:     req.Name,
+		Hosts:    req.Hosts,
+		Settings: req.Settings,
+		IsActive: req.IsActive == nil || *req.IsActive,
+	}
+
+	if err := h.tenantService.UpdateTenant(c.Context(), tenant); err != nil {
+		return h.tenantError(c, err, "Failed to update tenant")
+	}
+
+	return c.Status(http.StatusOK).JSON(tenantResponse(tenant))
+}
+
+// tenantError responds with the status matching an error of the tenant service
+func (h *TenantHandler) tenantError(c *fiber.Ctx, err error, message string) error {
+	switch {
+	case errors.IsInvalidInput(err):
+		return c.Status(http.StatusBadRequest).JSON(dto.ErrorResponse{
+			Error:   "Invalid request",
+			Message: err.Error(),
+		})
+	case errors.IsAlreadyExists(err):
+		return c.Status(http.StatusConflict).JSON(dto.ErrorResponse{
+			Error:   "Tenant already exists",
+			Message: err.Error(),
+		})
+	case errors.IsNotFound(err):
+		return c.Status(http.StatusNotFound).JSON(dto.ErrorResponse{
+			Error:   "Tenant not found",
+			Message: err.Error(),
+		})
+	}
+
+	h.logger.Error(c.Context(), message, logger.F("error", err))
+	return c.Status(http.StatusInternalServerError).JSON(dto.ErrorResponse{
+		Error:   message,
+		Message: err.Error(),
+	})
+}
+
+func tenantResponse(tenant *entities.Tenant) dto.TenantResponse {
+	return dto.TenantResponse{
+		ID:        tenant.ID,
+		Slug:      tenant.Slug,
+		Name:      tenant.Name,
+		Hosts:     tenant.Hosts,
+		Settings:  tenant.Settings,
+		IsActive:  tenant.IsActive,
+		CreatedAt: tenant.CreatedAt,
+		UpdatedAt: tenant.UpdatedAt,
+	}
+}
@@ -53,7 +53,8 @@ func (h *UserHandler) GetProfile(c *fiber.Ctx) error {
 		PhoneNumber: user.PhoneNumber,
 		Name:        user.Name,
 		Role:        string(user.Role),
-		IsActive:    user.IsActive,
+		IsActive:    user.IsActive(),
+		Status:      string(user.CurrentStatus()),
 		CreatedAt:   user.CreatedAt,
 		UpdatedAt:   user.UpdatedAt,
 	})
@@ -61,7 +62,7 @@ func (h *UserHandler) GetProfile(c *fiber.Ctx) error {
 
 // UpdateProfile updates the current user's profile
 // @Summary Update User Profile
-// @Description Update the profile information of the currently authenticated user
+// @Description Update the profile information of the currently authenticated user. The phone number is changed with /api/v1/users/phone/change instead.
 // @Tags Users
 // @Accept json
 // @Produce json
@@ -97,7 +98,8 @@ func (h *UserHandler) UpdateProfile(c *fiber.Ctx) error {
 		PhoneNumber: user.PhoneNumber,
 		Name:        user.Name,
 		Role:        string(user.Role),
-		IsActive:    user.IsActive,
+		IsActive:    user.IsActive(),
+		Status:      string(user.CurrentStatus()),
 		CreatedAt:   user.CreatedAt,
 		UpdatedAt:   user.UpdatedAt,
 	})
@@ -105,17 +107,20 @@ func (h *UserHandler) UpdateProfile(c *fiber.Ctx) error {
 
 // SearchUsers is a unified endpoint that handles both search and pagination
 // @Summary Get Users Unified
-// @Description Get users with optional search and pagination in a single endpoint
+// @Description Get users with optional search and pagination in a single endpoint. Open to API clients with the users:read or users:admin scope.
 // @Tags Users
 // @Accept json
 // @Produce json
 // @Security BearerAuth
+// @Security ApiKeyAuth
 // @Param query query string false "Search query (optional)"
 // @Param offset query int false "Pagination offset (default: 0)"
 // @Param limit query int false "Pagination limit (default: 10, max: 100)"
 // @Success 200 {object} dto.UnifiedUsersResponse "Users retrieved successfully"
 // @Failure 400 {object} dto.ErrorResponse "Invalid parameters"
-// @Failure 401 {object} dto.ErrorResponse "Unauthorized - invalid or missing JWT token"
+// @Failure 401 {object} dto.ErrorResponse "Unauthorized - invalid or missing JWT token or API key"
+// @Failure 403 {object} dto.ErrorResponse "The users:read scope required"
+// @Failure 429 {object} dto.ErrorResponse "Rate limit exceeded"
 // @Failure 500 {object} dto.ErrorResponse "Internal server error"
 // @Router /api/v1/users/search [get]
 func (h *UserHandler) SearchUsers(c *fiber.Ctx) error {
@@ -161,7 +166,8 @@ func (h *UserHandler) SearchUsers(c *fiber.Ctx) error {
 			PhoneNumber: user.PhoneNumber,
 			Name:        user.Name,
 			Role:        string(user.Role),
-			IsActive:    user.IsActive,
+			IsActive:    user.IsActive(),
+			Status:      string(user.CurrentStatus()),
 			CreatedAt:   user.CreatedAt,
 			UpdatedAt:   user.UpdatedAt,
 		}
@@ -0,0 +1,78 @@
+package handlers
+
+import (
+	"net/http"
+
+	"otp-server/internal/application"
+	"otp-server/internal/domain/errors"
+	"otp-server/internal/infrastructure/logger"
+	"otp-server/internal/interfaces/http/handlers/dto"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// WebhookHandler handles callbacks posted by external providers
+type WebhookHandler struct {
+	receiptService application.DeliveryReceiptServiceInterface
+	logger         logger.Logger
+}
+
+// NewWebhookHandler creates a new webhook handler
+func NewWebhookHandler(receiptService application.DeliveryReceiptServiceInterface, logger logger.Logger) *WebhookHandler {
+	return &WebhookHandler{
+		receiptService: receiptService,
+		logger:         logger,
+	}
+}
+
+// DeliveryReceipt ingests delivery receipts posted by a delivery provider
+// @Summary Delivery receipt webhook
+// @Description Receive delivery receipts (DLRs) for OTP messages. The request must carry the provider's signature; receipts update the delivery status of the matching OTP challenge.
+// @Tags Webhooks
+// @Accept json
+// @Produce json
+// @Param provider path string true "Delivery provider name" example(http)
+// @Success 200 {object} dto.DeliveryReceiptResponse "Receipts accepted"
+// @Failure 400 {object} dto.ErrorResponse "Malformed receipt"
+// @Failure 401 {object} dto.ErrorResponse "Invalid signature"
+// @Failure 404 {object} dto.ErrorResponse "Provider not configured or does not send receipts"
+// @Failure 500 {object} dto.ErrorResponse "Internal server error"
+// @Router /api/v1/webhooks/delivery/{provider} [post]
+func (h *WebhookHandler) DeliveryReceipt(c *fiber.Ctx) error {
+	provider := c.Params("provider")
+
+	header := func(key string) string { return c.Get(key) }
+
+	result, err := h.receiptService.HandleReceipts(c.Context(), provider, header, c.Body())
+	if err != nil {
+		switch {
+		case errors.IsNotFound(err):
+			return c.Status(http.StatusNotFound).JSON(dto.ErrorResponse{
+				Error:   "Not found",
+				Message: "Delivery provider not found",
+			})
+		case errors.IsUnauthorized(err):
+			h.logger.Warn(c.Context(), "Rejected delivery receipt", logger.F("provider", provider), logger.F("error", err))
+			return c.Status(http.StatusUnauthorized).JSON(dto.ErrorResponse{
+				Error:   "Unauthorized",
+				Message: "Invalid signature",
+			})
+		case errors.IsInvalidInput(err):
+			return c.Status(http.StatusBadRequest).JSON(dto.ErrorResponse{
+				Error:   "Invalid request",
+				Message: err.Error(),
+			})
+		}
+
+		h.logger.Error(c.Context(), "Failed to process delivery receipt", logger.F("provider", provider), logger.F("error", err))
+		return c.Status(http.StatusInternalServerError).JSON(dto.ErrorResponse{
+			Error:   "Internal server error",
+			Message: "Failed to process delivery receipt",
+		})
+	}
+
+	return c.Status(http.StatusOK).JSON(dto.DeliveryReceiptResponse{
+		Processed: result.Processed,
+		Ignored:   result.Ignored,
+	})
+}
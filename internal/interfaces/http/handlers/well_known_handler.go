@@ -0,0 +1,38 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+
+	"otp-server/internal/application"
+	"otp-server/internal/infrastructure/logger"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// WellKnownHandler serves the /.well-known documents other services use to
+// verify our tokens
+type WellKnownHandler struct {
+	keyring application.KeyringInterface
+	logger  logger.Logger
+}
+
+// NewWellKnownHandler creates a new well-known handler
+func NewWellKnownHandler(keyring application.KeyringInterface, logger logger.Logger) *WellKnownHandler {
+	return &WellKnownHandler{
+		keyring: keyring,
+		logger:  logger,
+	}
+}
+
+// JWKS serves the public keys that verify access tokens
+// @Summary JSON Web Key Set
+// @Description Public keys verifying the access tokens, identified by the kid header of each token. Includes the signing key, its announced successor and retired keys whose tokens may still be valid. Empty when tokens are signed with HS256.
+// @Tags System
+// @Produce json
+// @Success 200 {object} keyring.JWKSet "Public keys"
+// @Router /.well-known/jwks.json [get]
+func (h *WellKnownHandler) JWKS(c *fiber.Ctx) error {
+	c.Set(fiber.HeaderCacheControl, fmt.Sprintf("public, max-age=%d", int(h.keyring.CacheMaxAge().Seconds())))
+	return c.Status(http.StatusOK).JSON(h.keyring.JWKS())
+}
@@ -4,9 +4,11 @@ import (
 	"context"
 	"net/http"
 	"strconv"
+	"strings"
 	"time"
 
 	"otp-server/internal/application"
+	"otp-server/internal/domain/entities"
 	"otp-server/internal/infrastructure/config"
 	"otp-server/internal/infrastructure/logger"
 	"otp-server/internal/infrastructure/metrics"
@@ -17,11 +19,14 @@ import (
 
 // Middleware holds all middleware functions
 type Middleware struct {
-	authService application.AuthServiceInterface
-	config      *config.Config
-	logger      logger.Logger
-	redisClient *redis.Client
-	metrics     *metrics.MetricsService
+	authService      application.AuthServiceInterface
+	apiClientService application.APIClientServiceInterface
+	tenantService    application.TenantServiceInterface
+	roleService      application.RoleServiceInterface
+	config           *config.Config
+	logger           logger.Logger
+	redisClient      *redis.Client
+	metrics          *metrics.MetricsService
 }
 
 // NewMiddleware creates a new middleware instance
@@ -38,6 +43,21 @@ func (m *Middleware) SetAuthService(authService application.AuthServiceInterface
 	m.authService = authService
 }
 
+// SetAPIClientService sets the API client service for middleware
+func (m *Middleware) SetAPIClientService(apiClientService application.APIClientServiceInterface) {
+	m.apiClientService = apiClientService
+}
+
+// SetTenantService sets the tenant service for middleware
+func (m *Middleware) SetTenantService(tenantService application.TenantServiceInterface) {
+	m.tenantService = tenantService
+}
+
+// SetRoleService sets the role service for middleware
+func (m *Middleware) SetRoleService(roleService application.RoleServiceInterface) {
+	m.roleService = roleService
+}
+
 // SetMetricsService sets the metrics service for middleware
 func (m *Middleware) SetMetricsService(metricsService *metrics.MetricsService) {
 	m.metrics = metricsService
@@ -53,6 +73,11 @@ func (m *Middleware) GetRedisClient() *redis.Client {
 	return m.redisClient
 }
 
+// GetTenantService returns the tenant service instance
+func (m *Middleware) GetTenantService() application.TenantServiceInterface {
+	return m.tenantService
+}
+
 // GetMetricsService returns the metrics service instance
 func (m *Middleware) GetMetricsService() *metrics.MetricsService {
 	return m.metrics
@@ -63,7 +88,7 @@ func (m *Middleware) CORS() fiber.Handler {
 	return func(c *fiber.Ctx) error {
 		c.Set("Access-Control-Allow-Origin", "*")
 		c.Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-		c.Set("Access-Control-Allow-Headers", "Content-Type, Authorization, X-Requested-With")
+		c.Set("Access-Control-Allow-Headers", "Content-Type, Authorization, X-Requested-With, "+m.config.Tenants.Header)
 
 		if c.Method() == http.MethodOptions {
 			return c.SendStatus(http.StatusNoContent)
@@ -87,9 +112,52 @@ func (m *Middleware) SecurityHeaders() fiber.Handler {
 	}
 }
 
-// Auth middleware for JWT token authentication
-func (m *Middleware) Auth() fiber.Handler {
+// Tenant resolves the tenant of the request from the tenant header, then
+// the host, and makes it available to the handlers and services after it.
+// Requests naming an unknown or deactivated tenant are rejected.
+func (m *Middleware) Tenant() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if m.tenantService == nil {
+			return c.Next()
+		}
+
+		tenant, err := m.tenantService.Resolve(c.Hostname(), c.Get(m.config.Tenants.Header))
+		if err != nil {
+			return c.Status(http.StatusNotFound).JSON(map[string]interface{}{
+				"error":   "unknown_tenant",
+				"message": "The request names no known tenant",
+			})
+		}
+
+		setTenant(c, tenant)
+		return c.Next()
+	}
+}
+
+// setTenant makes the tenant the one of the request
+func setTenant(c *fiber.Ctx, tenant *entities.Tenant) {
+	c.Locals("tenant", tenant)
+	c.SetUserContext(entities.WithTenant(c.UserContext(), tenant))
+}
+
+// Auth middleware for JWT token authentication. Routes that list scopes
+// are open to API clients holding every one of them as well, by an
+// X-API-Key header or an access token of the client credentials grant;
+// users are let through whatever the scopes.
+func (m *Middleware) Auth(scopes ...string) fiber.Handler {
 	return func(c *fiber.Ctx) error {
+		if apiKey := c.Get("X-API-Key"); apiKey != "" && len(scopes) > 0 {
+			client, err := m.apiClientService.AuthenticateKey(c.UserContext(), apiKey)
+			if err != nil {
+				return c.Status(http.StatusUnauthorized).JSON(map[string]interface{}{
+					"error":   "Invalid API key",
+					"message": err.Error(),
+				})
+			}
+
+			return m.authenticateClient(c, client, strings.Join(client.Scopes, " "), scopes)
+		}
+
 		authHeader := c.Get("Authorization")
 		if authHeader == "" {
 			return c.Status(http.StatusUnauthorized).JSON(map[string]interface{}{
@@ -107,6 +175,18 @@ func (m *Middleware) Auth() fiber.Handler {
 
 		tokenString := authHeader[7:]
 
+		if len(scopes) > 0 && m.apiClientService.IsClientToken(tokenString) {
+			client, scope, err := m.apiClientService.GetClientFromToken(c.UserContext(), tokenString)
+			if err != nil {
+				return c.Status(http.StatusUnauthorized).JSON(map[string]interface{}{
+					"error":   "Invalid token",
+					"message": err.Error(),
+				})
+			}
+
+			return m.authenticateClient(c, client, scope, scopes)
+		}
+
 		user, err := m.authService.GetUserFromToken(tokenString)
 		if err != nil {
 			return c.Status(http.StatusUnauthorized).JSON(map[string]interface{}{
@@ -115,8 +195,26 @@ func (m *Middleware) Auth() fiber.Handler {
 			})
 		}
 
+		// Users only exist within their own tenant
+		if user.TenantID != entities.TenantIDFromContext(c.UserContext()) {
+			return c.Status(http.StatusUnauthorized).JSON(map[string]interface{}{
+				"error":   "Invalid token",
+				"message": "token was issued for another tenant",
+			})
+		}
+
+		// The role is looked up rather than read from the token, so role
+		// changes apply to tokens already issued
+		role, err := m.roleService.GetRole(user.Role)
+		if err != nil {
+			m.logger.Warn(c.UserContext(), "User holds an unknown role", logger.F("user_id", user.ID), logger.F("role", user.Role))
+			role = &entities.Role{Name: user.Role}
+		}
+
 		c.Locals("user", user)
 		c.Locals("user_id", user.ID)
+		c.Locals("role", role)
+		c.Locals("token", tokenString)
 
 		if uc := c.UserContext(); uc != nil {
 			c.SetUserContext(context.WithValue(uc, "user", user))
@@ -126,6 +224,112 @@ func (m *Middleware) Auth() fiber.Handler {
 	}
 }
 
+// authenticateClient lets an API client through if it was granted every
+// scope the route requires. The request acts within the client's tenant,
+// unless it explicitly names another one.
+func (m *Middleware) authenticateClient(c *fiber.Ctx, client *entities.APIClient, granted string, scopes []string) error {
+	if !entities.ScopeIncludes(granted, scopes...) {
+		return c.Status(http.StatusForbidden).JSON(map[string]interface{}{
+			"error":   "insufficient_scope",
+			"message": "The client requires the scopes: " + strings.Join(scopes, " "),
+		})
+	}
+
+	if m.tenantService != nil && client.TenantID != entities.TenantIDFromContext(c.UserContext()) {
+		tenant, err := m.tenantService.GetTenant(client.TenantID)
+		if err != nil || !tenant.IsActive || c.Get(m.config.Tenants.Header) != "" {
+			return c.Status(http.StatusForbidden).JSON(map[string]interface{}{
+				"error":   "tenant_mismatch",
+				"message": "The client may only act within its own tenant",
+			})
+		}
+		setTenant(c, tenant)
+	}
+
+	c.Locals("api_client", client)
+	c.Locals("client_id", client.ClientID)
+
+	return c.Next()
+}
+
+// RequireConfirmedPhone blocks users who logged in with a recovery code until
+// they confirm a phone number. It must run after Auth.
+func (m *Middleware) RequireConfirmedPhone() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		user, ok := c.Locals("user").(*entities.User)
+		if ok && user.PhoneConfirmationRequired {
+			return c.Status(http.StatusForbidden).JSON(map[string]interface{}{
+				"error":   "phone_confirmation_required",
+				"message": "Confirm a phone number before using the API",
+			})
+		}
+
+		return c.Next()
+	}
+}
+
+// RequireRecentAuth restricts a route to users who authenticated within
+// maxAge, by logging in or by stepping up. Others are told to step up, with
+// the error of RFC 9470 in the WWW-Authenticate header. API clients have no
+// user to authenticate again and pass. It must run after Auth.
+func (m *Middleware) RequireRecentAuth(maxAge time.Duration) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if _, ok := c.Locals("api_client").(*entities.APIClient); ok {
+			return c.Next()
+		}
+
+		tokenString, _ := c.Locals("token").(string)
+		if time.Since(m.authService.AuthTime(tokenString)) <= maxAge {
+			return c.Next()
+		}
+
+		seconds := int(maxAge.Seconds())
+		c.Set(fiber.HeaderWWWAuthenticate, `Bearer error="insufficient_user_authentication", error_description="A more recent authentication is required", max_age=`+strconv.Itoa(seconds))
+		return c.Status(http.StatusUnauthorized).JSON(map[string]interface{}{
+			"error":   "step_up_required",
+			"message": "Authenticate again through /api/v1/auth/step-up to continue",
+			"max_age": seconds,
+		})
+	}
+}
+
+// RequirePermission restricts a route to users whose role grants every
+// permission. API clients were already checked for the scopes of the route
+// by Auth, after which it must run.
+func (m *Middleware) RequirePermission(permissions ...string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if _, ok := c.Locals("api_client").(*entities.APIClient); ok {
+			return c.Next()
+		}
+
+		role, ok := c.Locals("role").(*entities.Role)
+		if !ok || !role.HasPermission(permissions...) {
+			return c.Status(http.StatusForbidden).JSON(map[string]interface{}{
+				"error":   "Forbidden",
+				"message": "The permissions required: " + strings.Join(permissions, " "),
+			})
+		}
+
+		return c.Next()
+	}
+}
+
+// RequireDefaultTenant restricts a route to requests within the default
+// tenant, for the administration of the deployment as a whole. It must run
+// after Auth.
+func (m *Middleware) RequireDefaultTenant() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if entities.TenantIDFromContext(c.UserContext()) != entities.DefaultTenantID {
+			return c.Status(http.StatusForbidden).JSON(map[string]interface{}{
+				"error":   "Forbidden",
+				"message": "Access within the default tenant required",
+			})
+		}
+
+		return c.Next()
+	}
+}
+
 // RateLimit middleware for rate limiting requests using Redis
 func (m *Middleware) RateLimit() fiber.Handler {
 	return func(c *fiber.Ctx) error {
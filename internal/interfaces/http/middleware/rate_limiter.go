@@ -7,6 +7,8 @@ import (
 	"strings"
 	"time"
 
+	"otp-server/internal/application"
+	"otp-server/internal/domain/entities"
 	"otp-server/internal/infrastructure/config"
 	"otp-server/internal/infrastructure/logger"
 	"otp-server/internal/infrastructure/metrics"
@@ -17,23 +19,25 @@ import (
 )
 
 type RateLimiter struct {
-	config      *config.Config
-	logger      logger.Logger
-	redisClient *redis.Client
-	metrics     *metrics.MetricsService
+	config        *config.Config
+	tenantService application.TenantServiceInterface
+	logger        logger.Logger
+	redisClient   *redis.Client
+	metrics       *metrics.MetricsService
 }
 
 type RateLimitMiddleware struct {
 	rateLimiter *RateLimiter
 }
 
-func NewRateLimitMiddleware(cfg *config.Config, logger logger.Logger, redisClient *redis.Client, metricsService *metrics.MetricsService) *RateLimitMiddleware {
+func NewRateLimitMiddleware(cfg *config.Config, tenantService application.TenantServiceInterface, logger logger.Logger, redisClient *redis.Client, metricsService *metrics.MetricsService) *RateLimitMiddleware {
 	return &RateLimitMiddleware{
 		rateLimiter: &RateLimiter{
-			config:      cfg,
-			logger:      logger,
-			redisClient: redisClient,
-			metrics:     metricsService,
+			config:        cfg,
+			tenantService: tenantService,
+			logger:        logger,
+			redisClient:   redisClient,
+			metrics:       metricsService,
 		},
 	}
 }
@@ -54,6 +58,10 @@ func (rlm *RateLimitMiddleware) User() fiber.Handler {
 	return rlm.rateLimiter.UserRateLimit()
 }
 
+func (rlm *RateLimitMiddleware) Client() fiber.Handler {
+	return rlm.rateLimiter.ClientRateLimit()
+}
+
 func (rlm *RateLimitMiddleware) AddRateLimitHeaders() fiber.Handler {
 	return func(c *fiber.Ctx) error {
 		c.Next()
@@ -80,15 +88,16 @@ func (rlm *RateLimitMiddleware) AddRateLimitHeaders() fiber.Handler {
 func (rl *RateLimiter) GlobalRateLimit() fiber.Handler {
 	return func(c *fiber.Ctx) error {
 		clientIP := c.IP()
-		key := fmt.Sprintf("rate_limit:global:%s", clientIP)
+		key := fmt.Sprintf("rate_limit:global:%d:%s", entities.TenantIDFromContext(c.UserContext()), clientIP)
 
-		if rl.config == nil || rl.config.RateLimiting.Global.Requests == 0 {
+		limits := rl.limits(c.UserContext())
+		if limits.Global.Requests == 0 {
 			rl.logger.Error(c.UserContext(), "Rate limiting config not properly initialized")
 			return c.Next()
 		}
 
-		limit := rl.config.RateLimiting.Global.Requests
-		duration := rl.config.RateLimiting.Global.Duration
+		limit := limits.Global.Requests
+		duration := limits.Global.Duration
 
 		if err := rl.checkRateLimit(c.UserContext(), key, limit, duration, clientIP, "global"); err != nil {
 			c.Set("Retry-After", strconv.FormatInt(int64(duration.Seconds()), 10))
@@ -104,15 +113,16 @@ func (rl *RateLimiter) GlobalRateLimit() fiber.Handler {
 func (rl *RateLimiter) AuthRateLimit() fiber.Handler {
 	return func(c *fiber.Ctx) error {
 		clientIP := c.IP()
-		key := fmt.Sprintf("rate_limit:auth:%s", clientIP)
+		key := fmt.Sprintf("rate_limit:auth:%d:%s", entities.TenantIDFromContext(c.UserContext()), clientIP)
 
-		if rl.config == nil || rl.config.RateLimiting.Auth.Requests == 0 {
+		limits := rl.limits(c.UserContext())
+		if limits.Auth.Requests == 0 {
 			rl.logger.Error(c.UserContext(), "Rate limiting config not properly initialized")
 			return c.Next()
 		}
 
-		limit := rl.config.RateLimiting.Auth.Requests
-		duration := rl.config.RateLimiting.Auth.Duration
+		limit := limits.Auth.Requests
+		duration := limits.Auth.Duration
 
 		if err := rl.checkRateLimit(c.UserContext(), key, limit, duration, clientIP, "auth"); err != nil {
 			c.Set("Retry-After", strconv.FormatInt(int64(duration.Seconds()), 10))
@@ -135,14 +145,15 @@ func (rl *RateLimiter) OTPRateLimit() fiber.Handler {
 			return c.Status(400).JSON(fiber.Map{"error": "Invalid request"})
 		}
 
-		if rl.config == nil || rl.config.RateLimiting.OTP.Requests == 0 {
+		limits := rl.limits(c.UserContext())
+		if limits.OTP.Requests == 0 {
 			rl.logger.Error(c.UserContext(), "Rate limiting config not properly initialized")
 			return c.Next()
 		}
 
-		key := fmt.Sprintf("rate_limit:otp:%s", req.PhoneNumber)
-		limit := rl.config.RateLimiting.OTP.Requests
-		duration := rl.config.RateLimiting.OTP.Duration
+		key := fmt.Sprintf("rate_limit:otp:%d:%s", entities.TenantIDFromContext(c.UserContext()), req.PhoneNumber)
+		limit := limits.OTP.Requests
+		duration := limits.OTP.Duration
 
 		if err := rl.checkRateLimit(c.UserContext(), key, limit, duration, req.PhoneNumber, "otp"); err != nil {
 			c.Set("Retry-After", strconv.FormatInt(int64(duration.Seconds()), 10))
@@ -157,16 +168,22 @@ func (rl *RateLimiter) OTPRateLimit() fiber.Handler {
 
 func (rl *RateLimiter) UserRateLimit() fiber.Handler {
 	return func(c *fiber.Ctx) error {
+		// API clients are counted by ClientRateLimit instead
+		if _, ok := c.Locals("api_client").(*entities.APIClient); ok {
+			return c.Next()
+		}
+
 		clientIP := c.IP()
-		key := fmt.Sprintf("rate_limit:user:%s", clientIP)
+		key := fmt.Sprintf("rate_limit:user:%d:%s", entities.TenantIDFromContext(c.UserContext()), clientIP)
 
-		if rl.config == nil || rl.config.RateLimiting.User.Requests == 0 {
+		limits := rl.limits(c.UserContext())
+		if limits.User.Requests == 0 {
 			rl.logger.Error(c.UserContext(), "Rate limiting config not properly initialized")
 			return c.Next()
 		}
 
-		limit := rl.config.RateLimiting.User.Requests
-		duration := rl.config.RateLimiting.User.Duration
+		limit := limits.User.Requests
+		duration := limits.User.Duration
 
 		if err := rl.checkRateLimit(c.UserContext(), key, limit, duration, clientIP, "user"); err != nil {
 			c.Set("Retry-After", strconv.FormatInt(int64(duration.Seconds()), 10))
@@ -179,6 +196,48 @@ func (rl *RateLimiter) UserRateLimit() fiber.Handler {
 	}
 }
 
+// ClientRateLimit counts the requests of an API client let through by Auth
+// against its own rate limit, or the default one. Users are not counted.
+func (rl *RateLimiter) ClientRateLimit() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		client, ok := c.Locals("api_client").(*entities.APIClient)
+		if !ok {
+			return c.Next()
+		}
+
+		limits := rl.limits(c.UserContext())
+		if limits.Client.Requests == 0 {
+			rl.logger.Error(c.UserContext(), "Rate limiting config not properly initialized")
+			return c.Next()
+		}
+
+		key := fmt.Sprintf("rate_limit:client:%d:%s", entities.TenantIDFromContext(c.UserContext()), client.ClientID)
+		limit := limits.Client.Requests
+		if client.RateLimit > 0 {
+			limit = client.RateLimit
+		}
+		duration := limits.Client.Duration
+
+		if err := rl.checkRateLimit(c.UserContext(), key, limit, duration, client.ClientID, "client"); err != nil {
+			c.Set("Retry-After", strconv.FormatInt(int64(duration.Seconds()), 10))
+			return c.Status(429).JSON(dto.ErrorResponse{
+				Error:   "rate_limit_exceeded",
+				Message: err.Error(),
+			})
+		}
+		return c.Next()
+	}
+}
+
+// limits returns the rate limits of the request's tenant. Counters are kept
+// per tenant as well, so tenants never use up each other's budget.
+func (rl *RateLimiter) limits(ctx context.Context) *config.RateLimitingConfig {
+	if rl.tenantService != nil {
+		return &rl.tenantService.Config(entities.TenantIDFromContext(ctx)).RateLimiting
+	}
+	return &rl.config.RateLimiting
+}
+
 func (rl *RateLimiter) checkRateLimit(ctx context.Context, key string, limit int, duration time.Duration, identifier, endpointType string) error {
 	current, err := rl.redisClient.Get(ctx, key)
 	if err != nil && current != "" {
@@ -198,7 +257,7 @@ func (rl *RateLimiter) checkRateLimit(ctx context.Context, key string, limit int
 			logger.F("duration", duration))
 
 		if rl.metrics != nil {
-			rl.metrics.RecordRateLimitExceeded(endpointType, identifier)
+			rl.metrics.RecordRateLimitExceeded(entities.TenantIDFromContext(ctx), endpointType, identifier)
 		}
 
 		return fmt.Errorf("too many requests. Limit: %d requests per %v. Please try again later.", limit, duration)
@@ -215,35 +274,33 @@ func (rl *RateLimiter) checkRateLimit(ctx context.Context, key string, limit int
 
 func (rl *RateLimiter) GetRateLimitHeaders(ctx context.Context, identifier, endpointType string) map[string]string {
 	headers := make(map[string]string)
-
-	if rl.config == nil {
-		return headers
-	}
+	limits := rl.limits(ctx)
+	tenantID := entities.TenantIDFromContext(ctx)
 
 	switch endpointType {
 	case "global":
-		if rl.config.RateLimiting.Global.Requests > 0 {
-			headers["X-RateLimit-Limit"] = strconv.Itoa(rl.config.RateLimiting.Global.Requests)
-			headers["X-RateLimit-Remaining"] = rl.getRemainingRequests(ctx, fmt.Sprintf("rate_limit:global:%s", identifier), rl.config.RateLimiting.Global.Requests)
-			headers["X-RateLimit-Reset"] = rl.getResetTime(ctx, fmt.Sprintf("rate_limit:global:%s", identifier))
+		if limits.Global.Requests > 0 {
+			headers["X-RateLimit-Limit"] = strconv.Itoa(limits.Global.Requests)
+			headers["X-RateLimit-Remaining"] = rl.getRemainingRequests(ctx, fmt.Sprintf("rate_limit:global:%d:%s", tenantID, identifier), limits.Global.Requests)
+			headers["X-RateLimit-Reset"] = rl.getResetTime(ctx, fmt.Sprintf("rate_limit:global:%d:%s", tenantID, identifier))
 		}
 	case "auth":
-		if rl.config.RateLimiting.Auth.Requests > 0 {
-			headers["X-RateLimit-Limit"] = strconv.Itoa(rl.config.RateLimiting.Auth.Requests)
-			headers["X-RateLimit-Remaining"] = rl.getRemainingRequests(ctx, fmt.Sprintf("rate_limit:auth:%s", identifier), rl.config.RateLimiting.Auth.Requests)
-			headers["X-RateLimit-Reset"] = rl.getResetTime(ctx, fmt.Sprintf("rate_limit:auth:%s", identifier))
+		if limits.Auth.Requests > 0 {
+			headers["X-RateLimit-Limit"] = strconv.Itoa(limits.Auth.Requests)
+			headers["X-RateLimit-Remaining"] = rl.getRemainingRequests(ctx, fmt.Sprintf("rate_limit:auth:%d:%s", tenantID, identifier), limits.Auth.Requests)
+			headers["X-RateLimit-Reset"] = rl.getResetTime(ctx, fmt.Sprintf("rate_limit:auth:%d:%s", tenantID, identifier))
 		}
 	case "otp":
-		if rl.config.RateLimiting.OTP.Requests > 0 {
-			headers["X-RateLimit-Limit"] = strconv.Itoa(rl.config.RateLimiting.OTP.Requests)
-			headers["X-RateLimit-Remaining"] = rl.getRemainingRequests(ctx, fmt.Sprintf("rate_limit:otp:%s", identifier), rl.config.RateLimiting.OTP.Requests)
-			headers["X-RateLimit-Reset"] = rl.getResetTime(ctx, fmt.Sprintf("rate_limit:otp:%s", identifier))
+		if limits.OTP.Requests > 0 {
+			headers["X-RateLimit-Limit"] = strconv.Itoa(limits.OTP.Requests)
+			headers["X-RateLimit-Remaining"] = rl.getRemainingRequests(ctx, fmt.Sprintf("rate_limit:otp:%d:%s", tenantID, identifier), limits.OTP.Requests)
+			headers["X-RateLimit-Reset"] = rl.getResetTime(ctx, fmt.Sprintf("rate_limit:otp:%d:%s", tenantID, identifier))
 		}
 	case "user":
-		if rl.config.RateLimiting.User.Requests > 0 {
-			headers["X-RateLimit-Limit"] = strconv.Itoa(rl.config.RateLimiting.User.Requests)
-			headers["X-RateLimit-Remaining"] = rl.getRemainingRequests(ctx, fmt.Sprintf("rate_limit:user:%s", identifier), rl.config.RateLimiting.User.Requests)
-			headers["X-RateLimit-Reset"] = rl.getResetTime(ctx, fmt.Sprintf("rate_limit:user:%s", identifier))
+		if limits.User.Requests > 0 {
+			headers["X-RateLimit-Limit"] = strconv.Itoa(limits.User.Requests)
+			headers["X-RateLimit-Remaining"] = rl.getRemainingRequests(ctx, fmt.Sprintf("rate_limit:user:%d:%s", tenantID, identifier), limits.User.Requests)
+			headers["X-RateLimit-Reset"] = rl.getResetTime(ctx, fmt.Sprintf("rate_limit:user:%d:%s", tenantID, identifier))
 		}
 	}
 
@@ -5,7 +5,9 @@ import (
 	"time"
 
 	_ "otp-server/docs" // Import generated Swagger docs
+	"otp-server/internal/domain/entities"
 	"otp-server/internal/infrastructure/config"
+	"otp-server/internal/infrastructure/keyring"
 	"otp-server/internal/infrastructure/metrics"
 	"otp-server/internal/interfaces/http/handlers"
 	"otp-server/internal/interfaces/http/middleware"
@@ -36,7 +38,10 @@ func NewRouter(handlers *handlers.Handlers, mw *middleware.Middleware, cfg *conf
 		metricsService = mw.GetMetricsService()
 	}
 
-	rateLimiter := middleware.NewRateLimitMiddleware(cfg, mw.GetLogger(), mw.GetRedisClient(), metricsService)
+	// Everything after this runs within the tenant of the request
+	app.Use(mw.Tenant())
+
+	rateLimiter := middleware.NewRateLimitMiddleware(cfg, mw.GetTenantService(), mw.GetLogger(), mw.GetRedisClient(), metricsService)
 	app.Use(rateLimiter.Global())
 
 	app.Use(rateLimiter.AddRateLimitHeaders())
@@ -51,21 +56,136 @@ func NewRouter(handlers *handlers.Handlers, mw *middleware.Middleware, cfg *conf
 
 	app.Get("/metrics", adaptor.HTTPHandler(promhttp.Handler()))
 
+	app.Get("/.well-known/jwks.json", handlers.WellKnownHandler.JWKS)
+
+	// Clients verify ID tokens with the JWKS, so OpenID Connect logins are
+	// only offered with an asymmetric algorithm; HS256 would sign them with
+	// the server's own secret
+	openID := cfg.JWT.Algorithm != keyring.AlgorithmHS256
+	if openID {
+		app.Get("/.well-known/openid-configuration", handlers.OIDCHandler.Discovery)
+	}
+
+	oauth := app.Group("/oauth")
+	// Resource servers introspect on every request they serve, so these two
+	// are only subject to the global limit
+	oauth.Post("/introspect", handlers.OIDCHandler.Introspect)
+	oauth.Post("/revoke", handlers.OIDCHandler.Revoke)
+
+	oauth.Use(rateLimiter.Auth())
+	oauth.Post("/token", handlers.OIDCHandler.Token)
+	if openID {
+		oauth.Get("/authorize", handlers.OIDCHandler.Authorize)
+		oauth.Post("/authorize", rateLimiter.OTP(), handlers.OIDCHandler.SendLoginOTP)
+		oauth.Post("/authorize/verify", handlers.OIDCHandler.VerifyLoginOTP)
+		oauth.Post("/authorize/totp", handlers.OIDCHandler.VerifyLoginTOTP)
+		oauth.Get("/userinfo", handlers.OIDCHandler.UserInfo)
+		oauth.Post("/userinfo", handlers.OIDCHandler.UserInfo)
+	}
+
 	v1 := app.Group("/api/v1")
 
 	auth := v1.Group("/auth")
 	auth.Use(rateLimiter.Auth())
 	auth.Post("/send-otp", rateLimiter.OTP(), handlers.AuthHandler.SendOTP)
+	auth.Post("/resend-otp", handlers.AuthHandler.ResendOTP)
 	auth.Post("/verify-otp", handlers.AuthHandler.VerifyOTP)
+	auth.Post("/verify-totp", handlers.AuthHandler.VerifyTOTP)
+	auth.Post("/refresh", handlers.AuthHandler.RefreshToken)
+	auth.Get("/otp/:verification_id", handlers.AuthHandler.GetOTPStatus)
+
+	webhooks := v1.Group("/webhooks")
+	webhooks.Post("/delivery/:provider", handlers.WebhookHandler.DeliveryReceipt)
+
+	// Routes open to API clients holding the listed scopes as well as to
+	// users. They must be registered before the protected group, which
+	// would otherwise only let users through.
+	v1.Post("/otp/login", mw.Auth(entities.ScopeOTPSend), mw.RequirePermission(entities.PermissionOTPSend), rateLimiter.Client(), rateLimiter.OTP(), handlers.AuthHandler.SendLoginOTP)
+	v1.Get("/users/search", mw.Auth(entities.ScopeUsersRead), mw.RequireConfirmedPhone(), rateLimiter.Client(), rateLimiter.User(), handlers.UserHandler.SearchUsers)
+
+	// User administration; every change invalidates the user cache and
+	// publishes a user_admin_action event
+	adminUsers := v1.Group("/admin/users")
+	adminUsers.Get("", mw.Auth(entities.ScopeUsersRead), mw.RequireConfirmedPhone(), mw.RequirePermission(entities.PermissionUsersRead), rateLimiter.Client(), handlers.AdminHandler.ListUsers)
+	adminUsers.Get("/:id", mw.Auth(entities.ScopeUsersRead), mw.RequireConfirmedPhone(), mw.RequirePermission(entities.PermissionUsersRead), rateLimiter.Client(), handlers.AdminHandler.GetUser)
+	adminUsers.Post("/:id/activate", mw.Auth(entities.ScopeUsersAdmin), mw.RequireConfirmedPhone(), mw.RequirePermission(entities.PermissionUsersWrite), rateLimiter.Client(), handlers.AdminHandler.ActivateUser)
+	adminUsers.Post("/:id/deactivate", mw.Auth(entities.ScopeUsersAdmin), mw.RequireConfirmedPhone(), mw.RequirePermission(entities.PermissionUsersWrite), rateLimiter.Client(), handlers.AdminHandler.DeactivateUser)
+	adminUsers.Put("/:id/status", mw.Auth(entities.ScopeUsersAdmin), mw.RequireConfirmedPhone(), mw.RequirePermission(entities.PermissionUsersWrite), rateLimiter.Client(), handlers.AdminHandler.SetUserStatus)
+	adminUsers.Get("/:id/status-history", mw.Auth(entities.ScopeUsersRead), mw.RequireConfirmedPhone(), mw.RequirePermission(entities.PermissionLogsRead), rateLimiter.Client(), handlers.AdminHandler.GetUserStatusHistory)
+	adminUsers.Put("/:id/role", mw.Auth(entities.ScopeUsersAdmin), mw.RequireConfirmedPhone(), mw.RequirePermission(entities.PermissionRolesWrite), rateLimiter.Client(), handlers.AdminHandler.ChangeRole)
+	adminUsers.Post("/:id/revoke-tokens", mw.Auth(entities.ScopeUsersAdmin), mw.RequireConfirmedPhone(), mw.RequirePermission(entities.PermissionUsersWrite), rateLimiter.Client(), handlers.AdminHandler.RevokeUserTokens)
 
 	protected := v1.Group("")
 	protected.Use(mw.Auth())
 
+	// The only routes open to users who still have to confirm a phone number
+	// after a recovery code login: logging out and the confirmation itself
+	protected.Post("/auth/logout", handlers.AuthHandler.Logout)
+	protected.Post("/auth/logout-all", handlers.AuthHandler.LogoutAll)
+
+	phone := protected.Group("/users/phone/confirm")
+	phone.Post("/send", rateLimiter.OTP(), handlers.RecoveryHandler.SendPhoneConfirmation)
+	phone.Post("/verify", handlers.RecoveryHandler.ConfirmPhone)
+
+	protected.Use(mw.RequireConfirmedPhone())
+
+	protected.Post("/auth/step-up", rateLimiter.OTP(), handlers.AuthHandler.SendStepUpOTP)
+	protected.Post("/auth/step-up/verify", handlers.AuthHandler.VerifyStepUp)
+
+	// Sensitive actions ask users who logged in too long ago to step up
+	recentAuth := mw.RequireRecentAuth(cfg.JWT.StepUpMaxAge)
+
+	otp := protected.Group("/otp")
+	otp.Post("/send", rateLimiter.OTP(), handlers.AuthHandler.SendConfirmationOTP)
+	otp.Post("/verify", handlers.AuthHandler.VerifyConfirmationOTP)
+	otp.Post("/transaction", rateLimiter.OTP(), handlers.AuthHandler.SendTransactionOTP)
+	otp.Post("/transaction/verify", handlers.AuthHandler.VerifyTransactionOTP)
+
 	users := protected.Group("/users")
 	users.Use(rateLimiter.User()) // Rate limiting for user operations
 	users.Get("/profile", handlers.UserHandler.GetProfile)
 	users.Put("/profile", handlers.UserHandler.UpdateProfile)
-	users.Get("/search", handlers.UserHandler.SearchUsers)
+	users.Get("/sessions", handlers.SessionHandler.ListSessions)
+	users.Delete("/sessions/:id", handlers.SessionHandler.RevokeSession)
+
+	// Changing the number takes a recent login, a code from the new number
+	// and, unless the tenant turned it off, one from the current number
+	users.Post("/phone/change", recentAuth, rateLimiter.OTP(), handlers.PhoneHandler.StartPhoneChange)
+	users.Post("/phone/change/verify", recentAuth, handlers.PhoneHandler.ChangePhoneNumber)
+
+	mfa := users.Group("/mfa")
+	mfa.Get("/factors", handlers.MFAHandler.ListFactors)
+	mfa.Delete("/factors/:id", recentAuth, handlers.MFAHandler.RemoveFactor)
+	mfa.Post("/totp", handlers.MFAHandler.StartTOTPEnrollment)
+	mfa.Post("/totp/:id/confirm", handlers.MFAHandler.ConfirmTOTPEnrollment)
+	mfa.Get("/recovery-codes", handlers.RecoveryHandler.GetCodesStatus)
+	mfa.Post("/recovery-codes", recentAuth, handlers.RecoveryHandler.GenerateCodes)
+
+	// Each admin route requires the permission of its own; users get them
+	// through their role
+	admin := protected.Group("/admin")
+
+	// OAuth clients are shared by all tenants and can introspect and revoke
+	// the tokens of any of them, so only the default tenant may manage them
+	admin.Post("/oauth/clients", mw.RequireDefaultTenant(), mw.RequirePermission(entities.PermissionClientsWrite), handlers.OAuthClientHandler.RegisterClient)
+	admin.Get("/oauth/clients", mw.RequireDefaultTenant(), mw.RequirePermission(entities.PermissionClientsRead), handlers.OAuthClientHandler.ListClients)
+	admin.Delete("/oauth/clients/:client_id", mw.RequireDefaultTenant(), mw.RequirePermission(entities.PermissionClientsWrite), handlers.OAuthClientHandler.DeactivateClient)
+
+	admin.Post("/api-clients", mw.RequirePermission(entities.PermissionClientsWrite), handlers.APIClientHandler.RegisterClient)
+	admin.Get("/api-clients", mw.RequirePermission(entities.PermissionClientsRead), handlers.APIClientHandler.ListClients)
+	admin.Delete("/api-clients/:client_id", mw.RequirePermission(entities.PermissionClientsWrite), handlers.APIClientHandler.DeactivateClient)
+
+	// Roles are shared by all tenants, so only the default tenant may
+	// change them
+	admin.Get("/roles", mw.RequirePermission(entities.PermissionRolesRead), handlers.RoleHandler.ListRoles)
+	admin.Get("/permissions", mw.RequirePermission(entities.PermissionRolesRead), handlers.RoleHandler.ListPermissions)
+	admin.Put("/roles/:name", mw.RequireDefaultTenant(), mw.RequirePermission(entities.PermissionRolesWrite), handlers.RoleHandler.SaveRole)
+
+	tenants := admin.Group("/tenants")
+	tenants.Use(mw.RequireDefaultTenant())
+	tenants.Post("", mw.RequirePermission(entities.PermissionTenantsWrite), handlers.TenantHandler.CreateTenant)
+	tenants.Get("", mw.RequirePermission(entities.PermissionTenantsRead), handlers.TenantHandler.ListTenants)
+	tenants.Put("/:id", mw.RequirePermission(entities.PermissionTenantsWrite), handlers.TenantHandler.UpdateTenant)
 
 	if cfg.Server.Environment == "development" {
 		app.Get("/swagger/*", fiberSwagger.WrapHandler)
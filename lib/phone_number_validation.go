@@ -3,6 +3,7 @@ package lib
 import (
 	"fmt"
 	"regexp"
+	"strings"
 )
 
 func ValidatePhoneNumber(phone string) error {
@@ -12,3 +13,40 @@ func ValidatePhoneNumber(phone string) error {
 	}
 	return nil
 }
+
+// MaskPhoneNumber hides all but the country prefix and the last four digits
+func MaskPhoneNumber(phone string) string {
+	if len(phone) <= 6 {
+		return phone
+	}
+	return phone[:2] + strings.Repeat("*", len(phone)-6) + phone[len(phone)-4:]
+}
+
+// twoDigitCallingCodes lists the two-digit E.164 country calling codes;
+// codes starting with 1 or 7 have one digit and all others three
+var twoDigitCallingCodes = map[string]bool{
+	"20": true, "27": true, "30": true, "31": true, "32": true, "33": true, "34": true,
+	"36": true, "39": true, "40": true, "41": true, "43": true, "44": true, "45": true,
+	"46": true, "47": true, "48": true, "49": true, "51": true, "52": true, "53": true,
+	"54": true, "55": true, "56": true, "57": true, "58": true, "60": true, "61": true,
+	"62": true, "63": true, "64": true, "65": true, "66": true, "81": true, "82": true,
+	"84": true, "86": true, "90": true, "91": true, "92": true, "93": true, "94": true,
+	"95": true, "98": true,
+}
+
+// CountryCallingCode returns the country calling code of an E.164 phone
+// number, e.g. "+98", or "unknown"
+func CountryCallingCode(phone string) string {
+	digits := strings.TrimPrefix(phone, "+")
+	if len(digits) < 4 || ValidatePhoneNumber(phone) != nil {
+		return "unknown"
+	}
+
+	switch {
+	case digits[0] == '1' || digits[0] == '7':
+		return "+" + digits[:1]
+	case twoDigitCallingCodes[digits[:2]]:
+		return "+" + digits[:2]
+	}
+	return "+" + digits[:3]
+}
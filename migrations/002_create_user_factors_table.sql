@@ -0,0 +1,37 @@
+-- Migration: Create user_factors table
+-- Created: 2024-02-01
+-- Description: Second authentication factors (TOTP authenticator apps) enrolled by users
+
+-- Create user_factors table
+CREATE TABLE user_factors (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    type VARCHAR(20) NOT NULL DEFAULT 'totp',
+    name VARCHAR(64) NOT NULL,
+    secret_encrypted BYTEA NOT NULL,
+    confirmed_at TIMESTAMP WITH TIME ZONE,
+    last_used_step BIGINT NOT NULL DEFAULT 0,
+    last_used_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    CONSTRAINT user_factors_type_check CHECK (type IN ('totp'))
+);
+
+-- Create indexes for better performance
+CREATE INDEX idx_user_factors_user_id ON user_factors(user_id);
+CREATE INDEX idx_user_factors_confirmed ON user_factors(user_id) WHERE confirmed_at IS NOT NULL;
+
+CREATE TRIGGER update_user_factors_updated_at
+    BEFORE UPDATE ON user_factors
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+-- Add comment to table
+COMMENT ON TABLE user_factors IS 'Second authentication factors enrolled by users';
+COMMENT ON COLUMN user_factors.user_id IS 'User the factor belongs to';
+COMMENT ON COLUMN user_factors.type IS 'Factor type: totp';
+COMMENT ON COLUMN user_factors.name IS 'User supplied label, e.g. the device name';
+COMMENT ON COLUMN user_factors.secret_encrypted IS 'TOTP secret encrypted with AES-256-GCM (nonce prepended)';
+COMMENT ON COLUMN user_factors.confirmed_at IS 'When enrollment was confirmed with a first code; unconfirmed factors are not used at login';
+COMMENT ON COLUMN user_factors.last_used_step IS 'Last accepted TOTP time step; codes for this step or earlier are rejected as replays';
+COMMENT ON COLUMN user_factors.last_used_at IS 'When a code was last accepted';
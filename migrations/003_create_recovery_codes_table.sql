@@ -0,0 +1,26 @@
+-- Migration: Create recovery_codes table
+-- Created: 2024-02-15
+-- Description: Single-use recovery codes for users who lost their phone, and the flag forcing a new phone confirmation after one is used
+
+-- Users who logged in with a recovery code must confirm a phone number again
+ALTER TABLE users ADD COLUMN phone_confirmation_required BOOLEAN NOT NULL DEFAULT false;
+
+COMMENT ON COLUMN users.phone_confirmation_required IS 'Whether the user must confirm a new phone number before using the API, set after a recovery code login';
+
+-- Create recovery_codes table
+CREATE TABLE recovery_codes (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    code_hash VARCHAR(64) NOT NULL,
+    used_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+-- Create indexes for better performance
+CREATE UNIQUE INDEX idx_recovery_codes_user_code ON recovery_codes(user_id, code_hash);
+
+-- Add comment to table
+COMMENT ON TABLE recovery_codes IS 'Single-use account recovery codes; regenerating replaces the whole set';
+COMMENT ON COLUMN recovery_codes.user_id IS 'User the code belongs to';
+COMMENT ON COLUMN recovery_codes.code_hash IS 'Keyed SHA-256 hash of the normalized code; the code itself is only shown once';
+COMMENT ON COLUMN recovery_codes.used_at IS 'When the code was redeemed; redeemed codes are never accepted again';
@@ -0,0 +1,29 @@
+-- Migration: Create refresh_tokens table
+-- Created: 2024-03-01
+-- Description: Opaque refresh tokens, rotated on every use and grouped into families for reuse detection
+
+-- Create refresh_tokens table
+CREATE TABLE refresh_tokens (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    family_id UUID NOT NULL,
+    token_hash VARCHAR(64) NOT NULL UNIQUE,
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    rotated_at TIMESTAMP WITH TIME ZONE,
+    revoked_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+-- Create indexes for better performance
+CREATE INDEX idx_refresh_tokens_family_id ON refresh_tokens(family_id);
+CREATE INDEX idx_refresh_tokens_user_id ON refresh_tokens(user_id);
+CREATE INDEX idx_refresh_tokens_expires_at ON refresh_tokens(expires_at);
+
+-- Add comment to table
+COMMENT ON TABLE refresh_tokens IS 'Refresh tokens; each use rotates the token into a new one of the same family';
+COMMENT ON COLUMN refresh_tokens.user_id IS 'User the token was issued to';
+COMMENT ON COLUMN refresh_tokens.family_id IS 'Tokens descending from the same login; a reused token revokes the whole family';
+COMMENT ON COLUMN refresh_tokens.token_hash IS 'SHA-256 of the token; the token itself is never stored';
+COMMENT ON COLUMN refresh_tokens.expires_at IS 'When the token stops being accepted';
+COMMENT ON COLUMN refresh_tokens.rotated_at IS 'When the token was exchanged for its successor; presenting it again signals theft';
+COMMENT ON COLUMN refresh_tokens.revoked_at IS 'When the token was revoked';
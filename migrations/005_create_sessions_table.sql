@@ -0,0 +1,40 @@
+-- Migration: Create sessions table
+-- Created: 2024-03-08
+-- Description: One session per login, listing the device it came from; its refresh token family shares its ID
+
+-- Create sessions table
+CREATE TABLE sessions (
+    id UUID PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    device_name VARCHAR(64),
+    user_agent VARCHAR(512),
+    ip_address VARCHAR(45),
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    last_used_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    revoked_at TIMESTAMP WITH TIME ZONE
+);
+
+-- Backfill a session for every refresh token family issued before sessions existed
+INSERT INTO sessions (id, user_id, created_at, last_used_at, expires_at, revoked_at)
+SELECT family_id, user_id, MIN(created_at), MAX(created_at), MAX(expires_at),
+       CASE WHEN BOOL_AND(revoked_at IS NOT NULL) THEN MAX(revoked_at) END
+FROM refresh_tokens
+GROUP BY family_id, user_id;
+
+ALTER TABLE refresh_tokens
+    ADD CONSTRAINT fk_refresh_tokens_session FOREIGN KEY (family_id) REFERENCES sessions(id) ON DELETE CASCADE;
+
+-- Create indexes for better performance
+CREATE INDEX idx_sessions_user_id ON sessions(user_id);
+CREATE INDEX idx_sessions_expires_at ON sessions(expires_at);
+
+-- Add comment to table
+COMMENT ON TABLE sessions IS 'Logins of a user, each on one device; revoking a session revokes its tokens';
+COMMENT ON COLUMN sessions.id IS 'Session ID; also the family_id of its refresh tokens and the sid claim of its access tokens';
+COMMENT ON COLUMN sessions.device_name IS 'Device name given by the client at login';
+COMMENT ON COLUMN sessions.user_agent IS 'User-Agent of the login request';
+COMMENT ON COLUMN sessions.ip_address IS 'Client IP of the login request';
+COMMENT ON COLUMN sessions.last_used_at IS 'When the session last refreshed its tokens';
+COMMENT ON COLUMN sessions.expires_at IS 'When the latest refresh token of the session expires';
+COMMENT ON COLUMN sessions.revoked_at IS 'When the session was logged out, evicted or revoked';
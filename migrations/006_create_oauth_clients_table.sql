@@ -0,0 +1,39 @@
+-- Migration: Create oauth_clients table
+-- Created: 2024-03-15
+-- Description: Applications logging users in through the OpenID Connect provider; sessions remember the client they belong to
+
+-- Create oauth_clients table
+CREATE TABLE oauth_clients (
+    id SERIAL PRIMARY KEY,
+    client_id VARCHAR(64) NOT NULL UNIQUE,
+    client_secret_hash VARCHAR(64),
+    name VARCHAR(100) NOT NULL,
+    redirect_uris TEXT[] NOT NULL,
+    scopes TEXT[] NOT NULL DEFAULT ARRAY['openid', 'phone', 'profile'],
+    is_active BOOLEAN NOT NULL DEFAULT true,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+-- Sessions started through a client carry its ID and the granted scope
+ALTER TABLE sessions
+    ADD COLUMN client_id VARCHAR(64) REFERENCES oauth_clients(client_id) ON DELETE CASCADE,
+    ADD COLUMN scope TEXT;
+
+-- Create indexes for better performance
+CREATE INDEX idx_sessions_client_id ON sessions(client_id);
+
+-- Create trigger to automatically update updated_at
+CREATE TRIGGER update_oauth_clients_updated_at
+    BEFORE UPDATE ON oauth_clients
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+-- Add comment to table
+COMMENT ON TABLE oauth_clients IS 'OpenID Connect clients allowed to log users in';
+COMMENT ON COLUMN oauth_clients.client_id IS 'Public client identifier';
+COMMENT ON COLUMN oauth_clients.client_secret_hash IS 'SHA-256 of the client secret; NULL for public clients, which must use PKCE';
+COMMENT ON COLUMN oauth_clients.redirect_uris IS 'Exact redirect URIs the client may use';
+COMMENT ON COLUMN oauth_clients.scopes IS 'Scopes the client may request';
+COMMENT ON COLUMN sessions.client_id IS 'Client the session was started through; NULL for direct logins';
+COMMENT ON COLUMN sessions.scope IS 'Scope granted to the client';
@@ -0,0 +1,29 @@
+-- Migration: Create api_clients table
+-- Created: 2024-03-22
+-- Description: Backend services calling the API without a user, with an API key or the client credentials grant
+
+-- Create api_clients table
+CREATE TABLE api_clients (
+    id SERIAL PRIMARY KEY,
+    client_id VARCHAR(64) NOT NULL UNIQUE,
+    secret_hash VARCHAR(64) NOT NULL UNIQUE,
+    name VARCHAR(100) NOT NULL,
+    scopes TEXT[] NOT NULL,
+    rate_limit INTEGER CHECK (rate_limit > 0),
+    is_active BOOLEAN NOT NULL DEFAULT true,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+-- Create trigger to automatically update updated_at
+CREATE TRIGGER update_api_clients_updated_at
+    BEFORE UPDATE ON api_clients
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+-- Add comment to table
+COMMENT ON TABLE api_clients IS 'Backend services allowed to call the API on their own behalf';
+COMMENT ON COLUMN api_clients.client_id IS 'Public client identifier';
+COMMENT ON COLUMN api_clients.secret_hash IS 'SHA-256 of the secret, which is both the API key and the client secret';
+COMMENT ON COLUMN api_clients.scopes IS 'Scopes granted to the client: otp:send, users:read, users:admin';
+COMMENT ON COLUMN api_clients.rate_limit IS 'Requests allowed per rate limit window; NULL for the default';
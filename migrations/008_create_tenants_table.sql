@@ -0,0 +1,43 @@
+-- Migration: Create tenants table
+-- Created: 2024-03-29
+-- Description: Isolated tenants (brands) sharing one deployment, each with its own users and settings
+
+-- Create tenants table
+CREATE TABLE tenants (
+    id SERIAL PRIMARY KEY,
+    slug VARCHAR(64) NOT NULL UNIQUE CHECK (slug ~ '^[a-z0-9][a-z0-9-]*$'),
+    name VARCHAR(100) NOT NULL,
+    hosts TEXT[] NOT NULL DEFAULT '{}',
+    settings JSONB NOT NULL DEFAULT '{}',
+    is_active BOOLEAN NOT NULL DEFAULT true,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+-- Create trigger to automatically update updated_at
+CREATE TRIGGER update_tenants_updated_at
+    BEFORE UPDATE ON tenants
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+-- The default tenant owns every existing user and requests that name no tenant
+INSERT INTO tenants (id, slug, name) VALUES (1, 'default', 'Default');
+SELECT setval(pg_get_serial_sequence('tenants', 'id'), 1);
+
+-- Users are unique per tenant and phone number
+ALTER TABLE users ADD COLUMN tenant_id INTEGER NOT NULL DEFAULT 1 REFERENCES tenants(id);
+ALTER TABLE users DROP CONSTRAINT users_phone_number_key;
+ALTER TABLE users ADD CONSTRAINT users_tenant_id_phone_number_key UNIQUE (tenant_id, phone_number);
+
+-- API clients act within a single tenant
+ALTER TABLE api_clients ADD COLUMN tenant_id INTEGER NOT NULL DEFAULT 1 REFERENCES tenants(id);
+
+CREATE INDEX idx_api_clients_tenant_id ON api_clients(tenant_id);
+
+-- Add comment to table
+COMMENT ON TABLE tenants IS 'Brands served by the deployment; users, OTP settings and rate limits are kept per tenant';
+COMMENT ON COLUMN tenants.slug IS 'Tenant identifier sent in the X-Tenant header';
+COMMENT ON COLUMN tenants.hosts IS 'Request hosts resolved to the tenant';
+COMMENT ON COLUMN tenants.settings IS 'Overrides of OTP, message template and rate limiting settings, keyed by environment variable name';
+COMMENT ON COLUMN users.tenant_id IS 'Tenant the user belongs to';
+COMMENT ON COLUMN api_clients.tenant_id IS 'Tenant the client acts within';
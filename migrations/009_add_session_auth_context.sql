@@ -0,0 +1,15 @@
+-- Migration: Add authentication context to sessions
+-- Created: 2024-04-02
+-- Description: When and how the user of a session authenticated, carried in the auth_time, amr and acr claims of its tokens
+
+-- Sessions started before this migration count as authenticated when they were created
+ALTER TABLE sessions ADD COLUMN auth_time TIMESTAMP WITH TIME ZONE;
+UPDATE sessions SET auth_time = created_at;
+ALTER TABLE sessions ALTER COLUMN auth_time SET NOT NULL;
+ALTER TABLE sessions ALTER COLUMN auth_time SET DEFAULT NOW();
+
+ALTER TABLE sessions ADD COLUMN auth_methods TEXT[] NOT NULL DEFAULT '{}';
+
+-- Add comment to columns
+COMMENT ON COLUMN sessions.auth_time IS 'When the user authenticated to start the session';
+COMMENT ON COLUMN sessions.auth_methods IS 'Authentication methods used to start the session, as in the amr claim';
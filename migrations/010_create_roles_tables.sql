@@ -0,0 +1,80 @@
+-- Migration: Create roles, permissions and role_permissions tables
+-- Created: 2024-04-05
+-- Description: Role-based access control replacing the user_role enum, with the default roles seeded
+
+-- Create permissions table
+CREATE TABLE permissions (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(100) NOT NULL UNIQUE,
+    description VARCHAR(255) NOT NULL DEFAULT ''
+);
+
+-- Create roles table
+CREATE TABLE roles (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(50) NOT NULL UNIQUE CHECK (name ~ '^[a-z][a-z0-9_-]*$'),
+    description VARCHAR(255) NOT NULL DEFAULT '',
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+-- Create role_permissions table
+CREATE TABLE role_permissions (
+    role_id INTEGER NOT NULL REFERENCES roles(id) ON DELETE CASCADE,
+    permission_id INTEGER NOT NULL REFERENCES permissions(id) ON DELETE CASCADE,
+    PRIMARY KEY (role_id, permission_id)
+);
+
+CREATE INDEX idx_role_permissions_permission_id ON role_permissions(permission_id);
+
+-- Create trigger to automatically update updated_at
+CREATE TRIGGER update_roles_updated_at
+    BEFORE UPDATE ON roles
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+-- Seed the permissions checked by the API
+INSERT INTO permissions (name, description) VALUES
+    ('otp:send', 'Send login OTPs on behalf of users'),
+    ('users:read', 'List, view and search users'),
+    ('users:write', 'Activate and deactivate users and revoke their tokens'),
+    ('roles:read', 'List roles and permissions'),
+    ('roles:write', 'Change the roles of users and the permissions of roles'),
+    ('clients:read', 'List OAuth and API clients'),
+    ('clients:write', 'Register and deactivate OAuth and API clients'),
+    ('tenants:read', 'List tenants'),
+    ('tenants:write', 'Create and update tenants'),
+    ('logs:read', 'Read audit logs');
+
+-- Seed the default roles; the user and admin roles carry over from the enum
+INSERT INTO roles (name, description) VALUES
+    ('user', 'Regular user'),
+    ('admin', 'Administrator with every permission'),
+    ('support', 'Support staff viewing users'),
+    ('auditor', 'Auditor reading users, clients, tenants, roles and audit logs');
+
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT r.id, p.id FROM roles r CROSS JOIN permissions p WHERE r.name = 'admin';
+
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT r.id, p.id FROM roles r JOIN permissions p ON p.name IN ('users:read')
+WHERE r.name = 'support';
+
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT r.id, p.id FROM roles r JOIN permissions p ON p.name IN ('users:read', 'roles:read', 'clients:read', 'tenants:read', 'logs:read')
+WHERE r.name = 'auditor';
+
+-- Users name their role instead of holding an enum value
+ALTER TABLE users ALTER COLUMN role DROP DEFAULT;
+ALTER TABLE users ALTER COLUMN role TYPE VARCHAR(50) USING role::text;
+ALTER TABLE users ALTER COLUMN role SET DEFAULT 'user';
+ALTER TABLE users ADD CONSTRAINT users_role_fkey FOREIGN KEY (role) REFERENCES roles(name) ON UPDATE CASCADE;
+
+DROP TYPE user_role;
+
+-- Add comment to table
+COMMENT ON TABLE permissions IS 'Permissions checked by the API routes';
+COMMENT ON TABLE roles IS 'Roles granting permissions to the users holding them, shared by every tenant';
+COMMENT ON TABLE role_permissions IS 'Permissions granted by each role';
+COMMENT ON COLUMN roles.name IS 'Role identifier stored in users.role and the role claim of access tokens';
+COMMENT ON COLUMN users.role IS 'Name of the role granting the user permissions';
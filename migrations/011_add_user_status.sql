@@ -0,0 +1,44 @@
+-- Migration: Add account status to users
+-- Created: 2024-04-08
+-- Description: Account status lifecycle (active, suspended, banned, pending deletion, deactivated) replacing is_active, with an audit trail of status changes
+
+ALTER TABLE users ADD COLUMN status VARCHAR(20) NOT NULL DEFAULT 'active'
+    CHECK (status IN ('active', 'suspended', 'banned', 'pending_deletion', 'deactivated'));
+ALTER TABLE users ADD COLUMN status_reason VARCHAR(500) NOT NULL DEFAULT '';
+ALTER TABLE users ADD COLUMN status_until TIMESTAMP WITH TIME ZONE;
+ALTER TABLE users ADD COLUMN status_changed_by VARCHAR(100) NOT NULL DEFAULT '';
+ALTER TABLE users ADD COLUMN status_changed_at TIMESTAMP WITH TIME ZONE;
+
+-- Only suspensions end by themselves
+ALTER TABLE users ADD CONSTRAINT users_status_until_check CHECK ((status = 'suspended') = (status_until IS NOT NULL));
+
+-- Users deactivated before this migration keep their status
+UPDATE users SET status = 'deactivated' WHERE NOT is_active;
+
+DROP INDEX idx_users_is_active;
+ALTER TABLE users DROP COLUMN is_active;
+
+CREATE INDEX idx_users_status ON users(status);
+
+-- Create user_status_changes table
+CREATE TABLE user_status_changes (
+    id BIGSERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    previous_status VARCHAR(20) NOT NULL,
+    status VARCHAR(20) NOT NULL,
+    reason VARCHAR(500) NOT NULL DEFAULT '',
+    until TIMESTAMP WITH TIME ZONE,
+    actor VARCHAR(100) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_user_status_changes_user_id ON user_status_changes(user_id, created_at);
+
+-- Add comment to table
+COMMENT ON COLUMN users.status IS 'Account status: active, suspended, banned, pending_deletion or deactivated; only active users can log in';
+COMMENT ON COLUMN users.status_reason IS 'Why the status was set';
+COMMENT ON COLUMN users.status_until IS 'When a suspension lifts by itself';
+COMMENT ON COLUMN users.status_changed_by IS 'Who set the status: user:<id> or api_client:<client_id>';
+COMMENT ON COLUMN users.status_changed_at IS 'When the status was set';
+COMMENT ON TABLE user_status_changes IS 'Audit trail of account status changes';
+COMMENT ON COLUMN user_status_changes.actor IS 'Who changed the status: user:<id> or api_client:<client_id>';